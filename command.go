@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+// runCommand runs the sub command and returns the exit code
+func runCommand(args []string) int {
+	switch args[0] {
+	case "migrate":
+		return runMigrate(args[1:])
+	case "export":
+		return runExport(args[1:])
+	case "import":
+		return runImport(args[1:])
+	case "workspace":
+		return runWorkspace(args[1:])
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %s\n", args[0])
+		return 1
+	}
+}
+
+// runMigrate migrates the schema of the database given by -datastore.database.
+// In the dynamic mode, it has to be run for each workspace database.
+func runMigrate(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintf(os.Stderr, "Usage: %s migrate up|down [steps]|status\n", config.AppName)
+		return 1
+	}
+
+	cfg := config.Config()
+	// The workspace database is migrated explicitly instead of automatically
+	cfg.Datastore.Dynamic = false
+	ctx := context.WithValue(context.Background(), config.CtxWorkspace, cfg.Datastore.Database)
+	p := datastore.Provider(ctx)
+	defer p.Close()
+
+	switch args[0] {
+	case "up":
+		if err := p.CreateTables(); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			var err error
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				fmt.Fprintf(os.Stderr, "Invalid steps %s\n", args[1])
+				return 1
+			}
+		}
+		if err := p.MigrateDown(steps); err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+	case "status":
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown migrate command %s\n", args[0])
+		return 1
+	}
+
+	schemaMigrations, err := p.SelectSchemaMigrations()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tAPPLIED\tDESCRIPTION")
+	for _, sm := range schemaMigrations {
+		applied := "pending"
+		if sm.IsApplied() {
+			applied = time.Unix(sm.Applied, 0).Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", sm.Version, applied, sm.Description)
+	}
+	w.Flush()
+
+	return 0
+}
+
+// runExport exports the database given by -datastore.database as NDJSON to the file, or stdout if it's omitted
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ContinueOnError)
+	withAssetData := fs.Bool("assets", false, "Export the binaries of the assets from the storage")
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s export [-assets] [file]\n", config.AppName)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 1
+	}
+
+	cfg := config.Config()
+	cfg.Datastore.Dynamic = false
+	ctx := context.WithValue(context.Background(), config.CtxWorkspace, cfg.Datastore.Database)
+	p := datastore.Provider(ctx)
+	defer p.Close()
+	if err := p.CreateTables(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	var w io.Writer = os.Stdout
+	if fs.NArg() > 0 {
+		file, err := os.Create(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		defer file.Close()
+		w = file
+	}
+	bw := bufio.NewWriter(w)
+
+	errRes := service.ExportWorkspace(ctx, &model.ExportWorkspaceRequest{
+		Workspace:     cfg.Datastore.Database,
+		WithAssetData: *withAssetData,
+	}, bw)
+	if errRes != nil {
+		printErrorResponse(errRes)
+		return 1
+	}
+	if err := bw.Flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+// runImport imports the NDJSON written by export from the file, or stdin if it's omitted,
+// into the database given by -datastore.database. The records which already exist are skipped.
+func runImport(args []string) int {
+	if len(args) > 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s import [file]\n", config.AppName)
+		return 1
+	}
+
+	cfg := config.Config()
+	cfg.Datastore.Dynamic = false
+	ctx := context.WithValue(context.Background(), config.CtxWorkspace, cfg.Datastore.Database)
+	p := datastore.Provider(ctx)
+	defer p.Close()
+	if err := p.CreateTables(); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	var r io.Reader = os.Stdin
+	if len(args) > 0 {
+		file, err := os.Open(args[0])
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err.Error())
+			return 1
+		}
+		defer file.Close()
+		r = file
+	}
+
+	res, errRes := service.ImportWorkspace(ctx, &model.ImportWorkspaceRequest{
+		Workspace: cfg.Datastore.Database,
+	}, bufio.NewReader(r))
+	if errRes != nil {
+		printErrorResponse(errRes)
+		return 1
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tIMPORTED\tSKIPPED")
+	for _, recordType := range []model.ExportRecordType{
+		model.ExportRecordTypeUser,
+		model.ExportRecordTypeBlockUser,
+		model.ExportRecordTypeDevice,
+		model.ExportRecordTypeRoom,
+		model.ExportRecordTypeMessage,
+		model.ExportRecordTypeRoomUser,
+		model.ExportRecordTypeAsset,
+		model.ExportRecordTypeWebhook,
+	} {
+		fmt.Fprintf(w, "%s\t%d\t%d\n", recordType, res.Imported[recordType], res.Skipped[recordType])
+	}
+	w.Flush()
+
+	return 0
+}
+
+// runWorkspace registers the existing databases as the workspaces in the dynamic mode.
+// The databases used as the workspaces before the registry was introduced have to be registered,
+// because the requests to the unregistered workspaces are rejected.
+func runWorkspace(args []string) int {
+	if len(args) < 2 || args[0] != "register" {
+		fmt.Fprintf(os.Stderr, "Usage: %s workspace register name...\n", config.AppName)
+		return 1
+	}
+
+	cfg := config.Config()
+	ctx := context.WithValue(context.Background(), config.CtxWorkspace, cfg.Datastore.Database)
+	defer datastore.Provider(ctx).Close()
+
+	code := 0
+	for _, name := range args[1:] {
+		workspace, errRes := service.RegisterWorkspace(ctx, &model.CreateWorkspaceRequest{
+			Name: name,
+		})
+		if errRes != nil {
+			fmt.Fprintf(os.Stderr, "%s: ", name)
+			printErrorResponse(errRes)
+			code = 1
+			continue
+		}
+		if workspace.AppClient != nil {
+			fmt.Fprintf(os.Stdout, "Registered %s. The client id is %s\n", name, workspace.AppClient.ClientID)
+		} else {
+			fmt.Fprintf(os.Stdout, "Registered %s\n", name)
+		}
+	}
+
+	return code
+}
+
+func printErrorResponse(errRes *model.ErrorResponse) {
+	fmt.Fprintln(os.Stderr, errRes.Message)
+	if errRes.Error != nil {
+		fmt.Fprintln(os.Stderr, errRes.Error.Error())
+	}
+}
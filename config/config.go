@@ -20,6 +20,8 @@ var (
 	showHelp    = false
 	// StopRun is a flag for stop run server
 	StopRun = false
+	// Command is a sub command and its arguments given after the flags. e.g. migrate up
+	Command []string
 )
 
 type config struct {
@@ -36,6 +38,9 @@ type config struct {
 	Producer               *Producer
 	Consumer               *Consumer
 	Notification           *Notification
+	RateLimiter            *RateLimiter `yaml:"rateLimiter"`
+	Retention              *Retention
+	Locale                 *Locale
 }
 
 // Logger is settings of logger
@@ -121,6 +126,14 @@ type Datastore struct {
 	Replicas          []*ServerInfo
 	EnableLogging     bool    `yaml:"enableLogging"`
 	SQLite            *SQLite `yaml:"sqlite"`
+	// ConnectionPoolSize is a max number of databases connected at once. 0 is unlimited.
+	ConnectionPoolSize int `yaml:"connectionPoolSize"`
+	// ConnectionIdleTimeout is seconds until the connections of an unused database are closed. 0 never closes them.
+	ConnectionIdleTimeout int `yaml:"connectionIdleTimeout"`
+	// ReplicaMaxLag is seconds of replication lag until a replica is ejected from reads. 0 is unlimited.
+	ReplicaMaxLag int `yaml:"replicaMaxLag"`
+	// StickToMasterAfterWrite is seconds while the reads of a user go to master after the user writes.
+	StickToMasterAfterWrite int `yaml:"stickToMasterAfterWrite"`
 }
 
 type SQLite struct {
@@ -198,6 +211,34 @@ type Notification struct {
 	}
 }
 
+// Retention is settings of message expiry and room retention policies
+type Retention struct {
+	// PurgeInterval is an interval in seconds between purges of expired messages.
+	PurgeInterval int `yaml:"purgeInterval"`
+	// HardDelete is a flag for deleting purged messages from the table instead of marking them as deleted.
+	HardDelete bool `yaml:"hardDelete"`
+	// RoomRestorePeriod is a period in seconds deleted rooms can be restored for. 0 means deleted rooms can not be restored.
+	RoomRestorePeriod int `yaml:"roomRestorePeriod"`
+}
+
+// Locale is settings of the languages of the texts generated by the server
+type Locale struct {
+	// DefaultLang is a language for the users who don't set their languages or set the unsupported ones.
+	DefaultLang string `yaml:"defaultLang"`
+	// CatalogPath is a directory of the message catalogs named {lang}.json, which override the built-in ones.
+	CatalogPath string `yaml:"catalogPath"`
+}
+
+// RateLimiter is settings of rate limiter
+type RateLimiter struct {
+	// Provider is a provider of rate limiter. If it is empty, requests are not limited.
+	Provider string
+	// Rate is a number of requests per second allowed for each user, or each remote address of the requests without user ID.
+	Rate float64
+	// Burst is a max number of requests allowed at once for each user, or each remote address of the requests without user ID.
+	Burst int
+}
+
 func NewConfig() *config {
 	log.SetFlags(log.Llongfile)
 
@@ -267,18 +308,35 @@ func defaultSetting() *config {
 			},
 		},
 		Datastore: &Datastore{
-			Dynamic:           false,
-			Provider:          "sqlite",
-			Database:          "swagchat",
-			MaxIdleConnection: 10,
-			MaxOpenConnection: 10,
-			ConnMaxLifetime:   10,
-			EnableLogging:     false,
-			SQLite:            sqlite,
+			Dynamic:                 false,
+			Provider:                "sqlite",
+			Database:                "swagchat",
+			MaxIdleConnection:       10,
+			MaxOpenConnection:       10,
+			ConnMaxLifetime:         10,
+			EnableLogging:           false,
+			SQLite:                  sqlite,
+			ConnectionPoolSize:      100,
+			ConnectionIdleTimeout:   600,
+			ReplicaMaxLag:           10,
+			StickToMasterAfterWrite: 5,
 		},
 		Producer:     &Producer{},
 		Consumer:     &Consumer{},
 		Notification: &Notification{},
+		RateLimiter: &RateLimiter{
+			Provider: "",
+			Rate:     10,
+			Burst:    50,
+		},
+		Retention: &Retention{
+			PurgeInterval:     60,
+			HardDelete:        false,
+			RoomRestorePeriod: 30 * 24 * 60 * 60,
+		},
+		Locale: &Locale{
+			DefaultLang: "en",
+		},
 	}
 }
 
@@ -483,6 +541,30 @@ func (c *config) loadEnv() {
 			c.Datastore.ConnMaxLifetime = cml
 		}
 	}
+	if v = os.Getenv("SWAG_DATASTORE_CONNECTION_POOL_SIZE"); v != "" {
+		cps, err := strconv.Atoi(v)
+		if err == nil {
+			c.Datastore.ConnectionPoolSize = cps
+		}
+	}
+	if v = os.Getenv("SWAG_DATASTORE_CONNECTION_IDLE_TIMEOUT"); v != "" {
+		cit, err := strconv.Atoi(v)
+		if err == nil {
+			c.Datastore.ConnectionIdleTimeout = cit
+		}
+	}
+	if v = os.Getenv("SWAG_DATASTORE_REPLICA_MAX_LAG"); v != "" {
+		rml, err := strconv.Atoi(v)
+		if err == nil {
+			c.Datastore.ReplicaMaxLag = rml
+		}
+	}
+	if v = os.Getenv("SWAG_DATASTORE_STICK_TO_MASTER_AFTER_WRITE"); v != "" {
+		stm, err := strconv.Atoi(v)
+		if err == nil {
+			c.Datastore.StickToMasterAfterWrite = stm
+		}
+	}
 
 	var master *ServerInfo
 	mHost := os.Getenv("SWAG_DATASTORE_MASTER_HOST")
@@ -679,6 +761,48 @@ func (c *config) loadEnv() {
 	if v = os.Getenv("SWAG_NOTIFICATION_AMAZONSNS_APPLICATION_ARN_ANDROID"); v != "" {
 		c.Notification.AmazonSNS.ApplicationArnAndroid = v
 	}
+
+	// RateLimiter
+	if v = os.Getenv("SWAG_RATE_LIMITER_PROVIDER"); v != "" {
+		c.RateLimiter.Provider = v
+	}
+	if v = os.Getenv("SWAG_RATE_LIMITER_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err == nil {
+			c.RateLimiter.Rate = rate
+		}
+	}
+	if v = os.Getenv("SWAG_RATE_LIMITER_BURST"); v != "" {
+		burst, err := strconv.Atoi(v)
+		if err == nil {
+			c.RateLimiter.Burst = burst
+		}
+	}
+
+	// Retention
+	if v = os.Getenv("SWAG_RETENTION_PURGE_INTERVAL"); v != "" {
+		purgeInterval, err := strconv.Atoi(v)
+		if err == nil {
+			c.Retention.PurgeInterval = purgeInterval
+		}
+	}
+	if v = os.Getenv("SWAG_RETENTION_HARD_DELETE"); v == "true" {
+		c.Retention.HardDelete = true
+	}
+	if v = os.Getenv("SWAG_RETENTION_ROOM_RESTORE_PERIOD"); v != "" {
+		roomRestorePeriod, err := strconv.Atoi(v)
+		if err == nil {
+			c.Retention.RoomRestorePeriod = roomRestorePeriod
+		}
+	}
+
+	// Locale
+	if v = os.Getenv("SWAG_LOCALE_DEFAULT_LANG"); v != "" {
+		c.Locale.DefaultLang = v
+	}
+	if v = os.Getenv("SWAG_LOCALE_CATALOG_PATH"); v != "" {
+		c.Locale.CatalogPath = v
+	}
 }
 
 func (c *config) parseFlag(args []string) error {
@@ -761,6 +885,10 @@ func (c *config) parseFlag(args []string) error {
 	flags.IntVar(&c.Datastore.MaxIdleConnection, "datastore.maxIdleConnection", c.Datastore.MaxIdleConnection, "")
 	flags.IntVar(&c.Datastore.MaxOpenConnection, "datastore.maxOpenConnection", c.Datastore.MaxOpenConnection, "")
 	flags.IntVar(&c.Datastore.ConnMaxLifetime, "datastore.connMaxLifetime", c.Datastore.ConnMaxLifetime, "")
+	flags.IntVar(&c.Datastore.ConnectionPoolSize, "datastore.connectionPoolSize", c.Datastore.ConnectionPoolSize, "")
+	flags.IntVar(&c.Datastore.ConnectionIdleTimeout, "datastore.connectionIdleTimeout", c.Datastore.ConnectionIdleTimeout, "")
+	flags.IntVar(&c.Datastore.ReplicaMaxLag, "datastore.replicaMaxLag", c.Datastore.ReplicaMaxLag, "")
+	flags.IntVar(&c.Datastore.StickToMasterAfterWrite, "datastore.stickToMasterAfterWrite", c.Datastore.StickToMasterAfterWrite, "")
 
 	var (
 		mHostStr           string
@@ -855,6 +983,20 @@ func (c *config) parseFlag(args []string) error {
 	flags.StringVar(&c.Notification.AmazonSNS.ApplicationArnIos, "notification.amazonsns.applicationArnIos", c.Notification.AmazonSNS.ApplicationArnIos, "")
 	flags.StringVar(&c.Notification.AmazonSNS.ApplicationArnAndroid, "notification.amazonsns.applicationArnAndroid", c.Notification.AmazonSNS.ApplicationArnAndroid, "")
 
+	// RateLimiter
+	flags.StringVar(&c.RateLimiter.Provider, "rateLimiter.provider", c.RateLimiter.Provider, "")
+	flags.Float64Var(&c.RateLimiter.Rate, "rateLimiter.rate", c.RateLimiter.Rate, "")
+	flags.IntVar(&c.RateLimiter.Burst, "rateLimiter.burst", c.RateLimiter.Burst, "")
+
+	// Retention
+	flags.IntVar(&c.Retention.PurgeInterval, "retention.purgeInterval", c.Retention.PurgeInterval, "")
+	flags.BoolVar(&c.Retention.HardDelete, "retention.hardDelete", c.Retention.HardDelete, "")
+	flags.IntVar(&c.Retention.RoomRestorePeriod, "retention.roomRestorePeriod", c.Retention.RoomRestorePeriod, "")
+
+	// Locale
+	flags.StringVar(&c.Locale.DefaultLang, "locale.defaultLang", c.Locale.DefaultLang, "")
+	flags.StringVar(&c.Locale.CatalogPath, "locale.catalogPath", c.Locale.CatalogPath, "")
+
 	configPath := ""
 	flags.StringVar(&configPath, "config", "", "config file(yaml format)")
 
@@ -867,7 +1009,10 @@ func (c *config) parseFlag(args []string) error {
 		return nil
 	}
 
+	Command = flags.Args()
+
 	if showHelp {
+		fmt.Printf("Usage: %s [flags] [migrate up|down [steps]|status]\n", AppName)
 		flags.PrintDefaults()
 		StopRun = true
 		return nil
@@ -1000,6 +1145,43 @@ func (c *config) validate() error {
 		}
 	}
 
+	// Datastore
+	if c.Datastore.ConnectionPoolSize < 0 {
+		return errors.New("Please set datastore.connectionPoolSize to a number greater than or equal to 0")
+	}
+	if c.Datastore.ConnectionIdleTimeout < 0 {
+		return errors.New("Please set datastore.connectionIdleTimeout to a number greater than or equal to 0")
+	}
+	if c.Datastore.ReplicaMaxLag < 0 {
+		return errors.New("Please set datastore.replicaMaxLag to a number greater than or equal to 0")
+	}
+	if c.Datastore.StickToMasterAfterWrite < 0 {
+		return errors.New("Please set datastore.stickToMasterAfterWrite to a number greater than or equal to 0")
+	}
+
+	// RateLimiter
+	if c.RateLimiter.Provider != "" {
+		if c.RateLimiter.Rate <= 0 {
+			return errors.New("Please set rateLimiter.rate to a number greater than 0")
+		}
+		if c.RateLimiter.Burst <= 0 {
+			return errors.New("Please set rateLimiter.burst to a number greater than 0")
+		}
+	}
+
+	// Retention
+	if c.Retention.PurgeInterval <= 0 {
+		return errors.New("Please set retention.purgeInterval to a number greater than 0")
+	}
+	if c.Retention.RoomRestorePeriod < 0 {
+		return errors.New("Please set retention.roomRestorePeriod to a number greater than or equal to 0")
+	}
+
+	// Locale
+	if c.Locale.DefaultLang == "" {
+		return errors.New("Please set locale.defaultLang")
+	}
+
 	return nil
 }
 
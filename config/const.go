@@ -27,6 +27,10 @@ const (
 	HeaderRealmRoles = "X-Realm-Roles"
 	// HeaderAccountRoles is http header for account roles
 	HeaderAccountRoles = "X-Account-Roles"
+	// HeaderEventID is http header for the deduplication ID of events
+	HeaderEventID = "X-Event-Id"
+	// HeaderAcceptLanguage is http header for the languages of the texts generated by the server
+	HeaderAcceptLanguage = "Accept-Language"
 
 	CtxDsCfg ctxKey = iota
 	CtxClientID
@@ -34,8 +38,34 @@ const (
 	CtxWorkspace
 	CtxRoomUser
 	CtxSubscription
+	CtxScheduledMessage
+	CtxStickiness
+	CtxLang
 
 	RoleGeneral int32 = 1
 
 	RetrieveRoomMessagesDefaultLimit = 50
+
+	SubscribeEventsResumeLimit = 1000
+
+	ScheduledMessageDispatchIntervalSecond = 10
+	ScheduledMessageDispatchLimit          = 100
+
+	PurgeMessagesLimit = 1000
+
+	OutboxRelayIntervalSecond   = 5
+	OutboxRelayLimit            = 100
+	OutboxEventLeaseSecond      = 60
+	OutboxEventMaxAttempts      = 10
+	OutboxEventMaxBackoffSecond = 600
+	OutboxEventRetentionSecond  = 7 * 24 * 60 * 60
+
+	RoomInvitationExpireIntervalSecond = 60
+
+	WorkspaceCacheExpireSecond         = 10
+	EvictIdleConnectionsIntervalSecond = 60
+	CloseEvictedConnectionsDelaySecond = 60
+	ReplicaHealthCheckIntervalSecond   = 10
+
+	MigrationLockTimeoutSecond = 600
 )
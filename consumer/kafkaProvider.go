@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package consumer
 
 import (
@@ -114,7 +117,7 @@ func (kp *kafkaProvider) SubscribeMessage() error {
 					break
 				}
 
-				msg := &model.Message{pbMsg, payload}
+				msg := &model.Message{pbMsg, payload, nil, 0}
 				req := msg.ConvertToSendMessageRequest()
 
 				ctx := context.Background()
@@ -129,6 +132,12 @@ func (kp *kafkaProvider) SubscribeMessage() error {
 				}
 				ctx = context.WithValue(ctx, config.CtxWorkspace, workspace)
 
+				errRes := service.ConfirmWorkspace(ctx, workspace)
+				if errRes != nil {
+					logger.Error(fmt.Sprintf("%s workspace[%s]", errRes.Message, workspace))
+					break
+				}
+
 				service.SendMessage(ctx, req)
 
 			case kafka.PartitionEOF:
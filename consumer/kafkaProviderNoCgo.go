@@ -0,0 +1,26 @@
+//go:build !cgo
+// +build !cgo
+
+package consumer
+
+import (
+	"context"
+
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+)
+
+// kafkaProvider is unavailable without cgo because confluent-kafka-go links librdkafka
+type kafkaProvider struct {
+	ctx context.Context
+}
+
+func (kp *kafkaProvider) SubscribeMessage() error {
+	err := errors.New("kafka consumer is not supported by the binary built without cgo")
+	logger.Error(err.Error())
+	return err
+}
+
+func (kp *kafkaProvider) UnsubscribeMessage() error {
+	return nil
+}
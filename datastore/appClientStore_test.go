@@ -0,0 +1,102 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreSelectFirstAppClient  = "[store] select first app client test"
+	TestStoreInsertAppClient       = "[store] insert app client test"
+	TestStoreSelectLatestAppClient = "[store] select latest app client test"
+)
+
+func testAppClientStore(t *testing.T) {
+	t.Run(TestStoreSelectFirstAppClient, func(t *testing.T) {
+		clientID := config.Config().FirstClientID
+		appClient, err := Provider(ctx).SelectLatestAppClient(SelectAppClientOptionFilterByClientID(clientID))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectFirstAppClient, err.Error())
+		}
+		if appClient == nil {
+			t.Fatalf("Failed to %s. Expected appClient to be not nil, but it was nil", TestStoreSelectFirstAppClient)
+		}
+		if appClient.Name != clientID {
+			t.Fatalf("Failed to %s. Expected appClient.Name to be \"%s\", but it was \"%s\"", TestStoreSelectFirstAppClient, clientID, appClient.Name)
+		}
+	})
+
+	t.Run(TestStoreInsertAppClient, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		appClients := []*model.AppClient{
+			{
+				Name:     "app-client-store-name",
+				ClientID: "app-client-store-client-id-0001",
+				Created:  nowTimestamp - 2,
+			},
+			{
+				Name:     "app-client-store-name",
+				ClientID: "app-client-store-client-id-0002",
+				Created:  nowTimestamp - 1,
+			},
+			{
+				Name:     "app-client-store-name",
+				ClientID: "app-client-store-client-id-0003",
+				Created:  nowTimestamp,
+				Expired:  nowTimestamp - 1,
+			},
+		}
+		for _, appClient := range appClients {
+			err := Provider(ctx).InsertAppClient(appClient)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertAppClient, err.Error())
+			}
+		}
+
+		err := Provider(ctx).InsertAppClient(&model.AppClient{
+			Name:     "app-client-store-name",
+			ClientID: "app-client-store-client-id-0001",
+			Created:  nowTimestamp,
+		})
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil for the duplicated clientId, but it was nil", TestStoreInsertAppClient)
+		}
+	})
+
+	t.Run(TestStoreSelectLatestAppClient, func(t *testing.T) {
+		appClient, err := Provider(ctx).SelectLatestAppClient(SelectAppClientOptionFilterByName("app-client-store-name"))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectLatestAppClient, err.Error())
+		}
+		if appClient == nil {
+			t.Fatalf("Failed to %s. Expected appClient to be not nil, but it was nil", TestStoreSelectLatestAppClient)
+		}
+		if appClient.ClientID != "app-client-store-client-id-0002" {
+			t.Fatalf("Failed to %s. Expected appClient.ClientID to be \"app-client-store-client-id-0002\", but it was \"%s\"", TestStoreSelectLatestAppClient, appClient.ClientID)
+		}
+
+		appClient, err = Provider(ctx).SelectLatestAppClient(SelectAppClientOptionFilterByClientID("app-client-store-client-id-0003"))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectLatestAppClient, err.Error())
+		}
+		if appClient != nil {
+			t.Fatalf("Failed to %s. Expected the expired appClient to be nil, but it was not nil", TestStoreSelectLatestAppClient)
+		}
+
+		_, err = Provider(ctx).SelectLatestAppClient()
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil without name and clientId, but it was nil", TestStoreSelectLatestAppClient)
+		}
+
+		_, err = Provider(ctx).SelectLatestAppClient(
+			SelectAppClientOptionFilterByName("app-client-store-name"),
+			SelectAppClientOptionFilterByClientID("app-client-store-client-id-0001"),
+		)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil with both name and clientId, but it was nil", TestStoreSelectLatestAppClient)
+		}
+	})
+}
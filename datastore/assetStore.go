@@ -2,9 +2,42 @@ package datastore
 
 import "github.com/swagchat/chat-api/model"
 
+type selectAssetsOptions struct {
+	userID string
+}
+
+type SelectAssetsOption func(*selectAssetsOptions)
+
+func SelectAssetsOptionFilterByUserID(userID string) SelectAssetsOption {
+	return func(ops *selectAssetsOptions) {
+		ops.userID = userID
+	}
+}
+
+type deleteAssetsOptions struct {
+	logicalDeleted int64
+	assetIDs       []string
+}
+
+type DeleteAssetsOption func(*deleteAssetsOptions)
+
+func DeleteAssetsOptionWithLogicalDeleted(logicalDeleted int64) DeleteAssetsOption {
+	return func(ops *deleteAssetsOptions) {
+		ops.logicalDeleted = logicalDeleted
+	}
+}
+
+func DeleteAssetsOptionFilterByAssetIDs(assetIDs []string) DeleteAssetsOption {
+	return func(ops *deleteAssetsOptions) {
+		ops.assetIDs = assetIDs
+	}
+}
+
 type assetStore interface {
 	createAssetStore()
 
 	InsertAsset(asset *model.Asset) error
 	SelectAsset(assetID string) (*model.Asset, error)
+	SelectAssets(limit, offset int32, opts ...SelectAssetsOption) ([]*model.Asset, error)
+	DeleteAssets(opts ...DeleteAssetsOption) error
 }
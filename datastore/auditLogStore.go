@@ -0,0 +1,50 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type selectAuditLogsOptions struct {
+	action        string
+	actorUserID   string
+	targetType    string
+	targetID      string
+	fromTimestamp int64
+	toTimestamp   int64
+}
+
+type SelectAuditLogsOption func(*selectAuditLogsOptions)
+
+func SelectAuditLogsOptionFilterByAction(action string) SelectAuditLogsOption {
+	return func(ops *selectAuditLogsOptions) {
+		ops.action = action
+	}
+}
+
+func SelectAuditLogsOptionFilterByActorUserID(actorUserID string) SelectAuditLogsOption {
+	return func(ops *selectAuditLogsOptions) {
+		ops.actorUserID = actorUserID
+	}
+}
+
+func SelectAuditLogsOptionFilterByTarget(targetType, targetID string) SelectAuditLogsOption {
+	return func(ops *selectAuditLogsOptions) {
+		ops.targetType = targetType
+		ops.targetID = targetID
+	}
+}
+
+// SelectAuditLogsOptionFilterByCreated filters the audit logs created in the range. Zero means unbounded.
+func SelectAuditLogsOptionFilterByCreated(fromTimestamp, toTimestamp int64) SelectAuditLogsOption {
+	return func(ops *selectAuditLogsOptions) {
+		ops.fromTimestamp = fromTimestamp
+		ops.toTimestamp = toTimestamp
+	}
+}
+
+type auditLogStore interface {
+	createAuditLogStore()
+
+	InsertAuditLog(auditLog *model.AuditLog) error
+	// SelectAuditLogs selects the audit logs in the order of the newest first
+	SelectAuditLogs(limit, offset int32, opts ...SelectAuditLogsOption) ([]*model.AuditLog, error)
+	SelectCountAuditLogs(opts ...SelectAuditLogsOption) (int64, error)
+}
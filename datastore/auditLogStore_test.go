@@ -0,0 +1,91 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreInsertAuditLog       = "[store] insert audit log test"
+	TestStoreSelectAuditLogs      = "[store] select audit logs test"
+	TestStoreSelectCountAuditLogs = "[store] select count audit logs test"
+	TestStoreTearDownAuditLog     = "[store] tear down audit log"
+)
+
+func testAuditLogStore(t *testing.T) {
+	auditLogs := []*model.AuditLog{
+		model.NewAuditLog(model.AuditActionCreateRoom, "audit-log-store-user-id-0001", "", "", model.AuditTargetTypeRoom, "audit-log-store-room-id-0001", nil, map[string]string{"name": "room"}),
+		model.NewAuditLog(model.AuditActionAddRoomUsers, "audit-log-store-user-id-0001", "", "", model.AuditTargetTypeRoom, "audit-log-store-room-id-0001", nil, nil),
+		model.NewAuditLog(model.AuditActionAddRoomUsers, "audit-log-store-user-id-0002", "", "", model.AuditTargetTypeRoom, "audit-log-store-room-id-0002", nil, nil),
+	}
+	for i, auditLog := range auditLogs {
+		auditLog.Created = int64(1000 + i)
+	}
+
+	t.Run(TestStoreInsertAuditLog, func(t *testing.T) {
+		for _, auditLog := range auditLogs {
+			err := Provider(ctx).InsertAuditLog(auditLog)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertAuditLog, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestStoreSelectAuditLogs, func(t *testing.T) {
+		selectedAuditLogs, err := Provider(ctx).SelectAuditLogs(
+			10,
+			0,
+			SelectAuditLogsOptionFilterByTarget(model.AuditTargetTypeRoom, "audit-log-store-room-id-0001"),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectAuditLogs, err.Error())
+		}
+		if len(selectedAuditLogs) != 2 {
+			t.Fatalf("Failed to %s. Expected audit logs count to be 2, but it was %d", TestStoreSelectAuditLogs, len(selectedAuditLogs))
+		}
+		if selectedAuditLogs[0].AuditLogID != auditLogs[1].AuditLogID {
+			t.Fatalf("Failed to %s. Expected the newest audit log to be first", TestStoreSelectAuditLogs)
+		}
+
+		selectedAuditLogs, err = Provider(ctx).SelectAuditLogs(
+			10,
+			0,
+			SelectAuditLogsOptionFilterByAction(string(model.AuditActionAddRoomUsers)),
+			SelectAuditLogsOptionFilterByActorUserID("audit-log-store-user-id-0002"),
+			SelectAuditLogsOptionFilterByCreated(1001, 1002),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectAuditLogs, err.Error())
+		}
+		if len(selectedAuditLogs) != 1 {
+			t.Fatalf("Failed to %s. Expected audit logs count to be 1, but it was %d", TestStoreSelectAuditLogs, len(selectedAuditLogs))
+		}
+		if string(selectedAuditLogs[0].Before) != "null" {
+			t.Fatalf("Failed to %s. Expected before to be null, but it was %s", TestStoreSelectAuditLogs, string(selectedAuditLogs[0].Before))
+		}
+	})
+
+	t.Run(TestStoreSelectCountAuditLogs, func(t *testing.T) {
+		count, err := Provider(ctx).SelectCountAuditLogs(SelectAuditLogsOptionFilterByCreated(1000, 1002))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectCountAuditLogs, err.Error())
+		}
+		if count != 3 {
+			t.Fatalf("Failed to %s. Expected count to be 3, but it was %d", TestStoreSelectCountAuditLogs, count)
+		}
+	})
+
+	t.Run(TestStoreTearDownAuditLog, func(t *testing.T) {
+		master := RdbStore(config.Config().Datastore.Database).master()
+		query := fmt.Sprintf("DELETE FROM %s WHERE audit_log_id=?;", tableNameAuditLog)
+		for _, auditLog := range auditLogs {
+			_, err := master.Exec(rebind(master, query), auditLog.AuditLogID)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownAuditLog, err.Error())
+			}
+		}
+	})
+}
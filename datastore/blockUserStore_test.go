@@ -20,7 +20,7 @@ const (
 	TestStoreTearDownBlockUser    = "[store] tear down blockUser"
 )
 
-func TestBlockUserStore(t *testing.T) {
+func testBlockUserStore(t *testing.T) {
 	var blockUser *model.BlockUser
 	var err error
 
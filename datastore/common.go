@@ -1,10 +1,33 @@
 package datastore
 
 import (
+	"bytes"
 	"reflect"
 	"strconv"
+
+	gorp "gopkg.in/gorp.v2"
 )
 
+// rebind replaces ? placeholders of the query with the bind variables of the dialect.
+// gorp binds only named parameters by the dialect, so the queries with ? have to be rebound for PostgreSQL.
+func rebind(dbMap *gorp.DbMap, query string) string {
+	if _, ok := dbMap.Dialect.(gorp.PostgresDialect); !ok {
+		return query
+	}
+
+	var buf bytes.Buffer
+	i := 0
+	for _, r := range query {
+		if r == '?' {
+			buf.WriteString(dbMap.Dialect.BindVar(i))
+			i++
+			continue
+		}
+		buf.WriteRune(r)
+	}
+	return buf.String()
+}
+
 // makePrepareExpressionParamsForInOperand makes prepare expression for in operand
 func makePrepareExpressionParamsForInOperand(target interface{}) (string, map[string]interface{}) {
 	bindParams := make(map[string]interface{})
@@ -0,0 +1,33 @@
+package datastore
+
+import (
+	"testing"
+
+	gorp "gopkg.in/gorp.v2"
+)
+
+const (
+	TestStoreRebindPostgres = "[store] rebind postgres test"
+	TestStoreRebindMySQL    = "[store] rebind mysql test"
+)
+
+func TestRebind(t *testing.T) {
+	query := "UPDATE tbl SET name=? WHERE id=? AND deleted=0;"
+
+	t.Run(TestStoreRebindPostgres, func(t *testing.T) {
+		dbMap := &gorp.DbMap{Dialect: gorp.PostgresDialect{}}
+		expected := "UPDATE tbl SET name=$1 WHERE id=$2 AND deleted=0;"
+		actual := rebind(dbMap, query)
+		if actual != expected {
+			t.Fatalf("Failed to %s. Expected %s, but it was %s", TestStoreRebindPostgres, expected, actual)
+		}
+	})
+
+	t.Run(TestStoreRebindMySQL, func(t *testing.T) {
+		dbMap := &gorp.DbMap{Dialect: gorp.MySQLDialect{}}
+		actual := rebind(dbMap, query)
+		if actual != query {
+			t.Fatalf("Failed to %s. Expected %s, but it was %s", TestStoreRebindMySQL, query, actual)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/swagchat/chat-api/config"
+)
+
+// conformanceTests are run in order against every datastore of conformanceDatastores,
+// so that all of the providers behave identically
+var conformanceTests = []struct {
+	name string
+	test func(t *testing.T)
+}{
+	{"appClientStore", testAppClientStore},
+	{"auditLogStore", testAuditLogStore},
+	{"blockUserStore", testBlockUserStore},
+	{"deviceStore", testDeviceStore},
+	{"exportStore", testExportStore},
+	{"messageStore", testMessageStore},
+	{"migrationStore", testMigrationStore},
+	{"outboxEventStore", testOutboxEventStore},
+	{"pinnedMessageStore", testPinnedMessageStore},
+	{"roomStore", testRoomStore},
+	{"roomInvitationStore", testRoomInvitationStore},
+	{"roomInviteLinkStore", testRoomInviteLinkStore},
+	{"roomUserStore", testRoomUserStore},
+	{"settingStore", testSettingStore},
+	{"subscriptionStore", testSubscriptionStore},
+	{"userRoleStore", testUserRoleStore},
+	{"userStore", testUserStore},
+	{"webhookStore", testWebhookStore},
+	{"workspaceStore", testWorkspaceStore},
+}
+
+// conformanceDatastores returns the datastores which the conformance tests run against.
+// On memory SQLite is always tested. The datastore configured by the SWAG_DATASTORE_* environment variables,
+// for example a local MySQL, is tested as well unless it's SQLite.
+func conformanceDatastores() []*config.Datastore {
+	dsCfg := config.Config().Datastore
+
+	sqlite := *dsCfg
+	sqlite.Provider = "sqlite"
+	sqliteCfg := *dsCfg.SQLite
+	sqliteCfg.OnMemory = true
+	sqlite.SQLite = &sqliteCfg
+	dsCfgs := []*config.Datastore{&sqlite}
+
+	if dsCfg.Provider != "sqlite" {
+		configured := *dsCfg
+		dsCfgs = append(dsCfgs, &configured)
+	}
+
+	return dsCfgs
+}
+
+func TestDatastoreConformance(t *testing.T) {
+	cfg := config.Config()
+	original := cfg.Datastore
+	defer func() {
+		cfg.Datastore = original
+	}()
+
+	for _, dsCfg := range conformanceDatastores() {
+		cfg.Datastore = dsCfg
+
+		t.Run(dsCfg.Provider, func(t *testing.T) {
+			_, err := CreateDatabase(ctx)
+			if err != nil {
+				t.Fatalf("Failed to create database. %s", err.Error())
+			}
+			err = Provider(ctx).CreateTables()
+			if err != nil {
+				t.Fatalf("Failed to create tables. %s", err.Error())
+			}
+			defer func() {
+				Provider(ctx).DropDatabase()
+				Provider(ctx).Close()
+			}()
+
+			for _, ct := range conformanceTests {
+				t.Run(ct.name, ct.test)
+			}
+		})
+	}
+}
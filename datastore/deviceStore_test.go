@@ -15,7 +15,7 @@ const (
 	TestStoreDeleteDevice  = "[store] delete block user test"
 )
 
-func TestDeviceStore(t *testing.T) {
+func testDeviceStore(t *testing.T) {
 	var device *model.Device
 	var err error
 
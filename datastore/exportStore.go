@@ -0,0 +1,19 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+// exportStore selects the records of the workspace with all of their columns in pages for the export
+type exportStore interface {
+	// SelectExportUsers selects the users with their roles in the order of creation
+	SelectExportUsers(limit, offset int32) ([]*model.User, error)
+	// SelectExportRooms selects the rooms in the order of creation
+	SelectExportRooms(limit, offset int32) ([]*model.Room, error)
+	// SelectExportRoomUsers selects the room users of the room
+	SelectExportRoomUsers(roomID string) ([]*model.RoomUser, error)
+	// SelectExportMessages selects the messages of the room including the deleted ones in the order of creation
+	SelectExportMessages(roomID string, limit, offset int32) ([]*model.Message, error)
+	// SelectExportMentions selects the mentions of the messages of the room
+	SelectExportMentions(roomID string) ([]*model.Mention, error)
+	// InsertMentionsAsIs inserts the imported mentions without counting up the mention count of the room users
+	InsertMentionsAsIs(mentions []*model.Mention) error
+}
@@ -0,0 +1,208 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreSetUpExport               = "[store] set up export"
+	TestStoreSelectExportUsers         = "[store] select export users test"
+	TestStoreSelectExportRooms         = "[store] select export rooms test"
+	TestStoreSelectExportRoomUsers     = "[store] select export room users test"
+	TestStoreInsertMessageAsIsOfExport = "[store] insert message as is test"
+	TestStoreSelectExportMessages      = "[store] select export messages test"
+	TestStoreInsertMentionsAsIs        = "[store] insert mentions as is test"
+)
+
+func testExportStore(t *testing.T) {
+	testUserID := "export-store-user-id-0001"
+	testRoomID := "export-store-room-id-0001"
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestStoreSetUpExport, func(t *testing.T) {
+		newUser := &model.User{}
+		newUser.UserID = testUserID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpExport, err.Error())
+		}
+
+		ur := &model.UserRole{}
+		ur.UserID = testUserID
+		ur.Role = 3
+		err = Provider(ctx).InsertUserRoles([]*model.UserRole{ur})
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpExport, err.Error())
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testRoomID
+		newRoom.UserID = testUserID
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		ru := &model.RoomUser{}
+		ru.RoomID = testRoomID
+		ru.UserID = testUserID
+		ru.Display = true
+		err = Provider(ctx).InsertRoom(newRoom, InsertRoomOptionWithRoomUser([]*model.RoomUser{ru}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpExport, err.Error())
+		}
+	})
+
+	t.Run(TestStoreSelectExportUsers, func(t *testing.T) {
+		users, err := Provider(ctx).SelectExportUsers(1000, 0)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportUsers, err.Error())
+		}
+		var user *model.User
+		for _, u := range users {
+			if u.UserID == testUserID {
+				user = u
+			}
+		}
+		if user == nil {
+			t.Fatalf("Failed to %s. Expected the user to be selected, but it was not", TestStoreSelectExportUsers)
+		}
+		if len(user.Roles) != 1 || user.Roles[0] != 3 {
+			t.Fatalf("Failed to %s. Expected user.Roles to be [3], but it was %v", TestStoreSelectExportUsers, user.Roles)
+		}
+
+		users, err = Provider(ctx).SelectExportUsers(1, 0)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportUsers, err.Error())
+		}
+		if len(users) != 1 {
+			t.Fatalf("Failed to %s. Expected users count to be 1, but it was %d", TestStoreSelectExportUsers, len(users))
+		}
+	})
+
+	t.Run(TestStoreSelectExportRooms, func(t *testing.T) {
+		rooms, err := Provider(ctx).SelectExportRooms(1000, 0)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportRooms, err.Error())
+		}
+		found := false
+		for _, room := range rooms {
+			if room.RoomID == testRoomID {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Failed to %s. Expected the room to be selected, but it was not", TestStoreSelectExportRooms)
+		}
+	})
+
+	t.Run(TestStoreSelectExportRoomUsers, func(t *testing.T) {
+		roomUsers, err := Provider(ctx).SelectExportRoomUsers(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportRoomUsers, err.Error())
+		}
+		if len(roomUsers) != 1 || roomUsers[0].UserID != testUserID {
+			t.Fatalf("Failed to %s. Expected the room user to be selected, but it was not", TestStoreSelectExportRoomUsers)
+		}
+	})
+
+	t.Run(TestStoreInsertMessageAsIsOfExport, func(t *testing.T) {
+		newMessage := &model.Message{}
+		newMessage.MessageID = "export-store-message-id-0001"
+		newMessage.RoomID = testRoomID
+		newMessage.UserID = "export-store-user-id-0002"
+		newMessage.Type = model.MessageTypeText
+		newMessage.Payload = []byte(`{"text":"imported"}`)
+		newMessage.Role = config.RoleGeneral
+		newMessage.CreatedTimestamp = nowTimestamp
+		newMessage.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertMessage(newMessage, InsertMessageOptionAsIs(true))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessageAsIsOfExport, err.Error())
+		}
+
+		room, err := Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessageAsIsOfExport, err.Error())
+		}
+		if room.LastMessage != "" {
+			t.Fatalf("Failed to %s. Expected room.LastMessage not to be updated, but it was \"%s\"", TestStoreInsertMessageAsIsOfExport, room.LastMessage)
+		}
+
+		roomUser, err := Provider(ctx).SelectRoomUser(testRoomID, testUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessageAsIsOfExport, err.Error())
+		}
+		if roomUser.UnreadCount != 0 {
+			t.Fatalf("Failed to %s. Expected roomUser.UnreadCount not to be updated, but it was %d", TestStoreInsertMessageAsIsOfExport, roomUser.UnreadCount)
+		}
+	})
+	t.Run(TestStoreSelectExportMessages, func(t *testing.T) {
+		deletedMessage := &model.Message{}
+		deletedMessage.MessageID = "export-store-message-id-0002"
+		deletedMessage.RoomID = testRoomID
+		deletedMessage.UserID = testUserID
+		deletedMessage.Type = model.MessageTypeText
+		deletedMessage.Payload = []byte(`{"text":"deleted"}`)
+		deletedMessage.Role = config.RoleGeneral
+		deletedMessage.CreatedTimestamp = nowTimestamp + 1
+		deletedMessage.ModifiedTimestamp = nowTimestamp + 1
+		deletedMessage.DeletedTimestamp = nowTimestamp + 1
+		err := Provider(ctx).InsertMessage(deletedMessage, InsertMessageOptionAsIs(true))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportMessages, err.Error())
+		}
+
+		messages, err := Provider(ctx).SelectExportMessages(testRoomID, 1000, 0)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportMessages, err.Error())
+		}
+		if len(messages) != 2 || messages[1].MessageID != deletedMessage.MessageID {
+			t.Fatalf("Failed to %s. Expected the deleted message to be selected in the order of creation, but it was not", TestStoreSelectExportMessages)
+		}
+
+		messages, err = Provider(ctx).SelectExportMessages(testRoomID, 1, 1)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectExportMessages, err.Error())
+		}
+		if len(messages) != 1 {
+			t.Fatalf("Failed to %s. Expected messages count to be 1, but it was %d", TestStoreSelectExportMessages, len(messages))
+		}
+	})
+
+	t.Run(TestStoreInsertMentionsAsIs, func(t *testing.T) {
+		mention := &model.Mention{
+			MessageID: "export-store-message-id-0002",
+			RoomID:    testRoomID,
+			UserID:    testUserID,
+			Created:   nowTimestamp,
+		}
+		err := Provider(ctx).InsertMentionsAsIs([]*model.Mention{mention})
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMentionsAsIs, err.Error())
+		}
+
+		mentions, err := Provider(ctx).SelectExportMentions(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMentionsAsIs, err.Error())
+		}
+		if len(mentions) != 1 || mentions[0].UserID != testUserID || mentions[0].Created != nowTimestamp {
+			t.Fatalf("Failed to %s. Expected the mention to be selected, but it was not", TestStoreInsertMentionsAsIs)
+		}
+
+		roomUser, err := Provider(ctx).SelectRoomUser(testRoomID, testUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMentionsAsIs, err.Error())
+		}
+		if roomUser.MentionCount != 0 {
+			t.Fatalf("Failed to %s. Expected roomUser.MentionCount not to be counted up, but it was %d", TestStoreInsertMentionsAsIs, roomUser.MentionCount)
+		}
+	})
+}
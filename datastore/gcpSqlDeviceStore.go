@@ -13,7 +13,7 @@ func (p *gcpSQLProvider) createDeviceStore() {
 }
 
 func (p *gcpSQLProvider) InsertDevice(device *model.Device, opts ...InsertDeviceOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting device")
@@ -39,17 +39,17 @@ func (p *gcpSQLProvider) InsertDevice(device *model.Device, opts ...InsertDevice
 }
 
 func (p *gcpSQLProvider) SelectDevices(opts ...SelectDevicesOption) ([]*model.Device, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectDevices(p.ctx, replica, opts...)
 }
 
 func (p *gcpSQLProvider) SelectDevice(userID string, platform scpb.Platform) (*model.Device, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectDevice(p.ctx, replica, userID, platform)
 }
 
 func (p *gcpSQLProvider) UpdateDevice(device *model.Device) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while updating device")
@@ -75,7 +75,7 @@ func (p *gcpSQLProvider) UpdateDevice(device *model.Device) error {
 }
 
 func (p *gcpSQLProvider) DeleteDevices(opts ...DeleteDevicesOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting device")
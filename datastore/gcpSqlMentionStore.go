@@ -0,0 +1,23 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *gcpSQLProvider) createMentionStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateMentionStore(p.ctx, master)
+}
+
+func (p *gcpSQLProvider) SelectMentionedMessages(limit, offset int32, userID string, opts ...SelectMentionedMessagesOption) ([]*model.Message, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMentionedMessages(p.ctx, replica, limit, offset, userID, opts...)
+}
+
+func (p *gcpSQLProvider) SelectCountMentionedMessages(userID string, opts ...SelectMentionedMessagesOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountMentionedMessages(p.ctx, replica, userID, opts...)
+}
+
+func (p *gcpSQLProvider) SelectMentionedUserIDs(messageID string) ([]string, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMentionedUserIDs(p.ctx, replica, messageID)
+}
@@ -32,7 +32,7 @@ type gcpSQLProvider struct {
 }
 
 func (p *gcpSQLProvider) Connect(dsCfg *config.Datastore) error {
-	if _, ok := rdbStores[dsCfg.Database]; ok {
+	if RdbStore(dsCfg.Database) != nil {
 		return nil
 	}
 
@@ -67,7 +67,7 @@ func (p *gcpSQLProvider) Connect(dsCfg *config.Datastore) error {
 	}
 
 	for _, replicaSi := range p.replicaSis {
-		if replicaSi.Host != "" && replicaSi.Port != "" && rs.replicaDbMaps == nil {
+		if replicaSi.Host != "" && replicaSi.Port != "" {
 			ds := fmt.Sprintf(
 				"%s:%s@tcp(%s:%s)/%s",
 				p.user,
@@ -95,23 +95,72 @@ func (p *gcpSQLProvider) Connect(dsCfg *config.Datastore) error {
 			rs.setReplica(replica)
 		}
 	}
-	rdbStores[dsCfg.Database] = rs
+	rdbStores.add(dsCfg.Database, rs)
 	return nil
 }
 
-func (p *gcpSQLProvider) CreateTables() {
+func (p *gcpSQLProvider) CreateTables() error {
+	p.mapTables()
+
+	master := RdbStore(p.database).master()
+	return rdbCreateTables(p.ctx, master)
+}
+
+func (p *gcpSQLProvider) mapTables() {
 	p.createAppClientStore()
 	p.createAssetStore()
+	p.createAuditLogStore()
 	p.createBlockUserStore()
 	p.createDeviceStore()
+	p.createMentionStore()
 	p.createMessageStore()
+	p.createOutboxEventStore()
+	p.createPinnedMessageStore()
 	p.createRoomStore()
+	p.createRoomInvitationStore()
+	p.createRoomInviteLinkStore()
 	p.createRoomUserStore()
+	p.createScheduledMessageStore()
 	p.createSettingStore()
 	p.createSubscriptionStore()
 	p.createUserStore()
 	p.createUserRoleStore()
 	p.createWebhookStore()
+	p.createWorkspaceStore()
+}
+
+// CreateDatabase creates the database. It returns false without creating it if it exists.
+func (p *gcpSQLProvider) CreateDatabase() (bool, error) {
+	ds := fmt.Sprintf(
+		"%s:%s@tcp(%s:%s)/",
+		p.user,
+		p.password,
+		p.masterSi.Host,
+		p.masterSi.Port)
+	db, err := p.openDb(ds, p.masterSi)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	defer db.Close()
+
+	var count int64
+	err = db.QueryRow("SELECT count(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME=?", p.database).Scan(&count)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s CHARACTER SET utf8mb4", p.database))
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
 }
 
 func (p *gcpSQLProvider) DropDatabase() error {
@@ -135,6 +184,8 @@ func (p *gcpSQLProvider) DropDatabase() error {
 			logger.Error(err.Error())
 			return err
 		}
+		rdbStores.remove(p.database)
+		migratedDatabases.Delete(p.database)
 	}
 	return nil
 }
@@ -183,16 +234,5 @@ func (p *gcpSQLProvider) openDb(dataSource string, si *config.ServerInfo) (*sql.
 }
 
 func (p *gcpSQLProvider) Close() {
-	for database, rdbStore := range rdbStores {
-		if rdbStore != nil {
-			master := rdbStore.masterDbMap
-			if master != nil {
-				close(database, master.Db)
-			}
-			for _, replica := range rdbStores[p.database].replicaDbMaps {
-				close(database, replica.Db)
-			}
-			delete(rdbStores, database)
-		}
-	}
+	rdbStores.removeAll()
 }
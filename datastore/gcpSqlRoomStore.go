@@ -12,7 +12,7 @@ func (p *gcpSQLProvider) createRoomStore() {
 }
 
 func (p *gcpSQLProvider) InsertRoom(room *model.Room, opts ...InsertRoomOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -38,22 +38,32 @@ func (p *gcpSQLProvider) InsertRoom(room *model.Room, opts ...InsertRoomOption)
 }
 
 func (p *gcpSQLProvider) SelectRooms(limit, offset int32, opts ...SelectRoomsOption) ([]*model.Room, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRooms(p.ctx, replica, limit, offset, opts...)
 }
 
 func (p *gcpSQLProvider) SelectRoom(roomID string, opts ...SelectRoomOption) (*model.Room, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRoom(p.ctx, replica, roomID, opts...)
 }
 
 func (p *gcpSQLProvider) SelectCountRooms(opts ...SelectRoomsOption) (int64, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectCountRooms(p.ctx, replica, opts...)
 }
 
+func (p *gcpSQLProvider) SelectPublicRooms(limit, offset int32, opts ...SelectPublicRoomsOption) ([]*model.PublicRoom, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectPublicRooms(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *gcpSQLProvider) SelectCountPublicRooms(opts ...SelectPublicRoomsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountPublicRooms(p.ctx, replica, opts...)
+}
+
 func (p *gcpSQLProvider) UpdateRoom(room *model.Room, opts ...UpdateRoomOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -77,3 +87,90 @@ func (p *gcpSQLProvider) UpdateRoom(room *model.Room, opts ...UpdateRoomOption)
 
 	return nil
 }
+
+func (p *gcpSQLProvider) UpdateRoomOwner(room *model.Room, userID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomOwner(p.ctx, master, tx, room, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *gcpSQLProvider) UpdateRoomLastMessage(roomID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomLastMessage(p.ctx, master, tx, roomID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *gcpSQLProvider) RestoreRoom(room *model.Room) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	restored, err := rdbRestoreRoom(p.ctx, master, tx, room)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !restored {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *gcpSQLProvider) PurgeDeletedRoomUsers(deletedTimestamp int64) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbPurgeDeletedRoomUsers(p.ctx, master, deletedTimestamp)
+}
@@ -8,6 +8,6 @@ func (p *gcpSQLProvider) createSettingStore() {
 }
 
 func (p *gcpSQLProvider) SelectLatestSetting() (*model.Setting, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectLatestSetting(p.ctx, replica)
 }
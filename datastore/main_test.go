@@ -9,7 +9,6 @@ import (
 	"github.com/betchi/tracer"
 	logger "github.com/betchi/zapper"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/swagchat/chat-api/config"
 )
 
@@ -34,16 +33,8 @@ func TestMain(m *testing.M) {
 
 	tracer.InitGlobalTracer(&tracer.Config{})
 
-	cfg.Datastore.SQLite.OnMemory = true
-	// cfg.Datastore.SQLite.OnMemory = false
-	// cfg.Datastore.SQLite.DirPath = "/Users/minobe/Desktop"
-	Provider(ctx).Connect(cfg.Datastore)
-	Provider(ctx).CreateTables()
-
+	// The datastores are connected by TestDatastoreConformance for each provider
 	code := m.Run()
 
-	Provider(ctx).DropDatabase()
-	Provider(ctx).Close()
-
 	os.Exit(code)
 }
@@ -0,0 +1,23 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type selectMentionedMessagesOptions struct {
+	roomID string
+}
+
+type SelectMentionedMessagesOption func(*selectMentionedMessagesOptions)
+
+func SelectMentionedMessagesOptionFilterByRoomID(roomID string) SelectMentionedMessagesOption {
+	return func(ops *selectMentionedMessagesOptions) {
+		ops.roomID = roomID
+	}
+}
+
+type mentionStore interface {
+	createMentionStore()
+
+	SelectMentionedMessages(limit, offset int32, userID string, opts ...SelectMentionedMessagesOption) ([]*model.Message, error)
+	SelectCountMentionedMessages(userID string, opts ...SelectMentionedMessagesOption) (int64, error)
+	SelectMentionedUserIDs(messageID string) ([]string, error)
+}
@@ -5,12 +5,36 @@ import (
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
+type insertMessageOptions struct {
+	outboxEvents []*model.OutboxEvent
+	asIs         bool
+}
+
+type InsertMessageOption func(*insertMessageOptions)
+
+// InsertMessageOptionWithOutboxEvents inserts the outbox events in the same transaction
+func InsertMessageOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) InsertMessageOption {
+	return func(ops *insertMessageOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
+// InsertMessageOptionAsIs inserts only the message. The last message of the room, the unread counts,
+// the hidden rooms and the mentions are not updated.
+func InsertMessageOptionAsIs(asIs bool) InsertMessageOption {
+	return func(ops *insertMessageOptions) {
+		ops.asIs = asIs
+	}
+}
+
 type selectMessagesOptions struct {
-	roomID          string
-	roleIDs         []int32
-	limitTimestamp  int64
-	offsetTimestamp int64
-	orders          []*scpb.OrderInfo
+	roomID           string
+	userID           string
+	roleIDs          []int32
+	limitTimestamp   int64
+	offsetTimestamp  int64
+	expiredTimestamp int64
+	orders           []*scpb.OrderInfo
 }
 
 type SelectMessagesOption func(*selectMessagesOptions)
@@ -21,6 +45,12 @@ func SelectMessagesOptionFilterByRoomID(roomID string) SelectMessagesOption {
 	}
 }
 
+func SelectMessagesOptionFilterByUserID(userID string) SelectMessagesOption {
+	return func(ops *selectMessagesOptions) {
+		ops.userID = userID
+	}
+}
+
 func SelectMessagesOptionFilterByRoleIDs(roleIDs []int32) SelectMessagesOption {
 	return func(ops *selectMessagesOptions) {
 		ops.roleIDs = roleIDs
@@ -45,12 +75,38 @@ func SelectMessagesOptionOffsetTimestamp(offsetTimestamp int64) SelectMessagesOp
 	}
 }
 
+func SelectMessagesOptionFilterByExpiredTimestamp(expiredTimestamp int64) SelectMessagesOption {
+	return func(ops *selectMessagesOptions) {
+		ops.expiredTimestamp = expiredTimestamp
+	}
+}
+
+type deleteMessagesOptions struct {
+	logicalDeleted int64
+	messageIDs     []string
+}
+
+type DeleteMessagesOption func(*deleteMessagesOptions)
+
+func DeleteMessagesOptionWithLogicalDeleted(logicalDeleted int64) DeleteMessagesOption {
+	return func(ops *deleteMessagesOptions) {
+		ops.logicalDeleted = logicalDeleted
+	}
+}
+
+func DeleteMessagesOptionFilterByMessageIDs(messageIDs []string) DeleteMessagesOption {
+	return func(ops *deleteMessagesOptions) {
+		ops.messageIDs = messageIDs
+	}
+}
+
 type messageStore interface {
 	createMessageStore()
 
-	InsertMessage(message *model.Message) error
+	InsertMessage(message *model.Message, opts ...InsertMessageOption) error
 	SelectMessages(limit, offset int32, opts ...SelectMessagesOption) ([]*model.Message, error)
 	SelectMessage(messageID string) (*model.Message, error)
 	SelectCountMessages(opts ...SelectMessagesOption) (int64, error)
 	UpdateMessage(message *model.Message) error
+	DeleteMessages(opts ...DeleteMessagesOption) error
 }
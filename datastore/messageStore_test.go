@@ -0,0 +1,283 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreSetUpMessage        = "[store] set up message"
+	TestStoreInsertMessage       = "[store] insert message test"
+	TestStoreSelectMessages      = "[store] select messages test"
+	TestStoreSelectMessage       = "[store] select message test"
+	TestStoreSelectCountMessages = "[store] select count messages test"
+	TestStoreUpdateMessage       = "[store] update message test"
+	TestStoreDeleteMessages      = "[store] delete messages test"
+	TestStoreTearDownMessage     = "[store] tear down message"
+)
+
+func testMessageStore(t *testing.T) {
+	testUserID := "message-store-user-id-0001"
+	testRoomID := "message-store-room-id-0001"
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestStoreSetUpMessage, func(t *testing.T) {
+		newUser := &model.User{}
+		newUser.UserID = testUserID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.LastAccessedTimestamp = nowTimestamp
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpMessage, err.Error())
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testRoomID
+		newRoom.UserID = testUserID
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err = Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpMessage, err.Error())
+		}
+	})
+
+	t.Run(TestStoreInsertMessage, func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			newMessage := &model.Message{}
+			newMessage.MessageID = fmt.Sprintf("message-store-message-id-%04d", i)
+			newMessage.RoomID = testRoomID
+			newMessage.UserID = testUserID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(fmt.Sprintf(`{"text":"message %d"}`, i))
+			newMessage.Role = config.RoleGeneral
+			newMessage.CreatedTimestamp = nowTimestamp + int64(i)
+			newMessage.ModifiedTimestamp = nowTimestamp + int64(i)
+			err := Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessage, err.Error())
+			}
+		}
+
+		room, err := Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessage, err.Error())
+		}
+		if room.LastMessage != "message 3" {
+			t.Fatalf("Failed to %s. Expected room.LastMessage to be \"message 3\", but it was \"%s\"", TestStoreInsertMessage, room.LastMessage)
+		}
+
+		newMessage := &model.Message{}
+		newMessage.MessageID = "message-store-message-id-9999"
+		newMessage.RoomID = "not-exist-room"
+		newMessage.UserID = testUserID
+		newMessage.Type = model.MessageTypeText
+		newMessage.Payload = []byte(`{"text":"not exist room"}`)
+		newMessage.CreatedTimestamp = nowTimestamp
+		newMessage.ModifiedTimestamp = nowTimestamp
+		err = Provider(ctx).InsertMessage(newMessage)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil for the room which does not exist, but it was nil", TestStoreInsertMessage)
+		}
+		message, err := Provider(ctx).SelectMessage("message-store-message-id-9999")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertMessage, err.Error())
+		}
+		if message != nil {
+			t.Fatalf("Failed to %s. Expected the message to be rolled back, but it was inserted", TestStoreInsertMessage)
+		}
+	})
+
+	t.Run(TestStoreSelectMessages, func(t *testing.T) {
+		messages, err := Provider(ctx).SelectMessages(10, 0, SelectMessagesOptionFilterByRoomID(testRoomID))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessages, err.Error())
+		}
+		if len(messages) != 3 {
+			t.Fatalf("Failed to %s. Expected messages count to be 3, but it was %d", TestStoreSelectMessages, len(messages))
+		}
+		for i, message := range messages {
+			expected := fmt.Sprintf("message-store-message-id-%04d", i+1)
+			if message.MessageID != expected {
+				t.Fatalf("Failed to %s. Expected messages[%d].MessageID to be %s, but it was %s", TestStoreSelectMessages, i, expected, message.MessageID)
+			}
+		}
+
+		orderInfo := &scpb.OrderInfo{
+			Field: "created",
+			Order: scpb.Order_Desc,
+		}
+		messages, err = Provider(ctx).SelectMessages(
+			2,
+			1,
+			SelectMessagesOptionFilterByRoomID(testRoomID),
+			SelectMessagesOptionOrders([]*scpb.OrderInfo{orderInfo}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessages, err.Error())
+		}
+		if len(messages) != 2 {
+			t.Fatalf("Failed to %s. Expected messages count to be 2, but it was %d", TestStoreSelectMessages, len(messages))
+		}
+		if messages[0].MessageID != "message-store-message-id-0002" || messages[1].MessageID != "message-store-message-id-0001" {
+			t.Fatalf("Failed to %s. Expected messages to be ordered by created desc, but they were %s and %s", TestStoreSelectMessages, messages[0].MessageID, messages[1].MessageID)
+		}
+
+		messages, err = Provider(ctx).SelectMessages(
+			10,
+			0,
+			SelectMessagesOptionFilterByRoomID(testRoomID),
+			SelectMessagesOptionLimitTimestamp(nowTimestamp+2),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessages, err.Error())
+		}
+		if len(messages) != 2 {
+			t.Fatalf("Failed to %s. Expected messages count to be 2, but it was %d", TestStoreSelectMessages, len(messages))
+		}
+
+		messages, err = Provider(ctx).SelectMessages(10, 0, SelectMessagesOptionFilterByRoleIDs([]int32{config.RoleGeneral + 1}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessages, err.Error())
+		}
+		if len(messages) != 0 {
+			t.Fatalf("Failed to %s. Expected messages count to be 0, but it was %d", TestStoreSelectMessages, len(messages))
+		}
+	})
+
+	t.Run(TestStoreSelectMessage, func(t *testing.T) {
+		message, err := Provider(ctx).SelectMessage("message-store-message-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessage, err.Error())
+		}
+		if message == nil {
+			t.Fatalf("Failed to %s. Expected message to be not nil, but it was nil", TestStoreSelectMessage)
+		}
+		if message.RoomID != testRoomID {
+			t.Fatalf("Failed to %s. Expected message.RoomID to be %s, but it was %s", TestStoreSelectMessage, testRoomID, message.RoomID)
+		}
+
+		message, err = Provider(ctx).SelectMessage("not-exist-message")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMessage, err.Error())
+		}
+		if message != nil {
+			t.Fatalf("Failed to %s. Expected message to be nil, but it was not nil", TestStoreSelectMessage)
+		}
+	})
+
+	t.Run(TestStoreSelectCountMessages, func(t *testing.T) {
+		count, err := Provider(ctx).SelectCountMessages(SelectMessagesOptionFilterByRoomID(testRoomID))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectCountMessages, err.Error())
+		}
+		if count != 3 {
+			t.Fatalf("Failed to %s. Expected count to be 3, but it was %d", TestStoreSelectCountMessages, count)
+		}
+	})
+
+	t.Run(TestStoreUpdateMessage, func(t *testing.T) {
+		message, err := Provider(ctx).SelectMessage("message-store-message-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreUpdateMessage, err.Error())
+		}
+
+		message.Payload = []byte(`{"text":"updated"}`)
+		err = Provider(ctx).UpdateMessage(message)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreUpdateMessage, err.Error())
+		}
+
+		updatedMessage, err := Provider(ctx).SelectMessage("message-store-message-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreUpdateMessage, err.Error())
+		}
+		bytes, err := updatedMessage.Payload.MarshalJSON()
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreUpdateMessage, err.Error())
+		}
+		if string(bytes) != `{"text":"updated"}` {
+			t.Fatalf("Failed to %s. Expected payload to be {\"text\":\"updated\"}, but it was %s", TestStoreUpdateMessage, string(bytes))
+		}
+	})
+
+	t.Run(TestStoreDeleteMessages, func(t *testing.T) {
+		err := Provider(ctx).DeleteMessages(
+			DeleteMessagesOptionWithLogicalDeleted(nowTimestamp),
+			DeleteMessagesOptionFilterByMessageIDs([]string{"message-store-message-id-0001"}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteMessages, err.Error())
+		}
+
+		count, err := Provider(ctx).SelectCountMessages(SelectMessagesOptionFilterByRoomID(testRoomID))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteMessages, err.Error())
+		}
+		if count != 2 {
+			t.Fatalf("Failed to %s. Expected count to be 2, but it was %d", TestStoreDeleteMessages, count)
+		}
+
+		message, err := Provider(ctx).SelectMessage("message-store-message-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteMessages, err.Error())
+		}
+		if message == nil || message.DeletedTimestamp != nowTimestamp {
+			t.Fatalf("Failed to %s. Expected the message to be logically deleted", TestStoreDeleteMessages)
+		}
+
+		err = Provider(ctx).DeleteMessages(
+			DeleteMessagesOptionFilterByMessageIDs([]string{"message-store-message-id-0001", "message-store-message-id-0002"}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteMessages, err.Error())
+		}
+
+		message, err = Provider(ctx).SelectMessage("message-store-message-id-0002")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteMessages, err.Error())
+		}
+		if message != nil {
+			t.Fatalf("Failed to %s. Expected the message to be physically deleted, but it was not nil", TestStoreDeleteMessages)
+		}
+
+		err = Provider(ctx).DeleteMessages()
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil without messageIds, but it was nil", TestStoreDeleteMessages)
+		}
+	})
+
+	t.Run(TestStoreTearDownMessage, func(t *testing.T) {
+		err := Provider(ctx).DeleteMessages(
+			DeleteMessagesOptionFilterByMessageIDs([]string{"message-store-message-id-0003"}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownMessage, err.Error())
+		}
+
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err = Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownMessage, err.Error())
+		}
+
+		deleteUser := &model.User{}
+		deleteUser.UserID = testUserID
+		deleteUser.DeletedTimestamp = 1
+		err = Provider(ctx).UpdateUser(deleteUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownMessage, err.Error())
+		}
+	})
+}
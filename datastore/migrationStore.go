@@ -0,0 +1,8 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type migrationStore interface {
+	MigrateDown(steps int) error
+	SelectSchemaMigrations() ([]*model.SchemaMigration, error)
+}
@@ -0,0 +1,137 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreSetUpMigration         = "[store] set up migration"
+	TestStoreSelectSchemaMigrations = "[store] select schema migrations test"
+	TestStoreMigrateDown            = "[store] migrate down test"
+	TestStoreMigrateUp              = "[store] migrate up test"
+)
+
+func testMigrationStore(t *testing.T) {
+	testRoomID := "migration-store-room-id-0001"
+	testMessageID := "migration-store-message-id-0001"
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestStoreSetUpMigration, func(t *testing.T) {
+		newRoom := &model.Room{}
+		newRoom.RoomID = testRoomID
+		newRoom.UserID = "migration-store-user-id-0001"
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpMigration, err.Error())
+		}
+
+		newMessage := &model.Message{}
+		newMessage.MessageID = testMessageID
+		newMessage.RoomID = testRoomID
+		newMessage.UserID = "migration-store-user-id-0001"
+		newMessage.Type = model.MessageTypeImage
+		newMessage.Payload = []byte(`{"mime":"image/png"}`)
+		newMessage.Role = config.RoleGeneral
+		newMessage.CreatedTimestamp = nowTimestamp
+		newMessage.ModifiedTimestamp = nowTimestamp
+		err = Provider(ctx).InsertMessage(newMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpMigration, err.Error())
+		}
+	})
+
+	t.Run(TestStoreSelectSchemaMigrations, func(t *testing.T) {
+		schemaMigrations, err := Provider(ctx).SelectSchemaMigrations()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectSchemaMigrations, err.Error())
+		}
+		if len(schemaMigrations) != len(migrations) {
+			t.Fatalf("Failed to %s. Expected schema migrations count to be %d, but it was %d", TestStoreSelectSchemaMigrations, len(migrations), len(schemaMigrations))
+		}
+		for i, sm := range schemaMigrations {
+			if sm.Version != migrations[i].version {
+				t.Fatalf("Failed to %s. Expected version to be %d, but it was %d", TestStoreSelectSchemaMigrations, migrations[i].version, sm.Version)
+			}
+			if !sm.IsApplied() {
+				t.Fatalf("Failed to %s. Expected version %d to be applied", TestStoreSelectSchemaMigrations, sm.Version)
+			}
+		}
+	})
+
+	t.Run(TestStoreMigrateDown, func(t *testing.T) {
+		// Revert down to version 4 to drop the columns added by version 5
+		err := Provider(ctx).MigrateDown(len(migrations) - 4)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateDown, err.Error())
+		}
+
+		schemaMigrations, err := Provider(ctx).SelectSchemaMigrations()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateDown, err.Error())
+		}
+		for _, sm := range schemaMigrations {
+			if sm.Version > 4 && sm.IsApplied() {
+				t.Fatalf("Failed to %s. Expected version %d to be pending", TestStoreMigrateDown, sm.Version)
+			}
+			if sm.Version <= 4 && !sm.IsApplied() {
+				t.Fatalf("Failed to %s. Expected version %d to be applied", TestStoreMigrateDown, sm.Version)
+			}
+		}
+
+		master := RdbStore(config.Config().Datastore.Database).master()
+		exist, err := existColumn(master, tableNameMessage, "expires")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateDown, err.Error())
+		}
+		if exist {
+			t.Fatalf("Failed to %s. Expected expires column to be dropped", TestStoreMigrateDown)
+		}
+	})
+
+	t.Run(TestStoreMigrateUp, func(t *testing.T) {
+		err := Provider(ctx).CreateTables()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateUp, err.Error())
+		}
+
+		schemaMigrations, err := Provider(ctx).SelectSchemaMigrations()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateUp, err.Error())
+		}
+		for _, sm := range schemaMigrations {
+			if !sm.IsApplied() {
+				t.Fatalf("Failed to %s. Expected version %d to be applied", TestStoreMigrateUp, sm.Version)
+			}
+		}
+
+		master := RdbStore(config.Config().Datastore.Database).master()
+		exist, err := existColumn(master, tableNameMessage, "expires")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateUp, err.Error())
+		}
+		if !exist {
+			t.Fatalf("Failed to %s. Expected expires column to be added", TestStoreMigrateUp)
+		}
+
+		// The last message details dropped with the columns are backfilled from the messages
+		room, err := Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreMigrateUp, err.Error())
+		}
+		if room.LastMessageID != testMessageID {
+			t.Fatalf("Failed to %s. Expected room.LastMessageID to be \"%s\", but it was \"%s\"", TestStoreMigrateUp, testMessageID, room.LastMessageID)
+		}
+		if room.LastMessageType != model.MessageTypeImage {
+			t.Fatalf("Failed to %s. Expected room.LastMessageType to be \"%s\", but it was \"%s\"", TestStoreMigrateUp, model.MessageTypeImage, room.LastMessageType)
+		}
+	})
+}
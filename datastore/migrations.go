@@ -0,0 +1,767 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+const (
+	dialectSQLite   = "sqlite"
+	dialectMySQL    = "mysql"
+	dialectPostgres = "postgres"
+)
+
+// migration is a versioned schema change.
+// up and down receive the master of the database and have to be written for every dialect.
+// Tables are created from the gorp table maps of the current models, so the statements that
+// add columns to existing tables have to tolerate that the columns already exist.
+type migration struct {
+	version     int
+	description string
+	up          func(dbMap *gorp.DbMap) error
+	down        func(dbMap *gorp.DbMap) error
+}
+
+// migrations are applied in the order of the version. Never change or remove the applied ones, add a new one instead.
+var migrations = []*migration{
+	{
+		version:     1,
+		description: "create tables",
+		up: func(dbMap *gorp.DbMap) error {
+			return createTables(dbMap,
+				model.AppClient{},
+				model.Asset{},
+				model.BlockUser{},
+				model.Device{},
+				model.Message{},
+				model.Room{},
+				model.RoomUser{},
+				model.Setting{},
+				model.Subscription{},
+				model.User{},
+				model.UserRole{},
+				model.Webhook{},
+			)
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap,
+				tableNameAppClient,
+				tableNameAsset,
+				tableNameBlockUser,
+				tableNameDevice,
+				tableNameMessage,
+				tableNameRoom,
+				tableNameRoomUser,
+				tableNameSetting,
+				tableNameSubscription,
+				tableNameUser,
+				tableNameUserRole,
+				tableNameWebhook,
+			)
+		},
+	},
+	{
+		version:     2,
+		description: "add index to message",
+		up: func(dbMap *gorp.DbMap) error {
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s ADD INDEX room_id_deleted_created (room_id, deleted, created);", tableNameMessage)
+				_, err := dbMap.Exec(query)
+				if err != nil && !strings.Contains(err.Error(), "Duplicate key name") {
+					return err
+				}
+				return nil
+			default:
+				// Index names of SQLite and PostgreSQL are unique in the schema, so it's prefixed with the table name
+				query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_room_id_deleted_created ON %s(room_id, deleted, created);", tableNameMessage, tableNameMessage)
+			}
+			_, err := dbMap.Exec(query)
+			return err
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s DROP INDEX room_id_deleted_created;", tableNameMessage)
+			default:
+				query = fmt.Sprintf("DROP INDEX IF EXISTS %s_room_id_deleted_created;", tableNameMessage)
+			}
+			_, err := dbMap.Exec(query)
+			return err
+		},
+	},
+	{
+		version:     3,
+		description: "create mention table and add mention count to room user",
+		up: func(dbMap *gorp.DbMap) error {
+			err := createTables(dbMap, model.Mention{})
+			if err != nil {
+				return err
+			}
+			return addColumn(dbMap, tableNameRoomUser, "mention_count", map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "int not null default 0",
+				dialectPostgres: "integer not null default 0",
+			})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			err := dropColumn(dbMap, tableNameRoomUser, "mention_count")
+			if err != nil {
+				return err
+			}
+			return dropTables(dbMap, tableNameMention)
+		},
+	},
+	{
+		version:     4,
+		description: "create scheduled message table",
+		up: func(dbMap *gorp.DbMap) error {
+			return createTables(dbMap, model.ScheduledMessage{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap, tableNameScheduledMessage)
+		},
+	},
+	{
+		version:     5,
+		description: "add message expiry and room retention",
+		up: func(dbMap *gorp.DbMap) error {
+			bigint := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "bigint not null default 0",
+				dialectPostgres: "bigint not null default 0",
+			}
+			err := addColumn(dbMap, tableNameMessage, "expires", bigint)
+			if err != nil {
+				return err
+			}
+			err = addColumn(dbMap, tableNameRoom, "retention_max_age", bigint)
+			if err != nil {
+				return err
+			}
+			return addColumn(dbMap, tableNameRoom, "retention_max_count", bigint)
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			err := dropColumn(dbMap, tableNameMessage, "expires")
+			if err != nil {
+				return err
+			}
+			err = dropColumn(dbMap, tableNameRoom, "retention_max_age")
+			if err != nil {
+				return err
+			}
+			return dropColumn(dbMap, tableNameRoom, "retention_max_count")
+		},
+	},
+	{
+		version:     6,
+		description: "create workspace table",
+		up: func(dbMap *gorp.DbMap) error {
+			return createTables(dbMap, model.Workspace{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap, tableNameWorkspace)
+		},
+	},
+	{
+		version:     7,
+		description: "create outbox event table",
+		up: func(dbMap *gorp.DbMap) error {
+			err := createTables(dbMap, model.OutboxEvent{})
+			if err != nil {
+				return err
+			}
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s ADD INDEX dispatched_failed_next_attempt (dispatched, failed, next_attempt);", tableNameOutboxEvent)
+				_, err := dbMap.Exec(query)
+				if err != nil && !strings.Contains(err.Error(), "Duplicate key name") {
+					return err
+				}
+				return nil
+			default:
+				query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_dispatched_failed_next_attempt ON %s(dispatched, failed, next_attempt);", tableNameOutboxEvent, tableNameOutboxEvent)
+			}
+			_, err = dbMap.Exec(query)
+			return err
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap, tableNameOutboxEvent)
+		},
+	},
+	{
+		version:     8,
+		description: "add user to asset and create audit log table",
+		up: func(dbMap *gorp.DbMap) error {
+			err := addColumn(dbMap, tableNameAsset, "user_id", map[string]string{
+				dialectSQLite:   "varchar(255) not null default ''",
+				dialectMySQL:    "varchar(255) not null default ''",
+				dialectPostgres: "varchar(255) not null default ''",
+			})
+			if err != nil {
+				return err
+			}
+			return createTables(dbMap, model.AuditLog{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			err := dropTables(dbMap, tableNameAuditLog)
+			if err != nil {
+				return err
+			}
+			return dropColumn(dbMap, tableNameAsset, "user_id")
+		},
+	},
+	{
+		version:     9,
+		description: "add index to audit log",
+		up: func(dbMap *gorp.DbMap) error {
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s ADD INDEX target_type_target_id_created (target_type, target_id, created);", tableNameAuditLog)
+				_, err := dbMap.Exec(query)
+				if err != nil && !strings.Contains(err.Error(), "Duplicate key name") {
+					return err
+				}
+				return nil
+			default:
+				query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_target_type_target_id_created ON %s(target_type, target_id, created);", tableNameAuditLog, tableNameAuditLog)
+			}
+			_, err := dbMap.Exec(query)
+			return err
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s DROP INDEX target_type_target_id_created;", tableNameAuditLog)
+			default:
+				query = fmt.Sprintf("DROP INDEX IF EXISTS %s_target_type_target_id_created;", tableNameAuditLog)
+			}
+			_, err := dbMap.Exec(query)
+			return err
+		},
+	},
+	{
+		version:     10,
+		description: "add speech roles to room",
+		up: func(dbMap *gorp.DbMap) error {
+			return addColumn(dbMap, tableNameRoom, "speech_roles", map[string]string{
+				dialectSQLite:   "varchar(255) not null default ''",
+				dialectMySQL:    "varchar(255) not null default ''",
+				dialectPostgres: "varchar(255) not null default ''",
+			})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropColumn(dbMap, tableNameRoom, "speech_roles")
+		},
+	},
+	{
+		version:     11,
+		description: "add room role to room user",
+		up: func(dbMap *gorp.DbMap) error {
+			err := addColumn(dbMap, tableNameRoomUser, "room_role", map[string]string{
+				dialectSQLite:   "varchar(255) not null default 'member'",
+				dialectMySQL:    "varchar(255) not null default 'member'",
+				dialectPostgres: "varchar(255) not null default 'member'",
+			})
+			if err != nil {
+				return err
+			}
+
+			// The creators of the rooms become the owners
+			query := fmt.Sprintf("UPDATE %s SET room_role=? WHERE room_role=? AND user_id=(SELECT user_id FROM %s WHERE %s.room_id=%s.room_id);", tableNameRoomUser, tableNameRoom, tableNameRoom, tableNameRoomUser)
+			_, err = dbMap.Exec(rebind(dbMap, query), string(model.RoomRoleOwner), string(model.RoomRoleMember))
+			return err
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropColumn(dbMap, tableNameRoomUser, "room_role")
+		},
+	},
+	{
+		version:     12,
+		description: "create room invitation and room invite link tables",
+		up: func(dbMap *gorp.DbMap) error {
+			return createTables(dbMap, model.RoomInvitation{}, model.RoomInviteLink{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap, tableNameRoomInviteLink, tableNameRoomInvitation)
+		},
+	},
+	{
+		version:     13,
+		description: "add tags to room for public room directory",
+		up: func(dbMap *gorp.DbMap) error {
+			err := addColumn(dbMap, tableNameRoom, "tags", map[string]string{
+				dialectSQLite:   "varchar(1024) not null default ''",
+				dialectMySQL:    "varchar(1024) not null default ''",
+				dialectPostgres: "varchar(1024) not null default ''",
+			})
+			if err != nil {
+				return err
+			}
+
+			// Set the tags of the existing rooms from their metaData
+			var rooms []*model.Room
+			query := fmt.Sprintf("SELECT * FROM %s;", tableNameRoom)
+			_, err = dbMap.Select(&rooms, query)
+			if err != nil {
+				return err
+			}
+			for _, room := range rooms {
+				room.SetTags()
+				if room.Tags == "" {
+					continue
+				}
+				query = fmt.Sprintf("UPDATE %s SET tags=? WHERE room_id=?;", tableNameRoom)
+				_, err = dbMap.Exec(rebind(dbMap, query), room.Tags, room.RoomID)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropColumn(dbMap, tableNameRoom, "tags")
+		},
+	},
+	{
+		version:     14,
+		description: "create pinned message table",
+		up: func(dbMap *gorp.DbMap) error {
+			return createTables(dbMap, model.PinnedMessage{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropTables(dbMap, tableNamePinnedMessage)
+		},
+	},
+	{
+		version:     15,
+		description: "add room archiving and deleted room user table for restoring rooms",
+		up: func(dbMap *gorp.DbMap) error {
+			err := addColumn(dbMap, tableNameRoom, "archived", map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "bigint not null default 0",
+				dialectPostgres: "bigint not null default 0",
+			})
+			if err != nil {
+				return err
+			}
+			return createTables(dbMap, model.DeletedRoomUser{})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			err := dropTables(dbMap, tableNameDeletedRoomUser)
+			if err != nil {
+				return err
+			}
+			return dropColumn(dbMap, tableNameRoom, "archived")
+		},
+	},
+	{
+		version:     16,
+		description: "add personal settings to room user",
+		up: func(dbMap *gorp.DbMap) error {
+			boolean := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "boolean not null default false",
+				dialectPostgres: "boolean not null default false",
+			}
+			bigint := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "bigint not null default 0",
+				dialectPostgres: "bigint not null default 0",
+			}
+			for _, columnName := range []string{"favorite", "hidden", "muted", "archived"} {
+				err := addColumn(dbMap, tableNameRoomUser, columnName, boolean)
+				if err != nil {
+					return err
+				}
+			}
+			err := addColumn(dbMap, tableNameRoomUser, "sort_key", bigint)
+			if err != nil {
+				return err
+			}
+			return addColumn(dbMap, tableNameRoomUser, "last_read", bigint)
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			for _, columnName := range []string{"favorite", "hidden", "muted", "archived", "sort_key", "last_read"} {
+				err := dropColumn(dbMap, tableNameRoomUser, columnName)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     17,
+		description: "add last message details to room and index user_id of room user",
+		up: func(dbMap *gorp.DbMap) error {
+			for _, columnName := range []string{"last_message_id", "last_message_type", "last_message_user_id"} {
+				err := addColumn(dbMap, tableNameRoom, columnName, map[string]string{
+					dialectSQLite:   "varchar(255) not null default ''",
+					dialectMySQL:    "varchar(255) not null default ''",
+					dialectPostgres: "varchar(255) not null default ''",
+				})
+				if err != nil {
+					return err
+				}
+			}
+
+			// The rooms of the user are selected by user_id, which is not the first column of the unique index
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s ADD INDEX user_id (user_id);", tableNameRoomUser)
+				_, err := dbMap.Exec(query)
+				if err != nil && !strings.Contains(err.Error(), "Duplicate key name") {
+					return err
+				}
+				return nil
+			default:
+				query = fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s_user_id ON %s(user_id);", tableNameRoomUser, tableNameRoomUser)
+			}
+			_, err := dbMap.Exec(query)
+			return err
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			var query string
+			switch dialect(dbMap) {
+			case dialectMySQL:
+				query = fmt.Sprintf("ALTER TABLE %s DROP INDEX user_id;", tableNameRoomUser)
+			default:
+				query = fmt.Sprintf("DROP INDEX IF EXISTS %s_user_id;", tableNameRoomUser)
+			}
+			_, err := dbMap.Exec(query)
+			if err != nil {
+				return err
+			}
+
+			for _, columnName := range []string{"last_message_id", "last_message_type", "last_message_user_id"} {
+				err := dropColumn(dbMap, tableNameRoom, columnName)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     18,
+		description: "add payload to outbox event",
+		up: func(dbMap *gorp.DbMap) error {
+			return addColumn(dbMap, tableNameOutboxEvent, "payload", map[string]string{
+				dialectSQLite:   "text not null default ''",
+				dialectMySQL:    "text not null",
+				dialectPostgres: "varchar(65535) not null default ''",
+			})
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			return dropColumn(dbMap, tableNameOutboxEvent, "payload")
+		},
+	},
+	{
+		version:     19,
+		description: "keep all columns of room user in deleted room user",
+		up: func(dbMap *gorp.DbMap) error {
+			integer := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "int not null default 0",
+				dialectPostgres: "integer not null default 0",
+			}
+			boolean := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "boolean not null default false",
+				dialectPostgres: "boolean not null default false",
+			}
+			bigint := map[string]string{
+				dialectSQLite:   "integer not null default 0",
+				dialectMySQL:    "bigint not null default 0",
+				dialectPostgres: "bigint not null default 0",
+			}
+			for _, columnName := range []string{"unread_count", "mention_count"} {
+				err := addColumn(dbMap, tableNameDeletedRoomUser, columnName, integer)
+				if err != nil {
+					return err
+				}
+			}
+			for _, columnName := range []string{"favorite", "hidden", "muted", "archived"} {
+				err := addColumn(dbMap, tableNameDeletedRoomUser, columnName, boolean)
+				if err != nil {
+					return err
+				}
+			}
+			for _, columnName := range []string{"sort_key", "last_read"} {
+				err := addColumn(dbMap, tableNameDeletedRoomUser, columnName, bigint)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			for _, columnName := range []string{"unread_count", "mention_count", "favorite", "hidden", "muted", "archived", "sort_key", "last_read"} {
+				err := dropColumn(dbMap, tableNameDeletedRoomUser, columnName)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	},
+	{
+		version:     20,
+		description: "backfill last message details of room",
+		up: func(dbMap *gorp.DbMap) error {
+			// The rooms which had messages before version 17 have no details of the last messages,
+			// so the previews of them are generated from the text stored at that time
+			var roomIDs []string
+			query := fmt.Sprintf("SELECT r.room_id FROM %s AS r WHERE r.last_message_id='' AND EXISTS (SELECT 1 FROM %s AS m WHERE m.room_id=r.room_id AND m.deleted=0);", tableNameRoom, tableNameMessage)
+			_, err := dbMap.Select(&roomIDs, query)
+			if err != nil {
+				return err
+			}
+
+			for _, roomID := range roomIDs {
+				tx, err := dbMap.Begin()
+				if err != nil {
+					return err
+				}
+				err = rdbUpdateRoomLastMessage(context.Background(), dbMap, tx, roomID)
+				if err != nil {
+					tx.Rollback()
+					return err
+				}
+				err = tx.Commit()
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+		down: func(dbMap *gorp.DbMap) error {
+			// The details are dropped with the columns by version 17
+			return nil
+		},
+	},
+}
+
+func dialect(dbMap *gorp.DbMap) string {
+	switch dbMap.Dialect.(type) {
+	case gorp.MySQLDialect:
+		return dialectMySQL
+	case gorp.PostgresDialect:
+		return dialectPostgres
+	default:
+		return dialectSQLite
+	}
+}
+
+// createTables creates the tables of the models from their gorp table maps if they don't exist
+func createTables(dbMap *gorp.DbMap, models ...interface{}) error {
+	for _, m := range models {
+		tableMap, err := dbMap.TableFor(reflect.TypeOf(m), false)
+		if err != nil {
+			return err
+		}
+		_, err = dbMap.Exec(tableMap.SqlForCreate(true))
+		if err != nil {
+			return errors.Wrap(err, tableMap.TableName)
+		}
+	}
+	return nil
+}
+
+func dropTables(dbMap *gorp.DbMap, tableNames ...string) error {
+	for _, tableName := range tableNames {
+		_, err := dbMap.Exec(fmt.Sprintf("DROP TABLE IF EXISTS %s;", tableName))
+		if err != nil {
+			return errors.Wrap(err, tableName)
+		}
+	}
+	return nil
+}
+
+func existColumn(dbMap *gorp.DbMap, tableName, columnName string) (bool, error) {
+	var query string
+	switch dialect(dbMap) {
+	case dialectMySQL:
+		query = "SELECT count(*) FROM information_schema.columns WHERE table_schema=DATABASE() AND table_name=? AND column_name=?;"
+	case dialectPostgres:
+		query = "SELECT count(*) FROM information_schema.columns WHERE table_schema=current_schema() AND table_name=? AND column_name=?;"
+	default:
+		query = "SELECT count(*) FROM pragma_table_info(?) WHERE name=?;"
+	}
+	count, err := dbMap.SelectInt(rebind(dbMap, query), tableName, columnName)
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// addColumn adds the column unless the table already has it. definitions are keyed by the dialect.
+func addColumn(dbMap *gorp.DbMap, tableName, columnName string, definitions map[string]string) error {
+	exist, err := existColumn(dbMap, tableName, columnName)
+	if err != nil {
+		return errors.Wrap(err, tableName)
+	}
+	if exist {
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s;", tableName, columnName, definitions[dialect(dbMap)])
+	_, err = dbMap.Exec(query)
+	if err != nil {
+		return errors.Wrap(err, tableName)
+	}
+	return nil
+}
+
+// dropColumn drops the column if the table has it
+func dropColumn(dbMap *gorp.DbMap, tableName, columnName string) error {
+	exist, err := existColumn(dbMap, tableName, columnName)
+	if err != nil {
+		return errors.Wrap(err, tableName)
+	}
+	if !exist {
+		return nil
+	}
+
+	if dialect(dbMap) == dialectSQLite {
+		err = rebuildTableWithoutColumn(dbMap, tableName, columnName)
+		if err != nil {
+			return errors.Wrap(err, tableName)
+		}
+		return nil
+	}
+
+	query := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", tableName, columnName)
+	_, err = dbMap.Exec(query)
+	if err != nil {
+		return errors.Wrap(err, tableName)
+	}
+	return nil
+}
+
+// rebuildTableWithoutColumn drops the column of SQLite by rebuilding the table,
+// because DROP COLUMN is not supported by SQLite older than 3.35.0 bundled with go-sqlite3.
+// The table is created from its CREATE TABLE statement without the column definition,
+// and the indexes which don't use the column are created again.
+func rebuildTableWithoutColumn(dbMap *gorp.DbMap, tableName, columnName string) error {
+	createSQL, err := dbMap.SelectStr("SELECT sql FROM sqlite_master WHERE type='table' AND name=?;", tableName)
+	if err != nil {
+		return err
+	}
+
+	var indexSQLs []string
+	_, err = dbMap.Select(&indexSQLs, "SELECT sql FROM sqlite_master WHERE type='index' AND tbl_name=? AND sql IS NOT NULL;", tableName)
+	if err != nil {
+		return err
+	}
+
+	start := strings.Index(createSQL, "(")
+	end := strings.LastIndex(createSQL, ")")
+	if start < 0 || end < start {
+		return fmt.Errorf("Unexpected table definition. %s", createSQL)
+	}
+
+	var definitions []string
+	var columnNames []string
+	for _, definition := range splitDefinitions(createSQL[start+1 : end]) {
+		name := strings.Trim(strings.Fields(definition)[0], "\"`[]")
+		if name == columnName {
+			continue
+		}
+		if usesColumn(definition, columnName) {
+			return fmt.Errorf("The constraint uses the column %s. %s", columnName, definition)
+		}
+		definitions = append(definitions, definition)
+		if !isTableConstraint(name) {
+			columnNames = append(columnNames, name)
+		}
+	}
+
+	tx, err := dbMap.Begin()
+	if err != nil {
+		return err
+	}
+
+	newTableName := fmt.Sprintf("%s_new", tableName)
+	columns := strings.Join(columnNames, ", ")
+	queries := []string{
+		fmt.Sprintf("CREATE TABLE %s (%s);", newTableName, strings.Join(definitions, ", ")),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s;", newTableName, columns, columns, tableName),
+		fmt.Sprintf("DROP TABLE %s;", tableName),
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s;", newTableName, tableName),
+	}
+	for _, indexSQL := range indexSQLs {
+		if !usesColumn(indexSQL, columnName) {
+			queries = append(queries, indexSQL)
+		}
+	}
+
+	for _, query := range queries {
+		_, err = tx.Exec(query)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// splitDefinitions splits the column definitions and the table constraints by the commas out of the parentheses and the quotes
+func splitDefinitions(definitions string) []string {
+	var splitted []string
+	depth := 0
+	var quote rune
+	begin := 0
+	for i, r := range definitions {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			splitted = append(splitted, strings.TrimSpace(definitions[begin:i]))
+			begin = i + 1
+		}
+	}
+	return append(splitted, strings.TrimSpace(definitions[begin:]))
+}
+
+func isTableConstraint(name string) bool {
+	switch strings.ToLower(name) {
+	case "constraint", "primary", "unique", "check", "foreign":
+		return true
+	}
+	return false
+}
+
+// usesColumn returns whether the statement has the column name as an identifier
+func usesColumn(statement, columnName string) bool {
+	for _, token := range strings.FieldsFunc(statement, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) {
+		if token == columnName {
+			return true
+		}
+	}
+	return false
+}
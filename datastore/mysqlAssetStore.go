@@ -8,11 +8,21 @@ func (p *mysqlProvider) createAssetStore() {
 }
 
 func (p *mysqlProvider) InsertAsset(asset *model.Asset) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	return rdbInsertAsset(p.ctx, master, asset)
 }
 
 func (p *mysqlProvider) SelectAsset(assetID string) (*model.Asset, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectAsset(p.ctx, replica, assetID)
 }
+
+func (p *mysqlProvider) SelectAssets(limit, offset int32, opts ...SelectAssetsOption) ([]*model.Asset, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectAssets(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *mysqlProvider) DeleteAssets(opts ...DeleteAssetsOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbDeleteAssets(p.ctx, master, opts...)
+}
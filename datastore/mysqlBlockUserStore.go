@@ -12,7 +12,7 @@ func (p *mysqlProvider) createBlockUserStore() {
 }
 
 func (p *mysqlProvider) InsertBlockUsers(blockUsers []*model.BlockUser, opts ...InsertBlockUsersOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting block users")
@@ -38,32 +38,32 @@ func (p *mysqlProvider) InsertBlockUsers(blockUsers []*model.BlockUser, opts ...
 }
 
 func (p *mysqlProvider) SelectBlockUsers(userID string) ([]*model.MiniUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectBlockUsers(p.ctx, replica, userID)
 }
 
 func (p *mysqlProvider) SelectBlockUserIDs(userID string) ([]string, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectBlockUserIDs(p.ctx, replica, userID)
 }
 
 func (p *mysqlProvider) SelectBlockedUsers(userID string) ([]*model.MiniUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectBlockedUsers(p.ctx, replica, userID)
 }
 
 func (p *mysqlProvider) SelectBlockedUserIDs(userID string) ([]string, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectBlockedUserIDs(p.ctx, replica, userID)
 }
 
 func (p *mysqlProvider) SelectBlockUser(userID, blockUserID string) (*model.BlockUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectBlockUser(p.ctx, replica, userID, blockUserID)
 }
 
 func (p *mysqlProvider) DeleteBlockUsers(opts ...DeleteBlockUsersOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting block users")
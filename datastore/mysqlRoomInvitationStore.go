@@ -0,0 +1,88 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *mysqlProvider) createRoomInvitationStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateRoomInvitationStore(p.ctx, master)
+}
+
+func (p *mysqlProvider) InsertRoomInvitations(invitations []*model.RoomInvitation, opts ...InsertRoomInvitationsOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting room invitations")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertRoomInvitations(p.ctx, master, tx, invitations, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting room invitations")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *mysqlProvider) SelectRoomInvitations(limit, offset int32, opts ...SelectRoomInvitationsOption) ([]*model.RoomInvitation, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomInvitations(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *mysqlProvider) SelectCountRoomInvitations(opts ...SelectRoomInvitationsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountRoomInvitations(p.ctx, replica, opts...)
+}
+
+func (p *mysqlProvider) SelectRoomInvitation(invitationID string) (*model.RoomInvitation, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomInvitation(p.ctx, replica, invitationID)
+}
+
+func (p *mysqlProvider) UpdateRoomInvitation(invitation *model.RoomInvitation, opts ...UpdateRoomInvitationOption) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room invitation")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	updated, err := rdbUpdateRoomInvitation(p.ctx, master, tx, invitation, opts...)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !updated {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room invitation")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *mysqlProvider) ExpireRoomInvitations(nowTimestamp int64) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbExpireRoomInvitations(p.ctx, master, nowTimestamp)
+}
@@ -0,0 +1,62 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *mysqlProvider) createRoomInviteLinkStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateRoomInviteLinkStore(p.ctx, master)
+}
+
+func (p *mysqlProvider) InsertRoomInviteLink(inviteLink *model.RoomInviteLink) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertRoomInviteLink(p.ctx, master, inviteLink)
+}
+
+func (p *mysqlProvider) SelectRoomInviteLinks(roomID string) ([]*model.RoomInviteLink, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomInviteLinks(p.ctx, replica, roomID)
+}
+
+func (p *mysqlProvider) SelectRoomInviteLink(inviteLinkID string) (*model.RoomInviteLink, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomInviteLink(p.ctx, replica, inviteLinkID)
+}
+
+func (p *mysqlProvider) DeleteRoomInviteLink(inviteLink *model.RoomInviteLink) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbDeleteRoomInviteLink(p.ctx, master, inviteLink)
+}
+
+func (p *mysqlProvider) UseRoomInviteLink(inviteLink *model.RoomInviteLink, roomUser *model.RoomUser, opts ...UseRoomInviteLinkOption) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while using room invite link")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	used, err := rdbUseRoomInviteLink(p.ctx, master, tx, inviteLink, roomUser, opts...)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !used {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while using room invite link")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
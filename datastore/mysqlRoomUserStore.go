@@ -12,7 +12,7 @@ func (p *mysqlProvider) createRoomUserStore() {
 }
 
 func (p *mysqlProvider) InsertRoomUsers(roomUsers []*model.RoomUser, opts ...InsertRoomUsersOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting room users")
@@ -38,42 +38,42 @@ func (p *mysqlProvider) InsertRoomUsers(roomUsers []*model.RoomUser, opts ...Ins
 }
 
 func (p *mysqlProvider) SelectRoomUsers(opts ...SelectRoomUsersOption) ([]*model.RoomUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRoomUsers(p.ctx, replica, opts...)
 }
 
 func (p *mysqlProvider) SelectRoomUser(roomID, userID string) (*model.RoomUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRoomUser(p.ctx, replica, roomID, userID)
 }
 
 func (p *mysqlProvider) SelectRoomUserOfOneOnOne(myUserID, opponentUserID string) (*model.RoomUser, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRoomUserOfOneOnOne(p.ctx, replica, myUserID, opponentUserID)
 }
 
 func (p *mysqlProvider) SelectUserIDsOfRoomUser(opts ...SelectUserIDsOfRoomUserOption) ([]string, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectUserIDsOfRoomUser(p.ctx, replica, opts...)
 }
 
 func (p *mysqlProvider) SelectMiniRoom(roomID, userID string) (*model.MiniRoom, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectMiniRoom(p.ctx, replica, roomID, userID)
 }
 
 func (p *mysqlProvider) SelectMiniRooms(limit, offset int32, userID string, opts ...SelectMiniRoomsOption) ([]*model.MiniRoom, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectMiniRooms(p.ctx, replica, limit, offset, userID, opts...)
 }
 
 func (p *mysqlProvider) SelectCountMiniRooms(userID string, opts ...SelectMiniRoomsOption) (int64, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectCountMiniRooms(p.ctx, replica, userID, opts...)
 }
 
 func (p *mysqlProvider) UpdateRoomUser(roomUser *model.RoomUser) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while updating room user")
@@ -99,7 +99,7 @@ func (p *mysqlProvider) UpdateRoomUser(roomUser *model.RoomUser) error {
 }
 
 func (p *mysqlProvider) DeleteRoomUsers(opts ...DeleteRoomUsersOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting room users")
@@ -0,0 +1,33 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *mysqlProvider) createScheduledMessageStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateScheduledMessageStore(p.ctx, master)
+}
+
+func (p *mysqlProvider) InsertScheduledMessage(scheduledMessage *model.ScheduledMessage) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertScheduledMessage(p.ctx, master, scheduledMessage)
+}
+
+func (p *mysqlProvider) SelectScheduledMessages(limit, offset int32, opts ...SelectScheduledMessagesOption) ([]*model.ScheduledMessage, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectScheduledMessages(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *mysqlProvider) SelectCountScheduledMessages(opts ...SelectScheduledMessagesOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountScheduledMessages(p.ctx, replica, opts...)
+}
+
+func (p *mysqlProvider) SelectScheduledMessage(scheduledMessageID string) (*model.ScheduledMessage, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectScheduledMessage(p.ctx, replica, scheduledMessageID)
+}
+
+func (p *mysqlProvider) UpdateScheduledMessage(scheduledMessage *model.ScheduledMessage) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbUpdateScheduledMessage(p.ctx, master, scheduledMessage)
+}
@@ -12,7 +12,7 @@ func (p *mysqlProvider) createUserRoleStore() {
 }
 
 func (p *mysqlProvider) InsertUserRoles(urs []*model.UserRole, opts ...InsertUserRolesOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -38,17 +38,17 @@ func (p *mysqlProvider) InsertUserRoles(urs []*model.UserRole, opts ...InsertUse
 }
 
 func (p *mysqlProvider) SelectRolesOfUserRole(userID string) ([]int32, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRolesOfUserRole(p.ctx, replica, userID)
 }
 
 func (p *mysqlProvider) SelectUserIDsOfUserRole(roleID int32) ([]string, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectUserIDsOfUserRole(p.ctx, replica, roleID)
 }
 
 func (p *mysqlProvider) DeleteUserRoles(opts ...DeleteUserRolesOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting user roles")
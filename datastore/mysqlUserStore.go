@@ -12,7 +12,7 @@ func (p *mysqlProvider) createUserStore() {
 }
 
 func (p *mysqlProvider) InsertUser(user *model.User, opts ...InsertUserOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user")
@@ -38,27 +38,27 @@ func (p *mysqlProvider) InsertUser(user *model.User, opts ...InsertUserOption) e
 }
 
 func (p *mysqlProvider) SelectUsers(limit, offset int32, opts ...SelectUsersOption) ([]*model.User, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectUsers(p.ctx, replica, limit, offset, opts...)
 }
 
 func (p *mysqlProvider) SelectUser(userID string, opts ...SelectUserOption) (*model.User, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectUser(p.ctx, replica, userID, opts...)
 }
 
 func (p *mysqlProvider) SelectCountUsers() (int64, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectCountUsers(p.ctx, replica)
 }
 
 func (p *mysqlProvider) SelectUserIDsOfUser(userIDs []string) ([]string, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectUserIDsOfUser(p.ctx, replica, userIDs)
 }
 
 func (p *mysqlProvider) UpdateUser(user *model.User, opts ...UpdateUserOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while updating user")
@@ -83,7 +83,33 @@ func (p *mysqlProvider) UpdateUser(user *model.User, opts ...UpdateUserOption) e
 	return nil
 }
 
+func (p *mysqlProvider) EraseUser(user *model.User, opts ...EraseUserOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbEraseUser(p.ctx, master, tx, user, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
 func (p *mysqlProvider) SelectContacts(userID string, limit, offset int32, opts ...SelectContactsOption) ([]*model.User, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectContacts(p.ctx, replica, userID, limit, offset, opts...)
 }
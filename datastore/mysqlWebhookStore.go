@@ -7,7 +7,12 @@ func (p *mysqlProvider) createWebhookStore() {
 	rdbCreateWebhookStore(p.ctx, master)
 }
 
+func (p *mysqlProvider) InsertWebhook(webhook *model.Webhook) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertWebhook(p.ctx, master, webhook)
+}
+
 func (p *mysqlProvider) SelectWebhooks(event model.WebhookEventType, opts ...SelectWebhooksOption) ([]*model.Webhook, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectWebhooks(p.ctx, replica, event, opts...)
 }
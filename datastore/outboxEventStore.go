@@ -0,0 +1,40 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type selectOutboxEventsOptions struct {
+	dueTimestamp int64
+}
+
+type SelectOutboxEventsOption func(*selectOutboxEventsOptions)
+
+// SelectOutboxEventsOptionFilterByDueTimestamp selects the events whose next attempt is due
+func SelectOutboxEventsOptionFilterByDueTimestamp(dueTimestamp int64) SelectOutboxEventsOption {
+	return func(ops *selectOutboxEventsOptions) {
+		ops.dueTimestamp = dueTimestamp
+	}
+}
+
+type deleteOutboxEventsOptions struct {
+	dispatchedTimestamp int64
+}
+
+type DeleteOutboxEventsOption func(*deleteOutboxEventsOptions)
+
+// DeleteOutboxEventsOptionFilterByDispatchedTimestamp deletes the events dispatched or failed until the timestamp
+func DeleteOutboxEventsOptionFilterByDispatchedTimestamp(dispatchedTimestamp int64) DeleteOutboxEventsOption {
+	return func(ops *deleteOutboxEventsOptions) {
+		ops.dispatchedTimestamp = dispatchedTimestamp
+	}
+}
+
+type outboxEventStore interface {
+	createOutboxEventStore()
+
+	SelectOutboxEvents(limit int32, opts ...SelectOutboxEventsOption) ([]*model.OutboxEvent, error)
+	// ClaimOutboxEvent postpones the next attempt of the event to leaseTimestamp unless another relay has claimed it.
+	// It returns false if the event is already claimed, dispatched or failed.
+	ClaimOutboxEvent(outboxEvent *model.OutboxEvent, leaseTimestamp int64) (bool, error)
+	UpdateOutboxEvent(outboxEvent *model.OutboxEvent) error
+	DeleteOutboxEvents(opts ...DeleteOutboxEventsOption) error
+}
@@ -0,0 +1,145 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreInsertOutboxEvents = "[store] insert outbox events test"
+	TestStoreSelectOutboxEvents = "[store] select outbox events test"
+	TestStoreClaimOutboxEvent   = "[store] claim outbox event test"
+	TestStoreUpdateOutboxEvent  = "[store] update outbox event test"
+	TestStoreDeleteOutboxEvents = "[store] delete outbox events test"
+	TestStoreTearDownOutbox     = "[store] tear down outbox"
+)
+
+func testOutboxEventStore(t *testing.T) {
+	var outboxEvents []*model.OutboxEvent
+
+	t.Run(TestStoreInsertOutboxEvents, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		newRoom := &model.Room{}
+		newRoom.RoomID = "outbox-event-store-room-id-0001"
+		newRoom.UserID = "outbox-event-store-user-id-0001"
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+
+		outboxEvents = model.NewOutboxEvents(
+			model.OutboxEventTypeRoom,
+			newRoom.RoomID,
+			"",
+			model.OutboxDestinationWebhook,
+			model.OutboxDestinationProducer,
+		)
+		err := Provider(ctx).InsertRoom(newRoom, InsertRoomOptionWithOutboxEvents(outboxEvents))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreInsertOutboxEvents, err.Error())
+		}
+		for _, outboxEvent := range outboxEvents {
+			if outboxEvent.ID == 0 {
+				t.Fatalf("Failed to %s. Expected id to be set", TestStoreInsertOutboxEvents)
+			}
+		}
+		if outboxEvents[0].EventID != outboxEvents[1].EventID {
+			t.Fatalf("Failed to %s. Expected eventId to be shared by the destinations", TestStoreInsertOutboxEvents)
+		}
+	})
+
+	t.Run(TestStoreSelectOutboxEvents, func(t *testing.T) {
+		dueEvents, err := Provider(ctx).SelectOutboxEvents(10, SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectOutboxEvents, err.Error())
+		}
+		if len(dueEvents) != 2 {
+			t.Fatalf("Failed to %s. Expected outbox events count to be 2, but it was %d", TestStoreSelectOutboxEvents, len(dueEvents))
+		}
+		if dueEvents[0].Destination != model.OutboxDestinationWebhook {
+			t.Fatalf("Failed to %s. Expected outbox events to be ordered by id", TestStoreSelectOutboxEvents)
+		}
+	})
+
+	t.Run(TestStoreClaimOutboxEvent, func(t *testing.T) {
+		stale := *outboxEvents[0]
+		leaseTimestamp := time.Now().Unix() + 60
+
+		claimed, err := Provider(ctx).ClaimOutboxEvent(outboxEvents[0], leaseTimestamp)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreClaimOutboxEvent, err.Error())
+		}
+		if !claimed {
+			t.Fatalf("Failed to %s. Expected outbox event to be claimed", TestStoreClaimOutboxEvent)
+		}
+		if outboxEvents[0].NextAttempt != leaseTimestamp {
+			t.Fatalf("Failed to %s. Expected nextAttempt to be %d, but it was %d", TestStoreClaimOutboxEvent, leaseTimestamp, outboxEvents[0].NextAttempt)
+		}
+
+		claimed, err = Provider(ctx).ClaimOutboxEvent(&stale, leaseTimestamp)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreClaimOutboxEvent, err.Error())
+		}
+		if claimed {
+			t.Fatalf("Failed to %s. Expected outbox event claimed by another relay not to be claimed", TestStoreClaimOutboxEvent)
+		}
+
+		dueEvents, err := Provider(ctx).SelectOutboxEvents(10, SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreClaimOutboxEvent, err.Error())
+		}
+		if len(dueEvents) != 1 {
+			t.Fatalf("Failed to %s. Expected outbox events count to be 1, but it was %d", TestStoreClaimOutboxEvent, len(dueEvents))
+		}
+	})
+
+	t.Run(TestStoreUpdateOutboxEvent, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		outboxEvents[0].Attempts = 1
+		outboxEvents[0].Dispatched = nowTimestamp
+		err := Provider(ctx).UpdateOutboxEvent(outboxEvents[0])
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateOutboxEvent, err.Error())
+		}
+
+		claimed, err := Provider(ctx).ClaimOutboxEvent(outboxEvents[0], nowTimestamp+60)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateOutboxEvent, err.Error())
+		}
+		if claimed {
+			t.Fatalf("Failed to %s. Expected dispatched outbox event not to be claimed", TestStoreUpdateOutboxEvent)
+		}
+	})
+
+	t.Run(TestStoreDeleteOutboxEvents, func(t *testing.T) {
+		err := Provider(ctx).DeleteOutboxEvents()
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil", TestStoreDeleteOutboxEvents)
+		}
+
+		err = Provider(ctx).DeleteOutboxEvents(DeleteOutboxEventsOptionFilterByDispatchedTimestamp(time.Now().Unix()))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeleteOutboxEvents, err.Error())
+		}
+
+		dueEvents, err := Provider(ctx).SelectOutboxEvents(10, SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()+60))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeleteOutboxEvents, err.Error())
+		}
+		if len(dueEvents) != 1 || dueEvents[0].ID != outboxEvents[1].ID {
+			t.Fatalf("Failed to %s. Expected only the pending outbox event to remain", TestStoreDeleteOutboxEvents)
+		}
+	})
+	t.Run(TestStoreTearDownOutbox, func(t *testing.T) {
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = "outbox-event-store-room-id-0001"
+		deleteRoom.DeletedTimestamp = 1
+		err := Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreTearDownOutbox, err.Error())
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type pinnedMessageOptions struct {
+	outboxEvents []*model.OutboxEvent
+}
+
+// PinnedMessageOption is the option of the changes of the pinned messages
+type PinnedMessageOption func(*pinnedMessageOptions)
+
+// PinnedMessageOptionWithOutboxEvents inserts the outbox events in the same transaction
+func PinnedMessageOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) PinnedMessageOption {
+	return func(ops *pinnedMessageOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
+type pinnedMessageStore interface {
+	createPinnedMessageStore()
+
+	// InsertPinnedMessage pins the message to the end of the pinned messages of the room
+	InsertPinnedMessage(pinnedMessage *model.PinnedMessage, opts ...PinnedMessageOption) error
+	// SelectPinnedMessages selects the pinned messages of the room in the order with their messages.
+	// The pinned messages of the deleted messages are not selected.
+	SelectPinnedMessages(roomID string) ([]*model.PinnedMessage, error)
+	SelectPinnedMessage(roomID, messageID string) (*model.PinnedMessage, error)
+	DeletePinnedMessage(roomID, messageID string, opts ...PinnedMessageOption) error
+	// UpdatePinnedMessagesOrder sets the order of the pinned messages of the room to the order of messageIDs
+	UpdatePinnedMessagesOrder(roomID string, messageIDs []string, opts ...PinnedMessageOption) error
+}
@@ -0,0 +1,191 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreSetUpPinnedMessage           = "[store] set up pinned message"
+	TestStoreInsertPinnedMessage          = "[store] insert pinned message test"
+	TestStoreSelectPinnedMessages         = "[store] select pinned messages test"
+	TestStoreSelectPinnedMessage          = "[store] select pinned message test"
+	TestStoreUpdatePinnedMessagesOrder    = "[store] update pinned messages order test"
+	TestStoreDeletePinnedMessage          = "[store] delete pinned message test"
+	TestStoreTearDownPinnedMessage        = "[store] tear down pinned message"
+	testStorePinnedMessageRoomID          = "pinned-message-store-room-id-0001"
+	testStorePinnedMessageUserID          = "pinned-message-store-user-id-0001"
+	testStorePinnedMessageMessageIDFormat = "pinned-message-store-message-id-%04d"
+)
+
+func testPinnedMessageStore(t *testing.T) {
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestStoreSetUpPinnedMessage, func(t *testing.T) {
+		newRoom := &model.Room{}
+		newRoom.RoomID = testStorePinnedMessageRoomID
+		newRoom.UserID = testStorePinnedMessageUserID
+		newRoom.Type = scpb.RoomType_NoticeRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpPinnedMessage, err.Error())
+		}
+
+		for i := 1; i <= 3; i++ {
+			newMessage := &model.Message{}
+			newMessage.MessageID = fmt.Sprintf(testStorePinnedMessageMessageIDFormat, i)
+			newMessage.RoomID = testStorePinnedMessageRoomID
+			newMessage.UserID = testStorePinnedMessageUserID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(fmt.Sprintf(`{"text":"message %d"}`, i))
+			newMessage.Role = config.RoleGeneral
+			newMessage.CreatedTimestamp = nowTimestamp
+			newMessage.ModifiedTimestamp = nowTimestamp
+			err := Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSetUpPinnedMessage, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestStoreInsertPinnedMessage, func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			pm := &model.PinnedMessage{
+				RoomID:    testStorePinnedMessageRoomID,
+				MessageID: fmt.Sprintf(testStorePinnedMessageMessageIDFormat, i),
+				UserID:    testStorePinnedMessageUserID,
+				Created:   nowTimestamp,
+			}
+			err := Provider(ctx).InsertPinnedMessage(pm)
+			if err != nil {
+				t.Fatalf("Failed to %s. %s", TestStoreInsertPinnedMessage, err.Error())
+			}
+			if pm.Order != int32(i) {
+				t.Fatalf("Failed to %s. Expected order to be %d, but it was %d", TestStoreInsertPinnedMessage, i, pm.Order)
+			}
+		}
+
+		pm := &model.PinnedMessage{
+			RoomID:    testStorePinnedMessageRoomID,
+			MessageID: fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 1),
+			UserID:    testStorePinnedMessageUserID,
+			Created:   nowTimestamp,
+		}
+		err := Provider(ctx).InsertPinnedMessage(pm)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil for the message already pinned", TestStoreInsertPinnedMessage)
+		}
+	})
+
+	t.Run(TestStoreSelectPinnedMessages, func(t *testing.T) {
+		pinnedMessages, err := Provider(ctx).SelectPinnedMessages(testStorePinnedMessageRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectPinnedMessages, err.Error())
+		}
+		if len(pinnedMessages) != 3 {
+			t.Fatalf("Failed to %s. Expected pinned messages count to be 3, but it was %d", TestStoreSelectPinnedMessages, len(pinnedMessages))
+		}
+		if pinnedMessages[0].Message == nil || pinnedMessages[0].Message.MessageID != fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 1) {
+			t.Fatalf("Failed to %s. Expected the first pinned message to have the message", TestStoreSelectPinnedMessages)
+		}
+	})
+
+	t.Run(TestStoreSelectPinnedMessage, func(t *testing.T) {
+		pm, err := Provider(ctx).SelectPinnedMessage(testStorePinnedMessageRoomID, fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 2))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectPinnedMessage, err.Error())
+		}
+		if pm == nil {
+			t.Fatalf("Failed to %s. Expected pinned message to be not nil", TestStoreSelectPinnedMessage)
+		}
+
+		pm, err = Provider(ctx).SelectPinnedMessage(testStorePinnedMessageRoomID, "not-exist-message-id")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectPinnedMessage, err.Error())
+		}
+		if pm != nil {
+			t.Fatalf("Failed to %s. Expected pinned message to be nil", TestStoreSelectPinnedMessage)
+		}
+	})
+
+	t.Run(TestStoreUpdatePinnedMessagesOrder, func(t *testing.T) {
+		messageIDs := []string{
+			fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 3),
+			fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 1),
+			fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 2),
+		}
+		err := Provider(ctx).UpdatePinnedMessagesOrder(testStorePinnedMessageRoomID, messageIDs)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdatePinnedMessagesOrder, err.Error())
+		}
+
+		pinnedMessages, err := Provider(ctx).SelectPinnedMessages(testStorePinnedMessageRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdatePinnedMessagesOrder, err.Error())
+		}
+		for i, pm := range pinnedMessages {
+			if pm.MessageID != messageIDs[i] {
+				t.Fatalf("Failed to %s. Expected pinned message %d to be %s, but it was %s", TestStoreUpdatePinnedMessagesOrder, i, messageIDs[i], pm.MessageID)
+			}
+		}
+	})
+
+	t.Run(TestStoreDeletePinnedMessage, func(t *testing.T) {
+		err := Provider(ctx).DeletePinnedMessage(testStorePinnedMessageRoomID, fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 3))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeletePinnedMessage, err.Error())
+		}
+
+		// The pinned messages of the deleted messages are not selected
+		err = Provider(ctx).DeleteMessages(
+			DeleteMessagesOptionWithLogicalDeleted(nowTimestamp),
+			DeleteMessagesOptionFilterByMessageIDs([]string{fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 1)}),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeletePinnedMessage, err.Error())
+		}
+
+		pinnedMessages, err := Provider(ctx).SelectPinnedMessages(testStorePinnedMessageRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeletePinnedMessage, err.Error())
+		}
+		if len(pinnedMessages) != 1 || pinnedMessages[0].MessageID != fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 2) {
+			t.Fatalf("Failed to %s. Expected only the pinned message 2 to be left", TestStoreDeletePinnedMessage)
+		}
+	})
+
+	t.Run(TestStoreTearDownPinnedMessage, func(t *testing.T) {
+		messageIDs := make([]string, 3)
+		for i := 1; i <= 3; i++ {
+			messageIDs[i-1] = fmt.Sprintf(testStorePinnedMessageMessageIDFormat, i)
+		}
+		err := Provider(ctx).DeleteMessages(DeleteMessagesOptionFilterByMessageIDs(messageIDs))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownPinnedMessage, err.Error())
+		}
+
+		pm, err := Provider(ctx).SelectPinnedMessage(testStorePinnedMessageRoomID, fmt.Sprintf(testStorePinnedMessageMessageIDFormat, 2))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownPinnedMessage, err.Error())
+		}
+		if pm != nil {
+			t.Fatalf("Failed to %s. Expected the pinned message to be deleted with the message", TestStoreTearDownPinnedMessage)
+		}
+
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testStorePinnedMessageRoomID
+		deleteRoom.DeletedTimestamp = nowTimestamp
+		err = Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownPinnedMessage, err.Error())
+		}
+	})
+}
@@ -0,0 +1,18 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createAppClientStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateAppClientStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertAppClient(appClient *model.AppClient) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertAppClient(p.ctx, master, appClient)
+}
+
+func (p *postgresProvider) SelectLatestAppClient(opts ...SelectAppClientOption) (*model.AppClient, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectLatestAppClient(p.ctx, replica, opts...)
+}
@@ -0,0 +1,28 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createAssetStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateAssetStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertAsset(asset *model.Asset) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertAsset(p.ctx, master, asset)
+}
+
+func (p *postgresProvider) SelectAsset(assetID string) (*model.Asset, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectAsset(p.ctx, replica, assetID)
+}
+
+func (p *postgresProvider) SelectAssets(limit, offset int32, opts ...SelectAssetsOption) ([]*model.Asset, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectAssets(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) DeleteAssets(opts ...DeleteAssetsOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbDeleteAssets(p.ctx, master, opts...)
+}
@@ -0,0 +1,23 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createAuditLogStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateAuditLogStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertAuditLog(auditLog *model.AuditLog) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertAuditLog(p.ctx, master, auditLog)
+}
+
+func (p *postgresProvider) SelectAuditLogs(limit, offset int32, opts ...SelectAuditLogsOption) ([]*model.AuditLog, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectAuditLogs(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) SelectCountAuditLogs(opts ...SelectAuditLogsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountAuditLogs(p.ctx, replica, opts...)
+}
@@ -0,0 +1,89 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createBlockUserStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateBlockUserStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertBlockUsers(blockUsers []*model.BlockUser, opts ...InsertBlockUsersOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting block users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertBlockUsers(p.ctx, master, tx, blockUsers, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting block users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectBlockUsers(userID string) ([]*model.MiniUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectBlockUsers(p.ctx, replica, userID)
+}
+
+func (p *postgresProvider) SelectBlockUserIDs(userID string) ([]string, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectBlockUserIDs(p.ctx, replica, userID)
+}
+
+func (p *postgresProvider) SelectBlockedUsers(userID string) ([]*model.MiniUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectBlockedUsers(p.ctx, replica, userID)
+}
+
+func (p *postgresProvider) SelectBlockedUserIDs(userID string) ([]string, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectBlockedUserIDs(p.ctx, replica, userID)
+}
+
+func (p *postgresProvider) SelectBlockUser(userID, blockUserID string) (*model.BlockUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectBlockUser(p.ctx, replica, userID, blockUserID)
+}
+
+func (p *postgresProvider) DeleteBlockUsers(opts ...DeleteBlockUsersOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting block users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbDeleteBlockUsers(p.ctx, master, tx, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while deleting block users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
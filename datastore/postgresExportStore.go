@@ -0,0 +1,58 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) SelectExportUsers(limit, offset int32) ([]*model.User, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectExportUsers(p.ctx, replica, limit, offset)
+}
+
+func (p *postgresProvider) SelectExportRooms(limit, offset int32) ([]*model.Room, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectExportRooms(p.ctx, replica, limit, offset)
+}
+
+func (p *postgresProvider) SelectExportRoomUsers(roomID string) ([]*model.RoomUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectExportRoomUsers(p.ctx, replica, roomID)
+}
+
+func (p *postgresProvider) SelectExportMessages(roomID string, limit, offset int32) ([]*model.Message, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectExportMessages(p.ctx, replica, roomID, limit, offset)
+}
+
+func (p *postgresProvider) SelectExportMentions(roomID string) ([]*model.Mention, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectExportMentions(p.ctx, replica, roomID)
+}
+
+func (p *postgresProvider) InsertMentionsAsIs(mentions []*model.Mention) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting mentions")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertMentionsAsIs(p.ctx, master, tx, mentions)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting mentions")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createMessageStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateMessageStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertMessage(message *model.Message, opts ...InsertMessageOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertMessage(p.ctx, master, tx, message, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectMessages(limit, offset int32, opts ...SelectMessagesOption) ([]*model.Message, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMessages(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) SelectMessage(messageID string) (*model.Message, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMessage(p.ctx, replica, messageID)
+}
+
+func (p *postgresProvider) SelectCountMessages(opts ...SelectMessagesOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountMessages(p.ctx, replica, opts...)
+}
+
+func (p *postgresProvider) UpdateMessage(message *model.Message) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbUpdateMessage(p.ctx, master, message)
+}
+
+func (p *postgresProvider) DeleteMessages(opts ...DeleteMessagesOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbDeleteMessages(p.ctx, master, tx, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
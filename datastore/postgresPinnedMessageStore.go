@@ -0,0 +1,100 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createPinnedMessageStore() {
+	master := RdbStore(p.database).master()
+	rdbCreatePinnedMessageStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertPinnedMessage(pinnedMessage *model.PinnedMessage, opts ...PinnedMessageOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting pinned message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertPinnedMessage(p.ctx, master, tx, pinnedMessage, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting pinned message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectPinnedMessages(roomID string) ([]*model.PinnedMessage, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectPinnedMessages(p.ctx, replica, roomID)
+}
+
+func (p *postgresProvider) SelectPinnedMessage(roomID, messageID string) (*model.PinnedMessage, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectPinnedMessage(p.ctx, replica, roomID, messageID)
+}
+
+func (p *postgresProvider) DeletePinnedMessage(roomID, messageID string, opts ...PinnedMessageOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting pinned message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbDeletePinnedMessage(p.ctx, master, tx, roomID, messageID, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while deleting pinned message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) UpdatePinnedMessagesOrder(roomID string, messageIDs []string, opts ...PinnedMessageOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating pinned messages order")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdatePinnedMessagesOrder(p.ctx, master, tx, roomID, messageIDs, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating pinned messages order")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
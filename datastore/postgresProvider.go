@@ -0,0 +1,210 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	gorp "gopkg.in/gorp.v2"
+
+	logger "github.com/betchi/zapper"
+	gorpLogger "github.com/betchi/zapper/gorp"
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/config"
+)
+
+type postgresProvider struct {
+	ctx               context.Context
+	user              string
+	password          string
+	database          string
+	masterSi          *config.ServerInfo
+	replicaSis        []*config.ServerInfo
+	maxIdleConnection int
+	maxOpenConnection int
+	connMaxLifetime   int
+	enableLogging     bool
+}
+
+func (p *postgresProvider) Connect(dsCfg *config.Datastore) error {
+	if RdbStore(dsCfg.Database) != nil {
+		return nil
+	}
+
+	rs := &rdbStore{}
+	ds := p.dataSource(p.masterSi, p.database)
+	db, err := p.openDb(ds)
+	if err != nil {
+		err = errors.Wrap(err, fmt.Sprintf("Failed to connect database. %s %s", dsCfg.Provider, p.maskedDataSource(ds)))
+		logger.Error(err.Error())
+		return err
+	}
+	logger.Info(fmt.Sprintf("Connected database. %s %s", dsCfg.Provider, p.maskedDataSource(ds)))
+
+	master := &gorp.DbMap{Db: db, Dialect: gorp.PostgresDialect{}}
+	if p.enableLogging {
+		master.TraceOn("[master]", gorpLogger.GlobalLogger())
+	}
+	rs.setMaster(master)
+
+	for _, replicaSi := range p.replicaSis {
+		if replicaSi.Host == "" || replicaSi.Port == "" {
+			continue
+		}
+
+		ds := p.dataSource(replicaSi, p.database)
+		db, err := p.openDb(ds)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("Failed to connect database. %s %s", dsCfg.Provider, p.maskedDataSource(ds)))
+			logger.Error(err.Error())
+			return err
+		}
+		logger.Info(fmt.Sprintf("Connected database. %s %s", dsCfg.Provider, p.maskedDataSource(ds)))
+
+		replica := &gorp.DbMap{Db: db, Dialect: gorp.PostgresDialect{}}
+		if p.enableLogging {
+			replica.TraceOn("[replica]", gorpLogger.GlobalLogger())
+		}
+		rs.setReplica(replica)
+	}
+
+	rdbStores.add(dsCfg.Database, rs)
+	return nil
+}
+
+func (p *postgresProvider) CreateTables() error {
+	p.mapTables()
+
+	master := RdbStore(p.database).master()
+	return rdbCreateTables(p.ctx, master)
+}
+
+func (p *postgresProvider) mapTables() {
+	p.createAppClientStore()
+	p.createAssetStore()
+	p.createAuditLogStore()
+	p.createBlockUserStore()
+	p.createDeviceStore()
+	p.createMentionStore()
+	p.createMessageStore()
+	p.createOutboxEventStore()
+	p.createPinnedMessageStore()
+	p.createRoomStore()
+	p.createRoomInvitationStore()
+	p.createRoomInviteLinkStore()
+	p.createRoomUserStore()
+	p.createScheduledMessageStore()
+	p.createSettingStore()
+	p.createSubscriptionStore()
+	p.createUserStore()
+	p.createUserRoleStore()
+	p.createWebhookStore()
+	p.createWorkspaceStore()
+}
+
+// CreateDatabase creates the database from the maintenance database "postgres".
+// It returns false without creating it if it exists.
+func (p *postgresProvider) CreateDatabase() (bool, error) {
+	db, err := p.openDb(p.dataSource(p.masterSi, "postgres"))
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	defer db.Close()
+
+	var count int64
+	err = db.QueryRow("SELECT count(*) FROM pg_database WHERE datname=$1", p.database).Scan(&count)
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+	if count > 0 {
+		return false, nil
+	}
+
+	_, err = db.Exec(fmt.Sprintf("CREATE DATABASE %s", p.database))
+	if err != nil {
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+// DropDatabase drops the database from the maintenance database "postgres",
+// because PostgreSQL can not drop the database while there are connections to it.
+func (p *postgresProvider) DropDatabase() error {
+	if RdbStore(p.database) == nil {
+		return nil
+	}
+
+	rdbStores.remove(p.database)
+	migratedDatabases.Delete(p.database)
+
+	db, err := p.openDb(p.dataSource(p.masterSi, "postgres"))
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+	defer db.Close()
+
+	_, err = db.Exec(fmt.Sprintf("DROP DATABASE IF EXISTS %s", p.database))
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) dataSource(si *config.ServerInfo, database string) string {
+	ds := fmt.Sprintf(
+		"host=%s port=%s user=%s password=%s dbname=%s",
+		si.Host,
+		si.Port,
+		p.user,
+		p.password,
+		database)
+
+	if si.ServerCaPath != "" && si.ClientCertPath != "" && si.ClientKeyPath != "" {
+		return fmt.Sprintf(
+			"%s sslmode=verify-full sslrootcert=%s sslcert=%s sslkey=%s",
+			ds,
+			si.ServerCaPath,
+			si.ClientCertPath,
+			si.ClientKeyPath)
+	}
+
+	return fmt.Sprintf("%s sslmode=disable", ds)
+}
+
+func (p *postgresProvider) maskedDataSource(ds string) string {
+	return strings.Replace(ds, fmt.Sprintf("password=%s", p.password), "password=****", 1)
+}
+
+func (p *postgresProvider) openDb(dataSource string) (*sql.DB, error) {
+	db, err := sql.Open("postgres", dataSource)
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	err = db.Ping()
+	if err != nil {
+		logger.Error(err.Error())
+		return nil, err
+	}
+
+	db.SetMaxIdleConns(p.maxIdleConnection)
+	db.SetMaxOpenConns(p.maxOpenConnection)
+	db.SetConnMaxLifetime(time.Duration(p.connMaxLifetime) * time.Second)
+
+	return db, nil
+}
+
+func (p *postgresProvider) Close() {
+	rdbStores.removeAll()
+}
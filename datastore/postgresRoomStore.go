@@ -0,0 +1,176 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createRoomStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateRoomStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertRoom(room *model.Room, opts ...InsertRoomOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertRoom(p.ctx, master, tx, room, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectRooms(limit, offset int32, opts ...SelectRoomsOption) ([]*model.Room, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRooms(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) SelectRoom(roomID string, opts ...SelectRoomOption) (*model.Room, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoom(p.ctx, replica, roomID, opts...)
+}
+
+func (p *postgresProvider) SelectCountRooms(opts ...SelectRoomsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountRooms(p.ctx, replica, opts...)
+}
+
+func (p *postgresProvider) SelectPublicRooms(limit, offset int32, opts ...SelectPublicRoomsOption) ([]*model.PublicRoom, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectPublicRooms(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) SelectCountPublicRooms(opts ...SelectPublicRoomsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountPublicRooms(p.ctx, replica, opts...)
+}
+
+func (p *postgresProvider) UpdateRoom(room *model.Room, opts ...UpdateRoomOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoom(p.ctx, master, tx, room, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting user roles")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) UpdateRoomOwner(room *model.Room, userID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomOwner(p.ctx, master, tx, room, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) UpdateRoomLastMessage(roomID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomLastMessage(p.ctx, master, tx, roomID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) RestoreRoom(room *model.Room) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	restored, err := rdbRestoreRoom(p.ctx, master, tx, room)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !restored {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *postgresProvider) PurgeDeletedRoomUsers(deletedTimestamp int64) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbPurgeDeletedRoomUsers(p.ctx, master, deletedTimestamp)
+}
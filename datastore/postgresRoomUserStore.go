@@ -0,0 +1,125 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createRoomUserStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateRoomUserStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertRoomUsers(roomUsers []*model.RoomUser, opts ...InsertRoomUsersOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting room users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertRoomUsers(p.ctx, master, tx, roomUsers, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting room users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectRoomUsers(opts ...SelectRoomUsersOption) ([]*model.RoomUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomUsers(p.ctx, replica, opts...)
+}
+
+func (p *postgresProvider) SelectRoomUser(roomID, userID string) (*model.RoomUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomUser(p.ctx, replica, roomID, userID)
+}
+
+func (p *postgresProvider) SelectRoomUserOfOneOnOne(myUserID, opponentUserID string) (*model.RoomUser, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectRoomUserOfOneOnOne(p.ctx, replica, myUserID, opponentUserID)
+}
+
+func (p *postgresProvider) SelectUserIDsOfRoomUser(opts ...SelectUserIDsOfRoomUserOption) ([]string, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectUserIDsOfRoomUser(p.ctx, replica, opts...)
+}
+
+func (p *postgresProvider) SelectMiniRoom(roomID, userID string) (*model.MiniRoom, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMiniRoom(p.ctx, replica, roomID, userID)
+}
+
+func (p *postgresProvider) SelectMiniRooms(limit, offset int32, userID string, opts ...SelectMiniRoomsOption) ([]*model.MiniRoom, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectMiniRooms(p.ctx, replica, limit, offset, userID, opts...)
+}
+
+func (p *postgresProvider) SelectCountMiniRooms(userID string, opts ...SelectMiniRoomsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountMiniRooms(p.ctx, replica, userID, opts...)
+}
+
+func (p *postgresProvider) UpdateRoomUser(roomUser *model.RoomUser) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomUser(p.ctx, master, tx, roomUser)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) DeleteRoomUsers(opts ...DeleteRoomUsersOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting room users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbDeleteRoomUsers(p.ctx, master, tx, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while deleting room users")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
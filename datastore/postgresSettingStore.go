@@ -0,0 +1,13 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createSettingStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateSettingStore(p.ctx, master)
+}
+
+func (p *postgresProvider) SelectLatestSetting() (*model.Setting, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectLatestSetting(p.ctx, replica)
+}
@@ -0,0 +1,115 @@
+package datastore
+
+import (
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+)
+
+func (p *postgresProvider) createUserStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateUserStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertUser(user *model.User, opts ...InsertUserOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbInsertUser(p.ctx, master, tx, user, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while inserting user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectUsers(limit, offset int32, opts ...SelectUsersOption) ([]*model.User, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectUsers(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *postgresProvider) SelectUser(userID string, opts ...SelectUserOption) (*model.User, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectUser(p.ctx, replica, userID, opts...)
+}
+
+func (p *postgresProvider) SelectCountUsers() (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountUsers(p.ctx, replica)
+}
+
+func (p *postgresProvider) SelectUserIDsOfUser(userIDs []string) ([]string, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectUserIDsOfUser(p.ctx, replica, userIDs)
+}
+
+func (p *postgresProvider) UpdateUser(user *model.User, opts ...UpdateUserOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateUser(p.ctx, master, tx, user, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) EraseUser(user *model.User, opts ...EraseUserOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbEraseUser(p.ctx, master, tx, user, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *postgresProvider) SelectContacts(userID string, limit, offset int32, opts ...SelectContactsOption) ([]*model.User, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectContacts(p.ctx, replica, userID, limit, offset, opts...)
+}
@@ -0,0 +1,18 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createWebhookStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateWebhookStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertWebhook(webhook *model.Webhook) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertWebhook(p.ctx, master, webhook)
+}
+
+func (p *postgresProvider) SelectWebhooks(event model.WebhookEventType, opts ...SelectWebhooksOption) ([]*model.Webhook, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectWebhooks(p.ctx, replica, event, opts...)
+}
@@ -0,0 +1,33 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *postgresProvider) createWorkspaceStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateWorkspaceStore(p.ctx, master)
+}
+
+func (p *postgresProvider) InsertWorkspace(workspace *model.Workspace) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertWorkspace(p.ctx, master, workspace)
+}
+
+func (p *postgresProvider) SelectWorkspaces(limit, offset int32) ([]*model.Workspace, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectWorkspaces(p.ctx, replica, limit, offset)
+}
+
+func (p *postgresProvider) SelectCountWorkspaces() (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountWorkspaces(p.ctx, replica)
+}
+
+func (p *postgresProvider) SelectWorkspace(name string) (*model.Workspace, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectWorkspace(p.ctx, replica, name)
+}
+
+func (p *postgresProvider) DeleteWorkspace(name string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbDeleteWorkspace(p.ctx, master, name)
+}
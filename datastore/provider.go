@@ -10,29 +10,84 @@ import (
 
 type provider interface {
 	Connect(dsCfg *config.Datastore) error
-	CreateTables()
+	// CreateTables maps the tables and applies the pending schema migrations
+	CreateTables() error
+	// mapTables maps the tables to the connection without migrating them
+	mapTables()
+	CreateDatabase() (bool, error)
 	DropDatabase() error
 	Close()
 	appClientStore
 	assetStore
+	auditLogStore
 	blockUserStore
 	deviceStore
+	exportStore
+	mentionStore
 	messageStore
+	migrationStore
+	outboxEventStore
+	pinnedMessageStore
 	roomStore
+	roomInvitationStore
+	roomInviteLinkStore
 	roomUserStore
+	scheduledMessageStore
 	settingStore
 	subscriptionStore
 	userStore
 	userRoleStore
 	webhookStore
+	workspaceStore
 }
 
 // Provider is get datastore provider
 func Provider(ctx context.Context) provider {
+	p, err := connect(ctx)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+	return p
+}
+
+// Connect connects to the database of the workspace in the context and migrates it if it's connected for the first time.
+// In dynamic mode, the requests have to call it before using Provider so that they fail if the database can't be migrated.
+func Connect(ctx context.Context) error {
+	_, err := connect(ctx)
+	return err
+}
+
+func connect(ctx context.Context) (provider, error) {
+	cfg := config.Config()
+	p, dsCfg := newProvider(ctx)
+
+	err := p.Connect(dsCfg)
+	if err != nil {
+		logger.Error(err.Error())
+		os.Exit(1)
+	}
+
+	// Every workspace database is migrated when it's connected for the first time
+	if !cfg.Datastore.Dynamic {
+		return p, nil
+	}
+	return p, RdbStore(dsCfg.Database).prepare(dsCfg.Database, p)
+}
+
+// CreateDatabase creates the database of the workspace in the context.
+// It returns false without creating it if it exists.
+func CreateDatabase(ctx context.Context) (bool, error) {
+	p, _ := newProvider(ctx)
+	return p.CreateDatabase()
+}
+
+// newProvider returns the provider for the database of the workspace in the context without connecting to it
+func newProvider(ctx context.Context) (provider, *config.Datastore) {
 	var p provider
 
 	cfg := config.Config()
-	dsCfg := cfg.Datastore
+	// Copy the setting not to overwrite the database of the config with the workspace
+	dsCfg := *cfg.Datastore
 
 	if cfg.Datastore.Dynamic {
 		dsCfg.Database = ctx.Value(config.CtxWorkspace).(string)
@@ -61,6 +116,19 @@ func Provider(ctx context.Context) provider {
 			maxOpenConnection: dsCfg.MaxOpenConnection,
 			enableLogging:     dsCfg.EnableLogging,
 		}
+	case "postgres":
+		p = &postgresProvider{
+			ctx:               ctx,
+			user:              dsCfg.User,
+			password:          dsCfg.Password,
+			database:          dsCfg.Database,
+			masterSi:          dsCfg.Master,
+			replicaSis:        dsCfg.Replicas,
+			maxIdleConnection: dsCfg.MaxIdleConnection,
+			maxOpenConnection: dsCfg.MaxOpenConnection,
+			connMaxLifetime:   dsCfg.ConnMaxLifetime,
+			enableLogging:     dsCfg.EnableLogging,
+		}
 	case "gcSql":
 		p = &gcpSQLProvider{
 			ctx:               ctx,
@@ -75,11 +143,5 @@ func Provider(ctx context.Context) provider {
 		}
 	}
 
-	err := p.Connect(dsCfg)
-	if err != nil {
-		logger.Error(err.Error())
-		os.Exit(1)
-	}
-
-	return p
+	return p, &dsCfg
 }
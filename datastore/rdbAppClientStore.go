@@ -13,6 +13,7 @@ import (
 	"github.com/pkg/errors"
 	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
 	"github.com/betchi/tracer"
 )
 
@@ -27,39 +28,42 @@ func rdbCreateAppClientStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating app client table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
+}
+
+// rdbInsertFirstAppClient inserts the first app client named after the first client id unless it exists.
+// Its client id is the first client id in the database configured by datastore.database, and it's generated
+// for each workspace in dynamic mode not to share the client id of the admin between the workspaces.
+func rdbInsertFirstAppClient(ctx context.Context, dbMap *gorp.DbMap) error {
+	span := tracer.StartSpan(ctx, "rdbInsertFirstAppClient", "datastore")
+	defer tracer.Finish(span)
 
 	cfg := config.Config()
 
 	ac, err := rdbSelectLatestAppClient(
 		ctx,
 		dbMap,
-		SelectAppClientOptionFilterByClientID(cfg.FirstClientID),
+		SelectAppClientOptionFilterByName(cfg.FirstClientID),
 	)
 	if err != nil {
-		return
+		return err
 	}
 
 	if ac != nil {
-		return
+		return nil
+	}
+
+	clientID := cfg.FirstClientID
+	if workspace, _ := ctx.Value(config.CtxWorkspace).(string); cfg.Datastore.Dynamic && workspace != cfg.Datastore.Database {
+		clientID = utils.GenerateClientID()
 	}
 
 	appClient := &model.AppClient{
 		Name:     cfg.FirstClientID,
-		ClientID: cfg.FirstClientID,
+		ClientID: clientID,
 		Created:  time.Now().Unix(),
 		Expired:  0,
 	}
-	err = rdbInsertAppClient(ctx, dbMap, appClient)
-	if err != nil {
-		return
-	}
+	return rdbInsertAppClient(ctx, dbMap, appClient)
 }
 
 func rdbInsertAppClient(ctx context.Context, dbMap *gorp.DbMap, appClient *model.AppClient) error {
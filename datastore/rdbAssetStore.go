@@ -23,13 +23,6 @@ func rdbCreateAssetStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating asset table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertAsset(ctx context.Context, dbMap *gorp.DbMap, asset *model.Asset) error {
@@ -67,3 +60,74 @@ func rdbSelectAsset(ctx context.Context, dbMap *gorp.DbMap, assetID string) (*mo
 
 	return nil, nil
 }
+
+// rdbSelectAssets selects the assets in the inserted order
+func rdbSelectAssets(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, opts ...SelectAssetsOption) ([]*model.Asset, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectAssets", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectAssetsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	var assets []*model.Asset
+	query := fmt.Sprintf("SELECT * FROM %s WHERE deleted = 0", tableNameAsset)
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id = :userId", query)
+	}
+
+	query = fmt.Sprintf("%s ORDER BY id ASC LIMIT :limit OFFSET :offset;", query)
+	_, err := dbMap.Select(&assets, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting assets")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return assets, nil
+}
+
+func rdbDeleteAssets(ctx context.Context, dbMap *gorp.DbMap, opts ...DeleteAssetsOption) error {
+	span := tracer.StartSpan(ctx, "rdbDeleteAssets", "datastore")
+	defer tracer.Finish(span)
+
+	opt := deleteAssetsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	if opt.assetIDs == nil || len(opt.assetIDs) == 0 {
+		err := errors.New("An error occurred while deleting assets. Be sure to specify assetIds")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	var query string
+	if opt.logicalDeleted != 0 {
+		query = fmt.Sprintf("UPDATE %s SET deleted=%d WHERE", tableNameAsset, opt.logicalDeleted)
+	} else {
+		query = fmt.Sprintf("DELETE FROM %s WHERE", tableNameAsset)
+	}
+
+	assetIDsQuery, assetIDsParams := makePrepareExpressionForInOperand(opt.assetIDs)
+	query = fmt.Sprintf("%s asset_id IN (%s)", query, assetIDsQuery)
+
+	_, err := dbMap.Exec(rebind(dbMap, query), assetIDsParams...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting assets")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,122 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateAuditLogStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateAuditLogStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.AuditLog{}, tableNameAuditLog)
+	tableMap.SetKeys(true, "id")
+	for _, columnMap := range tableMap.Columns {
+		if columnMap.ColumnName == "audit_log_id" {
+			columnMap.SetUnique(true)
+		}
+	}
+}
+
+func rdbInsertAuditLog(ctx context.Context, dbMap *gorp.DbMap, auditLog *model.AuditLog) error {
+	span := tracer.StartSpan(ctx, "rdbInsertAuditLog", "datastore")
+	defer tracer.Finish(span)
+
+	err := dbMap.Insert(auditLog)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting audit log")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbMakeAuditLogsCondition(opts ...SelectAuditLogsOption) (string, map[string]interface{}) {
+	opt := selectAuditLogsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	condition := "1=1"
+	params := make(map[string]interface{})
+
+	if opt.action != "" {
+		params["action"] = opt.action
+		condition = fmt.Sprintf("%s AND action=:action", condition)
+	}
+
+	if opt.actorUserID != "" {
+		params["actorUserId"] = opt.actorUserID
+		condition = fmt.Sprintf("%s AND actor_user_id=:actorUserId", condition)
+	}
+
+	if opt.targetType != "" {
+		params["targetType"] = opt.targetType
+		condition = fmt.Sprintf("%s AND target_type=:targetType", condition)
+	}
+
+	if opt.targetID != "" {
+		params["targetId"] = opt.targetID
+		condition = fmt.Sprintf("%s AND target_id=:targetId", condition)
+	}
+
+	if opt.fromTimestamp != 0 {
+		params["fromTimestamp"] = opt.fromTimestamp
+		condition = fmt.Sprintf("%s AND created>=:fromTimestamp", condition)
+	}
+
+	if opt.toTimestamp != 0 {
+		params["toTimestamp"] = opt.toTimestamp
+		condition = fmt.Sprintf("%s AND created<=:toTimestamp", condition)
+	}
+
+	return condition, params
+}
+
+func rdbSelectAuditLogs(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, opts ...SelectAuditLogsOption) ([]*model.AuditLog, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectAuditLogs", "datastore")
+	defer tracer.Finish(span)
+
+	condition, params := rdbMakeAuditLogsCondition(opts...)
+
+	var auditLogs []*model.AuditLog
+	query := fmt.Sprintf("SELECT * FROM %s WHERE %s ORDER BY created DESC, id DESC LIMIT :limit OFFSET :offset", tableNameAuditLog, condition)
+	params["limit"] = limit
+	params["offset"] = offset
+
+	_, err := dbMap.Select(&auditLogs, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting audit logs")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return auditLogs, nil
+}
+
+func rdbSelectCountAuditLogs(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectAuditLogsOption) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountAuditLogs", "datastore")
+	defer tracer.Finish(span)
+
+	condition, params := rdbMakeAuditLogsCondition(opts...)
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s WHERE %s", tableNameAuditLog, condition)
+	count, err := dbMap.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting audit log count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
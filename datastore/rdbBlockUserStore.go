@@ -17,13 +17,6 @@ func rdbCreateBlockUserStore(ctx context.Context, dbMap *gorp.DbMap) {
 
 	tableMap := dbMap.AddTableWithName(model.BlockUser{}, tableNameBlockUser)
 	tableMap.SetUniqueTogether("user_id", "block_user_id")
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating block user table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertBlockUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, bus []*model.BlockUser, opts ...InsertBlockUsersOption) error {
@@ -217,7 +210,7 @@ func rdbDeleteBlockUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transa
 	if len(opt.userIDs) > 0 {
 		userIDsQuery, userIDsParams := makePrepareExpressionForInOperand(opt.userIDs)
 		query := fmt.Sprintf("DELETE FROM %s WHERE user_id IN (%s)", tableNameBlockUser, userIDsQuery)
-		_, err := tx.Exec(query, userIDsParams...)
+		_, err := tx.Exec(rebind(dbMap, query), userIDsParams...)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting block users")
 			logger.Error(err.Error())
@@ -229,7 +222,7 @@ func rdbDeleteBlockUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transa
 	if len(opt.blockUserIDs) > 0 {
 		blockUserIDsQuery, blockUserIDsParams := makePrepareExpressionForInOperand(opt.blockUserIDs)
 		query := fmt.Sprintf("DELETE FROM %s WHERE block_user_id IN (%s)", tableNameBlockUser, blockUserIDsQuery)
-		_, err := tx.Exec(query, blockUserIDsParams...)
+		_, err := tx.Exec(rebind(dbMap, query), blockUserIDsParams...)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting block users")
 			logger.Error(err.Error())
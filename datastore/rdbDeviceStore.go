@@ -19,13 +19,6 @@ func rdbCreateDeviceStore(ctx context.Context, dbMap *gorp.DbMap) {
 	defer tracer.Finish(span)
 
 	_ = dbMap.AddTableWithName(model.Device{}, tableNameDevice)
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating device table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertDevice(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, device *model.Device, opts ...InsertDeviceOption) error {
@@ -158,7 +151,7 @@ func rdbUpdateDevice(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transactio
 	}
 
 	query := fmt.Sprintf("UPDATE %s SET token=?, notification_device_id=? WHERE user_id=? AND platform=?;", tableNameDevice)
-	_, err = tx.Exec(query, device.Token, device.NotificationDeviceID, device.UserID, device.Platform)
+	_, err = tx.Exec(rebind(dbMap, query), device.Token, device.NotificationDeviceID, device.UserID, device.Platform)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while updating device")
 		logger.Error(err.Error())
@@ -206,7 +199,7 @@ func rdbDeleteDevices(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 
 	if opt.userID != "" && opt.platform == scpb.Platform_PlatformNone {
 		query = fmt.Sprintf("%s user_id=?", query)
-		_, err := tx.Exec(query, opt.userID)
+		_, err := tx.Exec(rebind(dbMap, query), opt.userID)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting devices")
 			logger.Error(err.Error())
@@ -217,7 +210,7 @@ func rdbDeleteDevices(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 
 	if opt.userID != "" && opt.platform != scpb.Platform_PlatformNone {
 		query = fmt.Sprintf("%s user_id=? AND platform=?", query)
-		_, err := tx.Exec(query, opt.userID, opt.platform)
+		_, err := tx.Exec(rebind(dbMap, query), opt.userID, opt.platform)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting devices")
 			logger.Error(err.Error())
@@ -0,0 +1,163 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbSelectExportUsers(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32) ([]*model.User, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectExportUsers", "datastore")
+	defer tracer.Finish(span)
+
+	var users []*model.User
+	query := fmt.Sprintf("SELECT * FROM %s WHERE deleted=0 ORDER BY created ASC, user_id ASC LIMIT :limit OFFSET :offset;", tableNameUser)
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+	_, err := dbMap.Select(&users, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting users for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+	if len(users) == 0 {
+		return users, nil
+	}
+
+	userIDs := make([]string, len(users))
+	usersByID := make(map[string]*model.User, len(users))
+	for i, user := range users {
+		user.Roles = make([]int32, 0)
+		userIDs[i] = user.UserID
+		usersByID[user.UserID] = user
+	}
+
+	// The roles of the users in the page are selected at once
+	var userRoles []*model.UserRole
+	userIDsQuery, userIDsParams := makePrepareExpressionParamsForInOperand(userIDs)
+	query = fmt.Sprintf("SELECT * FROM %s WHERE user_id IN (%s);", tableNameUserRole, userIDsQuery)
+	_, err = dbMap.Select(&userRoles, query, userIDsParams)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting users for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+	for _, userRole := range userRoles {
+		if user, ok := usersByID[userRole.UserID]; ok {
+			user.Roles = append(user.Roles, userRole.Role)
+		}
+	}
+
+	return users, nil
+}
+
+func rdbSelectExportRooms(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32) ([]*model.Room, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectExportRooms", "datastore")
+	defer tracer.Finish(span)
+
+	var rooms []*model.Room
+	query := fmt.Sprintf("SELECT * FROM %s WHERE deleted=0 ORDER BY created ASC, room_id ASC LIMIT :limit OFFSET :offset;", tableNameRoom)
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+	_, err := dbMap.Select(&rooms, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting rooms for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func rdbSelectExportRoomUsers(ctx context.Context, dbMap *gorp.DbMap, roomID string) ([]*model.RoomUser, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectExportRoomUsers", "datastore")
+	defer tracer.Finish(span)
+
+	var roomUsers []*model.RoomUser
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId ORDER BY user_id ASC;", tableNameRoomUser)
+	params := map[string]interface{}{
+		"roomId": roomID,
+	}
+	_, err := dbMap.Select(&roomUsers, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room users for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return roomUsers, nil
+}
+
+func rdbSelectExportMessages(ctx context.Context, dbMap *gorp.DbMap, roomID string, limit, offset int32) ([]*model.Message, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectExportMessages", "datastore")
+	defer tracer.Finish(span)
+
+	var messages []*model.Message
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId ORDER BY created ASC, message_id ASC LIMIT :limit OFFSET :offset;", tableNameMessage)
+	params := map[string]interface{}{
+		"roomId": roomID,
+		"limit":  limit,
+		"offset": offset,
+	}
+	_, err := dbMap.Select(&messages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting messages for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func rdbSelectExportMentions(ctx context.Context, dbMap *gorp.DbMap, roomID string) ([]*model.Mention, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectExportMentions", "datastore")
+	defer tracer.Finish(span)
+
+	var mentions []*model.Mention
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId ORDER BY id ASC;", tableNameMention)
+	params := map[string]interface{}{
+		"roomId": roomID,
+	}
+	_, err := dbMap.Select(&mentions, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting mentions for export")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return mentions, nil
+}
+
+// rdbInsertMentionsAsIs inserts the mentions of an import.
+// Unlike rdbInsertMentions the mention count of the room users isn't counted up, because they are imported as exported.
+func rdbInsertMentionsAsIs(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, mentions []*model.Mention) error {
+	span := tracer.StartSpan(ctx, "rdbInsertMentionsAsIs", "datastore")
+	defer tracer.Finish(span)
+
+	for _, mention := range mentions {
+		err := tx.Insert(mention)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while inserting mentions")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,144 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateMentionStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateMentionStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.Mention{}, tableNameMention)
+	tableMap.SetKeys(true, "id")
+	tableMap.SetUniqueTogether("message_id", "user_id")
+}
+
+// rdbInsertMentions inserts mentions of the message and counts up mention count of the room users.
+// It is called in the transaction of inserting message.
+func rdbInsertMentions(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, message *model.Message) error {
+	span := tracer.StartSpan(ctx, "rdbInsertMentions", "datastore")
+	defer tracer.Finish(span)
+
+	for _, userID := range message.Mentions {
+		mention := &model.Mention{
+			MessageID: message.MessageID,
+			RoomID:    message.RoomID,
+			UserID:    userID,
+			Created:   message.CreatedTimestamp,
+		}
+		err := tx.Insert(mention)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while inserting mentions")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	userIDsQuery, userIDsParams := makePrepareExpressionForInOperand(message.Mentions)
+	query := fmt.Sprintf("UPDATE %s SET mention_count=mention_count+1 WHERE room_id=? AND user_id IN (%s);", tableNameRoomUser, userIDsQuery)
+	params := append([]interface{}{message.RoomID}, userIDsParams...)
+	_, err := tx.Exec(rebind(dbMap, query), params...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting mentions")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbSelectMentionedMessages(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, userID string, opts ...SelectMentionedMessagesOption) ([]*model.Message, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectMentionedMessages", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectMentionedMessagesOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	var messages []*model.Message
+	query := fmt.Sprintf(`SELECT m.* FROM %s AS mt
+LEFT JOIN %s AS m ON mt.message_id = m.message_id
+WHERE mt.user_id=:userId AND m.deleted=0`, tableNameMention, tableNameMessage)
+	params := map[string]interface{}{
+		"userId": userID,
+	}
+
+	if opt.roomID != "" {
+		params["roomId"] = opt.roomID
+		query = fmt.Sprintf("%s AND mt.room_id=:roomId", query)
+	}
+
+	query = fmt.Sprintf("%s ORDER BY mt.created DESC, mt.id DESC LIMIT :limit OFFSET :offset", query)
+	params["limit"] = limit
+	params["offset"] = offset
+
+	_, err := dbMap.Select(&messages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting mentioned messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+func rdbSelectCountMentionedMessages(ctx context.Context, dbMap *gorp.DbMap, userID string, opts ...SelectMentionedMessagesOption) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountMentionedMessages", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectMentionedMessagesOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf(`SELECT count(mt.id) FROM %s AS mt
+LEFT JOIN %s AS m ON mt.message_id = m.message_id
+WHERE mt.user_id=:userId AND m.deleted=0`, tableNameMention, tableNameMessage)
+	params := map[string]interface{}{
+		"userId": userID,
+	}
+
+	if opt.roomID != "" {
+		params["roomId"] = opt.roomID
+		query = fmt.Sprintf("%s AND mt.room_id=:roomId", query)
+	}
+
+	count, err := dbMap.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting mentioned message count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func rdbSelectMentionedUserIDs(ctx context.Context, dbMap *gorp.DbMap, messageID string) ([]string, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectMentionedUserIDs", "datastore")
+	defer tracer.Finish(span)
+
+	var userIDs []string
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE message_id=:messageId ORDER BY id ASC;", tableNameMention)
+	params := map[string]interface{}{"messageId": messageID}
+	_, err := dbMap.Select(&userIDs, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting mentioned userIds")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return userIDs, nil
+}
@@ -2,9 +2,7 @@ package datastore
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"strings"
 	"time"
 
 	"gopkg.in/gorp.v2"
@@ -12,7 +10,6 @@ import (
 	"github.com/pkg/errors"
 
 	logger "github.com/betchi/zapper"
-	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/model"
 	"github.com/betchi/tracer"
 	"github.com/swagchat/chat-api/utils"
@@ -28,36 +25,17 @@ func rdbCreateMessageStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating message table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
-
-	var addIndexQuery string
-	if config.Config().Datastore.Provider == "sqlite" {
-		addIndexQuery = fmt.Sprintf("CREATE INDEX room_id_deleted_created ON %s(room_id, deleted, created)", tableNameMessage)
-	} else {
-		addIndexQuery = fmt.Sprintf("ALTER TABLE %s ADD INDEX room_id_deleted_created (room_id, deleted, created)", tableNameMessage)
-		_, err = dbMap.Exec(addIndexQuery)
-		if err != nil {
-			errMessage := err.Error()
-			if strings.Index(errMessage, "Duplicate key name") < 0 {
-				err = errors.Wrap(err, "An error occurred while creating message table")
-				logger.Error(err.Error())
-				tracer.SetError(span, err)
-				return
-			}
-		}
-	}
 }
 
-func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, message *model.Message) error {
+func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, message *model.Message, opts ...InsertMessageOption) error {
 	span := tracer.StartSpan(ctx, "rdbInsertMessage", "datastore")
 	defer tracer.Finish(span)
 
+	opt := insertMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	err := tx.Insert(message)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting message")
@@ -66,6 +44,10 @@ func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 		return err
 	}
 
+	if opt.asIs {
+		return rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+	}
+
 	var rooms []*model.Room
 	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId AND deleted=0;", tableNameRoom)
 	params := map[string]interface{}{"roomId": message.RoomID}
@@ -83,22 +65,7 @@ func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 	}
 
 	room := rooms[0]
-	var lastMessage string
-	switch message.Type {
-	case "image":
-		lastMessage = "画像を受信しました"
-	case "file":
-		lastMessage = "ファイルを受信しました"
-	default:
-		var payloadText model.PayloadText
-		json.Unmarshal(message.Payload, &payloadText)
-		if payloadText.Text == "" {
-			lastMessage = "メッセージを受信しました"
-		} else {
-			lastMessage = payloadText.Text
-		}
-	}
-	room.LastMessage = lastMessage
+	room.SetLastMessage(message)
 	room.LastMessageUpdatedTimestamp = time.Now().Unix()
 	_, err = tx.Update(room)
 	if err != nil {
@@ -108,8 +75,9 @@ func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 		return err
 	}
 
-	query = fmt.Sprintf("UPDATE %s SET unread_count=unread_count+1 WHERE room_id=? AND user_id!=?;", tableNameRoomUser)
-	_, err = tx.Exec(query, message.RoomID, message.UserID)
+	// The rooms hidden by the users appear again with the new message
+	query = fmt.Sprintf("UPDATE %s SET unread_count=unread_count+1, hidden=? WHERE room_id=? AND user_id!=?;", tableNameRoomUser)
+	_, err = tx.Exec(rebind(dbMap, query), false, message.RoomID, message.UserID)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting message")
 		logger.Error(err.Error())
@@ -117,26 +85,25 @@ func rdbInsertMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transacti
 		return err
 	}
 
-	var users []*model.User
-	query = fmt.Sprintf("SELECT u.* FROM %s AS ru LEFT JOIN %s AS u ON ru.user_id = u.user_id WHERE room_id = :roomId;", tableNameRoomUser, tableNameUser)
-	params = map[string]interface{}{"roomId": message.RoomID}
-	_, err = tx.Select(&users, query, params)
+	query = fmt.Sprintf("UPDATE %s SET unread_count=unread_count+1 WHERE user_id IN (SELECT user_id FROM %s WHERE room_id=? AND user_id!=?);", tableNameUser, tableNameRoomUser)
+	_, err = tx.Exec(rebind(dbMap, query), message.RoomID, message.UserID)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting message")
 		logger.Error(err.Error())
 		tracer.SetError(span, err)
 		return err
 	}
-	for _, user := range users {
-		if user.UserID == message.UserID {
-			continue
+
+	if len(message.Mentions) > 0 {
+		err = rdbInsertMentions(ctx, dbMap, tx, message)
+		if err != nil {
+			return err
 		}
-		query := fmt.Sprintf("UPDATE %s SET unread_count=unread_count+1 WHERE user_id=?;", tableNameUser)
-		_, err = tx.Exec(query, user.UserID)
+	}
+
+	if len(opt.outboxEvents) > 0 {
+		err = rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
 		if err != nil {
-			err = errors.Wrap(err, "An error occurred while inserting message")
-			logger.Error(err.Error())
-			tracer.SetError(span, err)
 			return err
 		}
 	}
@@ -162,6 +129,11 @@ func rdbSelectMessages(ctx context.Context, dbMap *gorp.DbMap, limit, offset int
 		query = fmt.Sprintf("%s AND room_id = :roomId", query)
 	}
 
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id = :userId", query)
+	}
+
 	if opt.roleIDs != nil {
 		roleIDsQuery, roleIDsParam := makePrepareExpressionParamsForInOperand(opt.roleIDs)
 		params = utils.MergeMap(params, roleIDsParam)
@@ -178,6 +150,11 @@ func rdbSelectMessages(ctx context.Context, dbMap *gorp.DbMap, limit, offset int
 		query = fmt.Sprintf("%s AND created <= :offsetTimestamp", query)
 	}
 
+	if opt.expiredTimestamp != 0 {
+		params["expiredTimestamp"] = opt.expiredTimestamp
+		query = fmt.Sprintf("%s AND expires != 0 AND expires <= :expiredTimestamp", query)
+	}
+
 	query = fmt.Sprintf("%s ORDER BY", query)
 	if opt.orders == nil {
 		query = fmt.Sprintf("%s created ASC", query)
@@ -246,12 +223,22 @@ func rdbSelectCountMessages(ctx context.Context, dbMap *gorp.DbMap, opts ...Sele
 		query = fmt.Sprintf("%s AND room_id = :roomId", query)
 	}
 
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id = :userId", query)
+	}
+
 	if opt.roleIDs != nil {
 		roleIDsQuery, roleIDsParam := makePrepareExpressionParamsForInOperand(opt.roleIDs)
 		params = utils.MergeMap(params, roleIDsParam)
 		query = fmt.Sprintf("%s AND role IN (%s)", query, roleIDsQuery)
 	}
 
+	if opt.expiredTimestamp != 0 {
+		params["expiredTimestamp"] = opt.expiredTimestamp
+		query = fmt.Sprintf("%s AND expires != 0 AND expires <= :expiredTimestamp", query)
+	}
+
 	count, err := dbMap.SelectInt(query, params)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while getting message count")
@@ -277,3 +264,63 @@ func rdbUpdateMessage(ctx context.Context, dbMap *gorp.DbMap, message *model.Mes
 
 	return nil
 }
+
+func rdbDeleteMessages(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, opts ...DeleteMessagesOption) error {
+	span := tracer.StartSpan(ctx, "rdbDeleteMessages", "datastore")
+	defer tracer.Finish(span)
+
+	opt := deleteMessagesOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	if opt.messageIDs == nil || len(opt.messageIDs) == 0 {
+		err := errors.New("An error occurred while deleting messages. Be sure to specify messageIds")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	messageIDsQuery, messageIDsParams := makePrepareExpressionForInOperand(opt.messageIDs)
+
+	if opt.logicalDeleted != 0 {
+		query := fmt.Sprintf("UPDATE %s SET deleted=%d, modified=%d WHERE message_id IN (%s)", tableNameMessage, opt.logicalDeleted, opt.logicalDeleted, messageIDsQuery)
+		_, err := tx.Exec(rebind(dbMap, query), messageIDsParams...)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while deleting messages")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+		return nil
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE message_id IN (%s)", tableNameMention, messageIDsQuery)
+	_, err := tx.Exec(rebind(dbMap, query), messageIDsParams...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE message_id IN (%s)", tableNamePinnedMessage, messageIDsQuery)
+	_, err = tx.Exec(rebind(dbMap, query), messageIDsParams...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE message_id IN (%s)", tableNameMessage, messageIDsQuery)
+	_, err = tx.Exec(rebind(dbMap, query), messageIDsParams...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
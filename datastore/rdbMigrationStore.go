@@ -0,0 +1,237 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateSchemaMigrationTable(ctx context.Context, dbMap *gorp.DbMap) error {
+	span := tracer.StartSpan(ctx, "rdbCreateSchemaMigrationTable", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (version integer not null primary key, description varchar(255) not null, applied bigint not null);", tableNameSchemaMigration)
+	_, err := dbMap.Exec(query)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while creating schema migration table")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbSelectAppliedSchemaMigrations(ctx context.Context, dbMap *gorp.DbMap) (map[int]*model.SchemaMigration, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectAppliedSchemaMigrations", "datastore")
+	defer tracer.Finish(span)
+
+	err := rdbCreateSchemaMigrationTable(ctx, dbMap)
+	if err != nil {
+		return nil, err
+	}
+
+	var schemaMigrations []*model.SchemaMigration
+	query := fmt.Sprintf("SELECT version, description, applied FROM %s ORDER BY version;", tableNameSchemaMigration)
+	_, err = dbMap.Select(&schemaMigrations, query)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting schema migrations")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	applied := make(map[int]*model.SchemaMigration, len(schemaMigrations))
+	for _, sm := range schemaMigrations {
+		applied[sm.Version] = sm
+	}
+
+	return applied, nil
+}
+
+// rdbLockMigration takes the lock of the migrations of the database, so that the processes started
+// at the same time don't apply the same migration. It returns the function to release the lock.
+// The lock is held by a transaction of its own, which is rolled back when the lock is released.
+// PostgreSQL uses a transaction level advisory lock, and MySQL uses GET_LOCK named after the database.
+// SQLite is not locked because the database is used by a process and locking it blocks the migrations,
+// so run `migrate up` before starting the processes which share the database file.
+func rdbLockMigration(ctx context.Context, dbMap *gorp.DbMap) (func(), error) {
+	span := tracer.StartSpan(ctx, "rdbLockMigration", "datastore")
+	defer tracer.Finish(span)
+
+	d := dialect(dbMap)
+	if d == dialectSQLite {
+		return func() {}, nil
+	}
+
+	tx, err := dbMap.Db.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while locking schema migrations")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	lockName := tableNameSchemaMigration
+	switch d {
+	case dialectMySQL:
+		// The lock names are shared by the databases of the server
+		var locked sql.NullInt64
+		err = tx.QueryRow("SELECT GET_LOCK(SHA1(CONCAT(DATABASE(), '.', ?)), ?);", lockName, config.MigrationLockTimeoutSecond).Scan(&locked)
+		if err == nil && locked.Int64 != 1 {
+			err = errors.New("timed out")
+		}
+	case dialectPostgres:
+		_, err = tx.Exec("SELECT pg_advisory_xact_lock(hashtext($1));", lockName)
+	}
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while locking schema migrations")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return func() {
+		if d == dialectMySQL {
+			_, err := tx.Exec("SELECT RELEASE_LOCK(SHA1(CONCAT(DATABASE(), '.', ?)));", lockName)
+			if err != nil {
+				logger.Error(errors.Wrap(err, "An error occurred while unlocking schema migrations").Error())
+			}
+		}
+		tx.Rollback()
+	}, nil
+}
+
+// rdbCreateTables applies the pending migrations and inserts the initial data in the lock of the migrations.
+// The tables have to be mapped to dbMap before calling it.
+func rdbCreateTables(ctx context.Context, dbMap *gorp.DbMap) error {
+	unlock, err := rdbLockMigration(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	err = rdbMigrateUp(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+	return rdbInsertInitialData(ctx, dbMap)
+}
+
+// rdbMigrateUp applies the pending migrations in the order of the version.
+// The caller has to hold the lock of the migrations.
+func rdbMigrateUp(ctx context.Context, dbMap *gorp.DbMap) error {
+	span := tracer.StartSpan(ctx, "rdbMigrateUp", "datastore")
+	defer tracer.Finish(span)
+
+	applied, err := rdbSelectAppliedSchemaMigrations(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.version]; ok {
+			continue
+		}
+
+		err = m.up(dbMap)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("An error occurred while migrating schema up to version %d", m.version))
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+
+		query := fmt.Sprintf("INSERT INTO %s (version, description, applied) VALUES (?, ?, ?);", tableNameSchemaMigration)
+		_, err = dbMap.Exec(rebind(dbMap, query), m.version, m.description, time.Now().Unix())
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("An error occurred while migrating schema up to version %d", m.version))
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Migrated schema up to version %d. %s", m.version, m.description))
+	}
+
+	return nil
+}
+
+// rdbMigrateDown reverts the applied migrations from the latest version by the steps
+func rdbMigrateDown(ctx context.Context, dbMap *gorp.DbMap, steps int) error {
+	span := tracer.StartSpan(ctx, "rdbMigrateDown", "datastore")
+	defer tracer.Finish(span)
+
+	unlock, err := rdbLockMigration(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	applied, err := rdbSelectAppliedSchemaMigrations(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+
+	for i := len(migrations) - 1; i >= 0 && steps > 0; i-- {
+		m := migrations[i]
+		if _, ok := applied[m.version]; !ok {
+			continue
+		}
+
+		err = m.down(dbMap)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("An error occurred while migrating schema down from version %d", m.version))
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+
+		query := fmt.Sprintf("DELETE FROM %s WHERE version=?;", tableNameSchemaMigration)
+		_, err = dbMap.Exec(rebind(dbMap, query), m.version)
+		if err != nil {
+			err = errors.Wrap(err, fmt.Sprintf("An error occurred while migrating schema down from version %d", m.version))
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+
+		logger.Info(fmt.Sprintf("Migrated schema down from version %d. %s", m.version, m.description))
+		steps--
+	}
+
+	return nil
+}
+
+// rdbSelectSchemaMigrations returns all of the migrations. The pending ones have no applied timestamp.
+func rdbSelectSchemaMigrations(ctx context.Context, dbMap *gorp.DbMap) ([]*model.SchemaMigration, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectSchemaMigrations", "datastore")
+	defer tracer.Finish(span)
+
+	applied, err := rdbSelectAppliedSchemaMigrations(ctx, dbMap)
+	if err != nil {
+		return nil, err
+	}
+
+	schemaMigrations := make([]*model.SchemaMigration, len(migrations))
+	for i, m := range migrations {
+		schemaMigrations[i] = &model.SchemaMigration{
+			Version:     m.version,
+			Description: m.description,
+		}
+		if sm, ok := applied[m.version]; ok {
+			schemaMigrations[i].Applied = sm.Applied
+		}
+	}
+
+	return schemaMigrations, nil
+}
@@ -0,0 +1,149 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateOutboxEventStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateOutboxEventStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.OutboxEvent{}, tableNameOutboxEvent)
+	tableMap.SetKeys(true, "id")
+	tableMap.SetUniqueTogether("event_id", "destination")
+	for _, columnMap := range tableMap.Columns {
+		// The payload can be longer than varchar(255)
+		if columnMap.ColumnName == "payload" {
+			columnMap.SetMaxSize(65535)
+		}
+	}
+}
+
+// rdbInsertOutboxEvents inserts the outbox events.
+// It is called in the transaction of the change which the events notify.
+func rdbInsertOutboxEvents(ctx context.Context, tx *gorp.Transaction, outboxEvents []*model.OutboxEvent) error {
+	span := tracer.StartSpan(ctx, "rdbInsertOutboxEvents", "datastore")
+	defer tracer.Finish(span)
+
+	for _, outboxEvent := range outboxEvents {
+		err := tx.Insert(outboxEvent)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while inserting outbox event")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func rdbSelectOutboxEvents(ctx context.Context, dbMap *gorp.DbMap, limit int32, opts ...SelectOutboxEventsOption) ([]*model.OutboxEvent, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectOutboxEvents", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectOutboxEventsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	var outboxEvents []*model.OutboxEvent
+	query := fmt.Sprintf("SELECT * FROM %s WHERE dispatched=0 AND failed=0", tableNameOutboxEvent)
+	params := make(map[string]interface{})
+
+	if opt.dueTimestamp != 0 {
+		params["dueTimestamp"] = opt.dueTimestamp
+		query = fmt.Sprintf("%s AND next_attempt<=:dueTimestamp", query)
+	}
+
+	query = fmt.Sprintf("%s ORDER BY id ASC LIMIT :limit", query)
+	params["limit"] = limit
+
+	_, err := dbMap.Select(&outboxEvents, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting outbox events")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return outboxEvents, nil
+}
+
+func rdbClaimOutboxEvent(ctx context.Context, dbMap *gorp.DbMap, outboxEvent *model.OutboxEvent, leaseTimestamp int64) (bool, error) {
+	span := tracer.StartSpan(ctx, "rdbClaimOutboxEvent", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("UPDATE %s SET next_attempt=? WHERE id=? AND next_attempt=? AND dispatched=0 AND failed=0;", tableNameOutboxEvent)
+	result, err := dbMap.Exec(rebind(dbMap, query), leaseTimestamp, outboxEvent.ID, outboxEvent.NextAttempt)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while claiming outbox event")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while claiming outbox event")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+	if count != 1 {
+		return false, nil
+	}
+
+	outboxEvent.NextAttempt = leaseTimestamp
+	return true, nil
+}
+
+func rdbUpdateOutboxEvent(ctx context.Context, dbMap *gorp.DbMap, outboxEvent *model.OutboxEvent) error {
+	span := tracer.StartSpan(ctx, "rdbUpdateOutboxEvent", "datastore")
+	defer tracer.Finish(span)
+
+	_, err := dbMap.Update(outboxEvent)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating outbox event")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbDeleteOutboxEvents(ctx context.Context, dbMap *gorp.DbMap, opts ...DeleteOutboxEventsOption) error {
+	span := tracer.StartSpan(ctx, "rdbDeleteOutboxEvents", "datastore")
+	defer tracer.Finish(span)
+
+	opt := deleteOutboxEventsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	if opt.dispatchedTimestamp == 0 {
+		err := errors.New("An error occurred while deleting outbox events. Be sure to specify dispatchedTimestamp")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE (dispatched!=0 AND dispatched<=?) OR (failed!=0 AND failed<=?);", tableNameOutboxEvent)
+	_, err := dbMap.Exec(rebind(dbMap, query), opt.dispatchedTimestamp, opt.dispatchedTimestamp)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting outbox events")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
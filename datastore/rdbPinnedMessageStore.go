@@ -0,0 +1,174 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreatePinnedMessageStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreatePinnedMessageStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.PinnedMessage{}, tableNamePinnedMessage)
+	tableMap.SetKeys(true, "id")
+	tableMap.SetUniqueTogether("room_id", "message_id")
+}
+
+func rdbInsertPinnedMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, pinnedMessage *model.PinnedMessage, opts ...PinnedMessageOption) error {
+	span := tracer.StartSpan(ctx, "rdbInsertPinnedMessage", "datastore")
+	defer tracer.Finish(span)
+
+	opt := pinnedMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf("SELECT COALESCE(MAX(display_order), 0) FROM %s WHERE room_id=:roomId;", tableNamePinnedMessage)
+	params := map[string]interface{}{"roomId": pinnedMessage.RoomID}
+	maxOrder, err := tx.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting pinned message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+	pinnedMessage.Order = int32(maxOrder) + 1
+
+	err = tx.Insert(pinnedMessage)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting pinned message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+}
+
+func rdbSelectPinnedMessages(ctx context.Context, dbMap *gorp.DbMap, roomID string) ([]*model.PinnedMessage, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectPinnedMessages", "datastore")
+	defer tracer.Finish(span)
+
+	var pinnedMessages []*model.PinnedMessage
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId ORDER BY display_order ASC, id ASC;", tableNamePinnedMessage)
+	params := map[string]interface{}{"roomId": roomID}
+	_, err := dbMap.Select(&pinnedMessages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting pinned messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(pinnedMessages) == 0 {
+		return pinnedMessages, nil
+	}
+
+	messageIDs := make([]string, len(pinnedMessages))
+	for i, pm := range pinnedMessages {
+		messageIDs[i] = pm.MessageID
+	}
+
+	var messages []*model.Message
+	messageIDsQuery, messageIDsParams := makePrepareExpressionParamsForInOperand(messageIDs)
+	query = fmt.Sprintf("SELECT * FROM %s WHERE message_id IN (%s) AND deleted=0;", tableNameMessage, messageIDsQuery)
+	_, err = dbMap.Select(&messages, query, messageIDsParams)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting pinned messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	messageMap := make(map[string]*model.Message, len(messages))
+	for _, message := range messages {
+		messageMap[message.MessageID] = message
+	}
+
+	selected := make([]*model.PinnedMessage, 0, len(pinnedMessages))
+	for _, pm := range pinnedMessages {
+		message, ok := messageMap[pm.MessageID]
+		if !ok {
+			continue
+		}
+		pm.Message = message
+		selected = append(selected, pm)
+	}
+
+	return selected, nil
+}
+
+func rdbSelectPinnedMessage(ctx context.Context, dbMap *gorp.DbMap, roomID, messageID string) (*model.PinnedMessage, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectPinnedMessage", "datastore")
+	defer tracer.Finish(span)
+
+	var pinnedMessages []*model.PinnedMessage
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId AND message_id=:messageId;", tableNamePinnedMessage)
+	params := map[string]interface{}{
+		"roomId":    roomID,
+		"messageId": messageID,
+	}
+	_, err := dbMap.Select(&pinnedMessages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting pinned message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(pinnedMessages) == 1 {
+		return pinnedMessages[0], nil
+	}
+
+	return nil, nil
+}
+
+func rdbDeletePinnedMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, roomID, messageID string, opts ...PinnedMessageOption) error {
+	span := tracer.StartSpan(ctx, "rdbDeletePinnedMessage", "datastore")
+	defer tracer.Finish(span)
+
+	opt := pinnedMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE room_id=? AND message_id=?;", tableNamePinnedMessage)
+	_, err := tx.Exec(rebind(dbMap, query), roomID, messageID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting pinned message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+}
+
+func rdbUpdatePinnedMessagesOrder(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, roomID string, messageIDs []string, opts ...PinnedMessageOption) error {
+	span := tracer.StartSpan(ctx, "rdbUpdatePinnedMessagesOrder", "datastore")
+	defer tracer.Finish(span)
+
+	opt := pinnedMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET display_order=? WHERE room_id=? AND message_id=?;", tableNamePinnedMessage)
+	for i, messageID := range messageIDs {
+		_, err := tx.Exec(rebind(dbMap, query), i+1, roomID, messageID)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while updating pinned messages order")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	return rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+}
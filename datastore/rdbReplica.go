@@ -0,0 +1,173 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/config"
+	gorp "gopkg.in/gorp.v2"
+)
+
+// rdbReplica is a replica which is ejected from reads while it's unhealthy
+type rdbReplica struct {
+	dbMap   *gorp.DbMap
+	healthy int32
+	lag     int64
+}
+
+func newRdbReplica(dbMap *gorp.DbMap) *rdbReplica {
+	return &rdbReplica{
+		dbMap:   dbMap,
+		healthy: 1,
+	}
+}
+
+func (r *rdbReplica) isHealthy() bool {
+	return atomic.LoadInt32(&r.healthy) == 1
+}
+
+// check probes the replica and ejects it if it's unreachable or lags behind master more than maxLag seconds.
+// maxLag 0 doesn't limit the lag.
+func (r *rdbReplica) check(database string, maxLag int64) {
+	err := r.dbMap.Db.Ping()
+	if err == nil {
+		var lag int64
+		lag, err = replicationLag(r.dbMap)
+		if err == nil {
+			atomic.StoreInt64(&r.lag, lag)
+			if maxLag > 0 && lag > maxLag {
+				err = fmt.Errorf("Replication lag %d seconds exceeds %d seconds", lag, maxLag)
+			}
+		}
+	}
+
+	if err != nil {
+		if atomic.SwapInt32(&r.healthy, 0) == 1 {
+			logger.Warn(fmt.Sprintf("Replica was ejected. %s %s", database, err.Error()))
+		}
+		return
+	}
+
+	if atomic.SwapInt32(&r.healthy, 1) == 0 {
+		logger.Info(fmt.Sprintf("Replica was recovered. %s", database))
+	}
+}
+
+// replicationLag returns the seconds the replica lags behind master
+func replicationLag(dbMap *gorp.DbMap) (int64, error) {
+	switch dialect(dbMap) {
+	case dialectMySQL:
+		return mysqlReplicationLag(dbMap.Db)
+	case dialectPostgres:
+		// The replay timestamp stays old while master has no writes, so the lag is 0 if all of the received WAL is replayed
+		query := `SELECT CAST(CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+ELSE COALESCE(EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()), 0) END AS bigint);`
+		return dbMap.SelectInt(query)
+	default:
+		return 0, nil
+	}
+}
+
+func mysqlReplicationLag(db *sql.DB) (int64, error) {
+	rows, err := db.Query("SHOW SLAVE STATUS;")
+	if err != nil {
+		return 0, errors.Wrap(err, "An error occurred while getting replication lag")
+	}
+	defer rows.Close()
+
+	// The server is not a replica
+	if !rows.Next() {
+		return 0, rows.Err()
+	}
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, errors.Wrap(err, "An error occurred while getting replication lag")
+	}
+	values := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	err = rows.Scan(dest...)
+	if err != nil {
+		return 0, errors.Wrap(err, "An error occurred while getting replication lag")
+	}
+
+	for i, column := range columns {
+		if column != "Seconds_Behind_Master" {
+			continue
+		}
+		if !values[i].Valid {
+			return 0, errors.New("Replication is not running")
+		}
+		var lag int64
+		_, err = fmt.Sscanf(values[i].String, "%d", &lag)
+		if err != nil {
+			return 0, errors.Wrap(err, "An error occurred while getting replication lag")
+		}
+		return lag, nil
+	}
+
+	return 0, nil
+}
+
+// RunReplicaHealthChecker probes the replicas of the connected databases until ctx is done.
+// Unhealthy replicas are ejected from reads and put back when they recover.
+func RunReplicaHealthChecker(ctx context.Context) {
+	ticker := time.NewTicker(config.ReplicaHealthCheckIntervalSecond * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkReplicas()
+		}
+	}
+}
+
+func checkReplicas() {
+	maxLag := int64(config.Config().Datastore.ReplicaMaxLag)
+	deadline := time.Now().Add(-stickToMasterDuration())
+	for database, rs := range rdbStores.snapshot() {
+		for _, replica := range rs.replicas {
+			replica.check(database, maxLag)
+		}
+
+		rs.lastWrites.Range(func(requester, written interface{}) bool {
+			if written.(time.Time).Before(deadline) {
+				rs.lastWrites.Delete(requester)
+			}
+			return true
+		})
+	}
+}
+
+// stickiness tracks the writes with a context to read them from master
+type stickiness struct {
+	requester string
+	written   int32
+}
+
+// StickToMaster returns the context whose reads go to master after it writes.
+// requester identifies the user or the client of the request. Their reads keep going to master
+// for datastore.stickToMasterAfterWrite seconds after the write, so the following requests read the write as well.
+func StickToMaster(ctx context.Context, requester string) context.Context {
+	return context.WithValue(ctx, config.CtxStickiness, &stickiness{requester: requester})
+}
+
+// ReadFromMaster returns the context whose reads always go to master
+func ReadFromMaster(ctx context.Context) context.Context {
+	return context.WithValue(ctx, config.CtxStickiness, &stickiness{written: 1})
+}
+
+func stickToMasterDuration() time.Duration {
+	return time.Duration(config.Config().Datastore.StickToMasterAfterWrite) * time.Second
+}
@@ -0,0 +1,77 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+
+	"github.com/swagchat/chat-api/config"
+	gorp "gopkg.in/gorp.v2"
+)
+
+const (
+	TestStoreRdbReplicaEject          = "[store] rdb replica eject unhealthy test"
+	TestStoreRdbReplicaStickToMaster  = "[store] rdb replica stick to master after write test"
+	TestStoreRdbReplicaReadFromMaster = "[store] rdb replica read from master test"
+)
+
+func TestRdbReplica(t *testing.T) {
+	t.Run(TestStoreRdbReplicaEject, func(t *testing.T) {
+		rs := &rdbStore{}
+		rs.setMaster(&gorp.DbMap{})
+		rs.setReplica(&gorp.DbMap{})
+		rs.setReplica(&gorp.DbMap{})
+
+		rs.replicas[0].healthy = 0
+		for i := 0; i < 4; i++ {
+			if rs.replica() != rs.replicas[1].dbMap {
+				t.Fatalf("Failed to %s. Expected the healthy replica to be read", TestStoreRdbReplicaEject)
+			}
+		}
+
+		rs.replicas[1].healthy = 0
+		if rs.replica() != rs.master() {
+			t.Fatalf("Failed to %s. Expected master to be read when no replica is healthy", TestStoreRdbReplicaEject)
+		}
+	})
+
+	t.Run(TestStoreRdbReplicaStickToMaster, func(t *testing.T) {
+		config.Config().Datastore.StickToMasterAfterWrite = 5
+
+		rs := &rdbStore{}
+		rs.setMaster(&gorp.DbMap{})
+		rs.setReplica(&gorp.DbMap{})
+
+		ctx := StickToMaster(context.Background(), "rdb-replica-user-id-0001")
+		if rs.replicaFor(ctx) == rs.master() {
+			t.Fatalf("Failed to %s. Expected the replica to be read before the write", TestStoreRdbReplicaStickToMaster)
+		}
+
+		rs.masterFor(ctx)
+		if rs.replicaFor(ctx) != rs.master() {
+			t.Fatalf("Failed to %s. Expected master to be read after the write", TestStoreRdbReplicaStickToMaster)
+		}
+
+		nextCtx := StickToMaster(context.Background(), "rdb-replica-user-id-0001")
+		if rs.replicaFor(nextCtx) != rs.master() {
+			t.Fatalf("Failed to %s. Expected master to be read by the next request of the same user", TestStoreRdbReplicaStickToMaster)
+		}
+
+		otherCtx := StickToMaster(context.Background(), "rdb-replica-user-id-0002")
+		if rs.replicaFor(otherCtx) == rs.master() {
+			t.Fatalf("Failed to %s. Expected the replica to be read by the other user", TestStoreRdbReplicaStickToMaster)
+		}
+	})
+
+	t.Run(TestStoreRdbReplicaReadFromMaster, func(t *testing.T) {
+		rs := &rdbStore{}
+		rs.setMaster(&gorp.DbMap{})
+		rs.setReplica(&gorp.DbMap{})
+
+		if rs.replicaFor(ReadFromMaster(context.Background())) != rs.master() {
+			t.Fatalf("Failed to %s. Expected master to be read", TestStoreRdbReplicaReadFromMaster)
+		}
+		if rs.replicaFor(context.Background()) == rs.master() {
+			t.Fatalf("Failed to %s. Expected the replica to be read without stickiness", TestStoreRdbReplicaReadFromMaster)
+		}
+	})
+}
@@ -0,0 +1,206 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateRoomInvitationStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateRoomInvitationStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.RoomInvitation{}, tableNameRoomInvitation)
+	tableMap.SetKeys(true, "id")
+	for _, columnMap := range tableMap.Columns {
+		if columnMap.ColumnName == "invitation_id" {
+			columnMap.SetUnique(true)
+		}
+	}
+}
+
+func rdbInsertRoomInvitations(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, invitations []*model.RoomInvitation, opts ...InsertRoomInvitationsOption) error {
+	span := tracer.StartSpan(ctx, "rdbInsertRoomInvitations", "datastore")
+	defer tracer.Finish(span)
+
+	opt := insertRoomInvitationsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	for _, invitation := range invitations {
+		err := tx.Insert(invitation)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while inserting room invitations")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	return rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+}
+
+func rdbMakeRoomInvitationsCondition(opt selectRoomInvitationsOptions) (string, map[string]interface{}) {
+	query := "WHERE 1=1"
+	params := make(map[string]interface{})
+
+	if opt.roomID != "" {
+		params["roomId"] = opt.roomID
+		query = fmt.Sprintf("%s AND room_id=:roomId", query)
+	}
+
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id=:userId", query)
+	}
+
+	if opt.invitationType != "" {
+		params["type"] = opt.invitationType
+		query = fmt.Sprintf("%s AND type=:type", query)
+	}
+
+	if opt.status != "" {
+		params["status"] = opt.status
+		query = fmt.Sprintf("%s AND status=:status", query)
+	}
+
+	return query, params
+}
+
+func rdbSelectRoomInvitations(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, opts ...SelectRoomInvitationsOption) ([]*model.RoomInvitation, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectRoomInvitations", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectRoomInvitationsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	condition, params := rdbMakeRoomInvitationsCondition(opt)
+	query := fmt.Sprintf("SELECT * FROM %s %s ORDER BY created DESC, id DESC LIMIT :limit OFFSET :offset", tableNameRoomInvitation, condition)
+	params["limit"] = limit
+	params["offset"] = offset
+
+	var invitations []*model.RoomInvitation
+	_, err := dbMap.Select(&invitations, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room invitations")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return invitations, nil
+}
+
+func rdbSelectCountRoomInvitations(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectRoomInvitationsOption) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountRoomInvitations", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectRoomInvitationsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	condition, params := rdbMakeRoomInvitationsCondition(opt)
+	query := fmt.Sprintf("SELECT count(id) FROM %s %s", tableNameRoomInvitation, condition)
+	count, err := dbMap.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room invitation count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func rdbSelectRoomInvitation(ctx context.Context, dbMap *gorp.DbMap, invitationID string) (*model.RoomInvitation, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectRoomInvitation", "datastore")
+	defer tracer.Finish(span)
+
+	var invitations []*model.RoomInvitation
+	query := fmt.Sprintf("SELECT * FROM %s WHERE invitation_id=:invitationId;", tableNameRoomInvitation)
+	params := map[string]interface{}{"invitationId": invitationID}
+	_, err := dbMap.Select(&invitations, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room invitation")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(invitations) == 1 {
+		return invitations[0], nil
+	}
+
+	return nil, nil
+}
+
+func rdbUpdateRoomInvitation(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, invitation *model.RoomInvitation, opts ...UpdateRoomInvitationOption) (bool, error) {
+	span := tracer.StartSpan(ctx, "rdbUpdateRoomInvitation", "datastore")
+	defer tracer.Finish(span)
+
+	opt := updateRoomInvitationOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	// The status is checked in the update not to answer the invitation twice by the concurrent requests
+	query := fmt.Sprintf("UPDATE %s SET status=?, reviewer_user_id=?, modified=? WHERE invitation_id=? AND status=?;", tableNameRoomInvitation)
+	result, err := tx.Exec(rebind(dbMap, query), invitation.Status, invitation.ReviewerUserID, invitation.Modified, invitation.InvitationID, model.RoomInvitationStatusPending)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room invitation")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room invitation")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	if len(opt.roomUsers) > 0 {
+		err = rdbInsertRoomUsers(ctx, dbMap, tx, opt.roomUsers, InsertRoomUsersOptionWithOutboxEvents(opt.outboxEvents))
+		if err != nil {
+			return false, err
+		}
+		return true, nil
+	}
+
+	err = rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func rdbExpireRoomInvitations(ctx context.Context, dbMap *gorp.DbMap, nowTimestamp int64) error {
+	span := tracer.StartSpan(ctx, "rdbExpireRoomInvitations", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("UPDATE %s SET status=?, modified=? WHERE status=? AND expires>0 AND expires<=?;", tableNameRoomInvitation)
+	_, err := dbMap.Exec(rebind(dbMap, query), model.RoomInvitationStatusExpired, nowTimestamp, model.RoomInvitationStatusPending, nowTimestamp)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while expiring room invitations")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,140 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateRoomInviteLinkStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateRoomInviteLinkStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.RoomInviteLink{}, tableNameRoomInviteLink)
+	tableMap.SetKeys(true, "id")
+	for _, columnMap := range tableMap.Columns {
+		if columnMap.ColumnName == "invite_link_id" {
+			columnMap.SetUnique(true)
+		}
+	}
+}
+
+func rdbInsertRoomInviteLink(ctx context.Context, dbMap *gorp.DbMap, inviteLink *model.RoomInviteLink) error {
+	span := tracer.StartSpan(ctx, "rdbInsertRoomInviteLink", "datastore")
+	defer tracer.Finish(span)
+
+	err := dbMap.Insert(inviteLink)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting room invite link")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbSelectRoomInviteLinks(ctx context.Context, dbMap *gorp.DbMap, roomID string) ([]*model.RoomInviteLink, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectRoomInviteLinks", "datastore")
+	defer tracer.Finish(span)
+
+	var inviteLinks []*model.RoomInviteLink
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId AND deleted=0 ORDER BY created DESC, id DESC;", tableNameRoomInviteLink)
+	params := map[string]interface{}{"roomId": roomID}
+	_, err := dbMap.Select(&inviteLinks, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room invite links")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return inviteLinks, nil
+}
+
+func rdbSelectRoomInviteLink(ctx context.Context, dbMap *gorp.DbMap, inviteLinkID string) (*model.RoomInviteLink, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectRoomInviteLink", "datastore")
+	defer tracer.Finish(span)
+
+	var inviteLinks []*model.RoomInviteLink
+	query := fmt.Sprintf("SELECT * FROM %s WHERE invite_link_id=:inviteLinkId AND deleted=0;", tableNameRoomInviteLink)
+	params := map[string]interface{}{"inviteLinkId": inviteLinkID}
+	_, err := dbMap.Select(&inviteLinks, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting room invite link")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(inviteLinks) == 1 {
+		return inviteLinks[0], nil
+	}
+
+	return nil, nil
+}
+
+func rdbDeleteRoomInviteLink(ctx context.Context, dbMap *gorp.DbMap, inviteLink *model.RoomInviteLink) error {
+	span := tracer.StartSpan(ctx, "rdbDeleteRoomInviteLink", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("UPDATE %s SET deleted=?, modified=? WHERE invite_link_id=?;", tableNameRoomInviteLink)
+	_, err := dbMap.Exec(rebind(dbMap, query), inviteLink.Deleted, inviteLink.Deleted, inviteLink.InviteLinkID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting room invite link")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbUseRoomInviteLink(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, inviteLink *model.RoomInviteLink, roomUser *model.RoomUser, opts ...UseRoomInviteLinkOption) (bool, error) {
+	span := tracer.StartSpan(ctx, "rdbUseRoomInviteLink", "datastore")
+	defer tracer.Finish(span)
+
+	opt := useRoomInviteLinkOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	nowTimestamp := time.Now().Unix()
+
+	// The conditions are checked in the update not to exceed the max uses by the concurrent uses
+	query := fmt.Sprintf(`UPDATE %s SET uses=uses+1, modified=?
+WHERE invite_link_id=? AND deleted=0
+AND (max_uses=0 OR uses<max_uses)
+AND (expires=0 OR expires>?);`, tableNameRoomInviteLink)
+	result, err := tx.Exec(rebind(dbMap, query), nowTimestamp, inviteLink.InviteLinkID, nowTimestamp)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while using room invite link")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while using room invite link")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+	if affected == 0 {
+		return false, nil
+	}
+
+	err = rdbInsertRoomUsers(ctx, dbMap, tx, []*model.RoomUser{roomUser}, InsertRoomUsersOptionWithOutboxEvents(opt.outboxEvents))
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
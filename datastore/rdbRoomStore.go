@@ -3,11 +3,13 @@ package datastore
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	logger "github.com/betchi/zapper"
 	"github.com/pkg/errors"
 	"github.com/swagchat/chat-api/model"
 	"github.com/betchi/tracer"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 	"gopkg.in/gorp.v2"
 )
 
@@ -21,13 +23,9 @@ func rdbCreateRoomStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating room table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
+
+	tableMap = dbMap.AddTableWithName(model.DeletedRoomUser{}, tableNameDeletedRoomUser)
+	tableMap.SetUniqueTogether("room_id", "user_id")
 }
 
 func rdbInsertRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, room *model.Room, opts ...InsertRoomOption) error {
@@ -39,6 +37,7 @@ func rdbInsertRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		o(&opt)
 	}
 
+	room.SetTags()
 	err := tx.Insert(room)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting room")
@@ -47,15 +46,21 @@ func rdbInsertRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		return err
 	}
 
-	for _, ru := range opt.users {
+	if len(opt.users) > 0 {
 		query := fmt.Sprintf("DELETE FROM %s WHERE room_id=?;", tableNameRoomUser)
-		_, err = tx.Exec(query, room.RoomID)
+		_, err = tx.Exec(rebind(dbMap, query), room.RoomID)
 		if err != nil {
 			err := errors.Wrap(err, "An error occurred while inserting room")
 			logger.Error(err.Error())
 			tracer.SetError(span, err)
 			return err
 		}
+	}
+
+	for _, ru := range opt.users {
+		if ru.RoomRole == "" {
+			ru.RoomRole = model.RoomRoleMember
+		}
 
 		err = tx.Insert(ru)
 		if err != nil {
@@ -66,6 +71,13 @@ func rdbInsertRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		}
 	}
 
+	if len(opt.outboxEvents) > 0 {
+		err = rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -89,12 +101,21 @@ func rdbSelectRooms(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32,
 	type,
 	last_message,
 	last_message_updated,
+	last_message_id,
+	last_message_type,
+	last_message_user_id,
+	retention_max_age,
+	retention_max_count,
 	created,
 	modified
 	FROM %s
 	WHERE deleted = 0`, tableNameRoom)
 	params := make(map[string]interface{})
 
+	if opt.filterByRetentionEnabled {
+		query = fmt.Sprintf("%s AND (retention_max_age > 0 OR retention_max_count > 0)", query)
+	}
+
 	query = fmt.Sprintf("%s ORDER BY", query)
 	if opt.orders == nil {
 		query = fmt.Sprintf("%s created DESC", query)
@@ -133,7 +154,10 @@ func rdbSelectRoom(ctx context.Context, dbMap *gorp.DbMap, roomID string, opts .
 	}
 
 	var rooms []*model.Room
-	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId AND deleted=0;", tableNameRoom)
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId", tableNameRoom)
+	if !opt.withDeleted {
+		query = fmt.Sprintf("%s AND deleted=0", query)
+	}
 	params := map[string]interface{}{"roomId": roomID}
 	_, err := dbMap.Select(&rooms, query, params)
 	if err != nil {
@@ -179,7 +203,8 @@ u.can_block,
 u.last_accessed,
 u.created,
 u.modified,
-ru.display AS ru_display
+ru.display AS ru_display,
+ru.room_role AS ru_room_role
 FROM %s AS ru 
 LEFT JOIN %s AS u ON ru.user_id = u.user_id 
 WHERE ru.room_id = :roomId AND u.deleted = 0 
@@ -200,7 +225,15 @@ func rdbSelectCountRooms(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectR
 	span := tracer.StartSpan(ctx, "rdbSelectCountRooms", "datastore")
 	defer tracer.Finish(span)
 
-	query := fmt.Sprintf("SELECT count(id) FROM %s WHERE deleted = 0;", tableNameRoom)
+	opt := selectRoomsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s WHERE deleted = 0", tableNameRoom)
+	if opt.filterByRetentionEnabled {
+		query = fmt.Sprintf("%s AND (retention_max_age > 0 OR retention_max_count > 0)", query)
+	}
 	count, err := dbMap.SelectInt(query)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while getting room count")
@@ -212,6 +245,85 @@ func rdbSelectCountRooms(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectR
 	return count, nil
 }
 
+func rdbMakePublicRoomsCondition(opt selectPublicRoomsOptions) (string, map[string]interface{}) {
+	query := fmt.Sprintf("WHERE r.deleted=0 AND r.archived=0 AND r.type=%d", scpb.RoomType_PublicRoom)
+	params := make(map[string]interface{})
+
+	if opt.name != "" {
+		params["name"] = fmt.Sprintf("%%%s%%", strings.ToLower(opt.name))
+		query = fmt.Sprintf("%s AND LOWER(r.name) LIKE :name", query)
+	}
+
+	for i, tag := range opt.tags {
+		key := fmt.Sprintf("tag%d", i)
+		params[key] = fmt.Sprintf("%%,%s,%%", strings.ToLower(strings.TrimSpace(tag)))
+		query = fmt.Sprintf("%s AND r.tags LIKE :%s", query, key)
+	}
+
+	return query, params
+}
+
+func rdbSelectPublicRooms(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, opts ...SelectPublicRoomsOption) ([]*model.PublicRoom, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectPublicRooms", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectPublicRoomsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	condition, params := rdbMakePublicRoomsCondition(opt)
+	query := fmt.Sprintf(`SELECT
+r.room_id,
+r.user_id,
+r.name,
+r.picture_url,
+r.information_url,
+r.meta_data,
+(SELECT count(ru.id) FROM %s AS ru WHERE ru.room_id=r.room_id) AS member_count,
+r.last_message_updated,
+r.created
+FROM %s AS r
+%s
+ORDER BY member_count DESC, r.created DESC
+LIMIT :limit OFFSET :offset;`, tableNameRoomUser, tableNameRoom, condition)
+	params["limit"] = limit
+	params["offset"] = offset
+
+	var rooms []*model.PublicRoom
+	_, err := dbMap.Select(&rooms, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting public rooms")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return rooms, nil
+}
+
+func rdbSelectCountPublicRooms(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectPublicRoomsOption) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountPublicRooms", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectPublicRoomsOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	condition, params := rdbMakePublicRoomsCondition(opt)
+	query := fmt.Sprintf("SELECT count(r.id) FROM %s AS r %s;", tableNameRoom, condition)
+	count, err := dbMap.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting public room count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
 func rdbUpdateRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, room *model.Room, opts ...UpdateRoomOption) error {
 	span := tracer.StartSpan(ctx, "rdbUpdateRoom", "datastore")
 	defer tracer.Finish(span)
@@ -225,6 +337,7 @@ func rdbUpdateRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		return rdbUpdateRoomDeleted(ctx, dbMap, tx, room)
 	}
 
+	room.SetTags()
 	_, err := tx.Update(room)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while updating room")
@@ -233,17 +346,48 @@ func rdbUpdateRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		return err
 	}
 
-	for _, ru := range opt.users {
-		query := fmt.Sprintf("DELETE FROM %s WHERE room_id=?;", tableNameRoomUser)
-		_, err := tx.Exec(query, room.RoomID)
-		if err != nil {
-			err := errors.Wrap(err, "An error occurred while inserting room")
-			logger.Error(err.Error())
-			tracer.SetError(span, err)
-			return err
+	if len(opt.users) > 0 {
+		return rdbReplaceRoomUsers(ctx, dbMap, tx, room.RoomID, opt.users)
+	}
+
+	return nil
+}
+
+// rdbReplaceRoomUsers replaces the room users of the room with the given ones.
+// The users who stay in the room keep all of their columns except the room role, and only the others are deleted or inserted.
+func rdbReplaceRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, roomID string, rus []*model.RoomUser) error {
+	span := tracer.StartSpan(ctx, "rdbReplaceRoomUsers", "datastore")
+	defer tracer.Finish(span)
+
+	var currentUserIDs []string
+	query := fmt.Sprintf("SELECT user_id FROM %s WHERE room_id=:roomId;", tableNameRoomUser)
+	params := map[string]interface{}{"roomId": roomID}
+	_, err := tx.Select(&currentUserIDs, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	current := make(map[string]bool, len(currentUserIDs))
+	for _, userID := range currentUserIDs {
+		current[userID] = true
+	}
+
+	staying := make(map[string]bool, len(rus))
+	for _, ru := range rus {
+		if ru.RoomRole == "" {
+			ru.RoomRole = model.RoomRoleMember
 		}
+		staying[ru.UserID] = true
 
-		err = tx.Insert(ru)
+		if current[ru.UserID] {
+			query = fmt.Sprintf("UPDATE %s SET room_role=? WHERE room_id=? AND user_id=?;", tableNameRoomUser)
+			_, err = tx.Exec(rebind(dbMap, query), ru.RoomRole, roomID, ru.UserID)
+		} else {
+			err = tx.Insert(ru)
+		}
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while updating room")
 			logger.Error(err.Error())
@@ -252,14 +396,42 @@ func rdbUpdateRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 		}
 	}
 
-	return nil
+	var leftUserIDs []string
+	for _, userID := range currentUserIDs {
+		if !staying[userID] {
+			leftUserIDs = append(leftUserIDs, userID)
+		}
+	}
+	if len(leftUserIDs) == 0 {
+		return nil
+	}
+
+	return rdbDeleteRoomUsers(
+		ctx,
+		dbMap,
+		tx,
+		DeleteRoomUsersOptionFilterByRoomIDs([]string{roomID}),
+		DeleteRoomUsersOptionFilterByUserIDs(leftUserIDs),
+	)
 }
 
 func rdbUpdateRoomDeleted(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, room *model.Room) error {
 	span := tracer.StartSpan(ctx, "rdbUpdateRoomDeleted", "datastore")
 	defer tracer.Finish(span)
 
-	err := rdbDeleteRoomUsers(
+	// The room users are kept with all of their columns to restore the room.
+	// The deleted timestamp is written into the query, because a placeholder in the select list has no type in PostgreSQL.
+	columns := "room_id, user_id, unread_count, mention_count, display, room_role, favorite, hidden, muted, archived, sort_key, last_read"
+	query := fmt.Sprintf("INSERT INTO %s (%s, deleted) SELECT %s, %d FROM %s WHERE room_id=?;", tableNameDeletedRoomUser, columns, columns, room.DeletedTimestamp, tableNameRoomUser)
+	_, err := tx.Exec(rebind(dbMap, query), room.RoomID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	err = rdbDeleteRoomUsers(
 		ctx,
 		dbMap,
 		tx,
@@ -280,8 +452,8 @@ func rdbUpdateRoomDeleted(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Trans
 		return err
 	}
 
-	query := fmt.Sprintf("UPDATE %s SET deleted=? WHERE room_id=?;", tableNameRoom)
-	_, err = tx.Exec(query, room.DeletedTimestamp, room.RoomID)
+	query = fmt.Sprintf("UPDATE %s SET deleted=? WHERE room_id=?;", tableNameRoom)
+	_, err = tx.Exec(rebind(dbMap, query), room.DeletedTimestamp, room.RoomID)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting room")
 		logger.Error(err.Error())
@@ -291,3 +463,152 @@ func rdbUpdateRoomDeleted(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Trans
 
 	return nil
 }
+
+func rdbUpdateRoomOwner(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, room *model.Room, userID string) error {
+	span := tracer.StartSpan(ctx, "rdbUpdateRoomOwner", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("UPDATE %s SET room_role=? WHERE room_id=? AND user_id=?;", tableNameRoomUser)
+	_, err := tx.Exec(rebind(dbMap, query), model.RoomRoleAdmin, room.RoomID, room.UserID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	_, err = tx.Exec(rebind(dbMap, query), model.RoomRoleOwner, room.RoomID, userID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	query = fmt.Sprintf("UPDATE %s SET user_id=?, modified=? WHERE room_id=?;", tableNameRoom)
+	_, err = tx.Exec(rebind(dbMap, query), userID, room.ModifiedTimestamp, room.RoomID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbUpdateRoomLastMessage(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, roomID string) error {
+	span := tracer.StartSpan(ctx, "rdbUpdateRoomLastMessage", "datastore")
+	defer tracer.Finish(span)
+
+	var messages []*model.Message
+	query := fmt.Sprintf("SELECT * FROM %s WHERE room_id=:roomId AND deleted=0 ORDER BY created DESC, id DESC LIMIT 1;", tableNameMessage)
+	params := map[string]interface{}{"roomId": roomID}
+	_, err := tx.Select(&messages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	// Only the columns of the last message are updated not to overwrite the other changes of the room
+	room := &model.Room{}
+	if len(messages) == 0 {
+		room.SetLastMessage(nil)
+		query = fmt.Sprintf("UPDATE %s SET last_message=?, last_message_id=?, last_message_type=?, last_message_user_id=? WHERE room_id=?;", tableNameRoom)
+		_, err = tx.Exec(rebind(dbMap, query), room.LastMessage, room.LastMessageID, room.LastMessageType, room.LastMessageUserID, roomID)
+	} else {
+		room.SetLastMessage(messages[0])
+		query = fmt.Sprintf("UPDATE %s SET last_message=?, last_message_id=?, last_message_type=?, last_message_user_id=?, last_message_updated=? WHERE room_id=?;", tableNameRoom)
+		_, err = tx.Exec(rebind(dbMap, query), room.LastMessage, room.LastMessageID, room.LastMessageType, room.LastMessageUserID, room.LastMessageUpdatedTimestamp, roomID)
+	}
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbRestoreRoom(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, room *model.Room) (bool, error) {
+	span := tracer.StartSpan(ctx, "rdbRestoreRoom", "datastore")
+	defer tracer.Finish(span)
+
+	// The room can't be restored without the room users kept when it was deleted
+	query := fmt.Sprintf("SELECT count(*) FROM %s WHERE room_id=:roomId;", tableNameDeletedRoomUser)
+	params := map[string]interface{}{"roomId": room.RoomID}
+	count, err := tx.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+	if count == 0 {
+		return false, nil
+	}
+
+	// The users who have been deleted since the room was deleted are not restored
+	var deletedRoomUsers []*model.DeletedRoomUser
+	query = fmt.Sprintf(`SELECT dru.* FROM %s AS dru
+INNER JOIN %s AS u ON dru.user_id = u.user_id
+WHERE dru.room_id=:roomId AND u.deleted=0;`, tableNameDeletedRoomUser, tableNameUser)
+	_, err = tx.Select(&deletedRoomUsers, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	roomUsers := make([]*model.RoomUser, len(deletedRoomUsers))
+	for i, dru := range deletedRoomUsers {
+		roomUsers[i] = dru.RoomUser()
+	}
+	err = rdbInsertRoomUsers(ctx, dbMap, tx, roomUsers)
+	if err != nil {
+		return false, err
+	}
+
+	query = fmt.Sprintf("DELETE FROM %s WHERE room_id=?;", tableNameDeletedRoomUser)
+	_, err = tx.Exec(rebind(dbMap, query), room.RoomID)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	// The notification topic was deleted with the room
+	room.DeletedTimestamp = 0
+	room.NotificationTopicID = ""
+	room.SetTags()
+	_, err = tx.Update(room)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+func rdbPurgeDeletedRoomUsers(ctx context.Context, dbMap *gorp.DbMap, deletedTimestamp int64) error {
+	span := tracer.StartSpan(ctx, "rdbPurgeDeletedRoomUsers", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE deleted<?;", tableNameDeletedRoomUser)
+	_, err := dbMap.Exec(rebind(dbMap, query), deletedTimestamp)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while purging deleted room users")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
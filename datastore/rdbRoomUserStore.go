@@ -25,13 +25,6 @@ func rdbCreateRoomUserStore(ctx context.Context, dbMap *gorp.DbMap) {
 
 	tableMap := dbMap.AddTableWithName(model.RoomUser{}, tableNameRoomUser)
 	tableMap.SetUniqueTogether("room_id", "user_id")
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating room user table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, roomUsers []*model.RoomUser, opts ...InsertRoomUsersOption) error {
@@ -45,7 +38,7 @@ func rdbInsertRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 
 	if opt.beforeCleanRoomID != "" {
 		query := fmt.Sprintf("DELETE FROM %s WHERE room_id=:roomId;", tableNameRoomUser)
-		_, err := tx.Exec(query, opt.beforeCleanRoomID)
+		_, err := tx.Exec(rebind(dbMap, query), opt.beforeCleanRoomID)
 		if err != nil {
 			err := errors.Wrap(err, "An error occurred while recreating roomUser")
 			logger.Error(err.Error())
@@ -65,6 +58,10 @@ func rdbInsertRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 			}
 		}
 
+		if ru.RoomRole == "" {
+			ru.RoomRole = model.RoomRoleMember
+		}
+
 		err := tx.Insert(ru)
 		if err != nil {
 			err := errors.Wrap(err, "An error occurred while recreating roomUser")
@@ -74,6 +71,13 @@ func rdbInsertRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 		}
 	}
 
+	if len(opt.outboxEvents) > 0 {
+		err := rdbInsertOutboxEvents(ctx, tx, opt.outboxEvents)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
@@ -108,7 +112,7 @@ func rdbSelectRoomUsers(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectRo
 	}
 
 	var roomUsers []*model.RoomUser
-	query := fmt.Sprintf("SELECT ru.room_id, ru.user_id, ru.unread_count, ru.display FROM %s as ru", tableNameRoomUser)
+	query := fmt.Sprintf("SELECT ru.room_id, ru.user_id, ru.unread_count, ru.mention_count, ru.display, ru.room_role FROM %s as ru", tableNameRoomUser)
 
 	if opt.roles != nil {
 		rolesQuery, params := makePrepareExpressionParamsForInOperand(opt.roles)
@@ -319,13 +323,26 @@ r.type,
 r.last_message,
 r.last_message_updated,
 r.can_left,
+r.archived,
 r.created,
 r.modified,
-ru.unread_count AS ru_unread_count
+ru.unread_count AS ru_unread_count,
+ru.mention_count AS ru_mention_count,
+ru.favorite AS ru_favorite,
+ru.hidden AS ru_hidden,
+ru.muted AS ru_muted,
+ru.archived AS ru_archived,
+ru.sort_key AS ru_sort_key,
+ru.last_read AS ru_last_read,
+r.last_message_id,
+r.last_message_type,
+r.last_message_user_id,
+COALESCE(lmu.name, '') AS last_message_user_name
 FROM %s AS ru
 LEFT JOIN %s AS r ON ru.room_id = r.room_id
 LEFT JOIN %s AS u ON ru.user_id = u.user_id
-WHERE ru.room_id=:roomId AND r.deleted=0 AND u.deleted=0 AND ru.user_id=:userId`, tableNameRoomUser, tableNameRoom, tableNameUser)
+LEFT JOIN %s AS lmu ON r.last_message_user_id = lmu.user_id
+WHERE ru.room_id=:roomId AND r.deleted=0 AND u.deleted=0 AND ru.user_id=:userId`, tableNameRoomUser, tableNameRoom, tableNameUser, tableNameUser)
 	params := map[string]interface{}{
 		"roomId": roomID,
 		"userId": userID,
@@ -357,7 +374,8 @@ u.can_block,
 u.last_accessed,
 u.created,
 u.modified,
-ru.display as ru_display
+ru.display as ru_display,
+ru.room_role as ru_room_role
 FROM %s AS ru
 LEFT JOIN %s AS u ON ru.user_id=u.user_id
 WHERE ru.room_id=:roomId
@@ -395,28 +413,41 @@ r.type,
 r.last_message,
 r.last_message_updated,
 r.can_left,
+r.archived,
 r.created,
 r.modified,
-ru.unread_count AS ru_unread_count
+ru.unread_count AS ru_unread_count,
+ru.mention_count AS ru_mention_count,
+ru.favorite AS ru_favorite,
+ru.hidden AS ru_hidden,
+ru.muted AS ru_muted,
+ru.archived AS ru_archived,
+ru.sort_key AS ru_sort_key,
+ru.last_read AS ru_last_read,
+r.last_message_id,
+r.last_message_type,
+r.last_message_user_id,
+COALESCE(lmu.name, '') AS last_message_user_name
 FROM %s AS ru
 LEFT JOIN %s AS r ON ru.room_id = r.room_id
 LEFT JOIN %s AS u ON ru.user_id = u.user_id
-WHERE ru.room_id IN (	
-	SELECT room_id FROM %s WHERE user_id=:userId
-) AND r.deleted=0 AND u.deleted=0 AND ru.user_id=:userId`, tableNameRoomUser, tableNameRoom, tableNameUser, tableNameRoomUser)
+LEFT JOIN %s AS lmu ON r.last_message_user_id = lmu.user_id
+WHERE ru.user_id=:userId AND r.deleted=0 AND u.deleted=0`, tableNameRoomUser, tableNameRoom, tableNameUser, tableNameUser)
 	params := map[string]interface{}{"userId": userID}
 
-	switch opt.filter {
-	case scpb.UserRoomsFilter_Online:
-		lastAccessedTimestamp := time.Now().Unix() - beforeLastAccessedTimestamp
-		query = fmt.Sprintf("%s AND u.last_accessed>%d", query, lastAccessedTimestamp)
-	case scpb.UserRoomsFilter_Unread:
-		query = fmt.Sprintf("%s AND ru.unread_count!=0", query)
-	}
+	query = fmt.Sprintf("%s%s", query, miniRoomsConditions(opt, params))
 
-	query = fmt.Sprintf("%s ORDER BY", query)
+	// The favorite rooms are listed first
+	query = fmt.Sprintf("%s ORDER BY ru.favorite DESC,", query)
 	if opt.orders == nil {
-		query = fmt.Sprintf("%s r.last_message_updated DESC", query)
+		switch opt.sort {
+		case model.UserRoomsSortSortKey:
+			query = fmt.Sprintf("%s ru.sort_key ASC, r.last_message_updated DESC", query)
+		case model.UserRoomsSortName:
+			query = fmt.Sprintf("%s r.name ASC", query)
+		default:
+			query = fmt.Sprintf("%s r.last_message_updated DESC", query)
+		}
 	} else {
 		i := 1
 		for _, orderInfo := range opt.orders {
@@ -462,7 +493,8 @@ u.can_block,
 u.last_accessed,
 u.created,
 u.modified,
-ru.display as ru_display
+ru.display as ru_display,
+ru.room_role as ru_room_role
 FROM %s AS ru
 LEFT JOIN %s AS u ON ru.user_id=u.user_id
 WHERE ru.room_id IN (%s)`, tableNameRoomUser, tableNameUser, roomIDsQuery)
@@ -500,18 +532,10 @@ func rdbSelectCountMiniRooms(ctx context.Context, dbMap *gorp.DbMap, userID stri
 count(ru.room_id) FROM %s as ru
 LEFT JOIN %s AS r ON ru.room_id = r.room_id
 LEFT JOIN %s AS u ON ru.user_id = u.user_id
-WHERE ru.room_id IN (	
-	SELECT room_id FROM %s WHERE user_id=:userId
-) AND r.deleted=0 AND u.deleted=0 AND ru.user_id=:userId`, tableNameRoomUser, tableNameRoom, tableNameUser, tableNameRoomUser)
+WHERE ru.user_id=:userId AND r.deleted=0 AND u.deleted=0`, tableNameRoomUser, tableNameRoom, tableNameUser)
 	params := map[string]interface{}{"userId": userID}
 
-	switch opt.filter {
-	case scpb.UserRoomsFilter_Online:
-		lastAccessedTimestamp := time.Now().Unix() - beforeLastAccessedTimestamp
-		query = fmt.Sprintf("%s AND u.last_accessed>%d", query, lastAccessedTimestamp)
-	case scpb.UserRoomsFilter_Unread:
-		query = fmt.Sprintf("%s AND ru.unread_count!=0", query)
-	}
+	query = fmt.Sprintf("%s%s", query, miniRoomsConditions(opt, params))
 	count, err := dbMap.SelectInt(query, params)
 	if err != nil {
 		err := errors.Wrap(err, "An error occurred while selecting mini rooms count")
@@ -522,12 +546,61 @@ WHERE ru.room_id IN (
 	return count, nil
 }
 
+// miniRoomsConditions returns the conditions of the mini rooms by the options, and sets their params
+func miniRoomsConditions(opt selectMiniRoomsOptions, params map[string]interface{}) string {
+	conditions := ""
+
+	if !opt.includeArchived {
+		conditions = fmt.Sprintf("%s AND r.archived=0 AND ru.archived=:archived", conditions)
+		params["archived"] = false
+	}
+
+	if !opt.includeHidden {
+		conditions = fmt.Sprintf("%s AND ru.hidden=:hidden", conditions)
+		params["hidden"] = false
+	}
+
+	if opt.favorite != nil {
+		conditions = fmt.Sprintf("%s AND ru.favorite=:favorite", conditions)
+		params["favorite"] = *opt.favorite
+	}
+
+	if opt.muted != nil {
+		conditions = fmt.Sprintf("%s AND ru.muted=:muted", conditions)
+		params["muted"] = *opt.muted
+	}
+
+	switch opt.filter {
+	case scpb.UserRoomsFilter_Online:
+		lastAccessedTimestamp := time.Now().Unix() - beforeLastAccessedTimestamp
+		conditions = fmt.Sprintf("%s AND u.last_accessed>%d", conditions, lastAccessedTimestamp)
+	case scpb.UserRoomsFilter_Unread:
+		conditions = fmt.Sprintf("%s AND ru.unread_count!=0", conditions)
+	}
+
+	return conditions
+}
+
 func rdbUpdateRoomUser(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, ru *model.RoomUser) error {
 	span := tracer.StartSpan(ctx, "rdbUpdateRoomUser", "datastore")
 	defer tracer.Finish(span)
 
-	query := fmt.Sprintf("UPDATE %s SET unread_count=?, display=? WHERE room_id=? AND user_id=?;", tableNameRoomUser)
-	_, err := tx.Exec(query, ru.UnreadCount, ru.Display, ru.RoomID, ru.UserID)
+	query := fmt.Sprintf("UPDATE %s SET unread_count=?, mention_count=?, display=?, room_role=?, favorite=?, hidden=?, muted=?, archived=?, sort_key=?, last_read=? WHERE room_id=? AND user_id=?;", tableNameRoomUser)
+	_, err := tx.Exec(
+		rebind(dbMap, query),
+		ru.UnreadCount,
+		ru.MentionCount,
+		ru.Display,
+		ru.RoomRole,
+		ru.Favorite,
+		ru.Hidden,
+		ru.Muted,
+		ru.Archived,
+		ru.SortKey,
+		ru.LastReadTimestamp,
+		ru.RoomID,
+		ru.UserID,
+	)
 	if err != nil {
 		err := errors.Wrap(err, "An error occurred while updating room user")
 		logger.Error(err.Error())
@@ -539,7 +612,7 @@ func rdbUpdateRoomUser(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transact
 	UPDATE %s SET unread_count=(
 		SELECT SUM(unread_count) FROM %s WHERE user_id=?
 	) WHERE user_id=?`, tableNameUser, tableNameRoomUser)
-	_, err = tx.Exec(query, ru.UserID, ru.UserID)
+	_, err = tx.Exec(rebind(dbMap, query), ru.UserID, ru.UserID)
 	if err != nil {
 		err := errors.Wrap(err, "An error occurred while updating room user")
 		logger.Error(err.Error())
@@ -574,7 +647,7 @@ func rdbDeleteRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 			params[i+j] = userIDsParams[j]
 		}
 		query := fmt.Sprintf("DELETE FROM %s WHERE room_id IN (%s) AND user_id IN (%s)", tableNameRoomUser, roomIDsQuery, userIDsQuery)
-		_, err := tx.Exec(query, params...)
+		_, err := tx.Exec(rebind(dbMap, query), params...)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting room users")
 			logger.Error(err.Error())
@@ -604,7 +677,7 @@ func rdbDeleteRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 	if len(opt.roomIDs) > 0 {
 		roomIDsQuery, roomIDsParams := makePrepareExpressionForInOperand(opt.roomIDs)
 		query := fmt.Sprintf("DELETE FROM %s WHERE room_id IN (%s)", tableNameRoomUser, roomIDsQuery)
-		_, err := tx.Exec(query, roomIDsParams...)
+		_, err := tx.Exec(rebind(dbMap, query), roomIDsParams...)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting room users")
 			logger.Error(err.Error())
@@ -631,7 +704,7 @@ func rdbDeleteRoomUsers(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 	if len(opt.userIDs) > 0 {
 		userIDsQuery, userIDsParams := makePrepareExpressionForInOperand(opt.userIDs)
 		query := fmt.Sprintf("DELETE FROM %s WHERE user_id IN (%s)", tableNameRoomUser, userIDsQuery)
-		_, err := tx.Exec(query, userIDsParams...)
+		_, err := tx.Exec(rebind(dbMap, query), userIDsParams...)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting room users")
 			logger.Error(err.Error())
@@ -0,0 +1,158 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateScheduledMessageStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateScheduledMessageStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.ScheduledMessage{}, tableNameScheduledMessage)
+	tableMap.SetKeys(true, "id")
+	for _, columnMap := range tableMap.Columns {
+		if columnMap.ColumnName == "scheduled_message_id" {
+			columnMap.SetUnique(true)
+		}
+	}
+}
+
+func rdbInsertScheduledMessage(ctx context.Context, dbMap *gorp.DbMap, scheduledMessage *model.ScheduledMessage) error {
+	span := tracer.StartSpan(ctx, "rdbInsertScheduledMessage", "datastore")
+	defer tracer.Finish(span)
+
+	err := dbMap.Insert(scheduledMessage)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting scheduled message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbSelectScheduledMessages(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32, opts ...SelectScheduledMessagesOption) ([]*model.ScheduledMessage, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectScheduledMessages", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectScheduledMessagesOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	var scheduledMessages []*model.ScheduledMessage
+	query := fmt.Sprintf("SELECT * FROM %s WHERE sent=0 AND deleted=0", tableNameScheduledMessage)
+	params := make(map[string]interface{})
+
+	if opt.roomID != "" {
+		params["roomId"] = opt.roomID
+		query = fmt.Sprintf("%s AND room_id=:roomId", query)
+	}
+
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id=:userId", query)
+	}
+
+	if opt.dueTimestamp != 0 {
+		params["dueTimestamp"] = opt.dueTimestamp
+		query = fmt.Sprintf("%s AND scheduled<=:dueTimestamp", query)
+	}
+
+	query = fmt.Sprintf("%s ORDER BY scheduled ASC, id ASC LIMIT :limit OFFSET :offset", query)
+	params["limit"] = limit
+	params["offset"] = offset
+
+	_, err := dbMap.Select(&scheduledMessages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting scheduled messages")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return scheduledMessages, nil
+}
+
+func rdbSelectCountScheduledMessages(ctx context.Context, dbMap *gorp.DbMap, opts ...SelectScheduledMessagesOption) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountScheduledMessages", "datastore")
+	defer tracer.Finish(span)
+
+	opt := selectScheduledMessagesOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s WHERE sent=0 AND deleted=0", tableNameScheduledMessage)
+	params := make(map[string]interface{})
+
+	if opt.roomID != "" {
+		params["roomId"] = opt.roomID
+		query = fmt.Sprintf("%s AND room_id=:roomId", query)
+	}
+
+	if opt.userID != "" {
+		params["userId"] = opt.userID
+		query = fmt.Sprintf("%s AND user_id=:userId", query)
+	}
+
+	if opt.dueTimestamp != 0 {
+		params["dueTimestamp"] = opt.dueTimestamp
+		query = fmt.Sprintf("%s AND scheduled<=:dueTimestamp", query)
+	}
+
+	count, err := dbMap.SelectInt(query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting scheduled message count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func rdbSelectScheduledMessage(ctx context.Context, dbMap *gorp.DbMap, scheduledMessageID string) (*model.ScheduledMessage, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectScheduledMessage", "datastore")
+	defer tracer.Finish(span)
+
+	var scheduledMessages []*model.ScheduledMessage
+	query := fmt.Sprintf("SELECT * FROM %s WHERE scheduled_message_id=:scheduledMessageId AND deleted=0;", tableNameScheduledMessage)
+	params := map[string]interface{}{"scheduledMessageId": scheduledMessageID}
+	_, err := dbMap.Select(&scheduledMessages, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting scheduled message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(scheduledMessages) == 1 {
+		return scheduledMessages[0], nil
+	}
+
+	return nil, nil
+}
+
+func rdbUpdateScheduledMessage(ctx context.Context, dbMap *gorp.DbMap, scheduledMessage *model.ScheduledMessage) error {
+	span := tracer.StartSpan(ctx, "rdbUpdateScheduledMessage", "datastore")
+	defer tracer.Finish(span)
+
+	_, err := dbMap.Update(scheduledMessage)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating scheduled message")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
@@ -21,12 +21,6 @@ func rdbCreateSettingStore(ctx context.Context, dbMap *gorp.DbMap) {
 
 	tableMap := dbMap.AddTableWithName(model.Setting{}, tableNameSetting)
 	tableMap.SetKeys(true, "id")
-	if err := dbMap.CreateTablesIfNotExists(); err != nil {
-		err = errors.Wrap(err, "An error occurred while creating setting table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbSelectLatestSetting(ctx context.Context, dbMap *gorp.DbMap) (*model.Setting, error) {
@@ -49,3 +43,34 @@ func rdbSelectLatestSetting(ctx context.Context, dbMap *gorp.DbMap) (*model.Sett
 
 	return nil, nil
 }
+
+// rdbInsertFirstSetting inserts the empty setting unless the setting exists
+func rdbInsertFirstSetting(ctx context.Context, dbMap *gorp.DbMap) error {
+	span := tracer.StartSpan(ctx, "rdbInsertFirstSetting", "datastore")
+	defer tracer.Finish(span)
+
+	setting, err := rdbSelectLatestSetting(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+
+	if setting != nil {
+		return nil
+	}
+
+	nowTimestamp := time.Now().Unix()
+	setting = &model.Setting{
+		Values:   []byte("{}"),
+		Created:  nowTimestamp,
+		Modified: nowTimestamp,
+	}
+	err = dbMap.Insert(setting)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting setting")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
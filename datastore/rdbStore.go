@@ -1,42 +1,123 @@
 package datastore
 
 import (
+	"container/list"
+	"context"
 	"database/sql"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	logger "github.com/betchi/zapper"
 	"github.com/swagchat/chat-api/config"
 	gorp "gopkg.in/gorp.v2"
 )
 
+// migratedDatabases holds the databases migrated by this process not to migrate them again when they are reconnected
+var migratedDatabases sync.Map
+
 var (
-	rdbStores             = make(map[string]*rdbStore)
-	tableNameAppClient    = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "app_client")
-	tableNameAsset        = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "asset")
-	tableNameBlockUser    = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "block_user")
-	tableNameBot          = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "bot")
-	tableNameDevice       = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "device")
-	tableNameMessage      = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "message")
-	tableNameRoom         = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room")
-	tableNameRoomUser     = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room_user")
-	tableNameSetting      = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "setting")
-	tableNameSubscription = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "subscription")
-	tableNameUser         = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "user")
-	tableNameUserRole     = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "user_role")
-	tableNameWebhook      = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "webhook")
+	rdbStores                 = newRdbStorePool()
+	tableNameAppClient        = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "app_client")
+	tableNameAuditLog         = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "audit_log")
+	tableNameAsset            = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "asset")
+	tableNameBlockUser        = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "block_user")
+	tableNameBot              = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "bot")
+	tableNameDeletedRoomUser  = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "deleted_room_user")
+	tableNameDevice           = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "device")
+	tableNameMention          = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "mention")
+	tableNameMessage          = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "message")
+	tableNameOutboxEvent      = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "outbox_event")
+	tableNamePinnedMessage    = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "pinned_message")
+	tableNameRoom             = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room")
+	tableNameRoomInvitation   = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room_invitation")
+	tableNameRoomInviteLink   = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room_invite_link")
+	tableNameRoomUser         = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "room_user")
+	tableNameScheduledMessage = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "scheduled_message")
+	tableNameSchemaMigration  = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "schema_migration")
+	tableNameSetting          = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "setting")
+	tableNameSubscription     = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "subscription")
+	tableNameUser             = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "user")
+	tableNameUserRole         = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "user_role")
+	tableNameWebhook          = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "webhook")
+	tableNameWorkspace        = fmt.Sprintf("%s%s", config.Config().Datastore.TableNamePrefix, "workspace")
 )
 
 type rdbStore struct {
 	masterDbMap    *gorp.DbMap
-	replicaDbMaps  []*gorp.DbMap
+	replicas       []*rdbReplica
 	replicaCounter int64
+	// lastWrites holds the time of the last write of each requester for read-your-writes
+	lastWrites sync.Map
+	// prepared is whether the tables have been mapped to the connection in dynamic mode
+	prepared  bool
+	prepareMu sync.Mutex
+	// refs is the number of the requests holding the connection, which is not evicted while it's held
+	refs int64
 }
 
 func RdbStore(db string) *rdbStore {
-	if rs, ok := rdbStores[db]; ok {
-		return rs
+	return rdbStores.get(db)
+}
+
+// Hold keeps the connection of the database of the workspace in the context from being evicted
+// until the returned function is called. The requests hold it while they are processed.
+func Hold(ctx context.Context) func() {
+	_, dsCfg := newProvider(ctx)
+	rs := rdbStores.hold(dsCfg.Database)
+	if rs == nil {
+		return func() {}
+	}
+
+	return func() {
+		atomic.AddInt64(&rs.refs, -1)
+	}
+}
+
+// RunIdleConnectionEvictor evicts the databases which have not been used for the idle timeout,
+// and closes the connections of the evicted databases after the grace period
+func RunIdleConnectionEvictor(ctx context.Context) {
+	idleTimeout := time.Duration(config.Config().Datastore.ConnectionIdleTimeout) * time.Second
+
+	ticker := time.NewTicker(config.EvictIdleConnectionsIntervalSecond * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if idleTimeout > 0 {
+				rdbStores.removeIdle(idleTimeout)
+			}
+			rdbStores.closeEvicted(time.Now())
+		}
+	}
+}
+
+// prepare maps the tables to the connection once, and migrates the database unless this process has migrated it.
+// The concurrent requests wait for the migration, and the next request retries it if it fails.
+// The other processes are kept from migrating it at the same time by the lock of the migrations in CreateTables.
+func (rs *rdbStore) prepare(database string, p provider) error {
+	rs.prepareMu.Lock()
+	defer rs.prepareMu.Unlock()
+
+	if rs.prepared {
+		return nil
+	}
+
+	if _, ok := migratedDatabases.Load(database); ok {
+		p.mapTables()
+	} else {
+		err := p.CreateTables()
+		if err != nil {
+			return err
+		}
+		migratedDatabases.Store(database, struct{}{})
 	}
+
+	rs.prepared = true
 	return nil
 }
 
@@ -48,16 +129,274 @@ func (rs *rdbStore) setMaster(m *gorp.DbMap) {
 	rs.masterDbMap = m
 }
 
+// replica returns one of the healthy replicas in round robin. It returns master if no replica is healthy.
 func (rs *rdbStore) replica() *gorp.DbMap {
-	if rs.replicaDbMaps == nil {
+	if len(rs.replicas) == 0 {
 		return rs.masterDbMap
 	}
-	replicaCounter := atomic.AddInt64(&rs.replicaCounter, 1) % int64(len(rs.replicaDbMaps))
-	return rs.replicaDbMaps[replicaCounter]
+	start := atomic.AddInt64(&rs.replicaCounter, 1)
+	for i := int64(0); i < int64(len(rs.replicas)); i++ {
+		replica := rs.replicas[(start+i)%int64(len(rs.replicas))]
+		if replica.isHealthy() {
+			return replica.dbMap
+		}
+	}
+	return rs.masterDbMap
 }
 
 func (rs *rdbStore) setReplica(r *gorp.DbMap) {
-	rs.replicaDbMaps = append(rs.replicaDbMaps, r)
+	rs.replicas = append(rs.replicas, newRdbReplica(r))
+}
+
+// masterFor returns master to write with the context, and records the write for read-your-writes
+func (rs *rdbStore) masterFor(ctx context.Context) *gorp.DbMap {
+	if s, ok := ctx.Value(config.CtxStickiness).(*stickiness); ok {
+		atomic.StoreInt32(&s.written, 1)
+		if s.requester != "" && len(rs.replicas) > 0 {
+			rs.lastWrites.Store(s.requester, time.Now())
+		}
+	}
+	return rs.masterDbMap
+}
+
+// replicaFor returns the connection to read with the context.
+// It returns master if the context or its requester has written recently, so that the writes are read.
+func (rs *rdbStore) replicaFor(ctx context.Context) *gorp.DbMap {
+	if len(rs.replicas) == 0 {
+		return rs.masterDbMap
+	}
+
+	if s, ok := ctx.Value(config.CtxStickiness).(*stickiness); ok {
+		if atomic.LoadInt32(&s.written) == 1 {
+			return rs.masterDbMap
+		}
+		if s.requester != "" {
+			if v, ok := rs.lastWrites.Load(s.requester); ok && time.Since(v.(time.Time)) < stickToMasterDuration() {
+				return rs.masterDbMap
+			}
+		}
+	}
+
+	return rs.replica()
+}
+
+func (rs *rdbStore) close(database string) {
+	if rs.masterDbMap != nil {
+		close(database, rs.masterDbMap.Db)
+	}
+	for _, replica := range rs.replicas {
+		close(database, replica.dbMap.Db)
+	}
+}
+
+type rdbStorePoolEntry struct {
+	database     string
+	rs           *rdbStore
+	lastAccessed time.Time
+	// evicted is the time the database was evicted at
+	evicted time.Time
+}
+
+// rdbStorePool holds the connected databases in LRU order.
+// The least recently used database is evicted when the pool exceeds the connection pool size, except the ones held by the requests.
+// The connections of the evicted databases are closed after the grace period so that the running queries are not broken.
+type rdbStorePool struct {
+	mu      sync.Mutex
+	entries *list.List
+	index   map[string]*list.Element
+	evicted []*rdbStorePoolEntry
+}
+
+func newRdbStorePool() *rdbStorePool {
+	return &rdbStorePool{
+		entries: list.New(),
+		index:   make(map[string]*list.Element),
+	}
+}
+
+func (pool *rdbStorePool) get(database string) *rdbStore {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	e, ok := pool.index[database]
+	if !ok {
+		return nil
+	}
+	pool.entries.MoveToFront(e)
+	entry := e.Value.(*rdbStorePoolEntry)
+	entry.lastAccessed = time.Now()
+	return entry.rs
+}
+
+// hold gets the database and increments the references of it in the lock not to be evicted at the same time
+func (pool *rdbStorePool) hold(database string) *rdbStore {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	e, ok := pool.index[database]
+	if !ok {
+		return nil
+	}
+	pool.entries.MoveToFront(e)
+	entry := e.Value.(*rdbStorePoolEntry)
+	entry.lastAccessed = time.Now()
+	atomic.AddInt64(&entry.rs.refs, 1)
+	return entry.rs
+}
+
+// add adds the database to the pool. If the database has been added by another goroutine,
+// rs is closed and the added one is returned.
+func (pool *rdbStorePool) add(database string, rs *rdbStore) *rdbStore {
+	pool.mu.Lock()
+	if e, ok := pool.index[database]; ok {
+		pool.entries.MoveToFront(e)
+		pool.mu.Unlock()
+		rs.close(database)
+		return e.Value.(*rdbStorePoolEntry).rs
+	}
+
+	pool.index[database] = pool.entries.PushFront(&rdbStorePoolEntry{
+		database:     database,
+		rs:           rs,
+		lastAccessed: time.Now(),
+	})
+
+	size := config.Config().Datastore.ConnectionPoolSize
+	if size > 0 && evictable() {
+		now := time.Now()
+		for e := pool.entries.Back(); e != nil && e != pool.entries.Front() && pool.entries.Len() > size; {
+			prev := e.Prev()
+			if atomic.LoadInt64(&e.Value.(*rdbStorePoolEntry).rs.refs) == 0 {
+				pool.evict(e, now)
+			}
+			e = prev
+		}
+	}
+	pool.mu.Unlock()
+
+	pool.closeEvicted(time.Now())
+	return rs
+}
+
+// remove closes the connections of the database at once because the database is dropped
+func (pool *rdbStorePool) remove(database string) {
+	pool.mu.Lock()
+	var removed []*rdbStorePoolEntry
+	if e, ok := pool.index[database]; ok {
+		removed = append(removed, pool.removeElement(e))
+	}
+	evicted := pool.evicted[:0]
+	for _, entry := range pool.evicted {
+		if entry.database == database {
+			removed = append(removed, entry)
+		} else {
+			evicted = append(evicted, entry)
+		}
+	}
+	pool.evicted = evicted
+	pool.mu.Unlock()
+
+	for _, entry := range removed {
+		entry.rs.close(entry.database)
+	}
+}
+
+func (pool *rdbStorePool) removeAll() {
+	pool.mu.Lock()
+	removed := pool.evicted
+	pool.evicted = nil
+	for pool.entries.Len() > 0 {
+		removed = append(removed, pool.removeElement(pool.entries.Back()))
+	}
+	pool.mu.Unlock()
+
+	for _, entry := range removed {
+		entry.rs.close(entry.database)
+	}
+}
+
+func (pool *rdbStorePool) removeIdle(idleTimeout time.Duration) {
+	if !evictable() {
+		return
+	}
+
+	pool.mu.Lock()
+	now := time.Now()
+	deadline := now.Add(-idleTimeout)
+	for e := pool.entries.Back(); e != nil; {
+		entry := e.Value.(*rdbStorePoolEntry)
+		if entry.lastAccessed.After(deadline) {
+			break
+		}
+		prev := e.Prev()
+		if atomic.LoadInt64(&entry.rs.refs) == 0 {
+			pool.evict(e, now)
+		}
+		e = prev
+	}
+	pool.mu.Unlock()
+}
+
+// closeEvicted closes the connections of the databases evicted before the grace period
+func (pool *rdbStorePool) closeEvicted(now time.Time) {
+	deadline := now.Add(-config.CloseEvictedConnectionsDelaySecond * time.Second)
+
+	pool.mu.Lock()
+	var closed []*rdbStorePoolEntry
+	evicted := pool.evicted[:0]
+	for _, entry := range pool.evicted {
+		if entry.evicted.After(deadline) {
+			evicted = append(evicted, entry)
+		} else {
+			closed = append(closed, entry)
+		}
+	}
+	pool.evicted = evicted
+	pool.mu.Unlock()
+
+	// The connections are closed out of the lock because closing waits for the running queries
+	for _, entry := range closed {
+		entry.rs.close(entry.database)
+	}
+}
+
+func (pool *rdbStorePool) evict(e *list.Element, now time.Time) {
+	entry := pool.removeElement(e)
+	entry.evicted = now
+	pool.evicted = append(pool.evicted, entry)
+}
+
+// snapshot returns the connected databases
+func (pool *rdbStorePool) snapshot() map[string]*rdbStore {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	stores := make(map[string]*rdbStore, len(pool.index))
+	for database, e := range pool.index {
+		stores[database] = e.Value.(*rdbStorePoolEntry).rs
+	}
+	return stores
+}
+
+func (pool *rdbStorePool) removeElement(e *list.Element) *rdbStorePoolEntry {
+	entry := pool.entries.Remove(e).(*rdbStorePoolEntry)
+	delete(pool.index, entry.database)
+	return entry
+}
+
+// evictable returns false for on memory SQLite because closing the connections discards the database
+func evictable() bool {
+	dsCfg := config.Config().Datastore
+	return !(dsCfg.Provider == "sqlite" && dsCfg.SQLite.OnMemory)
+}
+
+// rdbInsertInitialData inserts the data which every database needs after the schema migrations
+func rdbInsertInitialData(ctx context.Context, dbMap *gorp.DbMap) error {
+	err := rdbInsertFirstAppClient(ctx, dbMap)
+	if err != nil {
+		return err
+	}
+	return rdbInsertFirstSetting(ctx, dbMap)
 }
 
 func close(database string, db *sql.DB) {
@@ -18,13 +18,6 @@ func rdbCreateSubscriptionStore(ctx context.Context, dbMap *gorp.DbMap) {
 	defer tracer.Finish(span)
 
 	_ = dbMap.AddTableWithName(model.Subscription{}, tableNameSubscription)
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating subscription table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertSubscription(ctx context.Context, dbMap *gorp.DbMap, subscription *model.Subscription) (*model.Subscription, error) {
@@ -125,7 +118,7 @@ func rdbSelectDeletedSubscriptions(ctx context.Context, dbMap *gorp.DbMap, opts
 		}
 	}
 
-	return nil, nil
+	return subscriptions, nil
 }
 
 func rdbDeleteSubscriptions(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, opts ...DeleteSubscriptionsOption) error {
@@ -160,7 +153,7 @@ func rdbDeleteSubscriptions(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Tra
 
 	if opt.roomID != "" {
 		query = fmt.Sprintf("%s room_id=?", query)
-		_, err := tx.Exec(query, opt.roomID)
+		_, err := tx.Exec(rebind(dbMap, query), opt.roomID)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while deleting subscriptions")
 			logger.Error(err.Error())
@@ -172,7 +165,7 @@ func rdbDeleteSubscriptions(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Tra
 
 	if opt.userID != "" && opt.platform == scpb.Platform_PlatformNone {
 		query = fmt.Sprintf("%s user_id=?", query)
-		_, err := tx.Exec(query, opt.userID)
+		_, err := tx.Exec(rebind(dbMap, query), opt.userID)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while getting deleted subscriptions")
 			logger.Error(err.Error())
@@ -184,7 +177,7 @@ func rdbDeleteSubscriptions(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Tra
 
 	if opt.userID != "" && opt.platform != scpb.Platform_PlatformNone {
 		query = fmt.Sprintf("%s user_id=? AND platform=?", query)
-		_, err := tx.Exec(query, opt.userID, opt.platform)
+		_, err := tx.Exec(rebind(dbMap, query), opt.userID, opt.platform)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while getting deleted subscriptions")
 			logger.Error(err.Error())
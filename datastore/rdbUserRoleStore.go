@@ -17,13 +17,6 @@ func rdbCreateUserRoleStore(ctx context.Context, dbMap *gorp.DbMap) {
 
 	tableMap := dbMap.AddTableWithName(model.UserRole{}, tableNameUserRole)
 	tableMap.SetUniqueTogether("user_id", "role")
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating user role table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertUserRoles(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, urs []*model.UserRole, opts ...InsertUserRolesOption) error {
@@ -177,7 +170,7 @@ func rdbDeleteUserRoles(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transac
 	}
 	query = query[0 : len(query)-len(" AND")]
 
-	_, err := tx.Exec(query, params...)
+	_, err := tx.Exec(rebind(dbMap, query), params...)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting user roles")
 		logger.Error(err.Error())
@@ -3,6 +3,7 @@ package datastore
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"gopkg.in/gorp.v2"
 
@@ -23,13 +24,6 @@ func rdbCreateUserStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
-	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating user table")
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return
-	}
 }
 
 func rdbInsertUser(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, user *model.User, opts ...InsertUserOption) error {
@@ -213,8 +207,8 @@ func rdbUpdateUser(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction,
 	}
 
 	if opt.markAllAsRead {
-		query := fmt.Sprintf("UPDATE %s SET unread_count=0 WHERE user_id=?;", tableNameRoomUser)
-		_, err := tx.Exec(query, user.UserID)
+		query := fmt.Sprintf("UPDATE %s SET unread_count=0, mention_count=0, last_read=? WHERE user_id=?;", tableNameRoomUser)
+		_, err := tx.Exec(rebind(dbMap, query), time.Now().Unix(), user.UserID)
 		if err != nil {
 			err = errors.Wrap(err, "An error occurred while updating user")
 			logger.Error(err.Error())
@@ -304,7 +298,7 @@ func rdbUpdateUserDeleted(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Trans
 	}
 
 	query := fmt.Sprintf("UPDATE %s SET deleted=? WHERE user_id=?;", tableNameUser)
-	_, err = tx.Exec(query, user.DeletedTimestamp, user.UserID)
+	_, err = tx.Exec(rebind(dbMap, query), user.DeletedTimestamp, user.UserID)
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while deleting user")
 		logger.Error(err.Error())
@@ -315,6 +309,167 @@ func rdbUpdateUserDeleted(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Trans
 	return nil
 }
 
+func rdbEraseUser(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, user *model.User, opts ...EraseUserOption) error {
+	span := tracer.StartSpan(ctx, "rdbEraseUser", "datastore")
+	defer tracer.Finish(span)
+
+	opt := eraseUserOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
+	err := rdbDeleteBlockUsers(ctx, dbMap, tx, DeleteBlockUsersOptionFilterByUserIDs([]string{user.UserID}))
+	if err != nil {
+		return err
+	}
+
+	err = rdbDeleteBlockUsers(ctx, dbMap, tx, DeleteBlockUsersOptionFilterByBlockUserIDs([]string{user.UserID}))
+	if err != nil {
+		return err
+	}
+
+	err = rdbDeleteSubscriptions(
+		ctx,
+		dbMap,
+		tx,
+		DeleteSubscriptionsOptionWithLogicalDeleted(user.DeletedTimestamp),
+		DeleteSubscriptionsOptionFilterByUserID(user.UserID),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = rdbDeleteRoomUsers(
+		ctx,
+		dbMap,
+		tx,
+		DeleteRoomUsersOptionFilterByUserIDs([]string{user.UserID}),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = rdbDeleteUserRoles(ctx, dbMap, tx, DeleteUserRolesOptionFilterByUserIDs([]string{user.UserID}))
+	if err != nil {
+		return err
+	}
+
+	var roomIDs []string
+	var queries []string
+	if opt.deleteMessages {
+		roomIDs, err = rdbUncountErasedMessages(ctx, dbMap, tx, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		queries = append(queries,
+			fmt.Sprintf("DELETE FROM %s WHERE message_id IN (SELECT message_id FROM %s WHERE user_id=?);", tableNameMention, tableNameMessage),
+			fmt.Sprintf("DELETE FROM %s WHERE message_id IN (SELECT message_id FROM %s WHERE user_id=?);", tableNamePinnedMessage, tableNameMessage),
+			fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameMessage),
+		)
+	} else {
+		queries = append(queries,
+			fmt.Sprintf("UPDATE %s SET user_id='%s', payload='%s' WHERE user_id=?;", tableNameMessage, model.ErasedUserID, model.ErasedMessagePayload),
+			fmt.Sprintf("UPDATE %s SET last_message='', last_message_user_id='%s' WHERE last_message_user_id=?;", tableNameRoom, model.ErasedUserID),
+		)
+	}
+	queries = append(queries,
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameMention),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameScheduledMessage),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameRoomInvitation),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameDeletedRoomUser),
+		fmt.Sprintf("UPDATE %s SET user_id='%s' WHERE user_id=?;", tableNameRoomInviteLink, model.ErasedUserID),
+		fmt.Sprintf("UPDATE %s SET user_id='%s' WHERE user_id=?;", tableNamePinnedMessage, model.ErasedUserID),
+		fmt.Sprintf("UPDATE %s SET user_id='%s' WHERE user_id=?;", tableNameRoom, model.ErasedUserID),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameDevice),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameAsset),
+		fmt.Sprintf("DELETE FROM %s WHERE user_id=?;", tableNameUser),
+	)
+
+	for _, query := range queries {
+		_, err = tx.Exec(rebind(dbMap, query), user.UserID)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while erasing user")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return err
+		}
+	}
+
+	for _, roomID := range roomIDs {
+		err = rdbUpdateRoomLastMessage(ctx, dbMap, tx, roomID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rdbUncountErasedMessages counts down the unread count and the mention count of the other room users
+// by the messages of the erased user which they haven't read, and returns the rooms of the messages.
+// It is called before the messages are deleted.
+func rdbUncountErasedMessages(ctx context.Context, dbMap *gorp.DbMap, tx *gorp.Transaction, userID string) ([]string, error) {
+	span := tracer.StartSpan(ctx, "rdbUncountErasedMessages", "datastore")
+	defer tracer.Finish(span)
+
+	var roomIDs []string
+	query := fmt.Sprintf("SELECT DISTINCT room_id FROM %s WHERE user_id=:userId;", tableNameMessage)
+	params := map[string]interface{}{"userId": userID}
+	_, err := tx.Select(&roomIDs, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+	if len(roomIDs) == 0 {
+		return roomIDs, nil
+	}
+
+	unreadQuery := fmt.Sprintf("SELECT COUNT(*) FROM %s AS m WHERE m.room_id=%s.room_id AND m.user_id=? AND m.created>%s.last_read", tableNameMessage, tableNameRoomUser, tableNameRoomUser)
+	mentionQuery := fmt.Sprintf(
+		"SELECT COUNT(*) FROM %s AS mn WHERE mn.room_id=%s.room_id AND mn.user_id=%s.user_id AND mn.created>%s.last_read AND mn.message_id IN (SELECT message_id FROM %s WHERE user_id=?)",
+		tableNameMention, tableNameRoomUser, tableNameRoomUser, tableNameRoomUser, tableNameMessage,
+	)
+	roomIDsQuery, roomIDsParams := makePrepareExpressionForInOperand(roomIDs)
+	query = fmt.Sprintf(
+		"UPDATE %s SET unread_count=CASE WHEN unread_count>(%s) THEN unread_count-(%s) ELSE 0 END, mention_count=CASE WHEN mention_count>(%s) THEN mention_count-(%s) ELSE 0 END WHERE user_id!=? AND room_id IN (%s);",
+		tableNameRoomUser, unreadQuery, unreadQuery, mentionQuery, mentionQuery, roomIDsQuery,
+	)
+	args := append([]interface{}{userID, userID, userID, userID, userID}, roomIDsParams...)
+	_, err = tx.Exec(rebind(dbMap, query), args...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	// The unread count of the users is the sum of their room users
+	var memberIDs []string
+	query = fmt.Sprintf("SELECT DISTINCT user_id FROM %s WHERE user_id!=? AND room_id IN (%s);", tableNameRoomUser, roomIDsQuery)
+	_, err = tx.Select(&memberIDs, rebind(dbMap, query), append([]interface{}{userID}, roomIDsParams...)...)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while erasing user")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+	query = fmt.Sprintf("UPDATE %s SET unread_count=(SELECT SUM(unread_count) FROM %s WHERE user_id=?) WHERE user_id=?;", tableNameUser, tableNameRoomUser)
+	for _, memberID := range memberIDs {
+		_, err = tx.Exec(rebind(dbMap, query), memberID, memberID)
+		if err != nil {
+			err = errors.Wrap(err, "An error occurred while erasing user")
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			return nil, err
+		}
+	}
+
+	return roomIDs, nil
+}
+
 func rdbSelectContacts(ctx context.Context, dbMap *gorp.DbMap, userID string, limit, offset int32, opts ...SelectContactsOption) ([]*model.User, error) {
 	span := tracer.StartSpan(ctx, "rdbSelectContacts", "datastore")
 	defer tracer.Finish(span)
@@ -352,8 +507,7 @@ WHERE
 		) AND
 		u.public_profile_scope=:publicProfileScope AND
 		u.deleted=0
-	)
-GROUP BY u.user_id`, tableNameUser, tableNameRoomUser, tableNameRoomUser, tableNameRoom)
+	)`, tableNameUser, tableNameRoomUser, tableNameRoomUser, tableNameRoom)
 	params := make(map[string]interface{})
 	params["publicProfileScope"] = scpb.PublicProfileScope_All
 	params["type"] = scpb.RoomType_NoticeRoom
@@ -22,13 +22,21 @@ func rdbCreateWebhookStore(ctx context.Context, dbMap *gorp.DbMap) {
 			columnMap.SetUnique(true)
 		}
 	}
-	err := dbMap.CreateTablesIfNotExists()
+}
+
+func rdbInsertWebhook(ctx context.Context, dbMap *gorp.DbMap, webhook *model.Webhook) error {
+	span := tracer.StartSpan(ctx, "rdbInsertWebhook", "datastore")
+	defer tracer.Finish(span)
+
+	err := dbMap.Insert(webhook)
 	if err != nil {
-		err = errors.Wrap(err, "An error occurred while creating webhook table")
+		err = errors.Wrap(err, "An error occurred while inserting webhook")
 		logger.Error(err.Error())
 		tracer.SetError(span, err)
-		return
+		return err
 	}
+
+	return nil
 }
 
 func rdbSelectWebhooks(ctx context.Context, dbMap *gorp.DbMap, event model.WebhookEventType, opts ...SelectWebhooksOption) ([]*model.Webhook, error) {
@@ -0,0 +1,115 @@
+package datastore
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/model"
+	gorp "gopkg.in/gorp.v2"
+)
+
+func rdbCreateWorkspaceStore(ctx context.Context, dbMap *gorp.DbMap) {
+	span := tracer.StartSpan(ctx, "rdbCreateWorkspaceStore", "datastore")
+	defer tracer.Finish(span)
+
+	tableMap := dbMap.AddTableWithName(model.Workspace{}, tableNameWorkspace)
+	tableMap.SetKeys(true, "id")
+	for _, columnMap := range tableMap.Columns {
+		if columnMap.ColumnName == "name" {
+			columnMap.SetUnique(true)
+		}
+	}
+}
+
+func rdbInsertWorkspace(ctx context.Context, dbMap *gorp.DbMap, workspace *model.Workspace) error {
+	span := tracer.StartSpan(ctx, "rdbInsertWorkspace", "datastore")
+	defer tracer.Finish(span)
+
+	err := dbMap.Insert(workspace)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while inserting workspace")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
+func rdbSelectWorkspaces(ctx context.Context, dbMap *gorp.DbMap, limit, offset int32) ([]*model.Workspace, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectWorkspaces", "datastore")
+	defer tracer.Finish(span)
+
+	var workspaces []*model.Workspace
+	query := fmt.Sprintf("SELECT * FROM %s ORDER BY name ASC LIMIT :limit OFFSET :offset", tableNameWorkspace)
+	params := map[string]interface{}{
+		"limit":  limit,
+		"offset": offset,
+	}
+	_, err := dbMap.Select(&workspaces, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting workspaces")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	return workspaces, nil
+}
+
+func rdbSelectCountWorkspaces(ctx context.Context, dbMap *gorp.DbMap) (int64, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectCountWorkspaces", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("SELECT count(id) FROM %s", tableNameWorkspace)
+	count, err := dbMap.SelectInt(query)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting workspace count")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func rdbSelectWorkspace(ctx context.Context, dbMap *gorp.DbMap, name string) (*model.Workspace, error) {
+	span := tracer.StartSpan(ctx, "rdbSelectWorkspace", "datastore")
+	defer tracer.Finish(span)
+
+	var workspaces []*model.Workspace
+	query := fmt.Sprintf("SELECT * FROM %s WHERE name=:name;", tableNameWorkspace)
+	params := map[string]interface{}{"name": name}
+	_, err := dbMap.Select(&workspaces, query, params)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while getting workspace")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return nil, err
+	}
+
+	if len(workspaces) == 1 {
+		return workspaces[0], nil
+	}
+
+	return nil, nil
+}
+
+func rdbDeleteWorkspace(ctx context.Context, dbMap *gorp.DbMap, name string) error {
+	span := tracer.StartSpan(ctx, "rdbDeleteWorkspace", "datastore")
+	defer tracer.Finish(span)
+
+	query := fmt.Sprintf("DELETE FROM %s WHERE name=?;", tableNameWorkspace)
+	_, err := dbMap.Exec(rebind(dbMap, query), name)
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting workspace")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
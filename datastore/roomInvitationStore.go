@@ -0,0 +1,84 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type selectRoomInvitationsOptions struct {
+	roomID         string
+	userID         string
+	invitationType string
+	status         string
+}
+
+type SelectRoomInvitationsOption func(*selectRoomInvitationsOptions)
+
+func SelectRoomInvitationsOptionFilterByRoomID(roomID string) SelectRoomInvitationsOption {
+	return func(ops *selectRoomInvitationsOptions) {
+		ops.roomID = roomID
+	}
+}
+
+func SelectRoomInvitationsOptionFilterByUserID(userID string) SelectRoomInvitationsOption {
+	return func(ops *selectRoomInvitationsOptions) {
+		ops.userID = userID
+	}
+}
+
+func SelectRoomInvitationsOptionFilterByType(invitationType string) SelectRoomInvitationsOption {
+	return func(ops *selectRoomInvitationsOptions) {
+		ops.invitationType = invitationType
+	}
+}
+
+func SelectRoomInvitationsOptionFilterByStatus(status string) SelectRoomInvitationsOption {
+	return func(ops *selectRoomInvitationsOptions) {
+		ops.status = status
+	}
+}
+
+type insertRoomInvitationsOptions struct {
+	outboxEvents []*model.OutboxEvent
+}
+
+type InsertRoomInvitationsOption func(*insertRoomInvitationsOptions)
+
+// InsertRoomInvitationsOptionWithOutboxEvents inserts the outbox events in the same transaction
+func InsertRoomInvitationsOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) InsertRoomInvitationsOption {
+	return func(ops *insertRoomInvitationsOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
+type updateRoomInvitationOptions struct {
+	roomUsers    []*model.RoomUser
+	outboxEvents []*model.OutboxEvent
+}
+
+type UpdateRoomInvitationOption func(*updateRoomInvitationOptions)
+
+// UpdateRoomInvitationOptionWithRoomUsers inserts the room users of the accepted invitation in the same transaction
+func UpdateRoomInvitationOptionWithRoomUsers(roomUsers []*model.RoomUser) UpdateRoomInvitationOption {
+	return func(ops *updateRoomInvitationOptions) {
+		ops.roomUsers = roomUsers
+	}
+}
+
+// UpdateRoomInvitationOptionWithOutboxEvents inserts the outbox events in the same transaction
+func UpdateRoomInvitationOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) UpdateRoomInvitationOption {
+	return func(ops *updateRoomInvitationOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
+type roomInvitationStore interface {
+	createRoomInvitationStore()
+
+	InsertRoomInvitations(invitations []*model.RoomInvitation, opts ...InsertRoomInvitationsOption) error
+	SelectRoomInvitations(limit, offset int32, opts ...SelectRoomInvitationsOption) ([]*model.RoomInvitation, error)
+	SelectCountRoomInvitations(opts ...SelectRoomInvitationsOption) (int64, error)
+	SelectRoomInvitation(invitationID string) (*model.RoomInvitation, error)
+	// UpdateRoomInvitation answers the pending invitation in a transaction.
+	// It returns false without updating if the invitation is not pending any more.
+	UpdateRoomInvitation(invitation *model.RoomInvitation, opts ...UpdateRoomInvitationOption) (bool, error)
+	// ExpireRoomInvitations changes the status of the pending invitations over the expiration to expired
+	ExpireRoomInvitations(nowTimestamp int64) error
+}
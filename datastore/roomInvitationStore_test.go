@@ -0,0 +1,185 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreInsertRoomInvitations      = "[store] insert room invitations test"
+	TestStoreSelectRoomInvitations      = "[store] select room invitations test"
+	TestStoreSelectCountRoomInvitations = "[store] select count room invitations test"
+	TestStoreSelectRoomInvitation       = "[store] select room invitation test"
+	TestStoreUpdateRoomInvitation       = "[store] update room invitation test"
+	TestStoreExpireRoomInvitations      = "[store] expire room invitations test"
+	testStoreRoomInvitationRoomID       = "room-invitation-store-room-id-0001"
+)
+
+func testRoomInvitationStore(t *testing.T) {
+	nowTimestamp := time.Now().Unix()
+	invitations := []*model.RoomInvitation{
+		&model.RoomInvitation{
+			InvitationID:  "room-invitation-store-invitation-id-0001",
+			Type:          model.RoomInvitationTypeInvitation,
+			RoomID:        testStoreRoomInvitationRoomID,
+			UserID:        "room-invitation-store-user-id-0001",
+			InviterUserID: "room-invitation-store-user-id-0000",
+			Status:        model.RoomInvitationStatusPending,
+			Created:       nowTimestamp,
+			Modified:      nowTimestamp,
+		},
+		&model.RoomInvitation{
+			InvitationID:  "room-invitation-store-invitation-id-0002",
+			Type:          model.RoomInvitationTypeInvitation,
+			RoomID:        testStoreRoomInvitationRoomID,
+			UserID:        "room-invitation-store-user-id-0002",
+			InviterUserID: "room-invitation-store-user-id-0000",
+			Status:        model.RoomInvitationStatusPending,
+			Expires:       nowTimestamp - 1,
+			Created:       nowTimestamp,
+			Modified:      nowTimestamp,
+		},
+		&model.RoomInvitation{
+			InvitationID: "room-invitation-store-invitation-id-0003",
+			Type:         model.RoomInvitationTypeJoinRequest,
+			RoomID:       testStoreRoomInvitationRoomID,
+			UserID:       "room-invitation-store-user-id-0003",
+			Status:       model.RoomInvitationStatusPending,
+			Created:      nowTimestamp,
+			Modified:     nowTimestamp,
+		},
+	}
+
+	t.Run(TestStoreInsertRoomInvitations, func(t *testing.T) {
+		err := Provider(ctx).InsertRoomInvitations(invitations)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreInsertRoomInvitations, err.Error())
+		}
+	})
+
+	t.Run(TestStoreSelectRoomInvitations, func(t *testing.T) {
+		selected, err := Provider(ctx).SelectRoomInvitations(
+			10,
+			0,
+			SelectRoomInvitationsOptionFilterByRoomID(testStoreRoomInvitationRoomID),
+			SelectRoomInvitationsOptionFilterByType(model.RoomInvitationTypeInvitation),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInvitations, err.Error())
+		}
+		if len(selected) != 2 {
+			t.Fatalf("Failed to %s. Expected room invitations count to be 2, but it was %d", TestStoreSelectRoomInvitations, len(selected))
+		}
+
+		selected, err = Provider(ctx).SelectRoomInvitations(
+			10,
+			0,
+			SelectRoomInvitationsOptionFilterByUserID("room-invitation-store-user-id-0003"),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInvitations, err.Error())
+		}
+		if len(selected) != 1 || selected[0].Type != model.RoomInvitationTypeJoinRequest {
+			t.Fatalf("Failed to %s. Expected the join request to be selected", TestStoreSelectRoomInvitations)
+		}
+	})
+
+	t.Run(TestStoreSelectCountRoomInvitations, func(t *testing.T) {
+		count, err := Provider(ctx).SelectCountRoomInvitations(
+			SelectRoomInvitationsOptionFilterByRoomID(testStoreRoomInvitationRoomID),
+			SelectRoomInvitationsOptionFilterByStatus(model.RoomInvitationStatusPending),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectCountRoomInvitations, err.Error())
+		}
+		if count != 3 {
+			t.Fatalf("Failed to %s. Expected room invitations count to be 3, but it was %d", TestStoreSelectCountRoomInvitations, count)
+		}
+	})
+
+	t.Run(TestStoreSelectRoomInvitation, func(t *testing.T) {
+		invitation, err := Provider(ctx).SelectRoomInvitation("room-invitation-store-invitation-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInvitation, err.Error())
+		}
+		if invitation == nil {
+			t.Fatalf("Failed to %s. Expected room invitation to be not nil", TestStoreSelectRoomInvitation)
+		}
+
+		invitation, err = Provider(ctx).SelectRoomInvitation("not-exist-invitation-id")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInvitation, err.Error())
+		}
+		if invitation != nil {
+			t.Fatalf("Failed to %s. Expected room invitation to be nil", TestStoreSelectRoomInvitation)
+		}
+	})
+
+	t.Run(TestStoreUpdateRoomInvitation, func(t *testing.T) {
+		invitations[0].Status = model.RoomInvitationStatusAccepted
+		invitations[0].Modified = time.Now().Unix()
+		ru := &model.RoomUser{}
+		ru.RoomID = testStoreRoomInvitationRoomID
+		ru.UserID = invitations[0].UserID
+		ru.Display = true
+		updated, err := Provider(ctx).UpdateRoomInvitation(invitations[0], UpdateRoomInvitationOptionWithRoomUsers([]*model.RoomUser{ru}))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateRoomInvitation, err.Error())
+		}
+		if !updated {
+			t.Fatalf("Failed to %s. Expected the pending invitation to be updated", TestStoreUpdateRoomInvitation)
+		}
+
+		// The answered invitation is not answered again
+		declined := *invitations[0]
+		declined.Status = model.RoomInvitationStatusDeclined
+		updated, err = Provider(ctx).UpdateRoomInvitation(&declined)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateRoomInvitation, err.Error())
+		}
+		if updated {
+			t.Fatalf("Failed to %s. Expected the answered invitation not to be updated", TestStoreUpdateRoomInvitation)
+		}
+
+		invitation, err := Provider(ctx).SelectRoomInvitation(invitations[0].InvitationID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateRoomInvitation, err.Error())
+		}
+		if invitation.Status != model.RoomInvitationStatusAccepted {
+			t.Fatalf("Failed to %s. Expected status to be %s, but it was %s", TestStoreUpdateRoomInvitation, model.RoomInvitationStatusAccepted, invitation.Status)
+		}
+
+		roomUser, err := Provider(ctx).SelectRoomUser(testStoreRoomInvitationRoomID, invitations[0].UserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUpdateRoomInvitation, err.Error())
+		}
+		if roomUser == nil || roomUser.RoomRole != model.RoomRoleMember {
+			t.Fatalf("Failed to %s. Expected the room user to be inserted as a member", TestStoreUpdateRoomInvitation)
+		}
+	})
+
+	t.Run(TestStoreExpireRoomInvitations, func(t *testing.T) {
+		err := Provider(ctx).ExpireRoomInvitations(time.Now().Unix())
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreExpireRoomInvitations, err.Error())
+		}
+
+		invitation, err := Provider(ctx).SelectRoomInvitation(invitations[1].InvitationID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreExpireRoomInvitations, err.Error())
+		}
+		if invitation.Status != model.RoomInvitationStatusExpired {
+			t.Fatalf("Failed to %s. Expected status to be %s, but it was %s", TestStoreExpireRoomInvitations, model.RoomInvitationStatusExpired, invitation.Status)
+		}
+
+		invitation, err = Provider(ctx).SelectRoomInvitation(invitations[2].InvitationID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreExpireRoomInvitations, err.Error())
+		}
+		if invitation.Status != model.RoomInvitationStatusPending {
+			t.Fatalf("Failed to %s. Expected the invitation without expiration not to be expired", TestStoreExpireRoomInvitations)
+		}
+	})
+}
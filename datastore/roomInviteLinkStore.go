@@ -0,0 +1,28 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type useRoomInviteLinkOptions struct {
+	outboxEvents []*model.OutboxEvent
+}
+
+type UseRoomInviteLinkOption func(*useRoomInviteLinkOptions)
+
+// UseRoomInviteLinkOptionWithOutboxEvents inserts the outbox events in the same transaction
+func UseRoomInviteLinkOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) UseRoomInviteLinkOption {
+	return func(ops *useRoomInviteLinkOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
+type roomInviteLinkStore interface {
+	createRoomInviteLinkStore()
+
+	InsertRoomInviteLink(inviteLink *model.RoomInviteLink) error
+	SelectRoomInviteLinks(roomID string) ([]*model.RoomInviteLink, error)
+	SelectRoomInviteLink(inviteLinkID string) (*model.RoomInviteLink, error)
+	DeleteRoomInviteLink(inviteLink *model.RoomInviteLink) error
+	// UseRoomInviteLink counts up the uses of the link and inserts the room user in a transaction.
+	// It returns false without inserting if the link is not available any more.
+	UseRoomInviteLink(inviteLink *model.RoomInviteLink, roomUser *model.RoomUser, opts ...UseRoomInviteLinkOption) (bool, error)
+}
@@ -0,0 +1,114 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreInsertRoomInviteLink  = "[store] insert room invite link test"
+	TestStoreSelectRoomInviteLinks = "[store] select room invite links test"
+	TestStoreSelectRoomInviteLink  = "[store] select room invite link test"
+	TestStoreUseRoomInviteLink     = "[store] use room invite link test"
+	TestStoreDeleteRoomInviteLink  = "[store] delete room invite link test"
+	testStoreRoomInviteLinkRoomID  = "room-invite-link-store-room-id-0001"
+)
+
+func testRoomInviteLinkStore(t *testing.T) {
+	nowTimestamp := time.Now().Unix()
+	inviteLink := &model.RoomInviteLink{
+		InviteLinkID: "room-invite-link-store-invite-link-id-0001",
+		RoomID:       testStoreRoomInviteLinkRoomID,
+		UserID:       "room-invite-link-store-user-id-0000",
+		MaxUses:      1,
+		Created:      nowTimestamp,
+		Modified:     nowTimestamp,
+	}
+
+	t.Run(TestStoreInsertRoomInviteLink, func(t *testing.T) {
+		err := Provider(ctx).InsertRoomInviteLink(inviteLink)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreInsertRoomInviteLink, err.Error())
+		}
+	})
+
+	t.Run(TestStoreSelectRoomInviteLinks, func(t *testing.T) {
+		inviteLinks, err := Provider(ctx).SelectRoomInviteLinks(testStoreRoomInviteLinkRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInviteLinks, err.Error())
+		}
+		if len(inviteLinks) != 1 {
+			t.Fatalf("Failed to %s. Expected room invite links count to be 1, but it was %d", TestStoreSelectRoomInviteLinks, len(inviteLinks))
+		}
+	})
+
+	t.Run(TestStoreSelectRoomInviteLink, func(t *testing.T) {
+		selected, err := Provider(ctx).SelectRoomInviteLink(inviteLink.InviteLinkID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectRoomInviteLink, err.Error())
+		}
+		if selected == nil {
+			t.Fatalf("Failed to %s. Expected room invite link to be not nil", TestStoreSelectRoomInviteLink)
+		}
+	})
+
+	t.Run(TestStoreUseRoomInviteLink, func(t *testing.T) {
+		ru := &model.RoomUser{}
+		ru.RoomID = testStoreRoomInviteLinkRoomID
+		ru.UserID = "room-invite-link-store-user-id-0001"
+		ru.Display = true
+		used, err := Provider(ctx).UseRoomInviteLink(inviteLink, ru)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUseRoomInviteLink, err.Error())
+		}
+		if !used {
+			t.Fatalf("Failed to %s. Expected room invite link to be used", TestStoreUseRoomInviteLink)
+		}
+
+		ru2 := &model.RoomUser{}
+		ru2.RoomID = testStoreRoomInviteLinkRoomID
+		ru2.UserID = "room-invite-link-store-user-id-0002"
+		ru2.Display = true
+		used, err = Provider(ctx).UseRoomInviteLink(inviteLink, ru2)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUseRoomInviteLink, err.Error())
+		}
+		if used {
+			t.Fatalf("Failed to %s. Expected room invite link over the max uses not to be used", TestStoreUseRoomInviteLink)
+		}
+
+		roomUser, err := Provider(ctx).SelectRoomUser(testStoreRoomInviteLinkRoomID, ru2.UserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUseRoomInviteLink, err.Error())
+		}
+		if roomUser != nil {
+			t.Fatalf("Failed to %s. Expected the room user not to be inserted", TestStoreUseRoomInviteLink)
+		}
+
+		selected, err := Provider(ctx).SelectRoomInviteLink(inviteLink.InviteLinkID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreUseRoomInviteLink, err.Error())
+		}
+		if selected.Uses != 1 {
+			t.Fatalf("Failed to %s. Expected uses to be 1, but it was %d", TestStoreUseRoomInviteLink, selected.Uses)
+		}
+	})
+
+	t.Run(TestStoreDeleteRoomInviteLink, func(t *testing.T) {
+		inviteLink.Deleted = time.Now().Unix()
+		err := Provider(ctx).DeleteRoomInviteLink(inviteLink)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeleteRoomInviteLink, err.Error())
+		}
+
+		selected, err := Provider(ctx).SelectRoomInviteLink(inviteLink.InviteLinkID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeleteRoomInviteLink, err.Error())
+		}
+		if selected != nil {
+			t.Fatalf("Failed to %s. Expected deleted room invite link to be nil", TestStoreDeleteRoomInviteLink)
+		}
+	})
+}
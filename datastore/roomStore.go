@@ -8,7 +8,8 @@ import (
 type InsertRoomOption func(*insertRoomOptions)
 
 type insertRoomOptions struct {
-	users []*model.RoomUser
+	users        []*model.RoomUser
+	outboxEvents []*model.OutboxEvent
 }
 
 func InsertRoomOptionWithRoomUser(users []*model.RoomUser) InsertRoomOption {
@@ -17,10 +18,18 @@ func InsertRoomOptionWithRoomUser(users []*model.RoomUser) InsertRoomOption {
 	}
 }
 
+// InsertRoomOptionWithOutboxEvents inserts the outbox events in the same transaction
+func InsertRoomOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) InsertRoomOption {
+	return func(ops *insertRoomOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
 type SelectRoomsOption func(*selectRoomsOptions)
 
 type selectRoomsOptions struct {
-	orders []*scpb.OrderInfo
+	orders                   []*scpb.OrderInfo
+	filterByRetentionEnabled bool
 }
 
 func SelectRoomsOptionWithOrders(orders []*scpb.OrderInfo) SelectRoomsOption {
@@ -29,10 +38,39 @@ func SelectRoomsOptionWithOrders(orders []*scpb.OrderInfo) SelectRoomsOption {
 	}
 }
 
+// SelectRoomsOptionFilterByRetentionEnabled selects only rooms which have either max age or max count of retention
+func SelectRoomsOptionFilterByRetentionEnabled(filterByRetentionEnabled bool) SelectRoomsOption {
+	return func(ops *selectRoomsOptions) {
+		ops.filterByRetentionEnabled = filterByRetentionEnabled
+	}
+}
+
+type SelectPublicRoomsOption func(*selectPublicRoomsOptions)
+
+type selectPublicRoomsOptions struct {
+	name string
+	tags []string
+}
+
+// SelectPublicRoomsOptionFilterByName selects the public rooms whose name includes the name
+func SelectPublicRoomsOptionFilterByName(name string) SelectPublicRoomsOption {
+	return func(ops *selectPublicRoomsOptions) {
+		ops.name = name
+	}
+}
+
+// SelectPublicRoomsOptionFilterByTags selects the public rooms which have all of the tags
+func SelectPublicRoomsOptionFilterByTags(tags []string) SelectPublicRoomsOption {
+	return func(ops *selectPublicRoomsOptions) {
+		ops.tags = tags
+	}
+}
+
 type SelectRoomOption func(*selectRoomOptions)
 
 type selectRoomOptions struct {
-	withUsers bool
+	withUsers   bool
+	withDeleted bool
 }
 
 func SelectRoomOptionWithUsers(withUsers bool) SelectRoomOption {
@@ -41,6 +79,13 @@ func SelectRoomOptionWithUsers(withUsers bool) SelectRoomOption {
 	}
 }
 
+// SelectRoomOptionWithDeleted selects the room even if it has been deleted
+func SelectRoomOptionWithDeleted(withDeleted bool) SelectRoomOption {
+	return func(ops *selectRoomOptions) {
+		ops.withDeleted = withDeleted
+	}
+}
+
 type UpdateRoomOption func(*updateRoomOptions)
 
 type updateRoomOptions struct {
@@ -60,5 +105,15 @@ type roomStore interface {
 	SelectRooms(limit, offset int32, opts ...SelectRoomsOption) ([]*model.Room, error)
 	SelectRoom(roomID string, opts ...SelectRoomOption) (*model.Room, error)
 	SelectCountRooms(opts ...SelectRoomsOption) (int64, error)
+	SelectPublicRooms(limit, offset int32, opts ...SelectPublicRoomsOption) ([]*model.PublicRoom, error)
+	SelectCountPublicRooms(opts ...SelectPublicRoomsOption) (int64, error)
 	UpdateRoom(room *model.Room, opts ...UpdateRoomOption) error
+	UpdateRoomOwner(room *model.Room, userID string) error
+	// UpdateRoomLastMessage sets the latest message of the room as the last message of the room
+	UpdateRoomLastMessage(roomID string) error
+	// RestoreRoom restores the deleted room and the room users who were in it when it was deleted.
+	// It returns false without restoring if the room users of the room are not kept any more.
+	RestoreRoom(room *model.Room) (bool, error)
+	// PurgeDeletedRoomUsers deletes the room users kept for the rooms deleted before deletedTimestamp
+	PurgeDeletedRoomUsers(deletedTimestamp int64) error
 }
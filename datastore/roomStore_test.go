@@ -10,16 +10,19 @@ import (
 )
 
 const (
-	TestRoomStoreSetUp       = "roomStore set up"
-	TestNameInsertRoom       = "insert room test"
-	TestNameSelectRooms      = "select rooms test"
-	TestNameSelectRoom       = "select room test"
-	TestNameSelectCountRooms = "select count rooms test"
-	TestNameUpdateRoom       = "update room test"
-	TestRoomStoreTearDown    = "roomStore tear down"
+	TestRoomStoreSetUp        = "roomStore set up"
+	TestNameInsertRoom        = "insert room test"
+	TestNameSelectRooms       = "select rooms test"
+	TestNameSelectRoom        = "select room test"
+	TestNameSelectCountRooms  = "select count rooms test"
+	TestNameUpdateRoom        = "update room test"
+	TestNameUpdateRoomUsers   = "update room users test"
+	TestNameSelectPublicRooms = "select public rooms test"
+	TestNameRestoreRoom       = "restore room test"
+	TestRoomStoreTearDown     = "roomStore tear down"
 )
 
-func TestRoomStore(t *testing.T) {
+func testRoomStore(t *testing.T) {
 	testRoomID := "room-id-0001"
 	var room *model.Room
 	var err error
@@ -104,6 +107,63 @@ func TestRoomStore(t *testing.T) {
 		}
 	})
 
+	t.Run(TestNameUpdateRoomUsers, func(t *testing.T) {
+		ownerID := "room-store-user-id-0001"
+		memberID := "room-store-user-id-0002"
+
+		ru, err := Provider(ctx).SelectRoomUser(testRoomID, ownerID)
+		if err != nil || ru == nil {
+			t.Fatalf("Failed to %s", TestNameUpdateRoomUsers)
+		}
+		ru.UnreadCount = 2
+		ru.Favorite = true
+		ru.SortKey = 10
+		ru.LastReadTimestamp = 100
+		err = Provider(ctx).UpdateRoomUser(ru)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestNameUpdateRoomUsers, err.Error())
+		}
+
+		owner := &model.RoomUser{}
+		owner.RoomID = testRoomID
+		owner.UserID = ownerID
+		owner.Display = true
+		owner.RoomRole = model.RoomRoleOwner
+		member := &model.RoomUser{}
+		member.RoomID = testRoomID
+		member.UserID = memberID
+		member.Display = true
+		err = Provider(ctx).UpdateRoom(room, UpdateRoomOptionWithRoomUser([]*model.RoomUser{owner, member}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestNameUpdateRoomUsers, err.Error())
+		}
+
+		ru, err = Provider(ctx).SelectRoomUser(testRoomID, ownerID)
+		if err != nil || ru == nil {
+			t.Fatalf("Failed to %s", TestNameUpdateRoomUsers)
+		}
+		if ru.RoomRole != model.RoomRoleOwner || ru.UnreadCount != 2 || !ru.Favorite || ru.SortKey != 10 || ru.LastReadTimestamp != 100 {
+			t.Fatalf("Failed to %s. Expected the staying room user to keep its columns, but it was %+v", TestNameUpdateRoomUsers, ru)
+		}
+		ru, err = Provider(ctx).SelectRoomUser(testRoomID, memberID)
+		if err != nil || ru == nil || ru.RoomRole != model.RoomRoleMember {
+			t.Fatalf("Failed to %s. Expected the new room user to be inserted as a member", TestNameUpdateRoomUsers)
+		}
+
+		err = Provider(ctx).UpdateRoom(room, UpdateRoomOptionWithRoomUser([]*model.RoomUser{owner}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestNameUpdateRoomUsers, err.Error())
+		}
+		ru, err = Provider(ctx).SelectRoomUser(testRoomID, memberID)
+		if err != nil || ru != nil {
+			t.Fatalf("Failed to %s. Expected the left room user to be deleted", TestNameUpdateRoomUsers)
+		}
+		ru, err = Provider(ctx).SelectRoomUser(testRoomID, ownerID)
+		if err != nil || ru == nil || ru.UnreadCount != 2 {
+			t.Fatalf("Failed to %s. Expected the staying room user to be kept", TestNameUpdateRoomUsers)
+		}
+	})
+
 	t.Run(TestNameUpdateRoom, func(t *testing.T) {
 		room.Name = "name-update"
 		err = Provider(ctx).UpdateRoom(room)
@@ -135,6 +195,88 @@ func TestRoomStore(t *testing.T) {
 		}
 	})
 
+	t.Run(TestNameRestoreRoom, func(t *testing.T) {
+		deletedRoom, err := Provider(ctx).SelectRoom(testRoomID, SelectRoomOptionWithDeleted(true))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if deletedRoom == nil {
+			t.Fatalf("Failed to %s. Expected the deleted room to be selected", TestNameRestoreRoom)
+		}
+
+		restored, err := Provider(ctx).RestoreRoom(deletedRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if !restored {
+			t.Fatalf("Failed to %s. Expected the room to be restored", TestNameRestoreRoom)
+		}
+		restoredRoom, err := Provider(ctx).SelectRoom(testRoomID, SelectRoomOptionWithUsers(true))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if restoredRoom == nil || len(restoredRoom.Users) != 1 {
+			t.Fatalf("Failed to %s. Expected the room to be restored with the room user", TestNameRestoreRoom)
+		}
+
+		// The settings of the room user are restored as they were
+		roomUser, err := Provider(ctx).SelectRoomUser(testRoomID, restoredRoom.Users[0].UserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		roomUser.UnreadCount = 3
+		roomUser.Favorite = true
+		roomUser.SortKey = 5
+		roomUser.LastReadTimestamp = 10
+		err = Provider(ctx).UpdateRoomUser(roomUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		restoredRoom.DeletedTimestamp = 1
+		err = Provider(ctx).UpdateRoom(restoredRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		restored, err = Provider(ctx).RestoreRoom(restoredRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if !restored {
+			t.Fatalf("Failed to %s. Expected the room to be restored", TestNameRestoreRoom)
+		}
+		restoredRoomUser, err := Provider(ctx).SelectRoomUser(testRoomID, roomUser.UserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if restoredRoomUser == nil || restoredRoomUser.UnreadCount != 3 || !restoredRoomUser.Favorite || restoredRoomUser.SortKey != 5 || restoredRoomUser.LastReadTimestamp != 10 {
+			t.Fatalf("Failed to %s. Expected the settings of the room user to be restored", TestNameRestoreRoom)
+		}
+
+		restoredRoom.DeletedTimestamp = 1
+		err = Provider(ctx).UpdateRoom(restoredRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		err = Provider(ctx).PurgeDeletedRoomUsers(2)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		restored, err = Provider(ctx).RestoreRoom(restoredRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if restored {
+			t.Fatalf("Failed to %s. Expected the room whose room users were purged not to be restored", TestNameRestoreRoom)
+		}
+		restoredRoom, err = Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameRestoreRoom, err.Error())
+		}
+		if restoredRoom != nil {
+			t.Fatalf("Failed to %s. Expected the room to stay deleted", TestNameRestoreRoom)
+		}
+	})
+
 	t.Run(TestNameSelectRooms, func(t *testing.T) {
 		rooms, err := Provider(ctx).SelectRooms(
 			0,
@@ -222,6 +364,75 @@ func TestRoomStore(t *testing.T) {
 		}
 	})
 
+	t.Run(TestNameSelectPublicRooms, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		metaDatas := []string{
+			`{"tags":["Soccer","sports"]}`,
+			`{"tags":["baseball","sports"]}`,
+			`{"key":"value"}`,
+		}
+		for i, metaData := range metaDatas {
+			newRoom := &model.Room{}
+			newRoom.RoomID = fmt.Sprintf("room-store-public-room-id-%04d", i+1)
+			newRoom.UserID = "room-store-user-id-0001"
+			newRoom.Name = fmt.Sprintf("Directory Room %d", i+1)
+			newRoom.Type = scpb.RoomType_PublicRoom
+			newRoom.MetaData = []byte(metaData)
+			newRoom.CreatedTimestamp = nowTimestamp + int64(i)
+			newRoom.ModifiedTimestamp = nowTimestamp + int64(i)
+			err := Provider(ctx).InsertRoom(newRoom)
+			if err != nil {
+				t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+			}
+		}
+
+		ru := &model.RoomUser{}
+		ru.RoomID = "room-store-public-room-id-0002"
+		ru.UserID = "room-store-user-id-0001"
+		ru.Display = true
+		err := Provider(ctx).InsertRoomUsers([]*model.RoomUser{ru})
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+		}
+
+		rooms, err := Provider(ctx).SelectPublicRooms(10, 0, SelectPublicRoomsOptionFilterByName("directory room"))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+		}
+		if len(rooms) != 3 {
+			t.Fatalf("Failed to %s. Expected public rooms count to be 3, but it was %d", TestNameSelectPublicRooms, len(rooms))
+		}
+		if rooms[0].RoomID != "room-store-public-room-id-0002" || rooms[0].MemberCount != 1 {
+			t.Fatalf("Failed to %s. Expected the room which has the most members to be first", TestNameSelectPublicRooms)
+		}
+
+		rooms, err = Provider(ctx).SelectPublicRooms(10, 0, SelectPublicRoomsOptionFilterByTags([]string{"sports", "soccer"}))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+		}
+		if len(rooms) != 1 || rooms[0].RoomID != "room-store-public-room-id-0001" {
+			t.Fatalf("Failed to %s. Expected only the room which has all of the tags to be selected", TestNameSelectPublicRooms)
+		}
+
+		count, err := Provider(ctx).SelectCountPublicRooms(SelectPublicRoomsOptionFilterByTags([]string{"sports"}))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+		}
+		if count != 2 {
+			t.Fatalf("Failed to %s. Expected public rooms count to be 2, but it was %d", TestNameSelectPublicRooms, count)
+		}
+
+		for i := range metaDatas {
+			deleteRoom := &model.Room{}
+			deleteRoom.RoomID = fmt.Sprintf("room-store-public-room-id-%04d", i+1)
+			deleteRoom.DeletedTimestamp = 1
+			err = Provider(ctx).UpdateRoom(deleteRoom)
+			if err != nil {
+				t.Fatalf("Failed to %s. %s", TestNameSelectPublicRooms, err.Error())
+			}
+		}
+	})
+
 	t.Run(TestRoomStoreTearDown, func(t *testing.T) {
 		deleteUser := &model.User{}
 		deleteUser.UserID = "room-store-user-id-0001"
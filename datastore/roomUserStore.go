@@ -7,6 +7,7 @@ import (
 
 type insertRoomUsersOptions struct {
 	beforeCleanRoomID string
+	outboxEvents      []*model.OutboxEvent
 }
 
 type InsertRoomUsersOption func(*insertRoomUsersOptions)
@@ -17,6 +18,13 @@ func InsertRoomUsersOptionBeforeCleanRoomID(beforeCleanRoomID string) InsertRoom
 	}
 }
 
+// InsertRoomUsersOptionWithOutboxEvents inserts the outbox events in the same transaction
+func InsertRoomUsersOptionWithOutboxEvents(outboxEvents []*model.OutboxEvent) InsertRoomUsersOption {
+	return func(ops *insertRoomUsersOptions) {
+		ops.outboxEvents = outboxEvents
+	}
+}
+
 type selectRoomUsersOptions struct {
 	roomID  string
 	userIDs []string
@@ -70,8 +78,13 @@ func SelectUserIDsOfRoomUserOptionWithRoles(roles []int32) SelectUserIDsOfRoomUs
 }
 
 type selectMiniRoomsOptions struct {
-	orders []*scpb.OrderInfo
-	filter scpb.UserRoomsFilter
+	orders          []*scpb.OrderInfo
+	filter          scpb.UserRoomsFilter
+	includeArchived bool
+	includeHidden   bool
+	favorite        *bool
+	muted           *bool
+	sort            model.UserRoomsSort
 }
 
 type SelectMiniRoomsOption func(*selectMiniRoomsOptions)
@@ -88,6 +101,36 @@ func SelectMiniRoomsOptionFilter(filter scpb.UserRoomsFilter) SelectMiniRoomsOpt
 	}
 }
 
+func SelectMiniRoomsOptionIncludeArchived(includeArchived bool) SelectMiniRoomsOption {
+	return func(ops *selectMiniRoomsOptions) {
+		ops.includeArchived = includeArchived
+	}
+}
+
+func SelectMiniRoomsOptionIncludeHidden(includeHidden bool) SelectMiniRoomsOption {
+	return func(ops *selectMiniRoomsOptions) {
+		ops.includeHidden = includeHidden
+	}
+}
+
+func SelectMiniRoomsOptionFilterByFavorite(favorite bool) SelectMiniRoomsOption {
+	return func(ops *selectMiniRoomsOptions) {
+		ops.favorite = &favorite
+	}
+}
+
+func SelectMiniRoomsOptionFilterByMuted(muted bool) SelectMiniRoomsOption {
+	return func(ops *selectMiniRoomsOptions) {
+		ops.muted = &muted
+	}
+}
+
+func SelectMiniRoomsOptionWithSort(sort model.UserRoomsSort) SelectMiniRoomsOption {
+	return func(ops *selectMiniRoomsOptions) {
+		ops.sort = sort
+	}
+}
+
 type deleteRoomUsersOptions struct {
 	roomIDs []string
 	userIDs []string
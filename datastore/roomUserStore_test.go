@@ -17,11 +17,12 @@ const (
 	TestStoreSelectRoomUserOfOneOnOne = "[store] select room user of one-on-one test"
 	TestStoreSelectUserIDsOfRoomUser  = "[store] select userIds of room user test"
 	TestStoreUpdateRoomUser           = "[store] update room user test"
+	TestStoreSelectMiniRoomsPersonal  = "[store] select mini rooms by personal settings test"
 	TestStoreDeleteRoomUsers          = "[store] delete room users test"
 	TestStoreTearDownRoomUser         = "[store] tear down roomUser"
 )
 
-func TestRoomUserStore(t *testing.T) {
+func testRoomUserStore(t *testing.T) {
 	var roomUser *model.RoomUser
 	var err error
 
@@ -378,6 +379,93 @@ func TestRoomUserStore(t *testing.T) {
 		}
 	})
 
+	t.Run(TestStoreSelectMiniRoomsPersonal, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		userID := "room-user-store-personal-user-id-0001"
+		newUser := &model.User{}
+		newUser.UserID = userID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+		}
+
+		// room 1 is the newest, room 2 is hidden and room 3 is the favorite
+		for i := 1; i <= 3; i++ {
+			roomID := fmt.Sprintf("room-user-store-personal-room-id-%04d", i)
+			newRoom := &model.Room{}
+			newRoom.RoomID = roomID
+			newRoom.UserID = userID
+			newRoom.Type = scpb.RoomType_PrivateRoom
+			newRoom.MetaData = []byte(`{"key":"value"}`)
+			newRoom.LastMessageUpdatedTimestamp = nowTimestamp - int64(i)
+			newRoom.CreatedTimestamp = nowTimestamp
+			newRoom.ModifiedTimestamp = nowTimestamp
+			ru := &model.RoomUser{}
+			ru.RoomID = roomID
+			ru.UserID = userID
+			ru.Display = true
+			ru.Hidden = i == 2
+			ru.Favorite = i == 3
+			ru.SortKey = int64(10 - i)
+			err := Provider(ctx).InsertRoom(newRoom, InsertRoomOptionWithRoomUser([]*model.RoomUser{ru}))
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+			}
+		}
+
+		miniRooms, err := Provider(ctx).SelectMiniRooms(10, 0, userID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+		}
+		if len(miniRooms) != 2 {
+			t.Fatalf("Failed to %s. Expected the hidden room to be excluded, but mini rooms count was %d", TestStoreSelectMiniRoomsPersonal, len(miniRooms))
+		}
+		if miniRooms[0].RoomID != "room-user-store-personal-room-id-0003" || !miniRooms[0].RuFavorite {
+			t.Fatalf("Failed to %s. Expected the favorite room to be first", TestStoreSelectMiniRoomsPersonal)
+		}
+
+		count, err := Provider(ctx).SelectCountMiniRooms(userID, SelectMiniRoomsOptionIncludeHidden(true))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+		}
+		if count != 3 {
+			t.Fatalf("Failed to %s. Expected mini rooms count to be 3, but it was %d", TestStoreSelectMiniRoomsPersonal, count)
+		}
+
+		miniRooms, err = Provider(ctx).SelectMiniRooms(
+			10,
+			0,
+			userID,
+			SelectMiniRoomsOptionIncludeHidden(true),
+			SelectMiniRoomsOptionFilterByFavorite(false),
+			SelectMiniRoomsOptionWithSort(model.UserRoomsSortSortKey),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+		}
+		if len(miniRooms) != 2 || miniRooms[0].RoomID != "room-user-store-personal-room-id-0002" {
+			t.Fatalf("Failed to %s. Expected the rooms not favorite to be in the order of the sort keys", TestStoreSelectMiniRoomsPersonal)
+		}
+
+		for i := 1; i <= 3; i++ {
+			deleteRoom := &model.Room{}
+			deleteRoom.RoomID = fmt.Sprintf("room-user-store-personal-room-id-%04d", i)
+			deleteRoom.DeletedTimestamp = 1
+			err = Provider(ctx).UpdateRoom(deleteRoom)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+			}
+		}
+		newUser.DeletedTimestamp = 1
+		err = Provider(ctx).UpdateUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectMiniRoomsPersonal, err.Error())
+		}
+	})
+
 	t.Run(TestStoreDeleteRoomUsers, func(t *testing.T) {
 		err = Provider(ctx).DeleteRoomUsers(
 			DeleteRoomUsersOptionFilterByRoomIDs([]string{"room-user-store-room-id-0002"}),
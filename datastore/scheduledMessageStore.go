@@ -0,0 +1,39 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type selectScheduledMessagesOptions struct {
+	roomID       string
+	userID       string
+	dueTimestamp int64
+}
+
+type SelectScheduledMessagesOption func(*selectScheduledMessagesOptions)
+
+func SelectScheduledMessagesOptionFilterByRoomID(roomID string) SelectScheduledMessagesOption {
+	return func(ops *selectScheduledMessagesOptions) {
+		ops.roomID = roomID
+	}
+}
+
+func SelectScheduledMessagesOptionFilterByUserID(userID string) SelectScheduledMessagesOption {
+	return func(ops *selectScheduledMessagesOptions) {
+		ops.userID = userID
+	}
+}
+
+func SelectScheduledMessagesOptionFilterByDueTimestamp(dueTimestamp int64) SelectScheduledMessagesOption {
+	return func(ops *selectScheduledMessagesOptions) {
+		ops.dueTimestamp = dueTimestamp
+	}
+}
+
+type scheduledMessageStore interface {
+	createScheduledMessageStore()
+
+	InsertScheduledMessage(scheduledMessage *model.ScheduledMessage) error
+	SelectScheduledMessages(limit, offset int32, opts ...SelectScheduledMessagesOption) ([]*model.ScheduledMessage, error)
+	SelectCountScheduledMessages(opts ...SelectScheduledMessagesOption) (int64, error)
+	SelectScheduledMessage(scheduledMessageID string) (*model.ScheduledMessage, error)
+	UpdateScheduledMessage(scheduledMessage *model.ScheduledMessage) error
+}
@@ -0,0 +1,24 @@
+package datastore
+
+import (
+	"testing"
+)
+
+const (
+	TestStoreSelectLatestSetting = "[store] select latest setting test"
+)
+
+func testSettingStore(t *testing.T) {
+	t.Run(TestStoreSelectLatestSetting, func(t *testing.T) {
+		setting, err := Provider(ctx).SelectLatestSetting()
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectLatestSetting, err.Error())
+		}
+		if setting == nil {
+			t.Fatalf("Failed to %s. Expected the first setting to be not nil, but it was nil", TestStoreSelectLatestSetting)
+		}
+		if string(setting.Values) != "{}" {
+			t.Fatalf("Failed to %s. Expected setting.Values to be {}, but it was %s", TestStoreSelectLatestSetting, string(setting.Values))
+		}
+	})
+}
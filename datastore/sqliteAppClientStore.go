@@ -8,11 +8,11 @@ func (p *sqliteProvider) createAppClientStore() {
 }
 
 func (p *sqliteProvider) InsertAppClient(appClient *model.AppClient) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	return rdbInsertAppClient(p.ctx, master, appClient)
 }
 
 func (p *sqliteProvider) SelectLatestAppClient(opts ...SelectAppClientOption) (*model.AppClient, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectLatestAppClient(p.ctx, replica, opts...)
 }
@@ -0,0 +1,11 @@
+//go:build cgo && !purego
+// +build cgo,!purego
+
+package datastore
+
+import (
+	// mattn/go-sqlite3 is used when cgo is available
+	_ "github.com/mattn/go-sqlite3"
+)
+
+const sqliteDriverName = "sqlite3"
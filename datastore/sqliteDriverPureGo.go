@@ -0,0 +1,12 @@
+//go:build !cgo || purego
+// +build !cgo purego
+
+package datastore
+
+import (
+	// modernc.org/sqlite is a cgo free SQLite for static binaries and the tests without cgo.
+	// It's used with the purego build tag as well.
+	_ "modernc.org/sqlite"
+)
+
+const sqliteDriverName = "sqlite"
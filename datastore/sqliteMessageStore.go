@@ -11,8 +11,8 @@ func (p *sqliteProvider) createMessageStore() {
 	rdbCreateMessageStore(p.ctx, master)
 }
 
-func (p *sqliteProvider) InsertMessage(message *model.Message) error {
-	master := RdbStore(p.database).master()
+func (p *sqliteProvider) InsertMessage(message *model.Message, opts ...InsertMessageOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -20,7 +20,7 @@ func (p *sqliteProvider) InsertMessage(message *model.Message) error {
 		return err
 	}
 
-	err = rdbInsertMessage(p.ctx, master, tx, message)
+	err = rdbInsertMessage(p.ctx, master, tx, message, opts...)
 	if err != nil {
 		tx.Rollback()
 		return err
@@ -38,21 +38,47 @@ func (p *sqliteProvider) InsertMessage(message *model.Message) error {
 }
 
 func (p *sqliteProvider) SelectMessages(limit, offset int32, opts ...SelectMessagesOption) ([]*model.Message, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectMessages(p.ctx, replica, limit, offset, opts...)
 }
 
 func (p *sqliteProvider) SelectMessage(messageID string) (*model.Message, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectMessage(p.ctx, replica, messageID)
 }
 
 func (p *sqliteProvider) SelectCountMessages(opts ...SelectMessagesOption) (int64, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectCountMessages(p.ctx, replica, opts...)
 }
 
 func (p *sqliteProvider) UpdateMessage(message *model.Message) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	return rdbUpdateMessage(p.ctx, master, message)
 }
+
+func (p *sqliteProvider) DeleteMessages(opts ...DeleteMessagesOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbDeleteMessages(p.ctx, master, tx, opts...)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while deleting messages")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
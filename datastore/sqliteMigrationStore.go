@@ -0,0 +1,13 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *sqliteProvider) MigrateDown(steps int) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbMigrateDown(p.ctx, master, steps)
+}
+
+func (p *sqliteProvider) SelectSchemaMigrations() ([]*model.SchemaMigration, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbSelectSchemaMigrations(p.ctx, master)
+}
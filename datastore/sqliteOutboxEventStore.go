@@ -0,0 +1,28 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+func (p *sqliteProvider) createOutboxEventStore() {
+	master := RdbStore(p.database).master()
+	rdbCreateOutboxEventStore(p.ctx, master)
+}
+
+func (p *sqliteProvider) SelectOutboxEvents(limit int32, opts ...SelectOutboxEventsOption) ([]*model.OutboxEvent, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectOutboxEvents(p.ctx, replica, limit, opts...)
+}
+
+func (p *sqliteProvider) ClaimOutboxEvent(outboxEvent *model.OutboxEvent, leaseTimestamp int64) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbClaimOutboxEvent(p.ctx, master, outboxEvent, leaseTimestamp)
+}
+
+func (p *sqliteProvider) UpdateOutboxEvent(outboxEvent *model.OutboxEvent) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbUpdateOutboxEvent(p.ctx, master, outboxEvent)
+}
+
+func (p *sqliteProvider) DeleteOutboxEvents(opts ...DeleteOutboxEventsOption) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbDeleteOutboxEvents(p.ctx, master, opts...)
+}
@@ -26,7 +26,7 @@ type sqliteProvider struct {
 }
 
 func (p *sqliteProvider) Connect(dsCfg *config.Datastore) error {
-	if _, ok := rdbStores[dsCfg.Database]; ok {
+	if RdbStore(dsCfg.Database) != nil {
 		return nil
 	}
 
@@ -41,7 +41,7 @@ func (p *sqliteProvider) Connect(dsCfg *config.Datastore) error {
 	} else {
 		ds = fmt.Sprintf("%s/%s.db?cache=shared", p.dirPath, p.database)
 	}
-	db, err := sql.Open("sqlite3", ds)
+	db, err := sql.Open(sqliteDriverName, ds)
 	if err != nil {
 		err = errors.Wrap(err, fmt.Sprintf("Failed to connect database. %s %s", dsCfg.Provider, ds))
 		logger.Error(err.Error())
@@ -60,26 +60,67 @@ func (p *sqliteProvider) Connect(dsCfg *config.Datastore) error {
 	}
 	rs.setMaster(master)
 
-	rdbStores[dsCfg.Database] = rs
+	rdbStores.add(dsCfg.Database, rs)
 	return nil
 }
 
-func (p *sqliteProvider) CreateTables() {
+func (p *sqliteProvider) CreateTables() error {
+	p.mapTables()
+
+	master := RdbStore(p.database).master()
+	return rdbCreateTables(p.ctx, master)
+}
+
+func (p *sqliteProvider) mapTables() {
 	p.createAppClientStore()
 	p.createAssetStore()
+	p.createAuditLogStore()
 	p.createBlockUserStore()
 	p.createDeviceStore()
+	p.createMentionStore()
 	p.createMessageStore()
+	p.createOutboxEventStore()
+	p.createPinnedMessageStore()
 	p.createRoomStore()
+	p.createRoomInvitationStore()
+	p.createRoomInviteLinkStore()
 	p.createRoomUserStore()
+	p.createScheduledMessageStore()
 	p.createSettingStore()
 	p.createSubscriptionStore()
 	p.createUserStore()
 	p.createUserRoleStore()
 	p.createWebhookStore()
+	p.createWorkspaceStore()
+}
+
+// CreateDatabase only confirms that the database does not exist because SQLite creates the database file when it's connected.
+// It returns false if it exists.
+func (p *sqliteProvider) CreateDatabase() (bool, error) {
+	if p.onMemory {
+		return RdbStore(p.database) == nil, nil
+	}
+
+	dbPath := fmt.Sprintf("%s/%s.db", p.dirPath, p.database)
+	_, err := os.Stat(dbPath)
+	if err == nil {
+		return false, nil
+	}
+	if !os.IsNotExist(err) {
+		err = errors.Wrap(err, "Create database failure")
+		logger.Error(err.Error())
+		return false, err
+	}
+	return true, nil
 }
 
 func (p *sqliteProvider) DropDatabase() error {
+	rdbStores.remove(p.database)
+	migratedDatabases.Delete(p.database)
+	if p.onMemory {
+		return nil
+	}
+
 	dbPath := fmt.Sprintf("%s/%s.db", p.dirPath, p.database)
 	if err := os.Remove(dbPath); err != nil {
 		err = errors.Wrap(err, "Drop database failure")
@@ -90,13 +131,5 @@ func (p *sqliteProvider) DropDatabase() error {
 }
 
 func (p *sqliteProvider) Close() {
-	for database, rdbStore := range rdbStores {
-		if rdbStore != nil {
-			master := rdbStore.masterDbMap
-			if master != nil {
-				close(database, master.Db)
-			}
-			delete(rdbStores, database)
-		}
-	}
+	rdbStores.removeAll()
 }
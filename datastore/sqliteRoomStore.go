@@ -12,7 +12,7 @@ func (p *sqliteProvider) createRoomStore() {
 }
 
 func (p *sqliteProvider) InsertRoom(room *model.Room, opts ...InsertRoomOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -38,22 +38,32 @@ func (p *sqliteProvider) InsertRoom(room *model.Room, opts ...InsertRoomOption)
 }
 
 func (p *sqliteProvider) SelectRooms(limit, offset int32, opts ...SelectRoomsOption) ([]*model.Room, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRooms(p.ctx, replica, limit, offset, opts...)
 }
 
 func (p *sqliteProvider) SelectRoom(roomID string, opts ...SelectRoomOption) (*model.Room, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectRoom(p.ctx, replica, roomID, opts...)
 }
 
 func (p *sqliteProvider) SelectCountRooms(opts ...SelectRoomsOption) (int64, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectCountRooms(p.ctx, replica, opts...)
 }
 
+func (p *sqliteProvider) SelectPublicRooms(limit, offset int32, opts ...SelectPublicRoomsOption) ([]*model.PublicRoom, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectPublicRooms(p.ctx, replica, limit, offset, opts...)
+}
+
+func (p *sqliteProvider) SelectCountPublicRooms(opts ...SelectPublicRoomsOption) (int64, error) {
+	replica := RdbStore(p.database).replicaFor(p.ctx)
+	return rdbSelectCountPublicRooms(p.ctx, replica, opts...)
+}
+
 func (p *sqliteProvider) UpdateRoom(room *model.Room, opts ...UpdateRoomOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user roles")
@@ -77,3 +87,90 @@ func (p *sqliteProvider) UpdateRoom(room *model.Room, opts ...UpdateRoomOption)
 
 	return nil
 }
+
+func (p *sqliteProvider) UpdateRoomOwner(room *model.Room, userID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomOwner(p.ctx, master, tx, room, userID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room owner")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *sqliteProvider) UpdateRoomLastMessage(roomID string) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	err = rdbUpdateRoomLastMessage(p.ctx, master, tx, roomID)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while updating room last message")
+		logger.Error(err.Error())
+		return err
+	}
+
+	return nil
+}
+
+func (p *sqliteProvider) RestoreRoom(room *model.Room) (bool, error) {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	tx, err := master.Begin()
+	if err != nil {
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	restored, err := rdbRestoreRoom(p.ctx, master, tx, room)
+	if err != nil {
+		tx.Rollback()
+		return false, err
+	}
+	if !restored {
+		tx.Rollback()
+		return false, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		tx.Rollback()
+		err = errors.Wrap(err, "An error occurred while restoring room")
+		logger.Error(err.Error())
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (p *sqliteProvider) PurgeDeletedRoomUsers(deletedTimestamp int64) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbPurgeDeletedRoomUsers(p.ctx, master, deletedTimestamp)
+}
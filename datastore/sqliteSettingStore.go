@@ -8,6 +8,6 @@ func (p *sqliteProvider) createSettingStore() {
 }
 
 func (p *sqliteProvider) SelectLatestSetting() (*model.Setting, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectLatestSetting(p.ctx, replica)
 }
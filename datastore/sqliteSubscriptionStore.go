@@ -13,22 +13,22 @@ func (p *sqliteProvider) createSubscriptionStore() {
 }
 
 func (p *sqliteProvider) InsertSubscription(room *model.Subscription) (*model.Subscription, error) {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	return rdbInsertSubscription(p.ctx, master, room)
 }
 
 func (p *sqliteProvider) SelectSubscription(roomID, userID string, platform scpb.Platform) (*model.Subscription, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectSubscription(p.ctx, replica, roomID, userID, platform)
 }
 
 func (p *sqliteProvider) SelectDeletedSubscriptions(opts ...SelectDeletedSubscriptionsOption) ([]*model.Subscription, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectDeletedSubscriptions(p.ctx, replica, opts...)
 }
 
 func (p *sqliteProvider) DeleteSubscriptions(opts ...DeleteSubscriptionsOption) error {
-	master := RdbStore(p.database).master()
+	master := RdbStore(p.database).masterFor(p.ctx)
 	tx, err := master.Begin()
 	if err != nil {
 		err = errors.Wrap(err, "An error occurred while inserting user")
@@ -7,7 +7,12 @@ func (p *sqliteProvider) createWebhookStore() {
 	rdbCreateWebhookStore(p.ctx, master)
 }
 
+func (p *sqliteProvider) InsertWebhook(webhook *model.Webhook) error {
+	master := RdbStore(p.database).masterFor(p.ctx)
+	return rdbInsertWebhook(p.ctx, master, webhook)
+}
+
 func (p *sqliteProvider) SelectWebhooks(event model.WebhookEventType, opts ...SelectWebhooksOption) ([]*model.Webhook, error) {
-	replica := RdbStore(p.database).replica()
+	replica := RdbStore(p.database).replicaFor(p.ctx)
 	return rdbSelectWebhooks(p.ctx, replica, event, opts...)
 }
@@ -0,0 +1,156 @@
+package datastore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestStoreInsertSubscription         = "[store] insert subscription test"
+	TestStoreSelectSubscription         = "[store] select subscription test"
+	TestStoreDeleteSubscriptions        = "[store] delete subscriptions test"
+	TestStoreSelectDeletedSubscriptions = "[store] select deleted subscriptions test"
+	TestStoreTearDownSubscription       = "[store] tear down subscription"
+)
+
+func testSubscriptionStore(t *testing.T) {
+	testUserID := "subscription-store-user-id-0001"
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestStoreInsertSubscription, func(t *testing.T) {
+		subscriptions := []*model.Subscription{
+			{
+				RoomID:                     "subscription-store-room-id-0001",
+				UserID:                     testUserID,
+				Platform:                   scpb.Platform_PlatformIos,
+				NotificationSubscriptionID: "subscription-store-notification-id-0001",
+			},
+			{
+				RoomID:                     "subscription-store-room-id-0001",
+				UserID:                     testUserID,
+				Platform:                   scpb.Platform_PlatformAndroid,
+				NotificationSubscriptionID: "subscription-store-notification-id-0002",
+			},
+			{
+				RoomID:                     "subscription-store-room-id-0002",
+				UserID:                     testUserID,
+				Platform:                   scpb.Platform_PlatformIos,
+				NotificationSubscriptionID: "subscription-store-notification-id-0003",
+			},
+		}
+		for _, subscription := range subscriptions {
+			_, err := Provider(ctx).InsertSubscription(subscription)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertSubscription, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestStoreSelectSubscription, func(t *testing.T) {
+		subscription, err := Provider(ctx).SelectSubscription("subscription-store-room-id-0001", testUserID, scpb.Platform_PlatformAndroid)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectSubscription, err.Error())
+		}
+		if subscription == nil {
+			t.Fatalf("Failed to %s. Expected subscription to be not nil, but it was nil", TestStoreSelectSubscription)
+		}
+		if subscription.NotificationSubscriptionID != "subscription-store-notification-id-0002" {
+			t.Fatalf("Failed to %s. Expected subscription.NotificationSubscriptionID to be \"subscription-store-notification-id-0002\", but it was \"%s\"", TestStoreSelectSubscription, subscription.NotificationSubscriptionID)
+		}
+
+		subscription, err = Provider(ctx).SelectSubscription("subscription-store-room-id-0002", testUserID, scpb.Platform_PlatformAndroid)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectSubscription, err.Error())
+		}
+		if subscription != nil {
+			t.Fatalf("Failed to %s. Expected subscription to be nil, but it was not nil", TestStoreSelectSubscription)
+		}
+	})
+
+	t.Run(TestStoreDeleteSubscriptions, func(t *testing.T) {
+		err := Provider(ctx).DeleteSubscriptions(
+			DeleteSubscriptionsOptionWithLogicalDeleted(nowTimestamp),
+			DeleteSubscriptionsOptionFilterByRoomID("subscription-store-room-id-0001"),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteSubscriptions, err.Error())
+		}
+
+		subscription, err := Provider(ctx).SelectSubscription("subscription-store-room-id-0001", testUserID, scpb.Platform_PlatformIos)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreDeleteSubscriptions, err.Error())
+		}
+		if subscription != nil {
+			t.Fatalf("Failed to %s. Expected the deleted subscription to be nil, but it was not nil", TestStoreDeleteSubscriptions)
+		}
+
+		err = Provider(ctx).DeleteSubscriptions()
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil without roomId and userId, but it was nil", TestStoreDeleteSubscriptions)
+		}
+
+		err = Provider(ctx).DeleteSubscriptions(
+			DeleteSubscriptionsOptionFilterByRoomID("subscription-store-room-id-0001"),
+			DeleteSubscriptionsOptionFilterByPlatform(scpb.Platform_PlatformIos),
+		)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil with both roomId and platform, but it was nil", TestStoreDeleteSubscriptions)
+		}
+	})
+
+	t.Run(TestStoreSelectDeletedSubscriptions, func(t *testing.T) {
+		subscriptions, err := Provider(ctx).SelectDeletedSubscriptions(
+			SelectDeletedSubscriptionsOptionFilterByRoomID("subscription-store-room-id-0001"),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectDeletedSubscriptions, err.Error())
+		}
+		if len(subscriptions) != 2 {
+			t.Fatalf("Failed to %s. Expected subscriptions count to be 2, but it was %d", TestStoreSelectDeletedSubscriptions, len(subscriptions))
+		}
+
+		subscriptions, err = Provider(ctx).SelectDeletedSubscriptions(
+			SelectDeletedSubscriptionsOptionFilterByUserID(testUserID),
+			SelectDeletedSubscriptionsOptionFilterByPlatform(scpb.Platform_PlatformAndroid),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectDeletedSubscriptions, err.Error())
+		}
+		if len(subscriptions) != 1 {
+			t.Fatalf("Failed to %s. Expected subscriptions count to be 1, but it was %d", TestStoreSelectDeletedSubscriptions, len(subscriptions))
+		}
+		if subscriptions[0].NotificationSubscriptionID != "subscription-store-notification-id-0002" {
+			t.Fatalf("Failed to %s. Expected subscriptions[0].NotificationSubscriptionID to be \"subscription-store-notification-id-0002\", but it was \"%s\"", TestStoreSelectDeletedSubscriptions, subscriptions[0].NotificationSubscriptionID)
+		}
+
+		_, err = Provider(ctx).SelectDeletedSubscriptions(
+			SelectDeletedSubscriptionsOptionFilterByRoomID("subscription-store-room-id-0001"),
+			SelectDeletedSubscriptionsOptionFilterByUserID(testUserID),
+		)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected err to be not nil with both roomId and userId, but it was nil", TestStoreSelectDeletedSubscriptions)
+		}
+	})
+
+	t.Run(TestStoreTearDownSubscription, func(t *testing.T) {
+		err := Provider(ctx).DeleteSubscriptions(
+			DeleteSubscriptionsOptionFilterByUserID(testUserID),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownSubscription, err.Error())
+		}
+
+		subscriptions, err := Provider(ctx).SelectDeletedSubscriptions(
+			SelectDeletedSubscriptionsOptionFilterByUserID(testUserID),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownSubscription, err.Error())
+		}
+		if len(subscriptions) != 0 {
+			t.Fatalf("Failed to %s. Expected subscriptions count to be 0, but it was %d", TestStoreTearDownSubscription, len(subscriptions))
+		}
+	})
+}
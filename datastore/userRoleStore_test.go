@@ -16,7 +16,7 @@ const (
 	TestStoreTearDownUserRole        = "[store] tear down userRole"
 )
 
-func TestUserRoleStore(t *testing.T) {
+func testUserRoleStore(t *testing.T) {
 	var err error
 
 	t.Run(TestStoreSetUpUserRole, func(t *testing.T) {
@@ -100,6 +100,19 @@ func UpdateUserOptionMarkAllAsRead(markAllAsRead bool) UpdateUserOption {
 	}
 }
 
+type EraseUserOption func(*eraseUserOptions)
+
+type eraseUserOptions struct {
+	deleteMessages bool
+}
+
+// EraseUserOptionDeleteMessages deletes the messages of the user instead of attributing them to model.ErasedUserID
+func EraseUserOptionDeleteMessages(deleteMessages bool) EraseUserOption {
+	return func(ops *eraseUserOptions) {
+		ops.deleteMessages = deleteMessages
+	}
+}
+
 type userStore interface {
 	createUserStore()
 
@@ -109,6 +122,9 @@ type userStore interface {
 	SelectCountUsers() (int64, error)
 	SelectUserIDsOfUser(userIDs []string) ([]string, error)
 	UpdateUser(user *model.User, opts ...UpdateUserOption) error
+	// EraseUser deletes the user and all of the data of the user physically.
+	// The subscriptions are deleted logically to be unsubscribed from the notification afterwards.
+	EraseUser(user *model.User, opts ...EraseUserOption) error
 
 	SelectContacts(userID string, limit, offset int32, opts ...SelectContactsOption) ([]*model.User, error)
 }
@@ -21,7 +21,7 @@ const (
 	TestStoreTearDownUser        = "[store] tear down user"
 )
 
-func TestUserStore(t *testing.T) {
+func testUserStore(t *testing.T) {
 	var user *model.User
 	var err error
 
@@ -35,5 +35,6 @@ func SelectWebhooksOptionWithRole(roleID int32) SelectWebhooksOption {
 type webhookStore interface {
 	createWebhookStore()
 
+	InsertWebhook(webhook *model.Webhook) error
 	SelectWebhooks(event model.WebhookEventType, opts ...SelectWebhooksOption) ([]*model.Webhook, error)
 }
@@ -0,0 +1,116 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreInsertWebhook   = "[store] insert webhook test"
+	TestStoreSelectWebhooks  = "[store] select webhooks test"
+	TestStoreTearDownWebhook = "[store] tear down webhook"
+)
+
+func testWebhookStore(t *testing.T) {
+	nowTimestamp := time.Now().Unix()
+
+	webhooks := []*model.Webhook{
+		{
+			WebhookID: "webhook-store-webhook-id-0001",
+			Event:     model.WebhookEventTypeMessage,
+			RoomID:    "webhook-store-room-id-0001",
+			RoleID:    config.RoleGeneral,
+			Protocol:  model.WebhookProtocolHTTP,
+			Endpoint:  "http://localhost/webhook-0001",
+			Created:   nowTimestamp,
+			Modified:  nowTimestamp,
+		},
+		{
+			WebhookID: "webhook-store-webhook-id-0002",
+			Event:     model.WebhookEventTypeMessage,
+			RoomID:    "webhook-store-room-id-0002",
+			RoleID:    config.RoleGeneral,
+			Protocol:  model.WebhookProtocolHTTP,
+			Endpoint:  "http://localhost/webhook-0002",
+			Created:   nowTimestamp,
+			Modified:  nowTimestamp,
+		},
+		{
+			WebhookID: "webhook-store-webhook-id-0003",
+			Event:     model.WebhookEventTypeRoom,
+			RoomID:    "webhook-store-room-id-0001",
+			Protocol:  model.WebhookProtocolHTTP,
+			Endpoint:  "http://localhost/webhook-0003",
+			Created:   nowTimestamp,
+			Modified:  nowTimestamp,
+		},
+		{
+			WebhookID: "webhook-store-webhook-id-0004",
+			Event:     model.WebhookEventTypeMessage,
+			RoomID:    "webhook-store-room-id-0001",
+			RoleID:    config.RoleGeneral,
+			Protocol:  model.WebhookProtocolHTTP,
+			Endpoint:  "http://localhost/webhook-0004",
+			Created:   nowTimestamp,
+			Modified:  nowTimestamp,
+			Deleted:   nowTimestamp,
+		},
+	}
+
+	t.Run(TestStoreInsertWebhook, func(t *testing.T) {
+		for _, webhook := range webhooks {
+			err := Provider(ctx).InsertWebhook(webhook)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreInsertWebhook, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestStoreSelectWebhooks, func(t *testing.T) {
+		selectedWebhooks, err := Provider(ctx).SelectWebhooks(model.WebhookEventTypeMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectWebhooks, err.Error())
+		}
+		if len(selectedWebhooks) != 2 {
+			t.Fatalf("Failed to %s. Expected webhooks count to be 2, but it was %d", TestStoreSelectWebhooks, len(selectedWebhooks))
+		}
+
+		selectedWebhooks, err = Provider(ctx).SelectWebhooks(
+			model.WebhookEventTypeMessage,
+			SelectWebhooksOptionWithRoomID("webhook-store-room-id-0001"),
+			SelectWebhooksOptionWithRole(config.RoleGeneral),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectWebhooks, err.Error())
+		}
+		if len(selectedWebhooks) != 1 {
+			t.Fatalf("Failed to %s. Expected webhooks count to be 1, but it was %d", TestStoreSelectWebhooks, len(selectedWebhooks))
+		}
+		if selectedWebhooks[0].WebhookID != "webhook-store-webhook-id-0001" {
+			t.Fatalf("Failed to %s. Expected webhookId to be \"webhook-store-webhook-id-0001\", but it was \"%s\"", TestStoreSelectWebhooks, selectedWebhooks[0].WebhookID)
+		}
+
+		selectedWebhooks, err = Provider(ctx).SelectWebhooks(model.WebhookEventTypeRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreSelectWebhooks, err.Error())
+		}
+		if len(selectedWebhooks) != 1 {
+			t.Fatalf("Failed to %s. Expected webhooks count to be 1, but it was %d", TestStoreSelectWebhooks, len(selectedWebhooks))
+		}
+	})
+
+	t.Run(TestStoreTearDownWebhook, func(t *testing.T) {
+		master := RdbStore(config.Config().Datastore.Database).master()
+		query := fmt.Sprintf("DELETE FROM %s WHERE webhook_id=?;", tableNameWebhook)
+		for _, webhook := range webhooks {
+			_, err := master.Exec(rebind(master, query), webhook.WebhookID)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestStoreTearDownWebhook, err.Error())
+			}
+		}
+	})
+}
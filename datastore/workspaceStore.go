@@ -0,0 +1,13 @@
+package datastore
+
+import "github.com/swagchat/chat-api/model"
+
+type workspaceStore interface {
+	createWorkspaceStore()
+
+	InsertWorkspace(workspace *model.Workspace) error
+	SelectWorkspaces(limit, offset int32) ([]*model.Workspace, error)
+	SelectCountWorkspaces() (int64, error)
+	SelectWorkspace(name string) (*model.Workspace, error)
+	DeleteWorkspace(name string) error
+}
@@ -0,0 +1,188 @@
+package datastore
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestStoreInsertWorkspace          = "[store] insert workspace test"
+	TestStoreSelectWorkspaces         = "[store] select workspaces test"
+	TestStoreSelectCountWorkspaces    = "[store] select count workspaces test"
+	TestStoreSelectWorkspace          = "[store] select workspace test"
+	TestStoreDeleteWorkspace          = "[store] delete workspace test"
+	TestStoreRdbStorePoolEvictLRU     = "[store] rdb store pool evict least recently used test"
+	TestStoreRdbStorePoolEvictIdle    = "[store] rdb store pool evict idle test"
+	TestStoreRdbStorePoolAddDuplicate = "[store] rdb store pool add duplicate test"
+	TestStoreRdbStorePoolHold         = "[store] rdb store pool hold test"
+	TestStoreRdbStorePoolCloseEvicted = "[store] rdb store pool close evicted test"
+)
+
+func testWorkspaceStore(t *testing.T) {
+	t.Run(TestStoreInsertWorkspace, func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			workspace := &model.Workspace{
+				Name:    fmt.Sprintf("workspace_store_%04d", i),
+				Created: time.Now().Unix(),
+			}
+			err := Provider(ctx).InsertWorkspace(workspace)
+			if err != nil {
+				t.Fatalf("Failed to %s. %s", TestStoreInsertWorkspace, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestStoreSelectWorkspaces, func(t *testing.T) {
+		workspaces, err := Provider(ctx).SelectWorkspaces(2, 1)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectWorkspaces, err.Error())
+		}
+		if len(workspaces) != 2 {
+			t.Fatalf("Failed to %s. Expected workspaces count to be 2, but it was %d", TestStoreSelectWorkspaces, len(workspaces))
+		}
+		if workspaces[0].Name != "workspace_store_0002" {
+			t.Fatalf("Failed to %s. Expected name to be workspace_store_0002, but it was %s", TestStoreSelectWorkspaces, workspaces[0].Name)
+		}
+	})
+
+	t.Run(TestStoreSelectCountWorkspaces, func(t *testing.T) {
+		count, err := Provider(ctx).SelectCountWorkspaces()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectCountWorkspaces, err.Error())
+		}
+		if count != 3 {
+			t.Fatalf("Failed to %s. Expected count to be 3, but it was %d", TestStoreSelectCountWorkspaces, count)
+		}
+	})
+
+	t.Run(TestStoreSelectWorkspace, func(t *testing.T) {
+		workspace, err := Provider(ctx).SelectWorkspace("workspace_store_0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectWorkspace, err.Error())
+		}
+		if workspace == nil {
+			t.Fatalf("Failed to %s. Expected workspace to be not nil, but it was nil", TestStoreSelectWorkspace)
+		}
+
+		workspace, err = Provider(ctx).SelectWorkspace("not_exist_workspace")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreSelectWorkspace, err.Error())
+		}
+		if workspace != nil {
+			t.Fatalf("Failed to %s. Expected workspace to be nil, but it was not nil", TestStoreSelectWorkspace)
+		}
+	})
+
+	t.Run(TestStoreDeleteWorkspace, func(t *testing.T) {
+		for i := 1; i <= 3; i++ {
+			err := Provider(ctx).DeleteWorkspace(fmt.Sprintf("workspace_store_%04d", i))
+			if err != nil {
+				t.Fatalf("Failed to %s. %s", TestStoreDeleteWorkspace, err.Error())
+			}
+		}
+
+		count, err := Provider(ctx).SelectCountWorkspaces()
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestStoreDeleteWorkspace, err.Error())
+		}
+		if count != 0 {
+			t.Fatalf("Failed to %s. Expected count to be 0, but it was %d", TestStoreDeleteWorkspace, count)
+		}
+	})
+}
+
+func TestRdbStorePool(t *testing.T) {
+	// On memory SQLite is never evicted, so the pool is tested as the other datastores
+	dsCfg := config.Config().Datastore
+	onMemory := dsCfg.SQLite.OnMemory
+	poolSize := dsCfg.ConnectionPoolSize
+	dsCfg.SQLite.OnMemory = false
+	dsCfg.ConnectionPoolSize = 2
+	defer func() {
+		dsCfg.SQLite.OnMemory = onMemory
+		dsCfg.ConnectionPoolSize = poolSize
+	}()
+
+	t.Run(TestStoreRdbStorePoolEvictLRU, func(t *testing.T) {
+		pool := newRdbStorePool()
+		pool.add("db1", &rdbStore{})
+		pool.add("db2", &rdbStore{})
+		pool.get("db1")
+		pool.add("db3", &rdbStore{})
+
+		if pool.get("db2") != nil {
+			t.Fatalf("Failed to %s. Expected db2 to be evicted", TestStoreRdbStorePoolEvictLRU)
+		}
+		if pool.get("db1") == nil || pool.get("db3") == nil {
+			t.Fatalf("Failed to %s. Expected db1 and db3 to remain", TestStoreRdbStorePoolEvictLRU)
+		}
+	})
+
+	t.Run(TestStoreRdbStorePoolEvictIdle, func(t *testing.T) {
+		pool := newRdbStorePool()
+		pool.add("db1", &rdbStore{})
+		pool.add("db2", &rdbStore{})
+		pool.index["db1"].Value.(*rdbStorePoolEntry).lastAccessed = time.Now().Add(-time.Hour)
+
+		pool.removeIdle(time.Minute)
+
+		if pool.get("db1") != nil {
+			t.Fatalf("Failed to %s. Expected db1 to be evicted", TestStoreRdbStorePoolEvictIdle)
+		}
+		if pool.get("db2") == nil {
+			t.Fatalf("Failed to %s. Expected db2 to remain", TestStoreRdbStorePoolEvictIdle)
+		}
+	})
+
+	t.Run(TestStoreRdbStorePoolAddDuplicate, func(t *testing.T) {
+		pool := newRdbStorePool()
+		rs := &rdbStore{}
+		pool.add("db1", rs)
+		if pool.add("db1", &rdbStore{}) != rs {
+			t.Fatalf("Failed to %s. Expected the added store to be returned", TestStoreRdbStorePoolAddDuplicate)
+		}
+	})
+
+	t.Run(TestStoreRdbStorePoolHold, func(t *testing.T) {
+		pool := newRdbStorePool()
+		pool.add("db1", &rdbStore{})
+		pool.add("db2", &rdbStore{})
+		rs := pool.hold("db1")
+		pool.get("db2")
+		pool.add("db3", &rdbStore{})
+
+		if pool.get("db1") == nil {
+			t.Fatalf("Failed to %s. Expected held db1 not to be evicted", TestStoreRdbStorePoolHold)
+		}
+		if pool.get("db2") != nil {
+			t.Fatalf("Failed to %s. Expected db2 to be evicted instead of db1", TestStoreRdbStorePoolHold)
+		}
+
+		rs.refs--
+		pool.index["db1"].Value.(*rdbStorePoolEntry).lastAccessed = time.Now().Add(-time.Hour)
+		pool.removeIdle(time.Minute)
+		if pool.get("db1") != nil {
+			t.Fatalf("Failed to %s. Expected released db1 to be evicted", TestStoreRdbStorePoolHold)
+		}
+	})
+
+	t.Run(TestStoreRdbStorePoolCloseEvicted, func(t *testing.T) {
+		pool := newRdbStorePool()
+		pool.add("db1", &rdbStore{})
+		pool.add("db2", &rdbStore{})
+		pool.add("db3", &rdbStore{})
+
+		if len(pool.evicted) != 1 {
+			t.Fatalf("Failed to %s. Expected evicted db1 to wait to be closed, but %d databases were waiting", TestStoreRdbStorePoolCloseEvicted, len(pool.evicted))
+		}
+
+		pool.closeEvicted(time.Now().Add(config.CloseEvictedConnectionsDelaySecond * time.Second))
+		if len(pool.evicted) != 0 {
+			t.Fatalf("Failed to %s. Expected evicted db1 to be closed after the grace period", TestStoreRdbStorePoolCloseEvicted)
+		}
+	})
+}
@@ -0,0 +1,57 @@
+package grpc
+
+import (
+	"context"
+
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+	"google.golang.org/grpc"
+)
+
+// auditLogService is the server API for AuditLogService.
+// It is not generated from swagchat/protobuf, so the service descriptor is declared by hand.
+type auditLogService interface {
+	RetrieveAuditLogs(context.Context, *model.RetrieveAuditLogsRequest) (*model.AuditLogsResponse, error)
+}
+
+func auditLogServiceRetrieveAuditLogsHandler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(model.RetrieveAuditLogsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(auditLogService).RetrieveAuditLogs(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/swagchat.protobuf.AuditLogService/RetrieveAuditLogs",
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(auditLogService).RetrieveAuditLogs(ctx, req.(*model.RetrieveAuditLogsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var auditLogServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swagchat.protobuf.AuditLogService",
+	HandlerType: (*auditLogService)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "RetrieveAuditLogs",
+			Handler:    auditLogServiceRetrieveAuditLogsHandler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "auditLogService.proto",
+}
+
+type auditLogServiceServer struct{}
+
+func (s *auditLogServiceServer) RetrieveAuditLogs(ctx context.Context, in *model.RetrieveAuditLogsRequest) (*model.AuditLogsResponse, error) {
+	res, errRes := service.RetrieveAuditLogs(ctx, in)
+	if errRes != nil {
+		return nil, statusError(ctx, errRes)
+	}
+
+	return res, nil
+}
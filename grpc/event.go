@@ -0,0 +1,60 @@
+package grpc
+
+import (
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+	"google.golang.org/grpc"
+)
+
+// eventService is the server API for EventService.
+// It is not generated from swagchat/protobuf, so the service descriptor is declared by hand.
+type eventService interface {
+	SubscribeEvents(*model.SubscribeEventsRequest, eventServiceSubscribeEventsServer) error
+}
+
+type eventServiceSubscribeEventsServer interface {
+	Send(*scpb.EventData) error
+	grpc.ServerStream
+}
+
+type eventServiceSubscribeEventsStream struct {
+	grpc.ServerStream
+}
+
+func (x *eventServiceSubscribeEventsStream) Send(m *scpb.EventData) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func eventServiceSubscribeEventsHandler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(model.SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(eventService).SubscribeEvents(m, &eventServiceSubscribeEventsStream{stream})
+}
+
+var eventServiceDesc = grpc.ServiceDesc{
+	ServiceName: "swagchat.protobuf.EventService",
+	HandlerType: (*eventService)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       eventServiceSubscribeEventsHandler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "eventService.proto",
+}
+
+type eventServiceServer struct{}
+
+func (s *eventServiceServer) SubscribeEvents(in *model.SubscribeEventsRequest, stream eventServiceSubscribeEventsServer) error {
+	errRes := service.SubscribeEvents(stream.Context(), in, stream.Send)
+	if errRes != nil {
+		return statusError(stream.Context(), errRes)
+	}
+
+	return nil
+}
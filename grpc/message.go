@@ -18,7 +18,7 @@ func (s *messageServer) SendMessage(ctx context.Context, in *scpb.SendMessageReq
 			return &scpb.Message{}, err
 		}
 	}
-	req := &model.SendMessageRequest{*in, payload}
+	req := &model.SendMessageRequest{*in, payload, nil, 0, 0}
 	message, errRes := service.SendMessage(ctx, req)
 	if errRes != nil {
 		return &scpb.Message{}, errRes.Error
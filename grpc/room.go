@@ -19,7 +19,7 @@ func (us *roomServiceServer) CreateRoom(ctx context.Context, in *scpb.CreateRoom
 			return &scpb.Room{}, err
 		}
 	}
-	req := &model.CreateRoomRequest{*in, metaData}
+	req := &model.CreateRoomRequest{*in, metaData, nil, nil}
 	room, errRes := service.CreateRoom(ctx, req)
 	if errRes != nil {
 		return &scpb.Room{}, errRes.Error
@@ -59,7 +59,7 @@ func (us *roomServiceServer) UpdateRoom(ctx context.Context, in *scpb.UpdateRoom
 			return &scpb.Room{}, err
 		}
 	}
-	req := &model.UpdateRoomRequest{*in, metaData}
+	req := &model.UpdateRoomRequest{*in, metaData, nil, nil}
 	room, errRes := service.UpdateRoom(ctx, req)
 	if errRes != nil {
 		return &scpb.Room{}, errRes.Error
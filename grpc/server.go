@@ -3,7 +3,10 @@ package grpc
 import (
 	"context"
 	"fmt"
+	"math"
 	"net"
+	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/betchi/tracer"
@@ -11,34 +14,137 @@ import (
 
 	logger "github.com/betchi/zapper"
 	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/i18n"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/ratelimiter"
+	"github.com/swagchat/chat-api/service"
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
 )
 
-func unaryServerInterceptor() grpc.UnaryServerInterceptor {
-	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		workspace := ""
-
-		headers, ok := metadata.FromIncomingContext(ctx)
-		if ok {
-			if v, ok := headers[strings.ToLower(config.HeaderWorkspace)]; ok {
-				if len(v) > 0 {
-					workspace = v[0]
-				}
+func workspaceContext(ctx context.Context) context.Context {
+	workspace := ""
+
+	headers, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if v, ok := headers[strings.ToLower(config.HeaderWorkspace)]; ok {
+			if len(v) > 0 {
+				workspace = v[0]
 			}
 		}
+	}
+
+	if workspace == "" {
+		workspace = config.Config().Datastore.Database
+	}
+
+	return context.WithValue(ctx, config.CtxWorkspace, workspace)
+}
+
+// stickinessContext makes the reads go to master after the user writes so that the user reads own writes
+func stickinessContext(ctx context.Context) context.Context {
+	requester := ""
+
+	headers, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if v, ok := headers[strings.ToLower(config.HeaderUserID)]; ok && len(v) > 0 {
+			requester = v[0]
+		} else if v, ok := headers[strings.ToLower(config.HeaderClientID)]; ok && len(v) > 0 {
+			requester = v[0]
+		}
+	}
+
+	return datastore.StickToMaster(ctx, requester)
+}
+
+// confirmWorkspace rejects the requests to the workspaces which are not registered in dynamic mode,
+// and the requests with the client ids which are not the app clients of the workspace
+func confirmWorkspace(ctx context.Context) error {
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	errRes := service.AuthenticateWorkspace(ctx, workspace, clientID)
+	if errRes != nil {
+		return statusError(ctx, errRes)
+	}
+	return nil
+}
+
+// requesterContext sets the user id, client id and accept language headers to the context.
+// They are not authenticated because there is no authentication in GRPC, so they are used to identify the requester for
+// the rate limiter and the audit logs.
+func requesterContext(ctx context.Context) context.Context {
+	clientID := ""
+	userID := ""
+	lang := ""
 
-		if workspace == "" {
-			workspace = config.Config().Datastore.Database
+	headers, ok := metadata.FromIncomingContext(ctx)
+	if ok {
+		if v, ok := headers[strings.ToLower(config.HeaderClientID)]; ok && len(v) > 0 {
+			clientID = v[0]
+		}
+		if v, ok := headers[strings.ToLower(config.HeaderUserID)]; ok && len(v) > 0 {
+			userID = v[0]
+		}
+		if v, ok := headers[strings.ToLower(config.HeaderAcceptLanguage)]; ok && len(v) > 0 {
+			lang = i18n.ParseAcceptLanguage(v[0])
 		}
+	}
+
+	ctx = context.WithValue(ctx, config.CtxLang, lang)
+	ctx = context.WithValue(ctx, config.CtxClientID, clientID)
+	return context.WithValue(ctx, config.CtxUserID, userID)
+}
+
+// rateLimit takes a token of the rate limiter for the request.
+func rateLimit(ctx context.Context) error {
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	allowed, retryAfter, err := ratelimiter.Provider(ctx).Take(ratelimiter.Key(workspace, clientID, userID, remoteAddr))
+	if err != nil {
+		// Do not block requests when the rate limiter backend is unavailable
+		logger.Error(err.Error())
+		return nil
+	}
 
-		ctx = context.WithValue(ctx, config.CtxWorkspace, workspace)
+	if !allowed {
+		seconds := int64(math.Ceil(retryAfter.Seconds()))
+		grpc.SetHeader(ctx, metadata.Pairs("retry-after", strconv.FormatInt(seconds, 10)))
+		errRes := model.NewErrorResponse(fmt.Sprintf("Too many requests. Please retry after %d seconds.", seconds), http.StatusTooManyRequests)
+		return statusError(ctx, errRes)
+	}
+
+	return nil
+}
+
+func unaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = workspaceContext(ctx)
+		ctx = requesterContext(ctx)
+		ctx = stickinessContext(ctx)
 
 		ctx, _ = tracer.StartTransaction(ctx, fmt.Sprintf("%s:%v", info.FullMethod, info.Server), "GRPC")
 		defer tracer.CloseTransaction(ctx)
 
+		if err := confirmWorkspace(ctx); err != nil {
+			return nil, err
+		}
+		defer datastore.Hold(ctx)()
+
+		if err := rateLimit(ctx); err != nil {
+			return nil, err
+		}
+
 		reply, err := handler(ctx, req)
 		if err != nil {
 			return nil, err
@@ -48,6 +154,70 @@ func unaryServerInterceptor() grpc.UnaryServerInterceptor {
 	}
 }
 
+type serverStreamWithContext struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStreamWithContext) Context() context.Context {
+	return s.ctx
+}
+
+func streamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := workspaceContext(ss.Context())
+		ctx = requesterContext(ctx)
+		ctx = stickinessContext(ctx)
+
+		ctx, _ = tracer.StartTransaction(ctx, fmt.Sprintf("%s:%v", info.FullMethod, srv), "GRPC")
+		defer tracer.CloseTransaction(ctx)
+
+		if err := confirmWorkspace(ctx); err != nil {
+			return err
+		}
+		defer datastore.Hold(ctx)()
+
+		if err := rateLimit(ctx); err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStreamWithContext{ss, ctx})
+	}
+}
+
+// statusError converts error response to grpc status error.
+// Validation errors have no error struct, so the message and status are used instead.
+// The message is translated into the language of the accept language header.
+func statusError(ctx context.Context, errRes *model.ErrorResponse) error {
+	if errRes.Error != nil {
+		return errRes.Error
+	}
+
+	code := codes.Internal
+	switch errRes.Status {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusUnauthorized:
+		code = codes.Unauthenticated
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict:
+		code = codes.AlreadyExists
+	case http.StatusTooManyRequests:
+		code = codes.ResourceExhausted
+	}
+
+	lang, _ := ctx.Value(config.CtxLang).(string)
+	message := i18n.Translate(lang, errRes.Message)
+	for _, invalidParam := range errRes.InvalidParams {
+		message = fmt.Sprintf("%s %s: %s", message, invalidParam.Name, invalidParam.Reason)
+	}
+
+	return status.Error(code, message)
+}
+
 // Run runs GRPC API server
 func Run(ctx context.Context) {
 	cfg := config.Config()
@@ -57,12 +227,17 @@ func Run(ctx context.Context) {
 		logger.Error(fmt.Sprintf("Failed to serve %s server[GRPC]. %v", config.AppName, err))
 	}
 
-	ops := []grpc.ServerOption{grpc.UnaryInterceptor(unaryServerInterceptor())}
+	ops := []grpc.ServerOption{
+		grpc.UnaryInterceptor(unaryServerInterceptor()),
+		grpc.StreamInterceptor(streamServerInterceptor()),
+	}
 	s := grpc.NewServer(ops...)
 	logger.Info(fmt.Sprintf("Starting %s server[GRPC] on listen tcp :%s", config.AppName, cfg.GRPCPort))
 
+	s.RegisterService(&auditLogServiceDesc, &auditLogServiceServer{})
 	scpb.RegisterBlockUserServiceServer(s, &blockUserServiceServer{})
 	scpb.RegisterDeviceServiceServer(s, &deviceServiceServer{})
+	s.RegisterService(&eventServiceDesc, &eventServiceServer{})
 	scpb.RegisterMessageServiceServer(s, &messageServer{})
 	scpb.RegisterRoomUserServiceServer(s, &roomUserServiceServer{})
 	scpb.RegisterUserServiceServer(s, &userServiceServer{})
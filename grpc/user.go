@@ -80,7 +80,7 @@ func (us *userServiceServer) DeleteUser(ctx context.Context, in *scpb.DeleteUser
 }
 
 func (urs *userServiceServer) RetrieveUserRooms(ctx context.Context, in *scpb.RetrieveUserRoomsRequest) (*scpb.UserRoomsResponse, error) {
-	req := &model.RetrieveUserRoomsRequest{*in}
+	req := &model.RetrieveUserRoomsRequest{RetrieveUserRoomsRequest: *in}
 	res, errRes := service.RetrieveUserRooms(ctx, req)
 	if errRes != nil {
 		return &scpb.UserRoomsResponse{}, errRes.Error
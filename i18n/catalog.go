@@ -0,0 +1,64 @@
+package i18n
+
+// builtinCatalogs returns the catalogs shipped with the server.
+// English has no catalog because the keys are the English texts.
+func builtinCatalogs() map[string]Catalog {
+	return map[string]Catalog{
+		"ja": {
+			// Previews of the messages
+			"Sent an image":            "画像を受信しました",
+			"Sent a file":              "ファイルを受信しました",
+			"Sent a message":           "メッセージを受信しました",
+			"Updated the room members": "ルームのメンバーが更新されました",
+			"Updated the room":         "ルームが更新されました",
+
+			// Push notifications
+			"%s mentioned you": "%sさんからメンションされました",
+
+			// Error messages
+			"Failed to create message.":               "メッセージの作成に失敗しました。",
+			"Failed to retrieve message.":             "メッセージの取得に失敗しました。",
+			"Failed to delete message.":               "メッセージの削除に失敗しました。",
+			"Failed to schedule message.":             "メッセージの予約に失敗しました。",
+			"Failed to pin message.":                  "メッセージのピン留めに失敗しました。",
+			"Failed to unpin message.":                "メッセージのピン留めの解除に失敗しました。",
+			"Failed to retrieve pinned messages.":     "ピン留めされたメッセージの取得に失敗しました。",
+			"Failed to update pinned messages order.": "ピン留めされたメッセージの並び替えに失敗しました。",
+			"Failed to create room.":                  "ルームの作成に失敗しました。",
+			"Failed to retrieve room.":                "ルームの取得に失敗しました。",
+			"Failed to update room.":                  "ルームの更新に失敗しました。",
+			"Failed to delete room.":                  "ルームの削除に失敗しました。",
+			"Failed to archive room.":                 "ルームのアーカイブに失敗しました。",
+			"Failed to unarchive room.":               "ルームのアーカイブの解除に失敗しました。",
+			"Failed to restore room.":                 "ルームの復元に失敗しました。",
+			"Failed to join room.":                    "ルームへの参加に失敗しました。",
+			"Failed to leave room.":                   "ルームからの退出に失敗しました。",
+			"Failed to transfer room owner.":          "ルームのオーナーの変更に失敗しました。",
+			"Failed to retrieve public rooms.":        "公開ルームの取得に失敗しました。",
+			"Failed to create room users.":            "ルームユーザーの追加に失敗しました。",
+			"Failed to retrieve room users.":          "ルームユーザーの取得に失敗しました。",
+			"Failed to update room user.":             "ルームユーザーの更新に失敗しました。",
+			"Failed to delete room users.":            "ルームユーザーの削除に失敗しました。",
+			"Failed to create room invitations.":      "ルームへの招待に失敗しました。",
+			"Failed to retrieve room invitations.":    "ルームへの招待の取得に失敗しました。",
+			"Failed to update room invitation.":       "ルームへの招待の更新に失敗しました。",
+			"Failed to create room join request.":     "ルームへの参加リクエストに失敗しました。",
+			"Failed to create room invite link.":      "招待リンクの作成に失敗しました。",
+			"Failed to retrieve room invite links.":   "招待リンクの取得に失敗しました。",
+			"Failed to delete room invite link.":      "招待リンクの削除に失敗しました。",
+			"Failed to create user.":                  "ユーザーの作成に失敗しました。",
+			"Failed to retrieve user.":                "ユーザーの取得に失敗しました。",
+			"Failed to retrieve users.":               "ユーザーの取得に失敗しました。",
+			"Failed to update user.":                  "ユーザーの更新に失敗しました。",
+			"Failed to delete user.":                  "ユーザーの削除に失敗しました。",
+			"Failed to retrieve user rooms.":          "ルーム一覧の取得に失敗しました。",
+			"Failed to get contacts.":                 "連絡先の取得に失敗しました。",
+			"Failed to create block users.":           "ユーザーのブロックに失敗しました。",
+			"Failed to delete block users.":           "ユーザーのブロックの解除に失敗しました。",
+			"Failed to create device.":                "デバイスの登録に失敗しました。",
+			"Failed to delete devices.":               "デバイスの削除に失敗しました。",
+			"Failed to upload file.":                  "ファイルのアップロードに失敗しました。",
+			"Failed to download file.":                "ファイルのダウンロードに失敗しました。",
+		},
+	}
+}
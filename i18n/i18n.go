@@ -0,0 +1,195 @@
+// Package i18n translates the texts generated by the server, such as the previews of the messages,
+// push notifications, system messages and error messages, into the languages of the users.
+// The keys of the catalogs are the English texts, so the texts which are not translated are shown in English.
+package i18n
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/swagchat/chat-api/config"
+)
+
+// SourceLang is the language of the keys of the catalogs
+const SourceLang = "en"
+
+// Catalog is the translations of the texts keyed by the English texts. The keys can have fmt verbs.
+type Catalog map[string]string
+
+var (
+	catalogsMu sync.RWMutex
+	catalogs   = builtinCatalogs()
+)
+
+// AddCatalog adds the translations of lang. They override the translations already added.
+func AddCatalog(lang string, catalog Catalog) {
+	lang = normalizeLang(lang)
+
+	catalogsMu.Lock()
+	defer catalogsMu.Unlock()
+
+	c, ok := catalogs[lang]
+	if !ok {
+		c = Catalog{}
+		catalogs[lang] = c
+	}
+	for key, text := range catalog {
+		c[key] = text
+	}
+}
+
+// LoadCatalogs loads the catalogs named {lang}.json in the directory, such as ja.json or pt-BR.json.
+// Each catalog is a JSON object of the English texts and their translations.
+func LoadCatalogs(dirPath string) error {
+	if dirPath == "" {
+		return nil
+	}
+
+	files, err := ioutil.ReadDir(dirPath)
+	if err != nil {
+		return errors.Wrap(err, "An error occurred while loading message catalogs")
+	}
+
+	for _, file := range files {
+		if file.IsDir() || filepath.Ext(file.Name()) != ".json" {
+			continue
+		}
+
+		buf, err := ioutil.ReadFile(filepath.Join(dirPath, file.Name()))
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("An error occurred while loading message catalog [%s]", file.Name()))
+		}
+
+		var catalog Catalog
+		err = json.Unmarshal(buf, &catalog)
+		if err != nil {
+			return errors.Wrap(err, fmt.Sprintf("An error occurred while loading message catalog [%s]", file.Name()))
+		}
+
+		AddCatalog(strings.TrimSuffix(file.Name(), ".json"), catalog)
+	}
+
+	return nil
+}
+
+// Translate returns the text of key in lang, and formats args into it by the fmt verbs of the text.
+// lang falls back to its primary language such as "ja" of "ja-JP", and then to locale.defaultLang if neither is supported.
+// The key is returned as it is if the language doesn't have the translation.
+func Translate(lang, key string, args ...interface{}) string {
+	text := lookup(lang, key)
+	if len(args) > 0 {
+		return fmt.Sprintf(text, args...)
+	}
+	return text
+}
+
+// IsSupported returns whether lang or its primary language has the catalog
+func IsSupported(lang string) bool {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	return isSupported(candidateLangs(lang))
+}
+
+// ParseAcceptLanguage returns the supported language preferred most in the Accept-Language header.
+// It returns empty if none of the languages are supported.
+func ParseAcceptLanguage(header string) string {
+	type weightedLang struct {
+		lang   string
+		weight float64
+	}
+
+	weightedLangs := []weightedLang{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		lang := strings.TrimSpace(fields[0])
+		if lang == "" || lang == "*" {
+			continue
+		}
+
+		weight := 1.0
+		for _, field := range fields[1:] {
+			field = strings.TrimSpace(field)
+			if !strings.HasPrefix(field, "q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(strings.TrimPrefix(field, "q="), 64)
+			if err == nil {
+				weight = q
+			}
+		}
+		if weight <= 0 {
+			continue
+		}
+
+		weightedLangs = append(weightedLangs, weightedLang{lang: lang, weight: weight})
+	}
+
+	sort.SliceStable(weightedLangs, func(i, j int) bool {
+		return weightedLangs[i].weight > weightedLangs[j].weight
+	})
+
+	for _, wl := range weightedLangs {
+		if IsSupported(wl.lang) {
+			return wl.lang
+		}
+	}
+	return ""
+}
+
+func lookup(lang, key string) string {
+	catalogsMu.RLock()
+	defer catalogsMu.RUnlock()
+
+	langs := candidateLangs(lang)
+	if !isSupported(langs) {
+		langs = candidateLangs(config.Config().Locale.DefaultLang)
+	}
+
+	for _, l := range langs {
+		if l == SourceLang {
+			return key
+		}
+		if text, ok := catalogs[l][key]; ok {
+			return text
+		}
+	}
+	return key
+}
+
+func isSupported(langs []string) bool {
+	for _, l := range langs {
+		if l == SourceLang {
+			return true
+		}
+		if _, ok := catalogs[l]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// candidateLangs returns lang and its primary language, such as "ja-jp" and "ja" of "ja_JP"
+func candidateLangs(lang string) []string {
+	lang = normalizeLang(lang)
+	if lang == "" {
+		return []string{}
+	}
+
+	i := strings.Index(lang, "-")
+	if i < 0 {
+		return []string{lang}
+	}
+	return []string{lang, lang[:i]}
+}
+
+func normalizeLang(lang string) string {
+	return strings.Replace(strings.ToLower(strings.TrimSpace(lang)), "_", "-", -1)
+}
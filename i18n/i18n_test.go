@@ -0,0 +1,99 @@
+package i18n
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const (
+	TestI18nTranslate           = "[i18n] translate test"
+	TestI18nParseAcceptLanguage = "[i18n] parse accept language test"
+	TestI18nLoadCatalogs        = "[i18n] load catalogs test"
+)
+
+func TestI18n(t *testing.T) {
+	t.Run(TestI18nTranslate, func(t *testing.T) {
+		text := Translate("ja", "Sent an image")
+		if text != "画像を受信しました" {
+			t.Fatalf("Failed to %s. Expected the text to be translated into Japanese, but it was %s", TestI18nTranslate, text)
+		}
+
+		text = Translate("ja_JP", "%s mentioned you", "user")
+		if text != "userさんからメンションされました" {
+			t.Fatalf("Failed to %s. Expected ja_JP to fall back to ja, but it was %s", TestI18nTranslate, text)
+		}
+
+		text = Translate("ja", "i18n-test-untranslated-text")
+		if text != "i18n-test-untranslated-text" {
+			t.Fatalf("Failed to %s. Expected the untranslated text to be the key, but it was %s", TestI18nTranslate, text)
+		}
+
+		text = Translate("xx", "Sent an image")
+		if text != "Sent an image" {
+			t.Fatalf("Failed to %s. Expected the unsupported language to fall back to the default language, but it was %s", TestI18nTranslate, text)
+		}
+
+		AddCatalog("ja-JP", Catalog{"Sent a file": "i18n-test-ja-jp-file"})
+		text = Translate("ja-JP", "Sent a file")
+		if text != "i18n-test-ja-jp-file" {
+			t.Fatalf("Failed to %s. Expected the text of ja-JP to be preferred, but it was %s", TestI18nTranslate, text)
+		}
+		text = Translate("ja-JP", "Sent a message")
+		if text != "メッセージを受信しました" {
+			t.Fatalf("Failed to %s. Expected the text missing in ja-JP to fall back to ja, but it was %s", TestI18nTranslate, text)
+		}
+	})
+
+	t.Run(TestI18nParseAcceptLanguage, func(t *testing.T) {
+		lang := ParseAcceptLanguage("fr;q=0.9, ja-JP;q=0.8, en;q=0.5")
+		if lang != "ja-JP" {
+			t.Fatalf("Failed to %s. Expected lang to be ja-JP, but it was %s", TestI18nParseAcceptLanguage, lang)
+		}
+
+		lang = ParseAcceptLanguage("ja;q=0, en")
+		if lang != "en" {
+			t.Fatalf("Failed to %s. Expected lang to be en, but it was %s", TestI18nParseAcceptLanguage, lang)
+		}
+
+		lang = ParseAcceptLanguage("fr, *")
+		if lang != "" {
+			t.Fatalf("Failed to %s. Expected lang to be empty, but it was %s", TestI18nParseAcceptLanguage, lang)
+		}
+	})
+
+	t.Run(TestI18nLoadCatalogs, func(t *testing.T) {
+		dirPath, err := ioutil.TempDir("", "i18n")
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestI18nLoadCatalogs, err.Error())
+		}
+		defer os.RemoveAll(dirPath)
+
+		err = ioutil.WriteFile(filepath.Join(dirPath, "de.json"), []byte(`{"Sent an image": "Hat ein Bild gesendet"}`), 0644)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestI18nLoadCatalogs, err.Error())
+		}
+
+		err = LoadCatalogs(dirPath)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestI18nLoadCatalogs, err.Error())
+		}
+		if !IsSupported("de-DE") {
+			t.Fatalf("Failed to %s. Expected de-DE to be supported", TestI18nLoadCatalogs)
+		}
+		text := Translate("de", "Sent an image")
+		if text != "Hat ein Bild gesendet" {
+			t.Fatalf("Failed to %s. Expected the text to be loaded from the file, but it was %s", TestI18nLoadCatalogs, text)
+		}
+
+		err = ioutil.WriteFile(filepath.Join(dirPath, "fr.json"), []byte(`not json`), 0644)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestI18nLoadCatalogs, err.Error())
+		}
+		err = LoadCatalogs(dirPath)
+		if err == nil {
+			t.Fatalf("Failed to %s. Expected the invalid catalog to be an error", TestI18nLoadCatalogs)
+		}
+	})
+}
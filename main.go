@@ -12,7 +12,6 @@ import (
 	"time"
 
 	"github.com/kylelemons/godebug/pretty"
-	_ "github.com/mattn/go-sqlite3"
 
 	"github.com/betchi/metrictor"
 	tracer "github.com/betchi/tracer"
@@ -23,7 +22,9 @@ import (
 	"github.com/swagchat/chat-api/consumer"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/grpc"
+	"github.com/swagchat/chat-api/i18n"
 	"github.com/swagchat/chat-api/rest"
+	"github.com/swagchat/chat-api/service"
 	"github.com/swagchat/chat-api/storage"
 )
 
@@ -54,6 +55,11 @@ func main() {
 	}
 	logger.Info(fmt.Sprintf("Config: %s", compact.Sprint(cfg)))
 
+	if len(config.Command) != 0 {
+		tracer.InitGlobalTracer(&tracer.Config{})
+		os.Exit(runCommand(config.Command))
+	}
+
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
@@ -68,6 +74,10 @@ func main() {
 		logger.Fatal(err.Error())
 	}
 
+	if err := i18n.LoadCatalogs(cfg.Locale.CatalogPath); err != nil {
+		logger.Fatal(err.Error())
+	}
+
 	go consumer.Provider(ctx).SubscribeMessage()
 
 	jaegerLogger.InitGlobalLogger(&jaegerLogger.Config{Noop: !cfg.Tracer.Logging})
@@ -94,9 +104,26 @@ func main() {
 	}
 	defer tracer.Close()
 
-	if !cfg.Datastore.Dynamic {
-		datastore.Provider(ctx).CreateTables()
+	if cfg.Datastore.Dynamic {
+		// The database configured by datastore.database holds the registry of workspaces.
+		// It's migrated when it's connected as well as the workspace databases.
+		err = datastore.Connect(context.WithValue(ctx, config.CtxWorkspace, cfg.Datastore.Database))
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
+		go datastore.RunIdleConnectionEvictor(ctx)
+	} else {
+		err = datastore.Provider(ctx).CreateTables()
+		if err != nil {
+			logger.Fatal(err.Error())
+		}
 	}
+	go datastore.RunReplicaHealthChecker(ctx)
+
+	go service.RunScheduledMessageDispatcher(ctx)
+	go service.RunMessagePurger(ctx)
+	go service.RunOutboxRelay(ctx)
+	go service.RunRoomInvitationExpirer(ctx)
 
 	sigChan := make(chan os.Signal)
 	signal.Notify(sigChan, syscall.SIGTERM, syscall.SIGINT, syscall.SIGQUIT, syscall.SIGTSTP, syscall.SIGKILL, syscall.SIGSTOP)
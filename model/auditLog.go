@@ -0,0 +1,167 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// AuditAction is the action recorded in the audit log
+type AuditAction string
+
+const (
+	AuditActionEraseUser          AuditAction = "user.erase"
+	AuditActionAddUserRoles       AuditAction = "userRole.add"
+	AuditActionAddBlockUsers      AuditAction = "blockUser.add"
+	AuditActionCreateRoom         AuditAction = "room.create"
+	AuditActionDeleteRoom         AuditAction = "room.delete"
+	AuditActionTransferRoomOwner  AuditAction = "room.transferOwner"
+	AuditActionArchiveRoom        AuditAction = "room.archive"
+	AuditActionUnarchiveRoom      AuditAction = "room.unarchive"
+	AuditActionRestoreRoom        AuditAction = "room.restore"
+	AuditActionAddRoomUsers       AuditAction = "roomUser.add"
+	AuditActionDeleteRoomUsers    AuditAction = "roomUser.delete"
+	AuditActionUpdateRoomUserRole AuditAction = "roomUser.updateRole"
+	AuditActionDeleteMessage      AuditAction = "message.delete"
+	AuditActionPinMessage         AuditAction = "message.pin"
+	AuditActionUnpinMessage       AuditAction = "message.unpin"
+)
+
+// Target types of the audit logs
+const (
+	AuditTargetTypeUser    = "user"
+	AuditTargetTypeRoom    = "room"
+	AuditTargetTypeMessage = "message"
+)
+
+// AuditLog is model of the record of who did what to which target.
+// Before and After are the JSON of the target before and after the action if they are recorded.
+// It is also a hand-written protobuf message for gRPC, field numbers must not be changed.
+type AuditLog struct {
+	ID          uint64      `json:"-" db:"id"`
+	AuditLogID  string      `protobuf:"bytes,1,opt,name=auditLogId,proto3" json:"auditLogId" db:"audit_log_id,notnull"`
+	Action      AuditAction `protobuf:"bytes,2,opt,name=action,proto3" json:"action" db:"action,notnull"`
+	ActorUserID string      `protobuf:"bytes,3,opt,name=actorUserId,proto3" json:"actorUserId" db:"actor_user_id,notnull"`
+	ClientID    string      `protobuf:"bytes,4,opt,name=clientId,proto3" json:"clientId" db:"client_id,notnull"`
+	Workspace   string      `protobuf:"bytes,5,opt,name=workspace,proto3" json:"workspace" db:"workspace,notnull"`
+	TargetType  string      `protobuf:"bytes,6,opt,name=targetType,proto3" json:"targetType" db:"target_type,notnull"`
+	TargetID    string      `protobuf:"bytes,7,opt,name=targetId,proto3" json:"targetId" db:"target_id,notnull"`
+	Before      JSONText    `protobuf:"bytes,8,opt,name=before,proto3" json:"before" db:"before_data"`
+	After       JSONText    `protobuf:"bytes,9,opt,name=after,proto3" json:"after" db:"after_data"`
+	Created     int64       `protobuf:"varint,10,opt,name=created,proto3" json:"created" db:"created,notnull"`
+}
+
+func (m *AuditLog) Reset()         { *m = AuditLog{} }
+func (m *AuditLog) String() string { return proto.CompactTextString(m) }
+func (*AuditLog) ProtoMessage()    {}
+
+// MarshalJSON is MarshalJSON of AuditLog
+func (al *AuditLog) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	return json.Marshal(&struct {
+		AuditLogID  string      `json:"auditLogId"`
+		Action      AuditAction `json:"action"`
+		ActorUserID string      `json:"actorUserId"`
+		ClientID    string      `json:"clientId"`
+		Workspace   string      `json:"workspace"`
+		TargetType  string      `json:"targetType"`
+		TargetID    string      `json:"targetId"`
+		Before      JSONText    `json:"before"`
+		After       JSONText    `json:"after"`
+		Created     string      `json:"created"`
+	}{
+		AuditLogID:  al.AuditLogID,
+		Action:      al.Action,
+		ActorUserID: al.ActorUserID,
+		ClientID:    al.ClientID,
+		Workspace:   al.Workspace,
+		TargetType:  al.TargetType,
+		TargetID:    al.TargetID,
+		Before:      al.Before,
+		After:       al.After,
+		Created:     time.Unix(al.Created, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// NewAuditLog generates the audit log of the action. before and after are encoded to JSON, and nil is recorded as null.
+func NewAuditLog(action AuditAction, actorUserID, clientID, workspace, targetType, targetID string, before, after interface{}) *AuditLog {
+	beforeJSON, err := json.Marshal(before)
+	if err != nil {
+		beforeJSON = []byte("null")
+	}
+	afterJSON, err := json.Marshal(after)
+	if err != nil {
+		afterJSON = []byte("null")
+	}
+
+	return &AuditLog{
+		AuditLogID:  utils.GenerateUUID(),
+		Action:      action,
+		ActorUserID: actorUserID,
+		ClientID:    clientID,
+		Workspace:   workspace,
+		TargetType:  targetType,
+		TargetID:    targetID,
+		Before:      beforeJSON,
+		After:       afterJSON,
+		Created:     time.Now().Unix(),
+	}
+}
+
+// RetrieveAuditLogsRequest is request of RetrieveAuditLogs. The audit logs are filtered by the non-empty fields.
+// It is a hand-written protobuf message, field numbers must not be changed.
+type RetrieveAuditLogsRequest struct {
+	Limit         int32  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32  `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	Action        string `protobuf:"bytes,3,opt,name=action,proto3" json:"action,omitempty"`
+	ActorUserID   string `protobuf:"bytes,4,opt,name=actorUserId,proto3" json:"actorUserId,omitempty"`
+	TargetType    string `protobuf:"bytes,5,opt,name=targetType,proto3" json:"targetType,omitempty"`
+	TargetID      string `protobuf:"bytes,6,opt,name=targetId,proto3" json:"targetId,omitempty"`
+	FromTimestamp int64  `protobuf:"varint,7,opt,name=fromTimestamp,proto3" json:"fromTimestamp,omitempty"`
+	ToTimestamp   int64  `protobuf:"varint,8,opt,name=toTimestamp,proto3" json:"toTimestamp,omitempty"`
+}
+
+func (m *RetrieveAuditLogsRequest) Reset()         { *m = RetrieveAuditLogsRequest{} }
+func (m *RetrieveAuditLogsRequest) String() string { return proto.CompactTextString(m) }
+func (*RetrieveAuditLogsRequest) ProtoMessage()    {}
+
+func (m *RetrieveAuditLogsRequest) Validate() *ErrorResponse {
+	if m.Limit < 0 || m.Offset < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "limit, offset",
+				Reason: "limit and offset must be zero or positive.",
+			},
+		}
+		return NewErrorResponse("Failed to retrieve audit logs.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if m.FromTimestamp != 0 && m.ToTimestamp != 0 && m.FromTimestamp > m.ToTimestamp {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "from, to",
+				Reason: "from must be before to.",
+			},
+		}
+		return NewErrorResponse("Failed to retrieve audit logs.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// AuditLogsResponse is response of RetrieveAuditLogs.
+// It is a hand-written protobuf message, field numbers must not be changed.
+type AuditLogsResponse struct {
+	AuditLogs []*AuditLog `protobuf:"bytes,1,rep,name=auditLogs,proto3" json:"auditLogs"`
+	AllCount  int64       `protobuf:"varint,2,opt,name=allCount,proto3" json:"allCount"`
+	Limit     int32       `protobuf:"varint,3,opt,name=limit,proto3" json:"limit"`
+	Offset    int32       `protobuf:"varint,4,opt,name=offset,proto3" json:"offset"`
+}
+
+func (m *AuditLogsResponse) Reset()         { *m = AuditLogsResponse{} }
+func (m *AuditLogsResponse) String() string { return proto.CompactTextString(m) }
+func (*AuditLogsResponse) ProtoMessage()    {}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"net/http"
+
+	"github.com/golang/protobuf/proto"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// SubscribeEventsRequest is request of SubscribeEvents.
+// It is a hand-written protobuf message, field numbers must not be changed.
+type SubscribeEventsRequest struct {
+	UserID          string `protobuf:"bytes,1,opt,name=userId,proto3" json:"userId,omitempty"`
+	RoomID          string `protobuf:"bytes,2,opt,name=roomId,proto3" json:"roomId,omitempty"`
+	ResumeMessageID string `protobuf:"bytes,3,opt,name=resumeMessageId,proto3" json:"resumeMessageId,omitempty"`
+}
+
+func (m *SubscribeEventsRequest) Reset()         { *m = SubscribeEventsRequest{} }
+func (m *SubscribeEventsRequest) String() string { return proto.CompactTextString(m) }
+func (*SubscribeEventsRequest) ProtoMessage()    {}
+
+func (m *SubscribeEventsRequest) Validate() *ErrorResponse {
+	if m.UserID == "" && m.RoomID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId, roomId",
+				Reason: "Either userId or roomId is required, but both are empty.",
+			},
+		}
+		return NewErrorResponse("Failed to subscribe events.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if m.UserID != "" && !isValidID(m.UserID) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is invalid. Available characters are alphabets, numbers and hyphens.",
+			},
+		}
+		return NewErrorResponse("Failed to subscribe events.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if m.RoomID != "" && !isValidID(m.RoomID) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomId",
+				Reason: "roomId is invalid. Available characters are alphabets, numbers and hyphens.",
+			},
+		}
+		return NewErrorResponse("Failed to subscribe events.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if m.ResumeMessageID != "" && !isValidID(m.ResumeMessageID) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "resumeMessageId",
+				Reason: "resumeMessageId is invalid. Available characters are alphabets, numbers and hyphens.",
+			},
+		}
+		return NewErrorResponse("Failed to subscribe events.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
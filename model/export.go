@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+)
+
+// ExportVersion is the version of the format of workspace exports.
+// Exports of the other versions are rejected by import.
+const ExportVersion = 1
+
+// ExportRecordType is the type of the records of workspace exports
+type ExportRecordType string
+
+const (
+	ExportRecordTypeHeader    ExportRecordType = "header"
+	ExportRecordTypeUser      ExportRecordType = "user"
+	ExportRecordTypeBlockUser ExportRecordType = "blockUser"
+	ExportRecordTypeDevice    ExportRecordType = "device"
+	ExportRecordTypeRoom      ExportRecordType = "room"
+	ExportRecordTypeMessage   ExportRecordType = "message"
+	ExportRecordTypeMention   ExportRecordType = "mention"
+	ExportRecordTypeRoomUser  ExportRecordType = "roomUser"
+	ExportRecordTypeAsset     ExportRecordType = "asset"
+	ExportRecordTypeWebhook   ExportRecordType = "webhook"
+)
+
+// ExportRecord is a line of workspace exports, which are NDJSON.
+// The first record is the header and the others are the entities given by the type.
+type ExportRecord struct {
+	Type ExportRecordType `json:"type"`
+	Data json.RawMessage  `json:"data"`
+}
+
+// ExportHeader is the header record of workspace exports
+type ExportHeader struct {
+	Version   int    `json:"version"`
+	Workspace string `json:"workspace"`
+	Exported  int64  `json:"exported"`
+}
+
+type ExportWorkspaceRequest struct {
+	Workspace string
+	// WithAssetData embeds the binaries of the assets from the storage
+	WithAssetData bool
+}
+
+type ImportWorkspaceRequest struct {
+	Workspace string
+}
+
+// ImportWorkspaceResponse is the number of the records imported and skipped for each type.
+// The records which already exist in the workspace are skipped, so that imports can be retried.
+type ImportWorkspaceResponse struct {
+	Imported map[ExportRecordType]int64 `json:"imported"`
+	Skipped  map[ExportRecordType]int64 `json:"skipped"`
+}
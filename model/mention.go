@@ -0,0 +1,62 @@
+package model
+
+import (
+	"encoding/json"
+	"regexp"
+	"time"
+)
+
+const (
+	// MentionAll is a mention to all users of the room
+	MentionAll = "all"
+)
+
+var mentionRegexp = regexp.MustCompile(`(^|\s)@([a-zA-Z0-9-]+)`)
+
+// Mention is model of mention
+type Mention struct {
+	ID        uint64 `json:"-" db:"id"`
+	MessageID string `json:"messageId" db:"message_id,notnull"`
+	RoomID    string `json:"roomId" db:"room_id,notnull"`
+	UserID    string `json:"userId" db:"user_id,notnull"`
+	Created   int64  `json:"created" db:"created,notnull"`
+}
+
+// MarshalJSON is MarshalJSON of Mention
+func (m *Mention) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	return json.Marshal(&struct {
+		MessageID string `json:"messageId"`
+		RoomID    string `json:"roomId"`
+		UserID    string `json:"userId"`
+		Created   string `json:"created"`
+	}{
+		MessageID: m.MessageID,
+		RoomID:    m.RoomID,
+		UserID:    m.UserID,
+		Created:   time.Unix(m.Created, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// ParseMentions extracts "@userId" and "@all" from text
+func ParseMentions(text string) []string {
+	mentions := []string{}
+	for _, match := range mentionRegexp.FindAllStringSubmatch(text, -1) {
+		mentions = append(mentions, match[2])
+	}
+	return mentions
+}
+
+type RetrieveMentionsRequest struct {
+	UserID string
+	RoomID string
+	Limit  int32
+	Offset int32
+}
+
+type MentionsResponse struct {
+	Messages []*Message `json:"messages"`
+	AllCount int64      `json:"allCount"`
+	Limit    int32      `json:"limit"`
+	Offset   int32      `json:"offset"`
+}
@@ -2,10 +2,12 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/i18n"
 	"github.com/swagchat/chat-api/utils"
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
@@ -17,40 +19,94 @@ const (
 	MessageTypeIndicatorStart = "indicator-start"
 	MessageTypeIndicatorEnd   = "indicator-end"
 	MessageTypeUpdateRoomUser = "updateRoomUser"
+	MessageTypeUpdateRoom     = "updateRoom"
 
 	EventNameMessage = "message"
 )
 
 type Message struct {
 	scpb.Message
-	Payload JSONText `json:"payload" db:"payload"`
+	Payload  JSONText `json:"payload" db:"payload"`
+	Mentions []string `json:"mentions,omitempty" db:"-"`
+	// ExpiresTimestamp is a time the message is purged at. 0 means the message does not expire.
+	ExpiresTimestamp int64 `json:"expiresTimestamp,omitempty" db:"expires,notnull"`
 }
 
 func (m *Message) MarshalJSON() ([]byte, error) {
 	l, _ := time.LoadLocation("Etc/GMT")
+	expires := ""
+	if m.ExpiresTimestamp != 0 {
+		expires = time.Unix(m.ExpiresTimestamp, 0).In(l).Format(time.RFC3339)
+	}
+	deleted := ""
+	if m.DeletedTimestamp != 0 {
+		deleted = time.Unix(m.DeletedTimestamp, 0).In(l).Format(time.RFC3339)
+	}
 	return json.Marshal(&struct {
 		MessageID        string   `json:"messageId"`
 		RoomID           string   `json:"roomId"`
 		UserID           string   `json:"userId"`
 		Type             string   `json:"type"`
 		Payload          JSONText `json:"payload"`
+		Mentions         []string `json:"mentions,omitempty"`
 		Role             int32    `json:"role"`
 		CreatedTimestamp int64    `json:"createdTimestamp"`
 		Created          string   `json:"created"`
 		Modified         string   `json:"modified"`
+		Expires          string   `json:"expires,omitempty"`
+		Deleted          string   `json:"deleted,omitempty"`
 	}{
 		MessageID:        m.MessageID,
 		RoomID:           m.RoomID,
 		UserID:           m.UserID,
 		Type:             m.Type,
 		Payload:          m.Payload,
+		Mentions:         m.Mentions,
 		Role:             m.Role,
 		CreatedTimestamp: m.CreatedTimestamp,
 		Created:          time.Unix(m.CreatedTimestamp, 0).In(l).Format(time.RFC3339),
 		Modified:         time.Unix(m.ModifiedTimestamp, 0).In(l).Format(time.RFC3339),
+		Expires:          expires,
+		Deleted:          deleted,
 	})
 }
 
+// messagePreviews are the previews of the messages which don't have their texts by the message types.
+// They are translated into the languages of the users.
+var messagePreviews = map[string]string{
+	MessageTypeImage:          "Sent an image",
+	MessageTypeFile:           "Sent a file",
+	MessageTypeUpdateRoomUser: "Updated the room members",
+	MessageTypeUpdateRoom:     "Updated the room",
+}
+
+// LastMessageText returns the text stored as the last message of the room.
+// It's empty if the message doesn't have a text, and the preview is made by MessagePreview when it's shown.
+func (m *Message) LastMessageText() string {
+	if m.Type == MessageTypeImage || m.Type == MessageTypeFile {
+		return ""
+	}
+
+	var payloadText PayloadText
+	json.Unmarshal(m.Payload, &payloadText)
+	return payloadText.Text
+}
+
+// MessagePreview returns the preview of the message shown in the room list.
+// The text is shown as it is, and the message without the text is described in lang such as "ja" or "ja-JP".
+func MessagePreview(messageType, text, lang string) string {
+	// The rooms which have no messages or the last messages stored before the types have no types
+	if text != "" || messageType == "" {
+		return text
+	}
+
+	preview, ok := messagePreviews[messageType]
+	if !ok {
+		preview = "Sent a message"
+	}
+	return i18n.Translate(lang, preview)
+}
+
 func (m *Message) ConvertToPbMessage() *scpb.Message {
 	pbMessage := &scpb.Message{}
 	pbMessage.MessageID = m.MessageID
@@ -73,6 +129,7 @@ func (m *Message) ConvertToSendMessageRequest() *SendMessageRequest {
 	req.Type = &m.Type
 	req.Payload = m.Payload
 	req.Role = &m.Role
+	req.Mentions = m.Mentions
 	return req
 }
 
@@ -93,7 +150,10 @@ type PayloadUsers struct {
 
 type SendMessageRequest struct {
 	scpb.SendMessageRequest
-	Payload JSONText `json:"payload" db:"payload"`
+	Payload            JSONText `json:"payload" db:"payload"`
+	Mentions           []string `json:"mentions,omitempty" db:"-"`
+	ScheduledTimestamp int64    `json:"scheduledTimestamp,omitempty" db:"-"`
+	TTL                int64    `json:"ttl,omitempty" db:"-"`
 }
 
 func (m *SendMessageRequest) Validate() *ErrorResponse {
@@ -195,9 +255,106 @@ func (m *SendMessageRequest) Validate() *ErrorResponse {
 		}
 	}
 
+	if m.ScheduledTimestamp != 0 && m.ScheduledTimestamp <= time.Now().Unix() {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "scheduledTimestamp",
+				Reason: "scheduledTimestamp must be a future time.",
+			},
+		}
+		return NewErrorResponse("Failed to create a message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if m.TTL < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "ttl",
+				Reason: "ttl must be 0 or more.",
+			},
+		}
+		return NewErrorResponse("Failed to create a message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	for _, mention := range m.Mentions {
+		if mention != MentionAll && !isValidID(mention) {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "mentions",
+					Reason: "mentions is invalid. Available values are userIds and \"all\".",
+				},
+			}
+			return NewErrorResponse("Failed to create a message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
 	return nil
 }
 
+// ValidatePolicy validates the message against the speech mode and the available message types of the room.
+// The roles of the user have to be selected with it.
+func (m *SendMessageRequest) ValidatePolicy(room *Room, user *User) *ErrorResponse {
+	if !room.CanSpeak(user) {
+		reason := "userId can not send messages to the room."
+		switch room.SpeechMode {
+		case SpeechModeOwnerOnly:
+			reason = "Only the owner of the room can send messages."
+		case SpeechModeRoles:
+			reason = "Only the users who have one of the speech roles of the room can send messages."
+		}
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: reason,
+			},
+		}
+		return NewErrorResponse("Failed to create a message.", http.StatusForbidden, WithInvalidParams(invalidParams))
+	}
+
+	messageType := *m.Type
+	if messageType != MessageTypeIndicatorStart && messageType != MessageTypeIndicatorEnd && !room.IsAvailableMessageType(messageType) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "type",
+				Reason: fmt.Sprintf("type is not available in the room. Available types are %s.", room.AvailableMessageTypes),
+			},
+		}
+		return NewErrorResponse("Failed to create a message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// ValidateRoomRole rejects the messages of the read-only room users.
+// roomUser is nil if the user is not a member of the room.
+func (m *SendMessageRequest) ValidateRoomRole(roomUser *RoomUser) *ErrorResponse {
+	if roomUser == nil || roomUser.RoomRole != RoomRoleReadOnly {
+		return nil
+	}
+
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "userId",
+			Reason: "Read-only users of the room can not send messages.",
+		},
+	}
+	return NewErrorResponse("Failed to create a message.", http.StatusForbidden, WithInvalidParams(invalidParams))
+}
+
+// MentionCandidates returns explicit mentions, or mentions written in the text payload if not specified
+func (m *SendMessageRequest) MentionCandidates() ([]string, bool) {
+	if len(m.Mentions) > 0 {
+		return m.Mentions, true
+	}
+
+	if m.Type == nil || *m.Type != MessageTypeText {
+		return []string{}, false
+	}
+
+	var pt PayloadText
+	json.Unmarshal(m.Payload, &pt)
+	return ParseMentions(pt.Text), false
+}
+
 func (cmr *SendMessageRequest) GenerateMessage() *Message {
 	m := &Message{}
 
@@ -223,5 +380,36 @@ func (cmr *SendMessageRequest) GenerateMessage() *Message {
 	m.ModifiedTimestamp = nowTimestamp
 	m.DeletedTimestamp = 0
 
+	if cmr.TTL > 0 {
+		m.ExpiresTimestamp = nowTimestamp + cmr.TTL
+	}
+
 	return m
 }
+
+// DeleteMessageRequest is the request to delete a message.
+// The messages are deleted logically, so they remain in the room history as deleted.
+type DeleteMessageRequest struct {
+	MessageID string `json:"messageId"`
+}
+
+// ValidateRoomRole validates that the request user can delete the message.
+// The senders can delete their own messages, and the owner and the admins of the room can moderate the others.
+// requestRoomUser is nil if the request is not restricted by the room roles.
+func (dmr *DeleteMessageRequest) ValidateRoomRole(message *Message, requestRoomUser, senderRoomUser *RoomUser) *ErrorResponse {
+	if requestRoomUser == nil || requestRoomUser.UserID == message.UserID {
+		return nil
+	}
+
+	if !requestRoomUser.CanModerate(senderRoomUser) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "messageId",
+				Reason: "You do not have permission to delete the message.",
+			},
+		}
+		return NewErrorResponse("Failed to delete message.", http.StatusForbidden, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/swagchat/chat-api/utils"
+)
+
+// Event types of the outbox
+const (
+	OutboxEventTypeMessage  = "message"
+	OutboxEventTypeRoom     = "room"
+	OutboxEventTypeRoomUser = "roomUser"
+	// OutboxEventTypeRoomEvent is the room event of the payload, such as invitations
+	OutboxEventTypeRoomEvent = "roomEvent"
+	// OutboxEventTypePinnedMessage is the change of the pinned messages of the room
+	OutboxEventTypePinnedMessage = "pinnedMessage"
+)
+
+// Destinations of the outbox events
+const (
+	OutboxDestinationProducer     = "producer"
+	OutboxDestinationWebhook      = "webhook"
+	OutboxDestinationNotification = "notification"
+)
+
+// OutboxEvent is model of the event relayed to a destination after the change is committed.
+// Every destination has its own row, so that a failure of one destination doesn't redeliver to the others.
+// EventID is shared by the rows of the same change and is sent as the deduplication ID.
+type OutboxEvent struct {
+	ID          uint64 `json:"-" db:"id"`
+	EventID     string `json:"eventId" db:"event_id,notnull"`
+	EventType   string `json:"eventType" db:"event_type,notnull"`
+	Destination string `json:"destination" db:"destination,notnull"`
+	RoomID      string `json:"roomId" db:"room_id,notnull"`
+	MessageID   string `json:"messageId" db:"message_id,notnull"`
+	Attempts    int32  `json:"attempts" db:"attempts,notnull"`
+	NextAttempt int64  `json:"nextAttempt" db:"next_attempt,notnull"`
+	LastError   string `json:"lastError" db:"last_error,notnull"`
+	Created     int64  `json:"created" db:"created,notnull"`
+	Dispatched  int64  `json:"dispatched" db:"dispatched,notnull"`
+	Failed      int64  `json:"failed" db:"failed,notnull"`
+	// Payload is the JSON of the data which can't be read from the datastore when the event is delivered
+	Payload string `json:"payload" db:"payload,notnull"`
+}
+
+// OutboxRoomEventPayload is the payload of OutboxEventTypeRoomEvent, which is published to the users as it is
+type OutboxRoomEventPayload struct {
+	Data    JSONText `json:"data"`
+	UserIDs []string `json:"userIds"`
+}
+
+// OutboxPinnedMessagePayload is the payload of OutboxEventTypePinnedMessage
+type OutboxPinnedMessagePayload struct {
+	Action string `json:"action"`
+	UserID string `json:"userId"`
+}
+
+// NewOutboxEvents generates the outbox events of a change for each destination
+func NewOutboxEvents(eventType, roomID, messageID string, destinations ...string) []*OutboxEvent {
+	eventID := utils.GenerateUUID()
+	created := time.Now().Unix()
+	events := make([]*OutboxEvent, len(destinations))
+	for i, destination := range destinations {
+		events[i] = &OutboxEvent{
+			EventID:     eventID,
+			EventType:   eventType,
+			Destination: destination,
+			RoomID:      roomID,
+			MessageID:   messageID,
+			NextAttempt: created,
+			Created:     created,
+		}
+	}
+	return events
+}
+
+// NewRoomEventOutboxEvents generates the outbox events which publish the room event of the data to the users
+func NewRoomEventOutboxEvents(roomID string, data interface{}, userIDs []string) []*OutboxEvent {
+	dataJSON, _ := json.Marshal(data)
+	payload, _ := json.Marshal(&OutboxRoomEventPayload{
+		Data:    JSONText(dataJSON),
+		UserIDs: userIDs,
+	})
+	events := NewOutboxEvents(OutboxEventTypeRoomEvent, roomID, "", OutboxDestinationProducer)
+	for _, event := range events {
+		event.Payload = string(payload)
+	}
+	return events
+}
+
+// NewPinnedMessageOutboxEvents generates the outbox events which notify the room users of the change of the pinned messages.
+// The pinned messages are read when the events are delivered.
+func NewPinnedMessageOutboxEvents(roomID, action, messageID, userID string) []*OutboxEvent {
+	payload, _ := json.Marshal(&OutboxPinnedMessagePayload{
+		Action: action,
+		UserID: userID,
+	})
+	events := NewOutboxEvents(OutboxEventTypePinnedMessage, roomID, messageID, OutboxDestinationProducer)
+	for _, event := range events {
+		event.Payload = string(payload)
+	}
+	return events
+}
@@ -0,0 +1,178 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// Actions of the pinned message events
+const (
+	PinnedMessageActionPin     = "pin"
+	PinnedMessageActionUnpin   = "unpin"
+	PinnedMessageActionReorder = "reorder"
+)
+
+// PinnedMessage is model of the message pinned to the room.
+// The pinned messages are shown in ascending order of Order.
+type PinnedMessage struct {
+	ID        uint64   `json:"-" db:"id"`
+	RoomID    string   `json:"roomId" db:"room_id,notnull"`
+	MessageID string   `json:"messageId" db:"message_id,notnull"`
+	UserID    string   `json:"userId" db:"user_id,notnull"`
+	Order     int32    `json:"order" db:"display_order,notnull"`
+	Created   int64    `json:"created" db:"created,notnull"`
+	Message   *Message `json:"message,omitempty" db:"-"`
+}
+
+// MarshalJSON is MarshalJSON of PinnedMessage
+func (pm *PinnedMessage) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	return json.Marshal(&struct {
+		RoomID    string   `json:"roomId"`
+		MessageID string   `json:"messageId"`
+		UserID    string   `json:"userId"`
+		Order     int32    `json:"order"`
+		Created   string   `json:"created"`
+		Message   *Message `json:"message,omitempty"`
+	}{
+		RoomID:    pm.RoomID,
+		MessageID: pm.MessageID,
+		UserID:    pm.UserID,
+		Order:     pm.Order,
+		Created:   time.Unix(pm.Created, 0).In(l).Format(time.RFC3339),
+		Message:   pm.Message,
+	})
+}
+
+// PinnedMessageEvent is the room event sent when the pinned messages of the room are changed.
+// MessageIDs are all of the pinned messages of the room in the order after the change.
+// The messages are not included because the users who can read them depend on their roles.
+type PinnedMessageEvent struct {
+	Action     string   `json:"action"`
+	RoomID     string   `json:"roomId"`
+	MessageID  string   `json:"messageId,omitempty"`
+	UserID     string   `json:"userId,omitempty"`
+	MessageIDs []string `json:"messageIds"`
+}
+
+func NewPinnedMessageEvent(action, roomID, messageID, userID string, pinnedMessages []*PinnedMessage) *PinnedMessageEvent {
+	messageIDs := make([]string, len(pinnedMessages))
+	for i, pm := range pinnedMessages {
+		messageIDs[i] = pm.MessageID
+	}
+	return &PinnedMessageEvent{
+		Action:     action,
+		RoomID:     roomID,
+		MessageID:  messageID,
+		UserID:     userID,
+		MessageIDs: messageIDs,
+	}
+}
+
+// FilterPinnedMessagesByRoles returns the pinned messages whose messages are sent to one of the roles
+func FilterPinnedMessagesByRoles(pinnedMessages []*PinnedMessage, roles []int32) []*PinnedMessage {
+	filtered := make([]*PinnedMessage, 0, len(pinnedMessages))
+	for _, pm := range pinnedMessages {
+		for _, role := range roles {
+			if pm.Message.Role == role {
+				filtered = append(filtered, pm)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// PinMessageRequest is the request to pin the message to the room
+type PinMessageRequest struct {
+	RoomID    string   `json:"roomId"`
+	MessageID string   `json:"messageId"`
+	Message   *Message `json:"-"`
+}
+
+func (pmr *PinMessageRequest) Validate() *ErrorResponse {
+	if pmr.Message.RoomID != pmr.RoomID {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "messageId",
+				Reason: "The message is not a message of the room.",
+			},
+		}
+		return NewErrorResponse("Failed to pin message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if pmr.Message.DeletedTimestamp != 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "messageId",
+				Reason: "The message has been deleted.",
+			},
+		}
+		return NewErrorResponse("Failed to pin message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+func (pmr *PinMessageRequest) GeneratePinnedMessage(userID string) *PinnedMessage {
+	return &PinnedMessage{
+		RoomID:    pmr.RoomID,
+		MessageID: pmr.MessageID,
+		UserID:    userID,
+		Created:   time.Now().Unix(),
+	}
+}
+
+// UnpinMessageRequest is the request to unpin the message from the room
+type UnpinMessageRequest struct {
+	RoomID    string `json:"roomId"`
+	MessageID string `json:"messageId"`
+}
+
+// UpdatePinnedMessagesOrderRequest is the request to reorder the pinned messages of the room.
+// MessageIDs have to be all of the pinned messages of the room in the new order.
+type UpdatePinnedMessagesOrderRequest struct {
+	RoomID     string           `json:"roomId"`
+	MessageIDs []string         `json:"messageIds"`
+	Pinned     []*PinnedMessage `json:"-"`
+}
+
+func (upmor *UpdatePinnedMessagesOrderRequest) Validate() *ErrorResponse {
+	pinnedMessageIDs := make([]string, len(upmor.Pinned))
+	for i, pm := range upmor.Pinned {
+		pinnedMessageIDs[i] = pm.MessageID
+	}
+
+	if len(utils.RemoveDuplicateString(upmor.MessageIDs)) != len(upmor.MessageIDs) || len(upmor.MessageIDs) != len(pinnedMessageIDs) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "messageIds",
+				Reason: "messageIds must be all of the pinned messages of the room without duplicates.",
+			},
+		}
+		return NewErrorResponse("Failed to update pinned messages order.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	for _, messageID := range upmor.MessageIDs {
+		if !utils.SearchStringValueInSlice(pinnedMessageIDs, messageID) {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "messageIds",
+					Reason: "messageIds must be all of the pinned messages of the room without duplicates.",
+				},
+			}
+			return NewErrorResponse("Failed to update pinned messages order.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
+	return nil
+}
+
+// PinnedMessagesResponse is the response of the pinned messages of the room
+type PinnedMessagesResponse struct {
+	PinnedMessages []*PinnedMessage `json:"pinnedMessages"`
+}
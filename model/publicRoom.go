@@ -0,0 +1,135 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// PublicRoom is a public room listed in the room directory
+type PublicRoom struct {
+	RoomID                      string   `db:"room_id"`
+	UserID                      string   `db:"user_id"`
+	Name                        string   `db:"name"`
+	PictureURL                  string   `db:"picture_url"`
+	InformationURL              string   `db:"information_url"`
+	MetaData                    JSONText `db:"meta_data"`
+	MemberCount                 int64    `db:"member_count"`
+	LastMessageUpdatedTimestamp int64    `db:"last_message_updated"`
+	CreatedTimestamp            int64    `db:"created"`
+}
+
+func (pr *PublicRoom) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	lmu := ""
+	if pr.LastMessageUpdatedTimestamp != 0 {
+		lmu = time.Unix(pr.LastMessageUpdatedTimestamp, 0).In(l).Format(time.RFC3339)
+	}
+	return json.Marshal(&struct {
+		RoomID             string   `json:"roomId"`
+		UserID             string   `json:"userId"`
+		Name               string   `json:"name"`
+		PictureURL         string   `json:"pictureUrl,omitempty"`
+		InformationURL     string   `json:"informationUrl,omitempty"`
+		MetaData           JSONText `json:"metaData"`
+		MemberCount        int64    `json:"memberCount"`
+		LastMessageUpdated string   `json:"lastMessageUpdated"`
+		Created            string   `json:"created"`
+	}{
+		RoomID:             pr.RoomID,
+		UserID:             pr.UserID,
+		Name:               pr.Name,
+		PictureURL:         pr.PictureURL,
+		InformationURL:     pr.InformationURL,
+		MetaData:           pr.MetaData,
+		MemberCount:        pr.MemberCount,
+		LastMessageUpdated: lmu,
+		Created:            time.Unix(pr.CreatedTimestamp, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// RetrievePublicRoomsRequest is the request to search the room directory.
+// Name is matched partially and the rooms have all of Tags.
+type RetrievePublicRoomsRequest struct {
+	Name   string
+	Tags   []string
+	Limit  int32
+	Offset int32
+}
+
+func (rprr *RetrievePublicRoomsRequest) Validate() *ErrorResponse {
+	for _, tag := range rprr.Tags {
+		if strings.Contains(tag, ",") {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "tags",
+					Reason: "tags can not include commas.",
+				},
+			}
+			return NewErrorResponse("Failed to retrieve public rooms.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
+	return nil
+}
+
+type PublicRoomsResponse struct {
+	Rooms    []*PublicRoom `json:"rooms"`
+	AllCount int64         `json:"allCount"`
+	Limit    int32         `json:"limit"`
+	Offset   int32         `json:"offset"`
+}
+
+// JoinRoomRequest is the request of the user to join the public room by themselves
+type JoinRoomRequest struct {
+	RoomID string `json:"roomId"`
+	UserID string `json:"userId"`
+	Room   *Room  `json:"-"`
+}
+
+func (jrr *JoinRoomRequest) Validate() *ErrorResponse {
+	if jrr.UserID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to join room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if jrr.Room.Type != scpb.RoomType_PublicRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "Only public rooms can be joined by themselves.",
+			},
+		}
+		return NewErrorResponse("Failed to join room.", http.StatusForbidden, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// LeaveRoomRequest is the request of the user to leave the room by themselves
+type LeaveRoomRequest struct {
+	RoomID string `json:"roomId"`
+	UserID string `json:"userId"`
+}
+
+func (lrr *LeaveRoomRequest) Validate() *ErrorResponse {
+	if lrr.UserID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to leave room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
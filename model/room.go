@@ -1,7 +1,10 @@
 package model
 
 import (
+	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"encoding/json"
@@ -11,10 +14,147 @@ import (
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
+// Speech modes of rooms, which decide who can send messages to the room.
+// They are out of the values of scpb.SpeechMode not to change the meaning of the rooms which already use them.
+const (
+	// SpeechModeEveryone lets every user send messages
+	SpeechModeEveryone = scpb.SpeechMode_SpeechModeNone
+	// SpeechModeOwnerOnly lets only the user who owns the room send messages
+	SpeechModeOwnerOnly scpb.SpeechMode = 100
+	// SpeechModeRoles lets only the users who have one of the speech roles of the room send messages
+	SpeechModeRoles scpb.SpeechMode = 101
+)
+
 type Room struct {
 	scpb.Room
 	MetaData JSONText    `db:"meta_data"`
 	Users    []*MiniUser `db:"-"`
+	// RetentionMaxAge is a max age in seconds of messages kept in the room. 0 means unlimited.
+	RetentionMaxAge int64 `db:"retention_max_age,notnull"`
+	// RetentionMaxCount is a max count of messages kept in the room. 0 means unlimited.
+	RetentionMaxCount int64 `db:"retention_max_count,notnull"`
+	// SpeechRoles is comma separated roles which can send messages in SpeechModeRoles
+	SpeechRoles string `db:"speech_roles,notnull"`
+	// Tags is metaData.tags of the room joined with commas, which is searched in the public room directory.
+	// It's wrapped with commas to match each tag by LIKE, and set by SetTags.
+	Tags string `db:"tags,notnull"`
+	// ArchivedTimestamp is a time the room was archived at. The archived rooms are read-only. 0 means the room is not archived.
+	ArchivedTimestamp int64 `db:"archived,notnull"`
+	// PinnedMessages are the messages pinned to the room in the order
+	PinnedMessages []*PinnedMessage `db:"-"`
+	// LastMessageID, LastMessageType and LastMessageUserID are of the last message, and LastMessage is its text
+	LastMessageID     string `db:"last_message_id,notnull"`
+	LastMessageType   string `db:"last_message_type,notnull"`
+	LastMessageUserID string `db:"last_message_user_id,notnull"`
+}
+
+// SetLastMessage sets the message as the last message of the room. nil clears the last message.
+func (r *Room) SetLastMessage(message *Message) {
+	if message == nil {
+		r.LastMessage = ""
+		r.LastMessageID = ""
+		r.LastMessageType = ""
+		r.LastMessageUserID = ""
+		return
+	}
+
+	r.LastMessage = message.LastMessageText()
+	r.LastMessageID = message.MessageID
+	r.LastMessageType = message.Type
+	r.LastMessageUserID = message.UserID
+	r.LastMessageUpdatedTimestamp = message.CreatedTimestamp
+}
+
+// SetTags sets Tags from the tags array of metaData, such as {"tags": ["sports", "soccer"]}.
+// The tags are lowercased, and the tags including commas are ignored.
+func (r *Room) SetTags() {
+	r.Tags = ""
+	if len(r.MetaData) == 0 {
+		return
+	}
+
+	var metaData struct {
+		Tags []string `json:"tags"`
+	}
+	err := json.Unmarshal(r.MetaData, &metaData)
+	if err != nil {
+		return
+	}
+
+	tags := make([]string, 0, len(metaData.Tags))
+	for _, tag := range metaData.Tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || strings.Contains(tag, ",") {
+			continue
+		}
+		tags = append(tags, tag)
+	}
+	tags = utils.RemoveDuplicateString(tags)
+	if len(tags) > 0 {
+		r.Tags = fmt.Sprintf(",%s,", strings.Join(tags, ","))
+	}
+}
+
+// CanSpeak returns whether the user can send messages to the room by the speech mode.
+// The roles of the user have to be selected with it.
+func (r *Room) CanSpeak(user *User) bool {
+	switch r.SpeechMode {
+	case SpeechModeOwnerOnly:
+		return user.UserID == r.UserID
+	case SpeechModeRoles:
+		speechRoles, _ := parseRoles(r.SpeechRoles)
+		for _, speechRole := range speechRoles {
+			for _, role := range user.Roles {
+				if role == speechRole {
+					return true
+				}
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// IsAvailableMessageType returns whether the message type can be sent to the room.
+// AvailableMessageTypes is comma separated message types, and all of the types are available if it's empty.
+func (r *Room) IsAvailableMessageType(messageType string) bool {
+	if r.AvailableMessageTypes == "" {
+		return true
+	}
+
+	for _, availableMessageType := range strings.Split(r.AvailableMessageTypes, ",") {
+		if strings.TrimSpace(availableMessageType) == messageType {
+			return true
+		}
+	}
+	return false
+}
+
+func parseRoles(roles string) ([]int32, error) {
+	if roles == "" {
+		return []int32{}, nil
+	}
+
+	parsedRoles := make([]int32, 0)
+	for _, role := range strings.Split(roles, ",") {
+		parsedRole, err := strconv.ParseInt(strings.TrimSpace(role), 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		parsedRoles = append(parsedRoles, int32(parsedRole))
+	}
+	return parsedRoles, nil
+}
+
+// isValidSpeechMode returns whether the speech mode is one of the speech modes of rooms or scpb.SpeechMode.
+// Every user can send messages in the modes of scpb.SpeechMode.
+func isValidSpeechMode(speechMode scpb.SpeechMode) bool {
+	if speechMode == SpeechModeOwnerOnly || speechMode == SpeechModeRoles {
+		return true
+	}
+	_, ok := scpb.SpeechMode_name[int32(speechMode)]
+	return ok
 }
 
 func (r *Room) MarshalJSON() ([]byte, error) {
@@ -23,24 +163,33 @@ func (r *Room) MarshalJSON() ([]byte, error) {
 	if r.LastMessageUpdatedTimestamp != 0 {
 		lmu = time.Unix(r.LastMessageUpdatedTimestamp, 0).In(l).Format(time.RFC3339)
 	}
+	archived := ""
+	if r.ArchivedTimestamp != 0 {
+		archived = time.Unix(r.ArchivedTimestamp, 0).In(l).Format(time.RFC3339)
+	}
 	return json.Marshal(&struct {
-		RoomID                string          `json:"roomId"`
-		UserID                string          `json:"userId"`
-		Name                  string          `json:"name"`
-		PictureURL            string          `json:"pictureUrl"`
-		InformationURL        string          `json:"informationUrl"`
-		Type                  scpb.RoomType   `json:"type"`
-		CanLeft               bool            `json:"canLeft"`
-		SpeechMode            scpb.SpeechMode `json:"speechMode"`
-		MetaData              JSONText        `json:"metaData"`
-		AvailableMessageTypes string          `json:"availableMessageTypes"`
-		LastMessage           string          `json:"lastMessage"`
-		LastMessageUpdated    string          `json:"lastMessageUpdated"`
-		MessageCount          int64           `json:"messageCount"`
-		NotificationTopicID   string          `json:"notificationTopicId"`
-		Created               string          `json:"created"`
-		Modified              string          `json:"modified"`
-		Users                 []*MiniUser     `json:"users,omitempty"`
+		RoomID                string           `json:"roomId"`
+		UserID                string           `json:"userId"`
+		Name                  string           `json:"name"`
+		PictureURL            string           `json:"pictureUrl"`
+		InformationURL        string           `json:"informationUrl"`
+		Type                  scpb.RoomType    `json:"type"`
+		CanLeft               bool             `json:"canLeft"`
+		SpeechMode            scpb.SpeechMode  `json:"speechMode"`
+		SpeechRoles           string           `json:"speechRoles"`
+		MetaData              JSONText         `json:"metaData"`
+		AvailableMessageTypes string           `json:"availableMessageTypes"`
+		LastMessage           string           `json:"lastMessage"`
+		LastMessageUpdated    string           `json:"lastMessageUpdated"`
+		MessageCount          int64            `json:"messageCount"`
+		RetentionMaxAge       int64            `json:"retentionMaxAge"`
+		RetentionMaxCount     int64            `json:"retentionMaxCount"`
+		NotificationTopicID   string           `json:"notificationTopicId"`
+		Created               string           `json:"created"`
+		Modified              string           `json:"modified"`
+		Archived              string           `json:"archived,omitempty"`
+		Users                 []*MiniUser      `json:"users,omitempty"`
+		PinnedMessages        []*PinnedMessage `json:"pinnedMessages,omitempty"`
 	}{
 		RoomID:                r.RoomID,
 		UserID:                r.UserID,
@@ -50,14 +199,19 @@ func (r *Room) MarshalJSON() ([]byte, error) {
 		Type:                  r.Type,
 		CanLeft:               r.CanLeft,
 		SpeechMode:            r.SpeechMode,
+		SpeechRoles:           r.SpeechRoles,
 		MetaData:              r.MetaData,
 		AvailableMessageTypes: r.AvailableMessageTypes,
-		LastMessage:           r.LastMessage,
+		LastMessage:           MessagePreview(r.LastMessageType, r.LastMessage, ""),
 		LastMessageUpdated:    lmu,
 		MessageCount:          r.MessageCount,
+		RetentionMaxAge:       r.RetentionMaxAge,
+		RetentionMaxCount:     r.RetentionMaxCount,
 		Created:               time.Unix(r.CreatedTimestamp, 0).In(l).Format(time.RFC3339),
 		Modified:              time.Unix(r.ModifiedTimestamp, 0).In(l).Format(time.RFC3339),
+		Archived:              archived,
 		Users:                 r.Users,
+		PinnedMessages:        r.PinnedMessages,
 	})
 }
 
@@ -100,6 +254,10 @@ func (r *Room) UpdateRoom(req *UpdateRoomRequest) {
 		r.SpeechMode = *req.SpeechMode
 	}
 
+	if req.SpeechRoles != nil {
+		r.SpeechRoles = *req.SpeechRoles
+	}
+
 	if req.MetaData != nil {
 		r.MetaData = req.MetaData
 	}
@@ -108,12 +266,21 @@ func (r *Room) UpdateRoom(req *UpdateRoomRequest) {
 		r.AvailableMessageTypes = *req.AvailableMessageTypes
 	}
 
+	if req.RetentionMaxAge != nil {
+		r.RetentionMaxAge = *req.RetentionMaxAge
+	}
+
+	if req.RetentionMaxCount != nil {
+		r.RetentionMaxCount = *req.RetentionMaxCount
+	}
+
 	nowTimestamp := time.Now().Unix()
 	r.ModifiedTimestamp = nowTimestamp
 }
 
 type MiniUser struct {
 	scpb.MiniUser
+	RuRoomRole RoomRole `json:"ruRoomRole,omitempty" db:"ru_room_role"`
 }
 
 func (ufr *MiniUser) MarshalJSON() ([]byte, error) {
@@ -129,6 +296,7 @@ func (ufr *MiniUser) MarshalJSON() ([]byte, error) {
 		Created        string   `json:"created"`
 		Modified       string   `json:"modified"`
 		RuDisplay      *bool    `json:"ruDisplay,omitempty"`
+		RuRoomRole     RoomRole `json:"ruRoomRole,omitempty"`
 	}{
 		UserID:         ufr.UserID,
 		Name:           ufr.Name,
@@ -140,12 +308,16 @@ func (ufr *MiniUser) MarshalJSON() ([]byte, error) {
 		Created:        time.Unix(ufr.CreatedTimestamp, 0).In(l).Format(time.RFC3339),
 		Modified:       time.Unix(ufr.ModifiedTimestamp, 0).In(l).Format(time.RFC3339),
 		RuDisplay:      ufr.RuDisplay,
+		RuRoomRole:     ufr.RuRoomRole,
 	})
 }
 
 type CreateRoomRequest struct {
 	scpb.CreateRoomRequest
-	MetaData JSONText `json:"metaData,omitempty" db:"meta_data"`
+	MetaData          JSONText `json:"metaData,omitempty" db:"meta_data"`
+	RetentionMaxAge   *int64   `json:"retentionMaxAge,omitempty" db:"-"`
+	RetentionMaxCount *int64   `json:"retentionMaxCount,omitempty" db:"-"`
+	SpeechRoles       *string  `json:"speechRoles,omitempty" db:"-"`
 }
 
 func (r *CreateRoomRequest) Validate() *ErrorResponse {
@@ -220,18 +392,47 @@ func (r *CreateRoomRequest) Validate() *ErrorResponse {
 		return NewErrorResponse("Failed to create room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
 	}
 
-	// if r.SpeechMode != nil && !(*r.SpeechMode > 0 && *r.SpeechMode < SpeechModeEnd) {
-	// 	return &ProblemDetail{
-	// 		Message: "Invalid params",
-	// 		InvalidParams: []*InvalidParam{
-	// 			&InvalidParam{
-	// 				Name:   "speechMode",
-	// 				Reason: "speechMode is incorrect.",
-	// 			},
-	// 		},
-	// 		Status: http.StatusBadRequest,
-	// 	}
-	// }
+	if r.RetentionMaxAge != nil && *r.RetentionMaxAge < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "retentionMaxAge",
+				Reason: "retentionMaxAge must be 0 or more.",
+			},
+		}
+		return NewErrorResponse("Failed to create room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if r.RetentionMaxCount != nil && *r.RetentionMaxCount < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "retentionMaxCount",
+				Reason: "retentionMaxCount must be 0 or more.",
+			},
+		}
+		return NewErrorResponse("Failed to create room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if r.SpeechMode != nil && !isValidSpeechMode(*r.SpeechMode) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "speechMode",
+				Reason: "speechMode is incorrect.",
+			},
+		}
+		return NewErrorResponse("Failed to create room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if r.SpeechRoles != nil {
+		if _, err := parseRoles(*r.SpeechRoles); err != nil {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "speechRoles",
+					Reason: "speechRoles must be comma separated roles.",
+				},
+			}
+			return NewErrorResponse("Failed to create room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
 
 	return nil
 }
@@ -283,6 +484,10 @@ func (crr *CreateRoomRequest) GenerateRoom() *Room {
 		r.SpeechMode = *crr.SpeechMode
 	}
 
+	if crr.SpeechRoles != nil {
+		r.SpeechRoles = *crr.SpeechRoles
+	}
+
 	if crr.MetaData == nil {
 		r.MetaData = []byte("{}")
 	} else {
@@ -295,6 +500,14 @@ func (crr *CreateRoomRequest) GenerateRoom() *Room {
 		r.AvailableMessageTypes = *crr.AvailableMessageTypes
 	}
 
+	if crr.RetentionMaxAge != nil {
+		r.RetentionMaxAge = *crr.RetentionMaxAge
+	}
+
+	if crr.RetentionMaxCount != nil {
+		r.RetentionMaxCount = *crr.RetentionMaxCount
+	}
+
 	nowTimestamp := time.Now().Unix()
 	r.LastMessageUpdatedTimestamp = nowTimestamp
 	r.CreatedTimestamp = nowTimestamp
@@ -310,6 +523,7 @@ func (crr *CreateRoomRequest) GenerateRoomUsers() []*RoomUser {
 	me.UserID = *crr.UserID
 	me.UnreadCount = int32(0)
 	me.Display = true
+	me.RoomRole = RoomRoleOwner
 
 	rus[0] = me
 	for i := 0; i < len(crr.UserIDs); i++ {
@@ -318,6 +532,7 @@ func (crr *CreateRoomRequest) GenerateRoomUsers() []*RoomUser {
 		ru.UserID = crr.UserIDs[i]
 		ru.UnreadCount = int32(0)
 		ru.Display = true
+		ru.RoomRole = RoomRoleMember
 		rus[i+1] = ru
 	}
 	return rus
@@ -358,7 +573,10 @@ type RetrieveRoomRequest struct {
 
 type UpdateRoomRequest struct {
 	scpb.UpdateRoomRequest
-	MetaData JSONText `json:"metaData,omitempty" db:"meta_data"`
+	MetaData          JSONText `json:"metaData,omitempty" db:"meta_data"`
+	RetentionMaxAge   *int64   `json:"retentionMaxAge,omitempty" db:"-"`
+	RetentionMaxCount *int64   `json:"retentionMaxCount,omitempty" db:"-"`
+	SpeechRoles       *string  `json:"speechRoles,omitempty" db:"-"`
 }
 
 func (uur *UpdateRoomRequest) Validate(room *Room) *ErrorResponse {
@@ -393,16 +611,66 @@ func (uur *UpdateRoomRequest) Validate(room *Room) *ErrorResponse {
 		return NewErrorResponse("Failed to update room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
 	}
 
+	if uur.RetentionMaxAge != nil && *uur.RetentionMaxAge < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "retentionMaxAge",
+				Reason: "retentionMaxAge must be 0 or more.",
+			},
+		}
+		return NewErrorResponse("Failed to update room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if uur.RetentionMaxCount != nil && *uur.RetentionMaxCount < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "retentionMaxCount",
+				Reason: "retentionMaxCount must be 0 or more.",
+			},
+		}
+		return NewErrorResponse("Failed to update room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if uur.SpeechMode != nil && !isValidSpeechMode(*uur.SpeechMode) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "speechMode",
+				Reason: "speechMode is incorrect.",
+			},
+		}
+		return NewErrorResponse("Failed to update room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if uur.SpeechRoles != nil {
+		if _, err := parseRoles(*uur.SpeechRoles); err != nil {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "speechRoles",
+					Reason: "speechRoles must be comma separated roles.",
+				},
+			}
+			return NewErrorResponse("Failed to update room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
 	return nil
 }
 
+// GenerateRoomUsers generates the room users replacing the current ones, which is done only when UserIDs is given.
+// The users who are already in the room keep their room roles, and the other columns are kept by the datastore.
 func (uur *UpdateRoomRequest) GenerateRoomUsers(room *Room) []*RoomUser {
+	roomRoles := make(map[string]RoomRole, len(room.Users))
+	for _, u := range room.Users {
+		roomRoles[u.UserID] = u.RuRoomRole
+	}
+
 	rus := make([]*RoomUser, len(uur.UserIDs)+1)
 	me := &RoomUser{}
 	me.RoomID = room.RoomID
 	me.UserID = room.UserID
 	me.UnreadCount = int32(0)
 	me.Display = true
+	me.RoomRole = RoomRoleOwner
 
 	rus[0] = me
 	for i := 0; i < len(uur.UserIDs); i++ {
@@ -411,6 +679,10 @@ func (uur *UpdateRoomRequest) GenerateRoomUsers(room *Room) []*RoomUser {
 		ru.UserID = uur.UserIDs[i]
 		ru.UnreadCount = int32(0)
 		ru.Display = true
+		ru.RoomRole = RoomRoleMember
+		if roomRole, ok := roomRoles[ru.UserID]; ok && roomRole.IsValid() && roomRole != RoomRoleOwner {
+			ru.RoomRole = roomRole
+		}
 		rus[i+1] = ru
 	}
 	return rus
@@ -420,6 +692,58 @@ type DeleteRoomRequest struct {
 	scpb.DeleteRoomRequest
 }
 
+// ArchiveRoomRequest is the request to archive the room or to unarchive the archived room
+type ArchiveRoomRequest struct {
+	RoomID string `json:"roomId"`
+	Room   *Room  `json:"-"`
+}
+
+func (arr *ArchiveRoomRequest) Validate() *ErrorResponse {
+	if arr.Room.Type == scpb.RoomType_OneOnOneRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "In case of 1-on-1 room type, Can not archive the room.",
+			},
+		}
+		return NewErrorResponse("Failed to archive room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// RestoreRoomRequest is the request to restore the deleted room within the restore period
+type RestoreRoomRequest struct {
+	RoomID string `json:"roomId"`
+	Room   *Room  `json:"-"`
+}
+
+// Validate validates that the room can be restored at nowTimestamp.
+// restorePeriod is the seconds the deleted rooms can be restored for.
+func (rrr *RestoreRoomRequest) Validate(nowTimestamp, restorePeriod int64) *ErrorResponse {
+	if rrr.Room.DeletedTimestamp == 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomId",
+				Reason: "The room has not been deleted.",
+			},
+		}
+		return NewErrorResponse("Failed to restore room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if restorePeriod == 0 || rrr.Room.DeletedTimestamp+restorePeriod < nowTimestamp {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomId",
+				Reason: "The restore period of the room has passed.",
+			},
+		}
+		return NewErrorResponse("Failed to restore room.", http.StatusGone, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
 type RetrieveRoomMessagesRequest struct {
 	scpb.RetrieveRoomMessagesRequest
 }
@@ -0,0 +1,276 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// Types of the room invitations
+const (
+	// RoomInvitationTypeInvitation is the invitation from a room admin to the user
+	RoomInvitationTypeInvitation = "invitation"
+	// RoomInvitationTypeJoinRequest is the request of the user to join the room, approved by a room admin
+	RoomInvitationTypeJoinRequest = "joinRequest"
+)
+
+// Statuses of the room invitations
+const (
+	RoomInvitationStatusPending  = "pending"
+	RoomInvitationStatusAccepted = "accepted"
+	RoomInvitationStatusDeclined = "declined"
+	RoomInvitationStatusExpired  = "expired"
+)
+
+// RoomInvitation is model of the invitation to the room and the request to join the room.
+// UserID is the user who joins the room, InviterUserID is the user who invited and ReviewerUserID is the user who approved or declined the join request.
+type RoomInvitation struct {
+	ID             uint64 `json:"-" db:"id"`
+	InvitationID   string `json:"invitationId" db:"invitation_id,notnull"`
+	Type           string `json:"type" db:"type,notnull"`
+	RoomID         string `json:"roomId" db:"room_id,notnull"`
+	UserID         string `json:"userId" db:"user_id,notnull"`
+	InviterUserID  string `json:"inviterUserId" db:"inviter_user_id,notnull"`
+	ReviewerUserID string `json:"reviewerUserId" db:"reviewer_user_id,notnull"`
+	Message        string `json:"message" db:"message,notnull"`
+	Status         string `json:"status" db:"status,notnull"`
+	Expires        int64  `json:"expires" db:"expires,notnull"`
+	Created        int64  `json:"created" db:"created,notnull"`
+	Modified       int64  `json:"modified" db:"modified,notnull"`
+}
+
+// MarshalJSON is MarshalJSON of RoomInvitation
+func (ri *RoomInvitation) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	expires := ""
+	if ri.Expires != 0 {
+		expires = time.Unix(ri.Expires, 0).In(l).Format(time.RFC3339)
+	}
+	return json.Marshal(&struct {
+		InvitationID   string `json:"invitationId"`
+		Type           string `json:"type"`
+		RoomID         string `json:"roomId"`
+		UserID         string `json:"userId"`
+		InviterUserID  string `json:"inviterUserId,omitempty"`
+		ReviewerUserID string `json:"reviewerUserId,omitempty"`
+		Message        string `json:"message,omitempty"`
+		Status         string `json:"status"`
+		Expires        string `json:"expires,omitempty"`
+		Created        string `json:"created"`
+		Modified       string `json:"modified"`
+	}{
+		InvitationID:   ri.InvitationID,
+		Type:           ri.Type,
+		RoomID:         ri.RoomID,
+		UserID:         ri.UserID,
+		InviterUserID:  ri.InviterUserID,
+		ReviewerUserID: ri.ReviewerUserID,
+		Message:        ri.Message,
+		Status:         ri.Status,
+		Expires:        expires,
+		Created:        time.Unix(ri.Created, 0).In(l).Format(time.RFC3339),
+		Modified:       time.Unix(ri.Modified, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// IsExpired returns whether the pending invitation is over the expiration
+func (ri *RoomInvitation) IsExpired(nowTimestamp int64) bool {
+	return ri.Status == RoomInvitationStatusPending && ri.Expires != 0 && ri.Expires <= nowTimestamp
+}
+
+// CreateRoomInvitationsRequest is the request to invite the users to the room
+type CreateRoomInvitationsRequest struct {
+	RoomID  string   `json:"roomId"`
+	UserIDs []string `json:"userIds"`
+	Message string   `json:"message,omitempty"`
+	// TTL is the seconds until the invitations expire. They don't expire if it's zero.
+	TTL  int64 `json:"ttl,omitempty"`
+	Room *Room `json:"-"`
+}
+
+func (crir *CreateRoomInvitationsRequest) Validate() *ErrorResponse {
+	if crir.Room.Type == scpb.RoomType_OneOnOneRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "In case of 1-on-1 room type, Can not invite users.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invitations.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if len(crir.UserIDs) == 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userIds",
+				Reason: "userIds is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invitations.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	for _, user := range crir.Room.Users {
+		if utils.SearchStringValueInSlice(crir.UserIDs, user.UserID) {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "userIds",
+					Reason: fmt.Sprintf("%s is already a member of the room.", user.UserID),
+				},
+			}
+			return NewErrorResponse("Failed to create room invitations.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
+	if crir.TTL < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "ttl",
+				Reason: "ttl must be zero or positive.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invitations.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+func (crir *CreateRoomInvitationsRequest) GenerateRoomInvitations(inviterUserID string) []*RoomInvitation {
+	nowTimestamp := time.Now().Unix()
+	var expires int64
+	if crir.TTL > 0 {
+		expires = nowTimestamp + crir.TTL
+	}
+
+	userIDs := utils.RemoveDuplicateString(crir.UserIDs)
+	invitations := make([]*RoomInvitation, len(userIDs))
+	for i, userID := range userIDs {
+		invitations[i] = &RoomInvitation{
+			InvitationID:  utils.GenerateUUID(),
+			Type:          RoomInvitationTypeInvitation,
+			RoomID:        crir.RoomID,
+			UserID:        userID,
+			InviterUserID: inviterUserID,
+			Message:       crir.Message,
+			Status:        RoomInvitationStatusPending,
+			Expires:       expires,
+			Created:       nowTimestamp,
+			Modified:      nowTimestamp,
+		}
+	}
+	return invitations
+}
+
+// CreateRoomJoinRequestRequest is the request of the user to join the private room
+type CreateRoomJoinRequestRequest struct {
+	RoomID  string `json:"roomId"`
+	UserID  string `json:"userId"`
+	Message string `json:"message,omitempty"`
+	Room    *Room  `json:"-"`
+}
+
+func (crjr *CreateRoomJoinRequestRequest) Validate() *ErrorResponse {
+	if crjr.UserID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to create room join request.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if crjr.Room.Type != scpb.RoomType_PrivateRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "Join requests are only for private rooms.",
+			},
+		}
+		return NewErrorResponse("Failed to create room join request.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	for _, user := range crjr.Room.Users {
+		if user.UserID == crjr.UserID {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "userId",
+					Reason: "userId is already a member of the room.",
+				},
+			}
+			return NewErrorResponse("Failed to create room join request.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+		}
+	}
+
+	return nil
+}
+
+func (crjr *CreateRoomJoinRequestRequest) GenerateRoomInvitation() *RoomInvitation {
+	nowTimestamp := time.Now().Unix()
+	return &RoomInvitation{
+		InvitationID: utils.GenerateUUID(),
+		Type:         RoomInvitationTypeJoinRequest,
+		RoomID:       crjr.RoomID,
+		UserID:       crjr.UserID,
+		Message:      crjr.Message,
+		Status:       RoomInvitationStatusPending,
+		Created:      nowTimestamp,
+		Modified:     nowTimestamp,
+	}
+}
+
+// RetrieveRoomInvitationsRequest is the request to retrieve the invitations and the join requests.
+// They are filtered by the non-empty fields.
+type RetrieveRoomInvitationsRequest struct {
+	RoomID string
+	UserID string
+	Type   string
+	Status string
+	Limit  int32
+	Offset int32
+}
+
+// RoomInvitationsResponse is the response of the invitations and the join requests
+type RoomInvitationsResponse struct {
+	RoomInvitations []*RoomInvitation `json:"roomInvitations"`
+	AllCount        int64             `json:"allCount"`
+	Limit           int32             `json:"limit"`
+	Offset          int32             `json:"offset"`
+}
+
+// UpdateRoomInvitationRequest is the request to accept or decline the invitation or the join request
+type UpdateRoomInvitationRequest struct {
+	InvitationID string `json:"invitationId"`
+	Status       string `json:"status"`
+}
+
+func (urir *UpdateRoomInvitationRequest) Validate(invitation *RoomInvitation) *ErrorResponse {
+	if urir.Status != RoomInvitationStatusAccepted && urir.Status != RoomInvitationStatusDeclined {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "status",
+				Reason: fmt.Sprintf("status must be %s or %s.", RoomInvitationStatusAccepted, RoomInvitationStatusDeclined),
+			},
+		}
+		return NewErrorResponse("Failed to update room invitation.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	status := invitation.Status
+	if invitation.IsExpired(time.Now().Unix()) {
+		status = RoomInvitationStatusExpired
+	}
+	if status != RoomInvitationStatusPending {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "invitationId",
+				Reason: fmt.Sprintf("The invitation is not pending. It's %s.", status),
+			},
+		}
+		return NewErrorResponse("Failed to update room invitation.", http.StatusConflict, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
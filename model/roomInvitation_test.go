@@ -0,0 +1,130 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestModelCreateRoomInvitationsRequest = "[model] CreateRoomInvitationsRequest test"
+	TestModelCreateRoomJoinRequestRequest = "[model] CreateRoomJoinRequestRequest test"
+	TestModelUpdateRoomInvitationRequest  = "[model] UpdateRoomInvitationRequest test"
+	TestModelRoomInviteLink               = "[model] RoomInviteLink test"
+)
+
+func TestRoomInvitation(t *testing.T) {
+	t.Run(TestModelCreateRoomInvitationsRequest, func(t *testing.T) {
+		room := &Room{}
+		room.Type = scpb.RoomType_PrivateRoom
+		room.Users = []*MiniUser{&MiniUser{}}
+		room.Users[0].UserID = "model-user-id-0001"
+
+		req := &CreateRoomInvitationsRequest{}
+		req.RoomID = "model-room-id-0001"
+		req.Room = room
+		errRes := req.Validate()
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected errRes to be not nil, but it was nil", TestModelCreateRoomInvitationsRequest)
+		}
+
+		req.UserIDs = []string{"model-user-id-0001"}
+		errRes = req.Validate()
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected the member not to be invited", TestModelCreateRoomInvitationsRequest)
+		}
+
+		req.UserIDs = []string{"model-user-id-0002", "model-user-id-0002", "model-user-id-0003"}
+		req.TTL = 60
+		errRes = req.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelCreateRoomInvitationsRequest)
+		}
+
+		invitations := req.GenerateRoomInvitations("model-user-id-0001")
+		if len(invitations) != 2 {
+			t.Fatalf("Failed to %s. Expected invitations count to be 2, but it was %d", TestModelCreateRoomInvitationsRequest, len(invitations))
+		}
+		if invitations[0].Status != RoomInvitationStatusPending || invitations[0].Expires == 0 {
+			t.Fatalf("Failed to %s. Expected the invitation to be pending with the expiration", TestModelCreateRoomInvitationsRequest)
+		}
+
+		room.Type = scpb.RoomType_OneOnOneRoom
+		errRes = req.Validate()
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected the users not to be invited to 1-on-1 room", TestModelCreateRoomInvitationsRequest)
+		}
+	})
+
+	t.Run(TestModelCreateRoomJoinRequestRequest, func(t *testing.T) {
+		room := &Room{}
+		room.Type = scpb.RoomType_PublicRoom
+
+		req := &CreateRoomJoinRequestRequest{}
+		req.RoomID = "model-room-id-0001"
+		req.UserID = "model-user-id-0001"
+		req.Room = room
+		errRes := req.Validate()
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected join requests not to be created for public room", TestModelCreateRoomJoinRequestRequest)
+		}
+
+		room.Type = scpb.RoomType_PrivateRoom
+		errRes = req.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelCreateRoomJoinRequestRequest)
+		}
+
+		invitation := req.GenerateRoomInvitation()
+		if invitation.Type != RoomInvitationTypeJoinRequest {
+			t.Fatalf("Failed to %s. Expected type to be %s, but it was %s", TestModelCreateRoomJoinRequestRequest, RoomInvitationTypeJoinRequest, invitation.Type)
+		}
+	})
+
+	t.Run(TestModelUpdateRoomInvitationRequest, func(t *testing.T) {
+		invitation := &RoomInvitation{}
+		invitation.Status = RoomInvitationStatusPending
+
+		req := &UpdateRoomInvitationRequest{}
+		req.Status = RoomInvitationStatusExpired
+		errRes := req.Validate(invitation)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the status to be invalid", TestModelUpdateRoomInvitationRequest)
+		}
+
+		req.Status = RoomInvitationStatusAccepted
+		errRes = req.Validate(invitation)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelUpdateRoomInvitationRequest)
+		}
+
+		invitation.Expires = time.Now().Unix() - 1
+		errRes = req.Validate(invitation)
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected the expired invitation not to be accepted", TestModelUpdateRoomInvitationRequest)
+		}
+	})
+
+	t.Run(TestModelRoomInviteLink, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		ril := &RoomInviteLink{}
+		ril.MaxUses = 2
+		ril.Uses = 1
+		if !ril.IsAvailable(nowTimestamp) {
+			t.Fatalf("Failed to %s. Expected the link to be available", TestModelRoomInviteLink)
+		}
+
+		ril.Uses = 2
+		if ril.IsAvailable(nowTimestamp) {
+			t.Fatalf("Failed to %s. Expected the link used up not to be available", TestModelRoomInviteLink)
+		}
+
+		ril.MaxUses = 0
+		ril.Expires = nowTimestamp
+		if ril.IsAvailable(nowTimestamp) {
+			t.Fatalf("Failed to %s. Expected the expired link not to be available", TestModelRoomInviteLink)
+		}
+	})
+}
@@ -0,0 +1,154 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// RoomInviteLink is model of the shareable link to join the room.
+// InviteLinkID is the token of the link. The link can be used MaxUses times, or unlimited times if MaxUses is zero.
+type RoomInviteLink struct {
+	ID           uint64 `json:"-" db:"id"`
+	InviteLinkID string `json:"inviteLinkId" db:"invite_link_id,notnull"`
+	RoomID       string `json:"roomId" db:"room_id,notnull"`
+	UserID       string `json:"userId" db:"user_id,notnull"`
+	MaxUses      int32  `json:"maxUses" db:"max_uses,notnull"`
+	Uses         int32  `json:"uses" db:"uses,notnull"`
+	Expires      int64  `json:"expires" db:"expires,notnull"`
+	Created      int64  `json:"created" db:"created,notnull"`
+	Modified     int64  `json:"modified" db:"modified,notnull"`
+	Deleted      int64  `json:"-" db:"deleted,notnull"`
+}
+
+// MarshalJSON is MarshalJSON of RoomInviteLink
+func (ril *RoomInviteLink) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	expires := ""
+	if ril.Expires != 0 {
+		expires = time.Unix(ril.Expires, 0).In(l).Format(time.RFC3339)
+	}
+	return json.Marshal(&struct {
+		InviteLinkID string `json:"inviteLinkId"`
+		RoomID       string `json:"roomId"`
+		UserID       string `json:"userId,omitempty"`
+		MaxUses      int32  `json:"maxUses"`
+		Uses         int32  `json:"uses"`
+		Expires      string `json:"expires,omitempty"`
+		Created      string `json:"created"`
+		Modified     string `json:"modified"`
+	}{
+		InviteLinkID: ril.InviteLinkID,
+		RoomID:       ril.RoomID,
+		UserID:       ril.UserID,
+		MaxUses:      ril.MaxUses,
+		Uses:         ril.Uses,
+		Expires:      expires,
+		Created:      time.Unix(ril.Created, 0).In(l).Format(time.RFC3339),
+		Modified:     time.Unix(ril.Modified, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// IsAvailable returns whether the link can be used to join the room
+func (ril *RoomInviteLink) IsAvailable(nowTimestamp int64) bool {
+	if ril.Deleted != 0 {
+		return false
+	}
+	if ril.Expires != 0 && ril.Expires <= nowTimestamp {
+		return false
+	}
+	return ril.MaxUses == 0 || ril.Uses < ril.MaxUses
+}
+
+// CreateRoomInviteLinkRequest is the request to create the invite link of the room
+type CreateRoomInviteLinkRequest struct {
+	RoomID  string `json:"roomId"`
+	MaxUses int32  `json:"maxUses,omitempty"`
+	// TTL is the seconds until the link expires. It doesn't expire if it's zero.
+	TTL  int64 `json:"ttl,omitempty"`
+	Room *Room `json:"-"`
+}
+
+func (crilr *CreateRoomInviteLinkRequest) Validate() *ErrorResponse {
+	if crilr.Room.Type == scpb.RoomType_OneOnOneRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "In case of 1-on-1 room type, Can not create invite links.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invite link.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if crilr.MaxUses < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "maxUses",
+				Reason: "maxUses must be zero or positive.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invite link.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if crilr.TTL < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "ttl",
+				Reason: "ttl must be zero or positive.",
+			},
+		}
+		return NewErrorResponse("Failed to create room invite link.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+func (crilr *CreateRoomInviteLinkRequest) GenerateRoomInviteLink(userID string) *RoomInviteLink {
+	nowTimestamp := time.Now().Unix()
+	ril := &RoomInviteLink{
+		InviteLinkID: utils.GenerateUUID(),
+		RoomID:       crilr.RoomID,
+		UserID:       userID,
+		MaxUses:      crilr.MaxUses,
+		Created:      nowTimestamp,
+		Modified:     nowTimestamp,
+	}
+	if crilr.TTL > 0 {
+		ril.Expires = nowTimestamp + crilr.TTL
+	}
+	return ril
+}
+
+// RoomInviteLinksResponse is the response of the invite links of the room
+type RoomInviteLinksResponse struct {
+	RoomInviteLinks []*RoomInviteLink `json:"roomInviteLinks"`
+}
+
+// DeleteRoomInviteLinkRequest is the request to revoke the invite link
+type DeleteRoomInviteLinkRequest struct {
+	RoomID       string `json:"roomId"`
+	InviteLinkID string `json:"inviteLinkId"`
+}
+
+// JoinRoomByInviteLinkRequest is the request to join the room with the invite link
+type JoinRoomByInviteLinkRequest struct {
+	InviteLinkID string `json:"inviteLinkId"`
+	UserID       string `json:"userId"`
+}
+
+func (jrilr *JoinRoomByInviteLinkRequest) Validate() *ErrorResponse {
+	if jrilr.UserID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to join room.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
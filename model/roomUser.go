@@ -1,23 +1,156 @@
 package model
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
+// RoomRole is the role of the user in the room
+type RoomRole string
+
+const (
+	// RoomRoleOwner can do everything in the room. A room has only one owner.
+	RoomRoleOwner RoomRole = "owner"
+	// RoomRoleAdmin can update the room and manage the members and the messages
+	RoomRoleAdmin RoomRole = "admin"
+	// RoomRoleMember can send messages
+	RoomRoleMember RoomRole = "member"
+	// RoomRoleReadOnly can only read messages
+	RoomRoleReadOnly RoomRole = "readOnly"
+)
+
+var roomRoleRanks = map[RoomRole]int{
+	RoomRoleOwner:    3,
+	RoomRoleAdmin:    2,
+	RoomRoleMember:   1,
+	RoomRoleReadOnly: 0,
+}
+
+// IsValid returns whether the role is defined
+func (rr RoomRole) IsValid() bool {
+	_, ok := roomRoleRanks[rr]
+	return ok
+}
+
+// Outranks returns whether the role is higher than the other role
+func (rr RoomRole) Outranks(other RoomRole) bool {
+	return roomRoleRanks[rr] > roomRoleRanks[other]
+}
+
+// DeletedRoomUser is the room user of the deleted room, which is kept with all of its columns to restore the room
+type DeletedRoomUser struct {
+	RoomID            string   `db:"room_id,notnull"`
+	UserID            string   `db:"user_id,notnull"`
+	UnreadCount       int32    `db:"unread_count,notnull"`
+	MentionCount      int32    `db:"mention_count,notnull"`
+	Display           bool     `db:"display,notnull"`
+	RoomRole          RoomRole `db:"room_role,notnull"`
+	Favorite          bool     `db:"favorite,notnull"`
+	Hidden            bool     `db:"hidden,notnull"`
+	Muted             bool     `db:"muted,notnull"`
+	Archived          bool     `db:"archived,notnull"`
+	SortKey           int64    `db:"sort_key,notnull"`
+	LastReadTimestamp int64    `db:"last_read,notnull"`
+	Deleted           int64    `db:"deleted,notnull"`
+}
+
+// RoomUser returns the room user to restore
+func (dru *DeletedRoomUser) RoomUser() *RoomUser {
+	ru := &RoomUser{}
+	ru.RoomID = dru.RoomID
+	ru.UserID = dru.UserID
+	ru.UnreadCount = dru.UnreadCount
+	ru.MentionCount = dru.MentionCount
+	ru.Display = dru.Display
+	ru.RoomRole = dru.RoomRole
+	ru.Favorite = dru.Favorite
+	ru.Hidden = dru.Hidden
+	ru.Muted = dru.Muted
+	ru.Archived = dru.Archived
+	ru.SortKey = dru.SortKey
+	ru.LastReadTimestamp = dru.LastReadTimestamp
+	return ru
+}
+
 type RoomUser struct {
 	scpb.RoomUser
+	MentionCount int32    `json:"mentionCount" db:"mention_count,notnull"`
+	RoomRole     RoomRole `json:"roomRole" db:"room_role,notnull"`
+	// Favorite rooms are listed at the top of the rooms of the user
+	Favorite bool `json:"favorite" db:"favorite,notnull"`
+	// Hidden rooms are excluded from the rooms of the user until a new message arrives
+	Hidden bool `json:"hidden" db:"hidden,notnull"`
+	// Muted rooms don't push the notifications except for the mentions
+	Muted bool `json:"muted" db:"muted,notnull"`
+	// Archived rooms are excluded from the rooms of the user like the rooms archived for everyone
+	Archived bool `json:"archived" db:"archived,notnull"`
+	// SortKey is the custom order of the rooms of the user in ascending order
+	SortKey           int64 `json:"sortKey" db:"sort_key,notnull"`
+	LastReadTimestamp int64 `json:"lastReadTimestamp" db:"last_read,notnull"`
+}
+
+// CanManageRoom returns whether the room user can update the room and manage the members
+func (ru *RoomUser) CanManageRoom() bool {
+	return ru.RoomRole == RoomRoleOwner || ru.RoomRole == RoomRoleAdmin
+}
+
+// CanModerate returns whether the room user can remove the other room user or delete the messages of them
+func (ru *RoomUser) CanModerate(other *RoomUser) bool {
+	if !ru.CanManageRoom() {
+		return false
+	}
+	if other == nil {
+		return true
+	}
+	return ru.RoomRole.Outranks(other.RoomRole)
 }
 
 func (ru *RoomUser) UpdateRoomUser(req *UpdateRoomUserRequest) {
 	if req.UnreadCount != nil {
 		ru.UnreadCount = *req.UnreadCount
+
+		// Mentions are read together with the unread messages
+		if ru.UnreadCount == 0 {
+			ru.MentionCount = 0
+		}
 	}
 
 	if req.Display != nil {
 		ru.Display = *req.Display
 	}
+
+	if req.RoomRole != nil {
+		ru.RoomRole = *req.RoomRole
+	}
+
+	if req.Favorite != nil {
+		ru.Favorite = *req.Favorite
+	}
+
+	if req.Hidden != nil {
+		ru.Hidden = *req.Hidden
+	}
+
+	if req.Muted != nil {
+		ru.Muted = *req.Muted
+	}
+
+	if req.Archived != nil {
+		ru.Archived = *req.Archived
+	}
+
+	if req.SortKey != nil {
+		ru.SortKey = *req.SortKey
+	}
+
+	if req.LastReadTimestamp != nil {
+		ru.LastReadTimestamp = *req.LastReadTimestamp
+	} else if req.UnreadCount != nil && *req.UnreadCount == 0 {
+		ru.LastReadTimestamp = time.Now().Unix()
+	}
 }
 
 type AddRoomUsersRequest struct {
@@ -79,6 +212,7 @@ func (crur *AddRoomUsersRequest) GenerateRoomUsers() []*RoomUser {
 		ru.UserID = userID
 		ru.UnreadCount = int32(0)
 		ru.Display = crur.Display
+		ru.RoomRole = RoomRoleMember
 		roomUsers[i] = ru
 	}
 	return roomUsers
@@ -124,6 +258,148 @@ func (rur *RoomUserIdsResponse) ConvertToPbRoomUserIDs() *scpb.RoomUserIdsRespon
 
 type UpdateRoomUserRequest struct {
 	scpb.UpdateRoomUserRequest
+	RoomRole          *RoomRole `json:"roomRole,omitempty"`
+	Favorite          *bool     `json:"favorite,omitempty"`
+	Hidden            *bool     `json:"hidden,omitempty"`
+	Muted             *bool     `json:"muted,omitempty"`
+	Archived          *bool     `json:"archived,omitempty"`
+	SortKey           *int64    `json:"sortKey,omitempty"`
+	LastReadTimestamp *int64    `json:"lastReadTimestamp,omitempty"`
+}
+
+// HasPersonalSettings returns whether the request updates the personal settings of the room user
+func (uurr *UpdateRoomUserRequest) HasPersonalSettings() bool {
+	return uurr.Favorite != nil ||
+		uurr.Hidden != nil ||
+		uurr.Muted != nil ||
+		uurr.Archived != nil ||
+		uurr.SortKey != nil ||
+		uurr.LastReadTimestamp != nil
+}
+
+func (uurr *UpdateRoomUserRequest) Validate() *ErrorResponse {
+	if uurr.LastReadTimestamp != nil && *uurr.LastReadTimestamp < 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "lastReadTimestamp",
+				Reason: "lastReadTimestamp must be greater than or equal to 0.",
+			},
+		}
+		return NewErrorResponse("Failed to update room user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if uurr.RoomRole == nil {
+		return nil
+	}
+
+	if !uurr.RoomRole.IsValid() {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomRole",
+				Reason: fmt.Sprintf("roomRole is incorrect. It must be one of %s, %s, %s or %s.", RoomRoleOwner, RoomRoleAdmin, RoomRoleMember, RoomRoleReadOnly),
+			},
+		}
+		return NewErrorResponse("Failed to update room user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if *uurr.RoomRole == RoomRoleOwner {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomRole",
+				Reason: "The owner can not be set by updating room user. Transfer the ownership of the room instead.",
+			},
+		}
+		return NewErrorResponse("Failed to update room user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// ValidateRoomRole validates that the request room user can change the role of the room user.
+// requestRoomUser is nil if the request is not restricted by the room roles.
+func (uurr *UpdateRoomUserRequest) ValidateRoomRole(requestRoomUser, roomUser *RoomUser) *ErrorResponse {
+	if uurr.RoomRole == nil || requestRoomUser == nil {
+		return nil
+	}
+
+	if !requestRoomUser.CanModerate(roomUser) || !requestRoomUser.RoomRole.Outranks(*uurr.RoomRole) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomRole",
+				Reason: "You do not have permission to change the role of the room user.",
+			},
+		}
+		return NewErrorResponse("Failed to update room user.", http.StatusForbidden, WithInvalidParams(invalidParams))
+	}
+
+	if roomUser.RoomRole == RoomRoleOwner {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "The role of the owner can not be changed. Transfer the ownership of the room instead.",
+			},
+		}
+		return NewErrorResponse("Failed to update room user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// ValidatePersonalSettings validates that only the room user updates their own personal settings.
+// requestUserID is empty if the request is not restricted to the user.
+func (uurr *UpdateRoomUserRequest) ValidatePersonalSettings(requestUserID string) *ErrorResponse {
+	if !uurr.HasPersonalSettings() || requestUserID == "" || requestUserID == uurr.UserID {
+		return nil
+	}
+
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "userId",
+			Reason: "The personal settings of the room can only be updated by the room user.",
+		},
+	}
+	return NewErrorResponse("Failed to update room user.", http.StatusForbidden, WithInvalidParams(invalidParams))
+}
+
+// TransferRoomOwnerRequest is the request to transfer the ownership of the room to the other room user
+type TransferRoomOwnerRequest struct {
+	RoomID string `json:"roomId"`
+	UserID string `json:"userId"`
+	Room   *Room  `json:"-"`
+}
+
+func (tror *TransferRoomOwnerRequest) Validate() *ErrorResponse {
+	if tror.UserID == "" {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is required, but it's empty.",
+			},
+		}
+		return NewErrorResponse("Failed to transfer room owner.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if tror.Room.Type == scpb.RoomType_OneOnOneRoom {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "room.type",
+				Reason: "In case of 1-on-1 room type, Can not transfer room owner.",
+			},
+		}
+		return NewErrorResponse("Failed to transfer room owner.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if tror.UserID == tror.Room.UserID {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is already the owner of the room.",
+			},
+		}
+		return NewErrorResponse("Failed to transfer room owner.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
 }
 
 type DeleteRoomUsersRequest struct {
@@ -176,3 +452,51 @@ func (drur *DeleteRoomUsersRequest) Validate() *ErrorResponse {
 
 	return nil
 }
+
+// ValidateCanLeft rejects the request user leaving the room if the room can not be left.
+// Removing the other users is not leaving, so it's not rejected.
+func (drur *DeleteRoomUsersRequest) ValidateCanLeft(requestUserID string) *ErrorResponse {
+	if drur.Room.CanLeft || requestUserID == "" {
+		return nil
+	}
+
+	for _, userID := range drur.UserIDs {
+		if userID == requestUserID {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "userIds",
+					Reason: "The room can not be left.",
+				},
+			}
+			return NewErrorResponse("Failed to delete room users.", http.StatusForbidden, WithInvalidParams(invalidParams))
+		}
+	}
+
+	return nil
+}
+
+// ValidateRoomRole validates that the request room user can remove the room users.
+// requestRoomUser is nil if the request is not restricted by the room roles.
+// Leaving the room is not removing, so it's not restricted.
+func (drur *DeleteRoomUsersRequest) ValidateRoomRole(requestRoomUser *RoomUser, roomUsers []*RoomUser) *ErrorResponse {
+	if requestRoomUser == nil {
+		return nil
+	}
+
+	for _, ru := range roomUsers {
+		if ru.UserID == requestRoomUser.UserID {
+			continue
+		}
+		if !requestRoomUser.CanModerate(ru) {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "userIds",
+					Reason: fmt.Sprintf("You do not have permission to remove %s from the room.", ru.UserID),
+				},
+			}
+			return NewErrorResponse("Failed to delete room users.", http.StatusForbidden, WithInvalidParams(invalidParams))
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package model
 
 import (
+	"net/http"
 	"testing"
 
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
@@ -12,6 +13,11 @@ const (
 	TestModelRoomUsersResponse      = "[model] RoomUsersResponse test"
 	TestModelRoomUserIdsResponse    = "[model] RoomUserIdsResponse test"
 	TestModelDeleteRoomUsersRequest = "[model] DeleteRoomUsersRequest test"
+	TestModelRoomRole               = "[model] RoomRole test"
+	TestModelUpdateRoomUserRole     = "[model] UpdateRoomUserRequest room role test"
+	TestModelDeleteRoomUsersRole    = "[model] DeleteRoomUsersRequest room role test"
+	TestModelTransferRoomOwner      = "[model] TransferRoomOwnerRequest test"
+	TestModelRoomUserPersonal       = "[model] UpdateRoomUserRequest personal settings test"
 )
 
 func TestRoomUser(t *testing.T) {
@@ -225,4 +231,149 @@ func TestRoomUser(t *testing.T) {
 			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil. %s is invalid", TestModelDeleteRoomUsersRequest, errRes.InvalidParams[0].Name)
 		}
 	})
+	owner := &RoomUser{RoomRole: RoomRoleOwner}
+	owner.UserID = "model-user-id-0001"
+	admin := &RoomUser{RoomRole: RoomRoleAdmin}
+	admin.UserID = "model-user-id-0002"
+	member := &RoomUser{RoomRole: RoomRoleMember}
+	member.UserID = "model-user-id-0003"
+	readOnly := &RoomUser{RoomRole: RoomRoleReadOnly}
+	readOnly.UserID = "model-user-id-0004"
+
+	t.Run(TestModelRoomRole, func(t *testing.T) {
+		if RoomRole("guest").IsValid() {
+			t.Fatalf("Failed to %s. Expected guest to be invalid", TestModelRoomRole)
+		}
+		if !owner.CanManageRoom() || !admin.CanManageRoom() || member.CanManageRoom() {
+			t.Fatalf("Failed to %s. Expected only the owner and the admins to be able to manage the room", TestModelRoomRole)
+		}
+		if !owner.CanModerate(admin) || admin.CanModerate(admin) || !admin.CanModerate(member) || member.CanModerate(readOnly) {
+			t.Fatalf("Failed to %s. Expected the managers to be able to moderate only the lower roles", TestModelRoomRole)
+		}
+	})
+
+	t.Run(TestModelUpdateRoomUserRole, func(t *testing.T) {
+		req := &UpdateRoomUserRequest{}
+		roomRole := RoomRoleOwner
+		req.RoomRole = &roomRole
+		errRes := req.Validate()
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the owner not to be set", TestModelUpdateRoomUserRole)
+		}
+
+		roomRole = RoomRoleAdmin
+		errRes = req.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelUpdateRoomUserRole)
+		}
+		errRes = req.ValidateRoomRole(admin, member)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the admin not to be able to grant admin", TestModelUpdateRoomUserRole)
+		}
+		errRes = req.ValidateRoomRole(owner, member)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected the owner to be able to grant admin", TestModelUpdateRoomUserRole)
+		}
+		errRes = req.ValidateRoomRole(nil, member)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected the request without room user not to be restricted", TestModelUpdateRoomUserRole)
+		}
+
+		roomRole = RoomRoleReadOnly
+		errRes = req.ValidateRoomRole(admin, member)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected the admin to be able to make the member read-only", TestModelUpdateRoomUserRole)
+		}
+	})
+
+	t.Run(TestModelDeleteRoomUsersRole, func(t *testing.T) {
+		req := &DeleteRoomUsersRequest{}
+		errRes := req.ValidateRoomRole(member, []*RoomUser{member})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected the member to be able to leave", TestModelDeleteRoomUsersRole)
+		}
+		errRes = req.ValidateRoomRole(member, []*RoomUser{readOnly})
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to remove the others", TestModelDeleteRoomUsersRole)
+		}
+		errRes = req.ValidateRoomRole(admin, []*RoomUser{member, readOnly})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected the admin to be able to remove the members", TestModelDeleteRoomUsersRole)
+		}
+	})
+
+	t.Run(TestModelTransferRoomOwner, func(t *testing.T) {
+		room := &Room{}
+		room.UserID = owner.UserID
+		room.Type = scpb.RoomType_PrivateRoom
+
+		req := &TransferRoomOwnerRequest{Room: room}
+		errRes := req.Validate()
+		if errRes == nil || errRes.InvalidParams[0].Name != "userId" {
+			t.Fatalf("Failed to %s. Expected userId to be required", TestModelTransferRoomOwner)
+		}
+
+		req.UserID = owner.UserID
+		errRes = req.Validate()
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected the current owner to be rejected", TestModelTransferRoomOwner)
+		}
+
+		req.UserID = admin.UserID
+		errRes = req.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelTransferRoomOwner)
+		}
+	})
+
+	t.Run(TestModelRoomUserPersonal, func(t *testing.T) {
+		req := &UpdateRoomUserRequest{}
+		req.UserID = "model-user-id-0001"
+		unreadCount := int32(0)
+		req.UnreadCount = &unreadCount
+		if req.HasPersonalSettings() {
+			t.Fatalf("Failed to %s. Expected the unread count not to be a personal setting", TestModelRoomUserPersonal)
+		}
+
+		favorite := true
+		req.Favorite = &favorite
+		sortKey := int64(3)
+		req.SortKey = &sortKey
+		if !req.HasPersonalSettings() {
+			t.Fatalf("Failed to %s. Expected the favorite to be a personal setting", TestModelRoomUserPersonal)
+		}
+
+		errRes := req.ValidatePersonalSettings("model-user-id-0002")
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the other user to be forbidden", TestModelRoomUserPersonal)
+		}
+		errRes = req.ValidatePersonalSettings("model-user-id-0001")
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelRoomUserPersonal)
+		}
+		errRes = req.ValidatePersonalSettings("")
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelRoomUserPersonal)
+		}
+
+		ru := &RoomUser{}
+		ru.UnreadCount = 5
+		ru.UpdateRoomUser(req)
+		if !ru.Favorite {
+			t.Fatalf("Failed to %s. Expected ru.Favorite to be true, but it was false", TestModelRoomUserPersonal)
+		}
+		if ru.SortKey != 3 {
+			t.Fatalf("Failed to %s. Expected ru.SortKey to be 3, but it was %d", TestModelRoomUserPersonal, ru.SortKey)
+		}
+		if ru.LastReadTimestamp == 0 {
+			t.Fatalf("Failed to %s. Expected ru.LastReadTimestamp to be set when all messages are read", TestModelRoomUserPersonal)
+		}
+
+		lastRead := int64(-1)
+		req.LastReadTimestamp = &lastRead
+		errRes = req.Validate()
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the negative lastReadTimestamp to be invalid", TestModelRoomUserPersonal)
+		}
+	})
 }
@@ -0,0 +1,252 @@
+package model
+
+import (
+	"net/http"
+	"testing"
+
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestModelRoomCanSpeak               = "[model] Room CanSpeak test"
+	TestModelRoomIsAvailableMessageType = "[model] Room IsAvailableMessageType test"
+	TestModelSendMessageRequestPolicy   = "[model] SendMessageRequest ValidatePolicy test"
+	TestModelRoomSpeechModeValidation   = "[model] CreateRoomRequest and UpdateRoomRequest speech mode test"
+	TestModelDeleteRoomUsersCanLeft     = "[model] DeleteRoomUsersRequest ValidateCanLeft test"
+	TestModelRoomSetTags                = "[model] Room SetTags test"
+	TestModelArchiveRoomRequest         = "[model] ArchiveRoomRequest Validate test"
+	TestModelRestoreRoomRequest         = "[model] RestoreRoomRequest Validate test"
+	TestModelRoomSetLastMessage         = "[model] Room SetLastMessage test"
+)
+
+func TestRoomPolicy(t *testing.T) {
+	owner := &User{}
+	owner.UserID = "model-user-id-0001"
+	member := &User{}
+	member.UserID = "model-user-id-0002"
+	member.Roles = []int32{1, 3}
+
+	t.Run(TestModelRoomCanSpeak, func(t *testing.T) {
+		room := &Room{}
+		room.UserID = owner.UserID
+
+		room.SpeechMode = SpeechModeEveryone
+		if !room.CanSpeak(member) {
+			t.Fatalf("Failed to %s. Expected everyone to be able to speak", TestModelRoomCanSpeak)
+		}
+
+		room.SpeechMode = SpeechModeOwnerOnly
+		if !room.CanSpeak(owner) || room.CanSpeak(member) {
+			t.Fatalf("Failed to %s. Expected only the owner to be able to speak", TestModelRoomCanSpeak)
+		}
+
+		room.SpeechMode = SpeechModeRoles
+		room.SpeechRoles = "2, 3"
+		if room.CanSpeak(owner) || !room.CanSpeak(member) {
+			t.Fatalf("Failed to %s. Expected only the users of the speech roles to be able to speak", TestModelRoomCanSpeak)
+		}
+	})
+
+	t.Run(TestModelRoomIsAvailableMessageType, func(t *testing.T) {
+		room := &Room{}
+		if !room.IsAvailableMessageType(MessageTypeImage) {
+			t.Fatalf("Failed to %s. Expected all of the types to be available", TestModelRoomIsAvailableMessageType)
+		}
+
+		room.AvailableMessageTypes = "text, file"
+		if !room.IsAvailableMessageType(MessageTypeText) || room.IsAvailableMessageType(MessageTypeImage) {
+			t.Fatalf("Failed to %s. Expected only text and file to be available", TestModelRoomIsAvailableMessageType)
+		}
+	})
+
+	t.Run(TestModelSendMessageRequestPolicy, func(t *testing.T) {
+		room := &Room{}
+		room.UserID = owner.UserID
+		room.SpeechMode = SpeechModeOwnerOnly
+		room.AvailableMessageTypes = MessageTypeText
+
+		messageType := MessageTypeText
+		req := &SendMessageRequest{}
+		req.Type = &messageType
+		errRes := req.ValidatePolicy(room, member)
+		if errRes == nil || errRes.Status != http.StatusForbidden || errRes.InvalidParams[0].Name != "userId" {
+			t.Fatalf("Failed to %s. Expected the member to be forbidden to speak", TestModelSendMessageRequestPolicy)
+		}
+
+		errRes = req.ValidatePolicy(room, owner)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelSendMessageRequestPolicy)
+		}
+
+		messageType = MessageTypeImage
+		errRes = req.ValidatePolicy(room, owner)
+		if errRes == nil || errRes.Status != http.StatusBadRequest || errRes.InvalidParams[0].Name != "type" {
+			t.Fatalf("Failed to %s. Expected image to be rejected", TestModelSendMessageRequestPolicy)
+		}
+
+		messageType = MessageTypeIndicatorStart
+		errRes = req.ValidatePolicy(room, owner)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected indicators to be always available", TestModelSendMessageRequestPolicy)
+		}
+	})
+
+	t.Run(TestModelRoomSpeechModeValidation, func(t *testing.T) {
+		userID := owner.UserID
+		roomType := scpb.RoomType_PublicRoom
+		speechMode := scpb.SpeechMode(99)
+		createReq := &CreateRoomRequest{}
+		createReq.UserID = &userID
+		createReq.Type = &roomType
+		createReq.SpeechMode = &speechMode
+		errRes := createReq.Validate()
+		if errRes == nil || errRes.InvalidParams[0].Name != "speechMode" {
+			t.Fatalf("Failed to %s. Expected speechMode to be invalid", TestModelRoomSpeechModeValidation)
+		}
+
+		speechMode = SpeechModeRoles
+		speechRoles := "1,admin"
+		createReq.SpeechRoles = &speechRoles
+		errRes = createReq.Validate()
+		if errRes == nil || errRes.InvalidParams[0].Name != "speechRoles" {
+			t.Fatalf("Failed to %s. Expected speechRoles to be invalid", TestModelRoomSpeechModeValidation)
+		}
+
+		speechRoles = "1,2"
+		errRes = createReq.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelRoomSpeechModeValidation)
+		}
+
+		invalidSpeechMode := scpb.SpeechMode(99)
+		updateReq := &UpdateRoomRequest{}
+		updateReq.SpeechMode = &invalidSpeechMode
+		errRes = updateReq.Validate(&Room{})
+		if errRes == nil || errRes.InvalidParams[0].Name != "speechMode" {
+			t.Fatalf("Failed to %s. Expected speechMode to be invalid", TestModelRoomSpeechModeValidation)
+		}
+	})
+
+	t.Run(TestModelDeleteRoomUsersCanLeft, func(t *testing.T) {
+		req := &DeleteRoomUsersRequest{}
+		req.Room = &Room{}
+		req.Room.UserID = owner.UserID
+		req.UserIDs = []string{member.UserID}
+
+		errRes := req.ValidateCanLeft(member.UserID)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected leaving the room to be forbidden", TestModelDeleteRoomUsersCanLeft)
+		}
+
+		errRes = req.ValidateCanLeft(owner.UserID)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected removing the other users to be allowed", TestModelDeleteRoomUsersCanLeft)
+		}
+
+		req.Room.CanLeft = true
+		errRes = req.ValidateCanLeft(member.UserID)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected leaving the room to be allowed", TestModelDeleteRoomUsersCanLeft)
+		}
+	})
+
+	t.Run(TestModelRoomSetTags, func(t *testing.T) {
+		room := &Room{}
+		room.MetaData = []byte(`{"tags":[" Sports ","soccer","sports","a,b",""]}`)
+		room.SetTags()
+		if room.Tags != ",sports,soccer," {
+			t.Fatalf("Failed to %s. Expected tags to be \",sports,soccer,\", but it was %s", TestModelRoomSetTags, room.Tags)
+		}
+
+		room.MetaData = []byte(`{"key":"value"}`)
+		room.SetTags()
+		if room.Tags != "" {
+			t.Fatalf("Failed to %s. Expected tags to be empty, but it was %s", TestModelRoomSetTags, room.Tags)
+		}
+	})
+
+	t.Run(TestModelArchiveRoomRequest, func(t *testing.T) {
+		req := &ArchiveRoomRequest{}
+		req.Room = &Room{}
+		req.Room.Type = scpb.RoomType_OneOnOneRoom
+		errRes := req.Validate()
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected archiving 1-on-1 room to be a bad request", TestModelArchiveRoomRequest)
+		}
+
+		req.Room.Type = scpb.RoomType_PrivateRoom
+		errRes = req.Validate()
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelArchiveRoomRequest)
+		}
+	})
+
+	t.Run(TestModelRestoreRoomRequest, func(t *testing.T) {
+		req := &RestoreRoomRequest{}
+		req.Room = &Room{}
+		errRes := req.Validate(1000, 100)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected restoring the room not deleted to be a bad request", TestModelRestoreRoomRequest)
+		}
+
+		req.Room.DeletedTimestamp = 950
+		errRes = req.Validate(1000, 100)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil", TestModelRestoreRoomRequest)
+		}
+
+		req.Room.DeletedTimestamp = 850
+		errRes = req.Validate(1000, 100)
+		if errRes == nil || errRes.Status != http.StatusGone {
+			t.Fatalf("Failed to %s. Expected the restore period to have passed", TestModelRestoreRoomRequest)
+		}
+
+		req.Room.DeletedTimestamp = 1000
+		errRes = req.Validate(1000, 0)
+		if errRes == nil || errRes.Status != http.StatusGone {
+			t.Fatalf("Failed to %s. Expected the room not to be restored when the restore period is 0", TestModelRestoreRoomRequest)
+		}
+	})
+
+	t.Run(TestModelRoomSetLastMessage, func(t *testing.T) {
+		message := &Message{}
+		message.MessageID = "model-message-id-0001"
+		message.UserID = owner.UserID
+		message.Type = MessageTypeImage
+		message.Payload = []byte(`{"sourceUrl":"http://example.com/image.png"}`)
+		message.CreatedTimestamp = 1
+
+		room := &Room{}
+		room.SetLastMessage(message)
+		if room.LastMessageID != message.MessageID || room.LastMessageType != MessageTypeImage || room.LastMessageUserID != owner.UserID {
+			t.Fatalf("Failed to %s. Expected the last message to be set", TestModelRoomSetLastMessage)
+		}
+		if room.LastMessage != "" {
+			t.Fatalf("Failed to %s. Expected room.LastMessage of the image to be empty, but it was %s", TestModelRoomSetLastMessage, room.LastMessage)
+		}
+
+		miniRoom := &MiniRoom{}
+		miniRoom.LastMessageID = room.LastMessageID
+		miniRoom.LastMessageType = room.LastMessageType
+		miniRoom.Lang = "ja-JP"
+		if miniRoom.LastMessagePreview() != "画像を受信しました" {
+			t.Fatalf("Failed to %s. Expected the preview to be in Japanese, but it was %s", TestModelRoomSetLastMessage, miniRoom.LastMessagePreview())
+		}
+		miniRoom.Lang = "xx"
+		if miniRoom.LastMessagePreview() != "Sent an image" {
+			t.Fatalf("Failed to %s. Expected the preview to be in the default language, but it was %s", TestModelRoomSetLastMessage, miniRoom.LastMessagePreview())
+		}
+
+		message.Type = MessageTypeText
+		message.Payload = []byte(`{"text":"hello"}`)
+		room.SetLastMessage(message)
+		if MessagePreview(room.LastMessageType, room.LastMessage, "ja") != "hello" {
+			t.Fatalf("Failed to %s. Expected the preview of the text to be the text", TestModelRoomSetLastMessage)
+		}
+
+		room.SetLastMessage(nil)
+		if room.LastMessageID != "" || room.LastMessage != "" {
+			t.Fatalf("Failed to %s. Expected the last message to be cleared", TestModelRoomSetLastMessage)
+		}
+	})
+}
@@ -0,0 +1,144 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// ScheduledMessage is model of message sent at the scheduled time
+type ScheduledMessage struct {
+	ID                 uint64   `json:"-" db:"id"`
+	ScheduledMessageID string   `json:"scheduledMessageId" db:"scheduled_message_id,notnull"`
+	RoomID             string   `json:"roomId" db:"room_id,notnull"`
+	UserID             string   `json:"userId" db:"user_id,notnull"`
+	Type               string   `json:"type" db:"type,notnull"`
+	Payload            JSONText `json:"payload" db:"payload"`
+	Mentions           JSONText `json:"mentions" db:"mentions"`
+	Role               int32    `json:"role" db:"role,notnull"`
+	TTL                int64    `json:"ttl" db:"ttl,notnull"`
+	Scheduled          int64    `json:"scheduled" db:"scheduled,notnull"`
+	Sent               int64    `json:"sent" db:"sent,notnull"`
+	Created            int64    `json:"created" db:"created,notnull"`
+	Modified           int64    `json:"modified" db:"modified,notnull"`
+	Deleted            int64    `json:"-" db:"deleted,notnull"`
+}
+
+// MarshalJSON is MarshalJSON of ScheduledMessage
+func (sm *ScheduledMessage) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	return json.Marshal(&struct {
+		ScheduledMessageID string   `json:"scheduledMessageId"`
+		RoomID             string   `json:"roomId"`
+		UserID             string   `json:"userId"`
+		Type               string   `json:"type"`
+		Payload            JSONText `json:"payload"`
+		Mentions           JSONText `json:"mentions"`
+		Role               int32    `json:"role"`
+		TTL                int64    `json:"ttl,omitempty"`
+		ScheduledTimestamp int64    `json:"scheduledTimestamp"`
+		Scheduled          string   `json:"scheduled"`
+		Created            string   `json:"created"`
+		Modified           string   `json:"modified"`
+	}{
+		ScheduledMessageID: sm.ScheduledMessageID,
+		RoomID:             sm.RoomID,
+		UserID:             sm.UserID,
+		Type:               sm.Type,
+		Payload:            sm.Payload,
+		Mentions:           sm.Mentions,
+		Role:               sm.Role,
+		TTL:                sm.TTL,
+		ScheduledTimestamp: sm.Scheduled,
+		Scheduled:          time.Unix(sm.Scheduled, 0).In(l).Format(time.RFC3339),
+		Created:            time.Unix(sm.Created, 0).In(l).Format(time.RFC3339),
+		Modified:           time.Unix(sm.Modified, 0).In(l).Format(time.RFC3339),
+	})
+}
+
+// ConvertToSendMessageRequest converts to the request sent at the scheduled time.
+// The scheduledMessageId is used as the messageId, so the message is never sent twice.
+func (sm *ScheduledMessage) ConvertToSendMessageRequest() *SendMessageRequest {
+	req := &SendMessageRequest{}
+	req.MessageID = &sm.ScheduledMessageID
+	req.RoomID = &sm.RoomID
+	req.UserID = &sm.UserID
+	req.Type = &sm.Type
+	req.Payload = sm.Payload
+	req.Role = &sm.Role
+	req.TTL = sm.TTL
+	sm.Mentions.Unmarshal(&req.Mentions)
+	return req
+}
+
+// GenerateScheduledMessage generates scheduled message from the request
+func (m *SendMessageRequest) GenerateScheduledMessage() *ScheduledMessage {
+	sm := &ScheduledMessage{}
+
+	if m.MessageID == nil || *m.MessageID == "" {
+		sm.ScheduledMessageID = utils.GenerateUUID()
+	} else {
+		sm.ScheduledMessageID = *m.MessageID
+	}
+
+	message := m.GenerateMessage()
+	sm.RoomID = message.RoomID
+	sm.UserID = message.UserID
+	sm.Type = message.Type
+	sm.Payload = message.Payload
+	sm.Role = message.Role
+	mentions := m.Mentions
+	if mentions == nil {
+		mentions = []string{}
+	}
+	sm.Mentions, _ = json.Marshal(mentions)
+	sm.TTL = m.TTL
+	sm.Scheduled = m.ScheduledTimestamp
+
+	nowTimestamp := time.Now().Unix()
+	sm.Created = nowTimestamp
+	sm.Modified = nowTimestamp
+
+	return sm
+}
+
+type RetrieveScheduledMessagesRequest struct {
+	RoomID string
+	Limit  int32
+	Offset int32
+}
+
+type ScheduledMessagesResponse struct {
+	ScheduledMessages []*ScheduledMessage `json:"scheduledMessages"`
+	AllCount          int64               `json:"allCount"`
+	Limit             int32               `json:"limit"`
+	Offset            int32               `json:"offset"`
+}
+
+type UpdateScheduledMessageRequest struct {
+	ScheduledMessageID string `json:"-"`
+	RoomID             string `json:"-"`
+	ScheduledTimestamp int64  `json:"scheduledTimestamp"`
+}
+
+func (usmr *UpdateScheduledMessageRequest) Validate() *ErrorResponse {
+	if usmr.ScheduledTimestamp <= time.Now().Unix() {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "scheduledTimestamp",
+				Reason: "scheduledTimestamp must be a future time.",
+			},
+		}
+		return NewErrorResponse("Failed to update scheduled message.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+type DeleteScheduledMessageRequest struct {
+	ScheduledMessageID string
+	RoomID             string
+}
@@ -0,0 +1,13 @@
+package model
+
+// SchemaMigration is model of schema migration
+type SchemaMigration struct {
+	Version     int    `json:"version" db:"version"`
+	Description string `json:"description" db:"description"`
+	Applied     int64  `json:"applied" db:"applied"`
+}
+
+// IsApplied returns whether the migration has been applied
+func (sm *SchemaMigration) IsApplied() bool {
+	return sm.Applied != 0
+}
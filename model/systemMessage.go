@@ -0,0 +1,118 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/utils"
+)
+
+// Actions of the system messages.
+// The changes of the room users are sent as MessageTypeUpdateRoomUser, and the changes of the room as MessageTypeUpdateRoom.
+const (
+	SystemMessageActionAdd           = "add"
+	SystemMessageActionJoin          = "join"
+	SystemMessageActionLeave         = "leave"
+	SystemMessageActionRemove        = "remove"
+	SystemMessageActionUpdateName    = "updateName"
+	SystemMessageActionUpdatePicture = "updatePicture"
+)
+
+// PayloadSystem is the payload of the system messages inserted into the room history by the server.
+// ActorID is the user who did the action, which is empty if it was done by the app client.
+// UserIDs are the users who were added, joined, left or were removed.
+type PayloadSystem struct {
+	Action     string   `json:"action"`
+	ActorID    string   `json:"actorId"`
+	UserIDs    []string `json:"userIds,omitempty"`
+	Name       string   `json:"name,omitempty"`
+	PictureURL string   `json:"pictureUrl,omitempty"`
+}
+
+// NewJoinSystemMessage returns the system message of the users who joined the room.
+// It's a join if the actor joined by themselves, and an addition by the actor otherwise.
+func NewJoinSystemMessage(roomID, actorID string, userIDs []string) *Message {
+	action := SystemMessageActionAdd
+	if len(userIDs) == 1 && userIDs[0] == actorID {
+		action = SystemMessageActionJoin
+	}
+
+	return newSystemMessage(roomID, actorID, MessageTypeUpdateRoomUser, &PayloadSystem{
+		Action:  action,
+		ActorID: actorID,
+		UserIDs: userIDs,
+	})
+}
+
+// NewLeaveSystemMessage returns the system message of the users who left the room.
+// It's a leave if the actor left by themselves, and a removal by the actor otherwise.
+func NewLeaveSystemMessage(roomID, actorID string, userIDs []string) *Message {
+	action := SystemMessageActionRemove
+	if len(userIDs) == 1 && userIDs[0] == actorID {
+		action = SystemMessageActionLeave
+	}
+
+	return newSystemMessage(roomID, actorID, MessageTypeUpdateRoomUser, &PayloadSystem{
+		Action:  action,
+		ActorID: actorID,
+		UserIDs: userIDs,
+	})
+}
+
+// NewUpdateRoomSystemMessages returns the system messages of the changes of the name and the picture of the room
+func NewUpdateRoomSystemMessages(before, after *Room, actorID string) []*Message {
+	messages := []*Message{}
+
+	if before.Name != after.Name {
+		messages = append(messages, newSystemMessage(after.RoomID, actorID, MessageTypeUpdateRoom, &PayloadSystem{
+			Action:  SystemMessageActionUpdateName,
+			ActorID: actorID,
+			Name:    after.Name,
+		}))
+	}
+
+	if before.PictureURL != after.PictureURL {
+		messages = append(messages, newSystemMessage(after.RoomID, actorID, MessageTypeUpdateRoom, &PayloadSystem{
+			Action:     SystemMessageActionUpdatePicture,
+			ActorID:    actorID,
+			PictureURL: after.PictureURL,
+		}))
+	}
+
+	return messages
+}
+
+// DiffUserIDs returns the user IDs which are in after but not in before, and the ones which are in before but not in after
+func DiffUserIDs(before, after []string) ([]string, []string) {
+	added := []string{}
+	for _, userID := range utils.RemoveDuplicateString(after) {
+		if !utils.SearchStringValueInSlice(before, userID) {
+			added = append(added, userID)
+		}
+	}
+
+	removed := []string{}
+	for _, userID := range utils.RemoveDuplicateString(before) {
+		if !utils.SearchStringValueInSlice(after, userID) {
+			removed = append(removed, userID)
+		}
+	}
+
+	return added, removed
+}
+
+func newSystemMessage(roomID, actorID, messageType string, payload *PayloadSystem) *Message {
+	m := &Message{}
+	m.MessageID = utils.GenerateUUID()
+	m.RoomID = roomID
+	m.UserID = actorID
+	m.Type = messageType
+	m.Payload, _ = json.Marshal(payload)
+	m.Role = config.RoleGeneral
+
+	nowTimestamp := time.Now().Unix()
+	m.CreatedTimestamp = nowTimestamp
+	m.ModifiedTimestamp = nowTimestamp
+	return m
+}
@@ -0,0 +1,93 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+const (
+	TestModelJoinSystemMessage        = "[model] NewJoinSystemMessage test"
+	TestModelLeaveSystemMessage       = "[model] NewLeaveSystemMessage test"
+	TestModelUpdateRoomSystemMessages = "[model] NewUpdateRoomSystemMessages test"
+	TestModelDiffUserIDs              = "[model] DiffUserIDs test"
+)
+
+func TestSystemMessage(t *testing.T) {
+	t.Run(TestModelJoinSystemMessage, func(t *testing.T) {
+		m := NewJoinSystemMessage("model-room-id-0001", "model-user-id-0001", []string{"model-user-id-0001"})
+		if m.Type != MessageTypeUpdateRoomUser || m.UserID != "model-user-id-0001" {
+			t.Fatalf("Failed to %s. Expected the message to be updateRoomUser sent by the actor, but it was %s sent by %s", TestModelJoinSystemMessage, m.Type, m.UserID)
+		}
+		var payload PayloadSystem
+		json.Unmarshal(m.Payload, &payload)
+		if payload.Action != SystemMessageActionJoin {
+			t.Fatalf("Failed to %s. Expected action to be join, but it was %s", TestModelJoinSystemMessage, payload.Action)
+		}
+
+		m = NewJoinSystemMessage("model-room-id-0001", "model-user-id-0001", []string{"model-user-id-0002", "model-user-id-0003"})
+		payload = PayloadSystem{}
+		json.Unmarshal(m.Payload, &payload)
+		if payload.Action != SystemMessageActionAdd || payload.ActorID != "model-user-id-0001" || len(payload.UserIDs) != 2 {
+			t.Fatalf("Failed to %s. Expected the actor to add 2 users, but it was %#v", TestModelJoinSystemMessage, payload)
+		}
+	})
+
+	t.Run(TestModelLeaveSystemMessage, func(t *testing.T) {
+		m := NewLeaveSystemMessage("model-room-id-0001", "model-user-id-0001", []string{"model-user-id-0001"})
+		var payload PayloadSystem
+		json.Unmarshal(m.Payload, &payload)
+		if payload.Action != SystemMessageActionLeave {
+			t.Fatalf("Failed to %s. Expected action to be leave, but it was %s", TestModelLeaveSystemMessage, payload.Action)
+		}
+
+		m = NewLeaveSystemMessage("model-room-id-0001", "model-user-id-0001", []string{"model-user-id-0002"})
+		payload = PayloadSystem{}
+		json.Unmarshal(m.Payload, &payload)
+		if payload.Action != SystemMessageActionRemove {
+			t.Fatalf("Failed to %s. Expected action to be remove, but it was %s", TestModelLeaveSystemMessage, payload.Action)
+		}
+	})
+
+	t.Run(TestModelUpdateRoomSystemMessages, func(t *testing.T) {
+		before := &Room{}
+		before.RoomID = "model-room-id-0001"
+		before.Name = "before"
+		before.PictureURL = "http://example.com/before.png"
+		after := *before
+
+		messages := NewUpdateRoomSystemMessages(before, &after, "model-user-id-0001")
+		if len(messages) != 0 {
+			t.Fatalf("Failed to %s. Expected no messages to be made, but it was %d", TestModelUpdateRoomSystemMessages, len(messages))
+		}
+
+		after.Name = "after"
+		after.PictureURL = "http://example.com/after.png"
+		messages = NewUpdateRoomSystemMessages(before, &after, "model-user-id-0001")
+		if len(messages) != 2 {
+			t.Fatalf("Failed to %s. Expected 2 messages to be made, but it was %d", TestModelUpdateRoomSystemMessages, len(messages))
+		}
+		var payload PayloadSystem
+		json.Unmarshal(messages[0].Payload, &payload)
+		if messages[0].Type != MessageTypeUpdateRoom || payload.Action != SystemMessageActionUpdateName || payload.Name != "after" {
+			t.Fatalf("Failed to %s. Expected the name to be updated to after, but it was %#v", TestModelUpdateRoomSystemMessages, payload)
+		}
+		payload = PayloadSystem{}
+		json.Unmarshal(messages[1].Payload, &payload)
+		if payload.Action != SystemMessageActionUpdatePicture || payload.PictureURL != "http://example.com/after.png" {
+			t.Fatalf("Failed to %s. Expected the picture to be updated, but it was %#v", TestModelUpdateRoomSystemMessages, payload)
+		}
+	})
+
+	t.Run(TestModelDiffUserIDs, func(t *testing.T) {
+		added, removed := DiffUserIDs(
+			[]string{"model-user-id-0001", "model-user-id-0002"},
+			[]string{"model-user-id-0001", "model-user-id-0003", "model-user-id-0003"},
+		)
+		if len(added) != 1 || added[0] != "model-user-id-0003" {
+			t.Fatalf("Failed to %s. Expected added to be [model-user-id-0003], but it was %v", TestModelDiffUserIDs, added)
+		}
+		if len(removed) != 1 || removed[0] != "model-user-id-0002" {
+			t.Fatalf("Failed to %s. Expected removed to be [model-user-id-0002], but it was %v", TestModelDiffUserIDs, removed)
+		}
+	})
+}
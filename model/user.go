@@ -2,6 +2,7 @@ package model
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -9,6 +10,12 @@ import (
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
+// ErasedUserID is the user ID which the messages of erased users are attributed to when they are anonymized
+const ErasedUserID = "erased-user"
+
+// ErasedMessagePayload is the payload which the messages of erased users are replaced with when they are anonymized
+const ErasedMessagePayload = "{}"
+
 type User struct {
 	scpb.User
 	MetaData JSONText  `db:"meta_data"`
@@ -140,8 +147,56 @@ func (u *User) UpdateUser(req *UpdateUserRequest) {
 
 type MiniRoom struct {
 	scpb.MiniRoom
-	MetaData JSONText    `json:"metaData" db:"meta_data"`
-	Users    []*MiniUser `json:"users,omitempty" db:"-"`
+	MetaData       JSONText    `json:"metaData" db:"meta_data"`
+	Users          []*MiniUser `json:"users,omitempty" db:"-"`
+	RuMentionCount int64       `json:"ruMentionCount" db:"ru_mention_count"`
+	// ArchivedTimestamp is non-zero only if the archived rooms are included
+	ArchivedTimestamp   int64 `json:"archivedTimestamp" db:"archived"`
+	RuFavorite          bool  `json:"ruFavorite" db:"ru_favorite"`
+	RuHidden            bool  `json:"ruHidden" db:"ru_hidden"`
+	RuMuted             bool  `json:"ruMuted" db:"ru_muted"`
+	RuArchived          bool  `json:"ruArchived" db:"ru_archived"`
+	RuSortKey           int64 `json:"ruSortKey" db:"ru_sort_key"`
+	RuLastReadTimestamp int64 `json:"ruLastReadTimestamp" db:"ru_last_read"`
+	// LastMessageID, LastMessageType, LastMessageUserID and LastMessageUserName are of the last message of the room
+	LastMessageID       string `json:"-" db:"last_message_id"`
+	LastMessageType     string `json:"-" db:"last_message_type"`
+	LastMessageUserID   string `json:"-" db:"last_message_user_id"`
+	LastMessageUserName string `json:"-" db:"last_message_user_name"`
+	// Lang is the language of the preview of the last message, which is the language of the user
+	Lang string `json:"-" db:"-"`
+}
+
+// LastMessageSummary is the last message of the room shown in the room list
+type LastMessageSummary struct {
+	MessageID string `json:"messageId"`
+	Type      string `json:"type"`
+	UserID    string `json:"userId"`
+	UserName  string `json:"userName"`
+	Preview   string `json:"preview"`
+	Created   string `json:"created"`
+}
+
+// LastMessagePreview returns the preview of the last message in the language of the mini room
+func (rfu *MiniRoom) LastMessagePreview() string {
+	return MessagePreview(rfu.LastMessageType, rfu.LastMessage, rfu.Lang)
+}
+
+// LastMessageSummary returns the last message of the room. It's nil if the room has no messages.
+func (rfu *MiniRoom) LastMessageSummary() *LastMessageSummary {
+	if rfu.LastMessageID == "" {
+		return nil
+	}
+
+	l, _ := time.LoadLocation("Etc/GMT")
+	return &LastMessageSummary{
+		MessageID: rfu.LastMessageID,
+		Type:      rfu.LastMessageType,
+		UserID:    rfu.LastMessageUserID,
+		UserName:  rfu.LastMessageUserName,
+		Preview:   rfu.LastMessagePreview(),
+		Created:   time.Unix(rfu.LastMessageUpdatedTimestamp, 0).In(l).Format(time.RFC3339),
+	}
 }
 
 func (rfu *MiniRoom) MarshalJSON() ([]byte, error) {
@@ -150,21 +205,38 @@ func (rfu *MiniRoom) MarshalJSON() ([]byte, error) {
 	if rfu.LastMessageUpdatedTimestamp != 0 {
 		lmu = time.Unix(rfu.LastMessageUpdatedTimestamp, 0).In(l).Format(time.RFC3339)
 	}
+	archived := ""
+	if rfu.ArchivedTimestamp != 0 {
+		archived = time.Unix(rfu.ArchivedTimestamp, 0).In(l).Format(time.RFC3339)
+	}
+	lastRead := ""
+	if rfu.RuLastReadTimestamp != 0 {
+		lastRead = time.Unix(rfu.RuLastReadTimestamp, 0).In(l).Format(time.RFC3339)
+	}
 	return json.Marshal(&struct {
-		RoomID             string        `json:"roomId"`
-		UserID             string        `json:"userId"`
-		Name               string        `json:"name"`
-		PictureURL         string        `json:"pictureUrl,omitempty"`
-		InformationURL     string        `json:"informationUrl,omitempty"`
-		MetaData           JSONText      `json:"metaData"`
-		Type               scpb.RoomType `json:"type,omitempty"`
-		LastMessage        string        `json:"lastMessage"`
-		LastMessageUpdated string        `json:"lastMessageUpdated"`
-		CanLeft            bool          `json:"canLeft,omitempty"`
-		Created            string        `json:"created"`
-		Modified           string        `json:"modified"`
-		Users              []*MiniUser   `json:"users"`
-		RuUnreadCount      int64         `json:"ruUnreadCount"`
+		RoomID             string              `json:"roomId"`
+		UserID             string              `json:"userId"`
+		Name               string              `json:"name"`
+		PictureURL         string              `json:"pictureUrl,omitempty"`
+		InformationURL     string              `json:"informationUrl,omitempty"`
+		MetaData           JSONText            `json:"metaData"`
+		Type               scpb.RoomType       `json:"type,omitempty"`
+		LastMessage        string              `json:"lastMessage"`
+		LastMessageUpdated string              `json:"lastMessageUpdated"`
+		CanLeft            bool                `json:"canLeft,omitempty"`
+		Created            string              `json:"created"`
+		Modified           string              `json:"modified"`
+		Users              []*MiniUser         `json:"users"`
+		RuUnreadCount      int64               `json:"ruUnreadCount"`
+		RuMentionCount     int64               `json:"ruMentionCount"`
+		Archived           string              `json:"archived,omitempty"`
+		RuFavorite         bool                `json:"ruFavorite"`
+		RuHidden           bool                `json:"ruHidden"`
+		RuMuted            bool                `json:"ruMuted"`
+		RuArchived         bool                `json:"ruArchived"`
+		RuSortKey          int64               `json:"ruSortKey"`
+		RuLastRead         string              `json:"ruLastRead,omitempty"`
+		LastMessageSummary *LastMessageSummary `json:"lastMessageSummary,omitempty"`
 	}{
 		RoomID:             rfu.RoomID,
 		UserID:             rfu.UserID,
@@ -173,13 +245,22 @@ func (rfu *MiniRoom) MarshalJSON() ([]byte, error) {
 		InformationURL:     rfu.InformationURL,
 		MetaData:           rfu.MetaData,
 		Type:               rfu.Type,
-		LastMessage:        rfu.LastMessage,
+		LastMessage:        rfu.LastMessagePreview(),
 		LastMessageUpdated: lmu,
 		CanLeft:            rfu.CanLeft,
 		Created:            time.Unix(rfu.CreatedTimestamp, 0).In(l).Format(time.RFC3339),
 		Modified:           time.Unix(rfu.ModifiedTimestamp, 0).In(l).Format(time.RFC3339),
 		Users:              rfu.Users,
 		RuUnreadCount:      rfu.RuUnreadCount,
+		RuMentionCount:     rfu.RuMentionCount,
+		Archived:           archived,
+		RuFavorite:         rfu.RuFavorite,
+		RuHidden:           rfu.RuHidden,
+		RuMuted:            rfu.RuMuted,
+		RuArchived:         rfu.RuArchived,
+		RuSortKey:          rfu.RuSortKey,
+		RuLastRead:         lastRead,
+		LastMessageSummary: rfu.LastMessageSummary(),
 	})
 }
 
@@ -199,6 +280,16 @@ func (cur *CreateUserRequest) Validate() *ErrorResponse {
 		return NewErrorResponse("Failed to create user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
 	}
 
+	if cur.UserID != nil && *cur.UserID == ErasedUserID {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "userId is reserved.",
+			},
+		}
+		return NewErrorResponse("Failed to create user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
 	if cur.Name == nil || (cur.Name != nil && *cur.Name == "") {
 		invalidParams := []*scpb.InvalidParam{
 			&scpb.InvalidParam{
@@ -449,13 +540,102 @@ type DeleteUserRequest struct {
 	scpb.DeleteUserRequest
 }
 
+// UserErasure is how the messages of the user are erased
+type UserErasure string
+
+const (
+	// UserErasureAnonymize keeps the messages in the rooms, but attributes them to ErasedUserID
+	UserErasureAnonymize UserErasure = "anonymize"
+	// UserErasureDelete deletes the messages
+	UserErasureDelete UserErasure = "delete"
+)
+
+// EraseUserRequest is the request to erase all of the data of the user instead of the soft delete
+type EraseUserRequest struct {
+	UserID  string
+	Erasure UserErasure
+}
+
+func (eur *EraseUserRequest) Validate() *ErrorResponse {
+	if eur.Erasure != UserErasureAnonymize && eur.Erasure != UserErasureDelete {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "erase",
+				Reason: "erase must be anonymize or delete.",
+			},
+		}
+		return NewErrorResponse("Failed to erase user.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+type RetrieveUserDataRequest struct {
+	UserID string
+}
+
+// UserDataResponse is all of the data of the user for data portability requests
+type UserDataResponse struct {
+	User              *User               `json:"user"`
+	RoomUsers         []*RoomUser         `json:"roomUsers"`
+	Messages          []*Message          `json:"messages"`
+	ScheduledMessages []*ScheduledMessage `json:"scheduledMessages"`
+	Assets            []*Asset            `json:"assets"`
+	Exported          string              `json:"exported"`
+}
+
+// UserRoomsSort is the order of the rooms of the user. The favorite rooms are always listed first.
+type UserRoomsSort string
+
+const (
+	// UserRoomsSortLastMessage lists the rooms in the descending order of the last message
+	UserRoomsSortLastMessage UserRoomsSort = "lastMessage"
+	// UserRoomsSortSortKey lists the rooms in the ascending order of the sort keys of the room user
+	UserRoomsSortSortKey UserRoomsSort = "sortKey"
+	// UserRoomsSortName lists the rooms in the ascending order of the names
+	UserRoomsSortName UserRoomsSort = "name"
+)
+
+// IsValid returns whether the sort is defined
+func (urs UserRoomsSort) IsValid() bool {
+	switch urs {
+	case UserRoomsSortLastMessage, UserRoomsSortSortKey, UserRoomsSortName:
+		return true
+	}
+	return false
+}
+
 type RetrieveUserRoomsRequest struct {
 	scpb.RetrieveUserRoomsRequest
+	// IncludeArchived includes the rooms archived for everyone or by the user, which are hidden by default
+	IncludeArchived bool
+	// IncludeHidden includes the rooms hidden by the user
+	IncludeHidden bool
+	// Favorite and Muted filter the rooms by the personal settings of the user if they are set
+	Favorite *bool
+	Muted    *bool
+	Sort     UserRoomsSort
+}
+
+func (rurr *RetrieveUserRoomsRequest) Validate() *ErrorResponse {
+	if rurr.Sort != "" && !rurr.Sort.IsValid() {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "sort",
+				Reason: fmt.Sprintf("sort is incorrect. It must be one of %s, %s or %s.", UserRoomsSortLastMessage, UserRoomsSortSortKey, UserRoomsSortName),
+			},
+		}
+		return NewErrorResponse("Failed to retrieve user rooms.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
 }
 
 type UserRoomsResponse struct {
 	scpb.UserRoomsResponse
 	Rooms []*MiniRoom `json:"rooms"`
+	// UnreadCount is the total of the unread counts of all of the rooms of the user
+	UnreadCount uint64 `json:"unreadCount"`
 }
 
 func (urr *UserRoomsResponse) ConvertToPbUserRooms() *scpb.UserRoomsResponse {
@@ -487,7 +667,7 @@ func (urr *UserRoomsResponse) ConvertToPbUserRooms() *scpb.UserRoomsResponse {
 			InformationURL:     r.InformationURL,
 			MetaData:           r.MetaData,
 			Type:               r.Type,
-			LastMessage:        r.LastMessage,
+			LastMessage:        r.LastMessagePreview(),
 			LastMessageUpdated: r.LastMessageUpdated,
 			CanLeft:            r.CanLeft,
 			Created:            r.Created,
@@ -0,0 +1,99 @@
+package model
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+	"time"
+
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// Workspace names are used as database names as they are, so they are limited to the identifiers valid in every datastore
+var workspaceNameRegexp = regexp.MustCompile(`^[a-z][a-z0-9_]{2,62}$`)
+
+// reservedWorkspaceNames are the system databases of the datastores, which must not be used as workspaces
+var reservedWorkspaceNames = map[string]struct{}{
+	"information_schema": struct{}{},
+	"mysql":              struct{}{},
+	"performance_schema": struct{}{},
+	"postgres":           struct{}{},
+	"sys":                struct{}{},
+	"template0":          struct{}{},
+	"template1":          struct{}{},
+}
+
+// Workspace is model of workspace.
+// AppClient is the first app client of the workspace, which is returned only when the workspace is created or registered.
+type Workspace struct {
+	ID        uint64     `json:"-" db:"id"`
+	Name      string     `json:"name" db:"name,notnull"`
+	Created   int64      `json:"created" db:"created,notnull"`
+	AppClient *AppClient `json:"appClient,omitempty" db:"-"`
+}
+
+// MarshalJSON is MarshalJSON of Workspace
+func (w *Workspace) MarshalJSON() ([]byte, error) {
+	l, _ := time.LoadLocation("Etc/GMT")
+	return json.Marshal(&struct {
+		Name      string     `json:"name"`
+		Created   string     `json:"created"`
+		AppClient *AppClient `json:"appClient,omitempty"`
+	}{
+		Name:      w.Name,
+		Created:   time.Unix(w.Created, 0).In(l).Format(time.RFC3339),
+		AppClient: w.AppClient,
+	})
+}
+
+// ValidateWorkspaceName validates the workspace name
+func ValidateWorkspaceName(name string) *ErrorResponse {
+	if !workspaceNameRegexp.MatchString(name) {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "name",
+				Reason: "name must be 3 to 63 characters of lowercase letters, digits and underscores, starting with a letter.",
+			},
+		}
+		return NewErrorResponse("Invalid workspace name.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	if _, ok := reservedWorkspaceNames[name]; ok {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "name",
+				Reason: "name is reserved by the datastore.",
+			},
+		}
+		return NewErrorResponse("Invalid workspace name.", http.StatusBadRequest, WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+type CreateWorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+func (cwr *CreateWorkspaceRequest) Validate() *ErrorResponse {
+	return ValidateWorkspaceName(cwr.Name)
+}
+
+func (cwr *CreateWorkspaceRequest) GenerateWorkspace() *Workspace {
+	return &Workspace{
+		Name:    cwr.Name,
+		Created: time.Now().Unix(),
+	}
+}
+
+type RetrieveWorkspacesRequest struct {
+	Limit  int32
+	Offset int32
+}
+
+type WorkspacesResponse struct {
+	Workspaces []*Workspace `json:"workspaces"`
+	AllCount   int64        `json:"allCount"`
+	Limit      int32        `json:"limit"`
+	Offset     int32        `json:"offset"`
+}
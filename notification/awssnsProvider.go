@@ -252,6 +252,80 @@ func (ap *awssnsProvider) Publish(notificationTopicID, roomID string, messageInf
 	result := NotificationResult{}
 
 	client := ap.newSnsClient()
+	message, err := ap.makeMessage(roomID, messageInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		result.Error = err
+		nc <- result
+		return nc
+	}
+
+	params := &sns.PublishInput{
+		Message:          aws.String(message),
+		MessageStructure: aws.String("json"),
+		Subject:          aws.String("subject"),
+		TopicArn:         aws.String(notificationTopicID),
+	}
+	res, err := client.Publish(params)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		result.Error = err
+		nc <- result
+		return nc
+	}
+	logger.Info(fmt.Sprintf("[Amazon SNS]Publish message topicArn:%s message:%s response:%s", notificationTopicID, message, res.String()))
+
+	nc <- result
+
+	select {
+	case <-ap.ctx.Done():
+		return nc
+	case <-nc:
+		return nc
+	}
+}
+
+func (ap *awssnsProvider) PublishEndpoint(notificationDeviceID, roomID string, messageInfo *MessageInfo) NotificationChannel {
+	span := tracer.StartSpan(ap.ctx, "PublishEndpoint", "notification")
+	defer tracer.Finish(span)
+
+	nc := make(NotificationChannel, 1)
+	defer close(nc)
+	result := NotificationResult{}
+
+	client := ap.newSnsClient()
+	message, err := ap.makeMessage(roomID, messageInfo)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		result.Error = err
+		nc <- result
+		return nc
+	}
+
+	params := &sns.PublishInput{
+		Message:          aws.String(message),
+		MessageStructure: aws.String("json"),
+		Subject:          aws.String("subject"),
+		TargetArn:        aws.String(notificationDeviceID),
+	}
+	res, err := client.Publish(params)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		result.Error = err
+		nc <- result
+		return nc
+	}
+	logger.Info(fmt.Sprintf("[Amazon SNS]Publish message targetArn:%s message:%s response:%s", notificationDeviceID, message, res.String()))
+
+	nc <- result
+	return nc
+}
+
+func (ap *awssnsProvider) makeMessage(roomID string, messageInfo *MessageInfo) (string, error) {
 	contentAvailable := 1
 	iosPush := iosPush{
 		Alert:            messageInfo.Text,
@@ -269,10 +343,7 @@ func (ap *awssnsProvider) Publish(notificationTopicID, roomID string, messageInf
 	}
 	b, err := json.Marshal(ios)
 	if err != nil {
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		result.Error = err
-		nc <- result
+		return "", err
 	}
 	wrapper.APNS = string(b[:])
 	wrapper.APNSSandbox = wrapper.APNS
@@ -285,41 +356,13 @@ func (ap *awssnsProvider) Publish(notificationTopicID, roomID string, messageInf
 	}
 	b, err = json.Marshal(gcm)
 	if err != nil {
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		result.Error = err
-		nc <- result
+		return "", err
 	}
 	wrapper.GCM = string(b[:])
 	pushData, err := json.Marshal(wrapper)
 	if err != nil {
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		result.Error = err
-		nc <- result
+		return "", err
 	}
-	message := string(pushData[:])
 
-	params := &sns.PublishInput{
-		Message:          aws.String(message),
-		MessageStructure: aws.String("json"),
-		Subject:          aws.String("subject"),
-		TopicArn:         aws.String(notificationTopicID),
-	}
-	res, err := client.Publish(params)
-	if err != nil {
-		logger.Error(err.Error())
-		tracer.SetError(span, err)
-		return nil
-	}
-	logger.Info(fmt.Sprintf("[Amazon SNS]Publish message topicArn:%s message:%s response:%s", notificationTopicID, message, res.String()))
-
-	nc <- result
-
-	select {
-	case <-ap.ctx.Done():
-		return nc
-	case <-nc:
-		return nc
-	}
+	return string(pushData[:]), nil
 }
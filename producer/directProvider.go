@@ -18,10 +18,15 @@ type directProvider struct {
 	ctx context.Context
 }
 
-func (dp directProvider) PublishMessage(rtmEvent *scpb.EventData) error {
+func (dp directProvider) PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error {
 	span := tracer.StartSpan(dp.ctx, "PublishMessage", "producer")
 	defer tracer.Finish(span)
 
+	opt := publishMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	buffer := new(bytes.Buffer)
 	json.NewEncoder(buffer).Encode(rtmEvent)
 
@@ -34,6 +39,10 @@ func (dp directProvider) PublishMessage(rtmEvent *scpb.EventData) error {
 		return err
 	}
 
+	if opt.eventID != "" {
+		req.Header.Set(config.HeaderEventID, opt.eventID)
+	}
+
 	tracer.InjectHTTPRequest(span, req)
 
 	resp, err := http.DefaultClient.Do(req)
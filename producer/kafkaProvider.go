@@ -1,3 +1,6 @@
+//go:build cgo
+// +build cgo
+
 package producer
 
 import (
@@ -20,10 +23,15 @@ type kafkaProvider struct {
 	ctx context.Context
 }
 
-func (kp kafkaProvider) PublishMessage(rtmEvent *scpb.EventData) error {
+func (kp kafkaProvider) PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error {
 	span := tracer.StartSpan(kp.ctx, "PublishMessage", "producer")
 	defer tracer.Finish(span)
 
+	opt := publishMessageOptions{}
+	for _, o := range opts {
+		o(&opt)
+	}
+
 	cfg := config.Config()
 	buffer := new(bytes.Buffer)
 	json.NewEncoder(buffer).Encode(rtmEvent)
@@ -35,7 +43,9 @@ func (kp kafkaProvider) PublishMessage(rtmEvent *scpb.EventData) error {
 		err = errors.Wrap(err, "Kafka create producer failure")
 		logger.Error(err.Error())
 		tracer.SetError(span, err)
+		return err
 	}
+	defer p.Close()
 
 	// Delivery report handler for produced messages
 	go func() {
@@ -56,14 +66,29 @@ func (kp kafkaProvider) PublishMessage(rtmEvent *scpb.EventData) error {
 	// Produce messages to topic (asynchronously)
 	topic := cfg.Producer.Kafka.Topic
 	// for _, word := range []string{"Welcome", "to", "the", "Confluent", "Kafka", "Golang", "client"} {
-	p.Produce(&kafka.Message{
+	km := &kafka.Message{
 		TopicPartition: kafka.TopicPartition{Topic: &topic, Partition: kafka.PartitionAny},
 		Value:          buffer.Bytes(),
-	}, nil)
+	}
+	if opt.eventID != "" {
+		km.Headers = []kafka.Header{{Key: config.HeaderEventID, Value: []byte(opt.eventID)}}
+	}
+	err = p.Produce(km, nil)
+	if err != nil {
+		err = errors.Wrap(err, "Kafka produce failure")
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
 	// }
 
 	// Wait for message deliveries
-	p.Flush(15 * 1000)
+	if remaining := p.Flush(15 * 1000); remaining > 0 {
+		err = fmt.Errorf("Kafka flush timeout. %d messages are not delivered", remaining)
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
 
 	return nil
 }
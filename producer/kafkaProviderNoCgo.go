@@ -0,0 +1,23 @@
+//go:build !cgo
+// +build !cgo
+
+package producer
+
+import (
+	"context"
+
+	logger "github.com/betchi/zapper"
+	"github.com/pkg/errors"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// kafkaProvider is unavailable without cgo because confluent-kafka-go links librdkafka
+type kafkaProvider struct {
+	ctx context.Context
+}
+
+func (kp kafkaProvider) PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error {
+	err := errors.New("kafka producer is not supported by the binary built without cgo")
+	logger.Error(err.Error())
+	return err
+}
@@ -10,7 +10,7 @@ type noopProvider struct {
 	ctx context.Context
 }
 
-func (np noopProvider) PublishMessage(rtmEvent *scpb.EventData) error {
+func (np noopProvider) PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error {
 	// Do not process anything
 	return nil
 }
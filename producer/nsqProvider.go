@@ -30,7 +30,7 @@ func b2s(b []byte) string {
 	return *(*string)(unsafe.Pointer(&sh))
 }
 
-func (np nsqProvider) PublishMessage(rtmEvent *scpb.EventData) error {
+func (np nsqProvider) PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error {
 	span := tracer.StartSpan(np.ctx, "PublishMessage", "producer")
 	defer tracer.Finish(span)
 
@@ -58,6 +58,7 @@ func (np nsqProvider) PublishMessage(rtmEvent *scpb.EventData) error {
 		err = fmt.Errorf("http status code[%d]", resp.StatusCode)
 		logger.Error(err.Error())
 		tracer.SetError(span, err)
+		return err
 	}
 
 	return nil
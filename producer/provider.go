@@ -7,8 +7,22 @@ import (
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
+type publishMessageOptions struct {
+	eventID string
+}
+
+type PublishMessageOption func(*publishMessageOptions)
+
+// PublishMessageOptionWithEventID attaches the deduplication ID of the event.
+// The event may be published more than once with the same ID, so consumers should dedupe by it.
+func PublishMessageOptionWithEventID(eventID string) PublishMessageOption {
+	return func(ops *publishMessageOptions) {
+		ops.eventID = eventID
+	}
+}
+
 type provider interface {
-	PublishMessage(*scpb.EventData) error
+	PublishMessage(rtmEvent *scpb.EventData, opts ...PublishMessageOption) error
 }
 
 func Provider(ctx context.Context) provider {
@@ -0,0 +1,75 @@
+package ratelimiter
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+)
+
+const localBucketSweepInterval = time.Minute
+
+type localBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+var (
+	localBucketsMutex sync.Mutex
+	localBuckets      = make(map[string]*localBucket)
+	localLastSwept    = time.Now()
+)
+
+// localProvider is the token bucket rate limiter kept in memory of this process
+type localProvider struct {
+	ctx context.Context
+}
+
+func (lp *localProvider) Take(key string) (bool, time.Duration, error) {
+	cfg := config.Config()
+	allowed, retryAfter := takeLocalBucket(key, cfg.RateLimiter.Rate, cfg.RateLimiter.Burst, time.Now())
+	return allowed, retryAfter, nil
+}
+
+func takeLocalBucket(key string, rate float64, burst int, now time.Time) (bool, time.Duration) {
+	localBucketsMutex.Lock()
+	defer localBucketsMutex.Unlock()
+
+	if now.Sub(localLastSwept) > localBucketSweepInterval {
+		sweepLocalBuckets(rate, burst, now)
+	}
+
+	b, ok := localBuckets[key]
+	if !ok {
+		b = &localBucket{
+			tokens:  float64(burst),
+			updated: now,
+		}
+		localBuckets[key] = b
+	}
+
+	b.tokens = math.Min(float64(burst), b.tokens+now.Sub(b.updated).Seconds()*rate)
+	b.updated = now
+
+	if b.tokens < 1 {
+		if rate <= 0 {
+			return false, 0
+		}
+		return false, time.Duration((1 - b.tokens) / rate * float64(time.Second))
+	}
+
+	b.tokens--
+	return true, 0
+}
+
+// sweepLocalBuckets removes the buckets that have been refilled, they are same as new buckets.
+func sweepLocalBuckets(rate float64, burst int, now time.Time) {
+	for key, b := range localBuckets {
+		if b.tokens+now.Sub(b.updated).Seconds()*rate >= float64(burst) {
+			delete(localBuckets, key)
+		}
+	}
+	localLastSwept = now
+}
@@ -0,0 +1,55 @@
+package ratelimiter
+
+import (
+	"testing"
+	"time"
+)
+
+const (
+	TestRateLimiterTakeLocalBucket = "[ratelimiter] take local bucket test"
+)
+
+func TestLocalProvider(t *testing.T) {
+	t.Run(TestRateLimiterTakeLocalBucket, func(t *testing.T) {
+		key := Key("ratelimiter-workspace", "ratelimiter-client-id", "ratelimiter-user-id-0001", "192.0.2.1:50000")
+		now := time.Now()
+
+		for i := 0; i < 3; i++ {
+			allowed, _ := takeLocalBucket(key, 1, 3, now)
+			if !allowed {
+				t.Fatalf("Failed to %s. Expected token %d to be allowed, but it was not allowed", TestRateLimiterTakeLocalBucket, i+1)
+			}
+		}
+
+		allowed, retryAfter := takeLocalBucket(key, 1, 3, now)
+		if allowed {
+			t.Fatalf("Failed to %s. Expected empty bucket not to be allowed, but it was allowed", TestRateLimiterTakeLocalBucket)
+		}
+		if retryAfter != time.Second {
+			t.Fatalf("Failed to %s. Expected retryAfter to be %v, but it was %v", TestRateLimiterTakeLocalBucket, time.Second, retryAfter)
+		}
+
+		otherKey := Key("ratelimiter-workspace", "ratelimiter-client-id", "ratelimiter-user-id-0002", "192.0.2.1:50000")
+		allowed, _ = takeLocalBucket(otherKey, 1, 3, now)
+		if !allowed {
+			t.Fatalf("Failed to %s. Expected other user to be allowed, but it was not allowed", TestRateLimiterTakeLocalBucket)
+		}
+
+		anonymousKey := Key("ratelimiter-workspace", "ratelimiter-client-id", "", "192.0.2.1:50000")
+		allowed, _ = takeLocalBucket(anonymousKey, 1, 3, now)
+		if !allowed {
+			t.Fatalf("Failed to %s. Expected anonymous caller to be allowed, but it was not allowed", TestRateLimiterTakeLocalBucket)
+		}
+		if anonymousKey != Key("ratelimiter-workspace", "ratelimiter-client-id", "", "192.0.2.1:60000") {
+			t.Fatalf("Failed to %s. Expected anonymous callers to be keyed by the remote host", TestRateLimiterTakeLocalBucket)
+		}
+		if anonymousKey == Key("ratelimiter-workspace", "ratelimiter-client-id", "", "192.0.2.2:50000") {
+			t.Fatalf("Failed to %s. Expected anonymous callers of other hosts not to share the bucket", TestRateLimiterTakeLocalBucket)
+		}
+
+		allowed, _ = takeLocalBucket(key, 1, 3, now.Add(time.Second))
+		if !allowed {
+			t.Fatalf("Failed to %s. Expected refilled token to be allowed, but it was not allowed", TestRateLimiterTakeLocalBucket)
+		}
+	})
+}
@@ -0,0 +1,15 @@
+package ratelimiter
+
+import (
+	"context"
+	"time"
+)
+
+type noopProvider struct {
+	ctx context.Context
+}
+
+func (np *noopProvider) Take(key string) (bool, time.Duration, error) {
+	// Do not process anything
+	return true, 0, nil
+}
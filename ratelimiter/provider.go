@@ -0,0 +1,53 @@
+package ratelimiter
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+)
+
+// provider is the interface of rate limiter backends.
+// Shared backends (e.g. redis) can be added by implementing this interface
+// so that the limit is applied across multiple API servers.
+type provider interface {
+	// Take consumes a token of the bucket identified by key.
+	// If the bucket is empty, it returns false and the duration until a token is available.
+	Take(key string) (bool, time.Duration, error)
+}
+
+// Provider returns the rate limiter provider
+func Provider(ctx context.Context) provider {
+	cfg := config.Config()
+
+	var p provider
+	switch cfg.RateLimiter.Provider {
+	case "local":
+		p = &localProvider{
+			ctx: ctx,
+		}
+	default:
+		p = &noopProvider{
+			ctx: ctx,
+		}
+	}
+
+	return p
+}
+
+// Key makes a bucket key from workspace, client ID and user ID.
+// The requests without user ID, such as the ones of anonymous or admin callers, are keyed by the remote address
+// so that they don't share one bucket.
+func Key(workspace, clientID, userID, remoteAddr string) string {
+	if userID != "" {
+		return fmt.Sprintf("%s:%s:user:%s", workspace, clientID, userID)
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err == nil {
+		remoteAddr = host
+	}
+	return fmt.Sprintf("%s:%s:addr:%s", workspace, clientID, remoteAddr)
+}
@@ -0,0 +1,69 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+func setAuditLogMux() {
+	mux.GetFunc("/auditLogs", commonHandler(adminAuthzHandler(getAuditLogs)))
+}
+
+func getAuditLogs(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getAuditLogs", "rest")
+	defer tracer.Finish(span)
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req := &model.RetrieveAuditLogsRequest{}
+	req.Limit = limit
+	req.Offset = offset
+	req.Action = params.Get("action")
+	req.ActorUserID = params.Get("actorUserId")
+	req.TargetType = params.Get("targetType")
+	req.TargetID = params.Get("targetId")
+
+	for name, timestamp := range map[string]*int64{"from": &req.FromTimestamp, "to": &req.ToTimestamp} {
+		value, ok := params[name]
+		if !ok {
+			continue
+		}
+		*timestamp, err = strconv.ParseInt(value[0], 10, 64)
+		if err != nil {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   name,
+					Reason: name + " must be unix timestamp.",
+				},
+			}
+			respondError(w, r, model.NewErrorResponse("Failed to retrieve audit logs.", http.StatusBadRequest, model.WithInvalidParams(invalidParams)))
+			return
+		}
+	}
+
+	auditLogs, errRes := service.RetrieveAuditLogs(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", auditLogs)
+}
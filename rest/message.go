@@ -3,6 +3,7 @@ package rest
 import (
 	"net/http"
 
+	"github.com/go-zoo/bone"
 	"github.com/swagchat/chat-api/model"
 	"github.com/swagchat/chat-api/service"
 	"github.com/betchi/tracer"
@@ -10,6 +11,7 @@ import (
 
 func setMessageMux() {
 	mux.PostFunc("/messages", commonHandler(updateLastAccessedHandler(postMessage)))
+	mux.DeleteFunc("/messages/#messageId^[a-z0-9-]$", commonHandler(deleteMessage))
 	// mux.GetFunc("/messages/#messageId^[a-z0-9-]$", commonHandler(updateLastAccessedHandler(getMessage)))
 }
 
@@ -24,6 +26,17 @@ func postMessage(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if req.ScheduledTimestamp != 0 {
+		scheduledMessage, errRes := service.ScheduleMessage(ctx, &req)
+		if errRes != nil {
+			respondError(w, r, errRes)
+			return
+		}
+
+		respond(w, r, http.StatusCreated, "application/json", scheduledMessage)
+		return
+	}
+
 	message, errRes := service.SendMessage(ctx, &req)
 	if errRes != nil {
 		respondError(w, r, errRes)
@@ -49,3 +62,20 @@ func postMessage(w http.ResponseWriter, r *http.Request) {
 // 	setLastModified(w, message.Modified)
 // 	respond(w, r, http.StatusOK, "application/json", message)
 // }
+
+func deleteMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "deleteMessage", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.DeleteMessageRequest{}
+	req.MessageID = bone.GetValue(r, "messageId")
+
+	errRes := service.DeleteMessage(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
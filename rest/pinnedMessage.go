@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/betchi/tracer"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+func setPinnedMessageMux() {
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/pinnedMessages", commonHandler(roomMemberAuthzHandler(postPinnedMessage)))
+	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$/pinnedMessages", commonHandler(roomMemberAuthzHandler(getPinnedMessages)))
+	mux.PutFunc("/rooms/#roomId^[a-z0-9-]$/pinnedMessages", commonHandler(roomMemberAuthzHandler(putPinnedMessagesOrder)))
+	mux.DeleteFunc("/rooms/#roomId^[a-z0-9-]$/pinnedMessages/#messageId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(deletePinnedMessage)))
+}
+
+func postPinnedMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postPinnedMessage", "rest")
+	defer tracer.Finish(span)
+
+	var req model.PinMessageRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	pinnedMessage, errRes := service.PinMessage(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, "application/json", pinnedMessage)
+}
+
+func getPinnedMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getPinnedMessages", "rest")
+	defer tracer.Finish(span)
+
+	pinnedMessages, errRes := service.RetrievePinnedMessages(ctx, bone.GetValue(r, "roomId"))
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", pinnedMessages)
+}
+
+func putPinnedMessagesOrder(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "putPinnedMessagesOrder", "rest")
+	defer tracer.Finish(span)
+
+	var req model.UpdatePinnedMessagesOrderRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	pinnedMessages, errRes := service.UpdatePinnedMessagesOrder(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", pinnedMessages)
+}
+
+func deletePinnedMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "deletePinnedMessage", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.UnpinMessageRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+	req.MessageID = bone.GetValue(r, "messageId")
+
+	errRes := service.UnpinMessage(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
@@ -0,0 +1,107 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/betchi/tracer"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+func setPublicRoomMux() {
+	mux.GetFunc("/publicRooms", commonHandler(getPublicRooms))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/join", commonHandler(postJoinRoom))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/leave", commonHandler(roomMemberAuthzHandler(postLeaveRoom)))
+}
+
+func getPublicRooms(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getPublicRooms", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.RetrievePublicRoomsRequest{}
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.Limit = limit
+	req.Offset = offset
+
+	if nameArray, ok := params["name"]; ok {
+		req.Name = nameArray[0]
+	}
+
+	// Tags are given as comma separated values, or by repeating the parameter
+	for _, tags := range params["tags"] {
+		for _, tag := range strings.Split(tags, ",") {
+			if tag != "" {
+				req.Tags = append(req.Tags, tag)
+			}
+		}
+	}
+
+	rooms, errRes := service.RetrievePublicRooms(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", rooms)
+}
+
+func postJoinRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postJoinRoom", "rest")
+	defer tracer.Finish(span)
+
+	var req model.JoinRoomRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	room, errRes := service.JoinRoom(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", room)
+}
+
+func postLeaveRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postLeaveRoom", "rest")
+	defer tracer.Finish(span)
+
+	var req model.LeaveRoomRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	errRes := service.LeaveRoom(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
@@ -16,6 +16,10 @@ func setRoomMux() {
 	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(getRoom)))
 	mux.PutFunc("/rooms/#roomId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(putRoom)))
 	mux.DeleteFunc("/rooms/#roomId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(deleteRoom)))
+	mux.PutFunc("/rooms/#roomId^[a-z0-9-]$/owner", commonHandler(roomMemberAuthzHandler(putRoomOwner)))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/archive", commonHandler(roomMemberAuthzHandler(postArchiveRoom)))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/unarchive", commonHandler(roomMemberAuthzHandler(postUnarchiveRoom)))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/restore", commonHandler(adminAuthzHandler(postRestoreRoom)))
 	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$/messages", commonHandler(roomMemberAuthzHandler(updateLastAccessedHandler(getRoomMessages))))
 }
 
@@ -131,6 +135,79 @@ func deleteRoom(w http.ResponseWriter, r *http.Request) {
 	respond(w, r, http.StatusNoContent, "", nil)
 }
 
+func putRoomOwner(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "putRoomOwner", "rest")
+	defer tracer.Finish(span)
+
+	var req model.TransferRoomOwnerRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	errRes := service.TransferRoomOwner(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
+
+func postArchiveRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postArchiveRoom", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.ArchiveRoomRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	room, errRes := service.ArchiveRoom(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", room)
+}
+
+func postUnarchiveRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postUnarchiveRoom", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.ArchiveRoomRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	room, errRes := service.UnarchiveRoom(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", room)
+}
+
+func postRestoreRoom(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postRestoreRoom", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.RestoreRoomRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	room, errRes := service.RestoreRoom(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", room)
+}
+
 func getRoomMessages(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	span := tracer.StartSpan(ctx, "getRoomMessages", "rest")
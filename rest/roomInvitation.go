@@ -0,0 +1,156 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/betchi/tracer"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+func setRoomInvitationMux() {
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/invitations", commonHandler(roomMemberAuthzHandler(postRoomInvitations)))
+	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$/invitations", commonHandler(roomMemberAuthzHandler(getRoomInvitations)))
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/joinRequests", commonHandler(postRoomJoinRequest))
+	mux.GetFunc("/users/#userId^[a-z0-9-]$/invitations", commonHandler(selfResourceAuthzHandler(getUserInvitations)))
+	mux.PutFunc("/invitations/#invitationId^[a-z0-9-]$", commonHandler(putRoomInvitation))
+}
+
+func postRoomInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postRoomInvitations", "rest")
+	defer tracer.Finish(span)
+
+	var req model.CreateRoomInvitationsRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	invitations, errRes := service.CreateRoomInvitations(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, "application/json", invitations)
+}
+
+func getRoomInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getRoomInvitations", "rest")
+	defer tracer.Finish(span)
+
+	req, errRes := makeRetrieveRoomInvitationsRequest(r)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	invitations, errRes := service.RetrieveRoomInvitations(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", invitations)
+}
+
+func getUserInvitations(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getUserInvitations", "rest")
+	defer tracer.Finish(span)
+
+	req, errRes := makeRetrieveRoomInvitationsRequest(r)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.UserID = bone.GetValue(r, "userId")
+
+	invitations, errRes := service.RetrieveRoomInvitations(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", invitations)
+}
+
+func makeRetrieveRoomInvitationsRequest(r *http.Request) (*model.RetrieveRoomInvitationsRequest, *model.ErrorResponse) {
+	req := &model.RetrieveRoomInvitationsRequest{}
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		return nil, model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	req.Limit = limit
+	req.Offset = offset
+
+	if typeArray, ok := params["type"]; ok {
+		req.Type = typeArray[0]
+	}
+
+	if statusArray, ok := params["status"]; ok {
+		req.Status = statusArray[0]
+	}
+
+	return req, nil
+}
+
+func postRoomJoinRequest(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postRoomJoinRequest", "rest")
+	defer tracer.Finish(span)
+
+	var req model.CreateRoomJoinRequestRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	invitation, errRes := service.CreateRoomJoinRequest(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, "application/json", invitation)
+}
+
+func putRoomInvitation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "putRoomInvitation", "rest")
+	defer tracer.Finish(span)
+
+	var req model.UpdateRoomInvitationRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.InvitationID = bone.GetValue(r, "invitationId")
+
+	invitation, errRes := service.UpdateRoomInvitation(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", invitation)
+}
@@ -0,0 +1,93 @@
+package rest
+
+import (
+	"net/http"
+
+	"github.com/betchi/tracer"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+func setRoomInviteLinkMux() {
+	mux.PostFunc("/rooms/#roomId^[a-z0-9-]$/inviteLinks", commonHandler(roomMemberAuthzHandler(postRoomInviteLink)))
+	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$/inviteLinks", commonHandler(roomMemberAuthzHandler(getRoomInviteLinks)))
+	mux.DeleteFunc("/rooms/#roomId^[a-z0-9-]$/inviteLinks/#inviteLinkId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(deleteRoomInviteLink)))
+	mux.PostFunc("/inviteLinks/#inviteLinkId^[a-z0-9-]$/join", commonHandler(postJoinRoomByInviteLink))
+}
+
+func postRoomInviteLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postRoomInviteLink", "rest")
+	defer tracer.Finish(span)
+
+	var req model.CreateRoomInviteLinkRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	inviteLink, errRes := service.CreateRoomInviteLink(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, "application/json", inviteLink)
+}
+
+func getRoomInviteLinks(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getRoomInviteLinks", "rest")
+	defer tracer.Finish(span)
+
+	inviteLinks, errRes := service.RetrieveRoomInviteLinks(ctx, bone.GetValue(r, "roomId"))
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", inviteLinks)
+}
+
+func deleteRoomInviteLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "deleteRoomInviteLink", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.DeleteRoomInviteLinkRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+	req.InviteLinkID = bone.GetValue(r, "inviteLinkId")
+
+	errRes := service.DeleteRoomInviteLink(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
+
+func postJoinRoomByInviteLink(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postJoinRoomByInviteLink", "rest")
+	defer tracer.Finish(span)
+
+	var req model.JoinRoomByInviteLinkRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.InviteLinkID = bone.GetValue(r, "inviteLinkId")
+
+	room, errRes := service.JoinRoomByInviteLink(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", room)
+}
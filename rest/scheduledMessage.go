@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+
+	"github.com/betchi/tracer"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+)
+
+func setScheduledMessageMux() {
+	mux.GetFunc("/rooms/#roomId^[a-z0-9-]$/scheduledMessages", commonHandler(roomMemberAuthzHandler(getScheduledMessages)))
+	mux.PutFunc("/rooms/#roomId^[a-z0-9-]$/scheduledMessages/#scheduledMessageId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(putScheduledMessage)))
+	mux.DeleteFunc("/rooms/#roomId^[a-z0-9-]$/scheduledMessages/#scheduledMessageId^[a-z0-9-]$", commonHandler(roomMemberAuthzHandler(deleteScheduledMessage)))
+}
+
+func getScheduledMessages(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getScheduledMessages", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.RetrieveScheduledMessagesRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.Limit = limit
+	req.Offset = offset
+
+	scheduledMessages, errRes := service.RetrieveScheduledMessages(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", scheduledMessages)
+}
+
+func putScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "putScheduledMessage", "rest")
+	defer tracer.Finish(span)
+
+	var req model.UpdateScheduledMessageRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	req.RoomID = bone.GetValue(r, "roomId")
+	req.ScheduledMessageID = bone.GetValue(r, "scheduledMessageId")
+
+	scheduledMessage, errRes := service.UpdateScheduledMessage(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", scheduledMessage)
+}
+
+func deleteScheduledMessage(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "deleteScheduledMessage", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.DeleteScheduledMessageRequest{}
+	req.RoomID = bone.GetValue(r, "roomId")
+	req.ScheduledMessageID = bone.GetValue(r, "scheduledMessageId")
+
+	errRes := service.DeleteScheduledMessage(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
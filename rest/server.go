@@ -5,6 +5,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -18,7 +19,9 @@ import (
 	"github.com/shogo82148/go-gracedown"
 	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/i18n"
 	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/ratelimiter"
 	"github.com/swagchat/chat-api/service"
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
@@ -53,14 +56,21 @@ func Run(ctx context.Context) {
 	mux.GetFunc("/", indexHandler)
 	mux.OptionsFunc("/*", optionsHandler)
 	setAssetMux()
+	setAuditLogMux()
 	setBlockUserMux()
 	setDeviceMux()
 	setMessageMux()
+	setPinnedMessageMux()
+	setPublicRoomMux()
 	setRoomMux()
+	setRoomInvitationMux()
+	setRoomInviteLinkMux()
 	setRoomUserMux()
+	setScheduledMessageMux()
 	setSettingMux()
 	setUserMux()
 	setUserRoleMux()
+	setWorkspaceMux()
 
 	if cfg.Storage.Provider == "awsS3" {
 		setAssetAwsSnsMux()
@@ -112,11 +122,29 @@ func commonHandler(fn http.HandlerFunc) http.HandlerFunc {
 	return (colsHandler(
 		tracer.HandlerFunc(
 			jwtHandler(
-				judgeAppClientHandler(
-					func(w http.ResponseWriter, r *http.Request) {
-						defer r.Body.Close()
-						fn(w, r)
-					})))))
+				workspaceHandler(
+					judgeAppClientHandler(
+						rateLimitHandler(
+							func(w http.ResponseWriter, r *http.Request) {
+								defer r.Body.Close()
+								fn(w, r)
+							})))))))
+}
+
+// workspaceAdminHandler is the handler for the workspace APIs.
+// They are authorized by the app clients of the database configured by datastore.database instead of the workspace.
+func workspaceAdminHandler(fn http.HandlerFunc) http.HandlerFunc {
+	return (colsHandler(
+		tracer.HandlerFunc(
+			jwtHandler(
+				controlDatabaseHandler(
+					judgeAppClientHandler(
+						rateLimitHandler(
+							adminAuthzHandler(
+								func(w http.ResponseWriter, r *http.Request) {
+									defer r.Body.Close()
+									fn(w, r)
+								}))))))))
 }
 
 func colsHandler(fn http.HandlerFunc) http.HandlerFunc {
@@ -134,6 +162,39 @@ func jwtHandler(fn http.HandlerFunc) http.HandlerFunc {
 		workspace := r.Header.Get(config.HeaderWorkspace)
 		ctx = context.WithValue(ctx, config.CtxWorkspace, workspace)
 
+		lang := i18n.ParseAcceptLanguage(r.Header.Get(config.HeaderAcceptLanguage))
+		ctx = context.WithValue(ctx, config.CtxLang, lang)
+
+		// Reads go to master after the user writes so that the user reads own writes
+		requester := userID
+		if requester == "" {
+			requester = r.Header.Get(config.HeaderClientID)
+		}
+		ctx = datastore.StickToMaster(ctx, requester)
+
+		fn(w, r.WithContext(ctx))
+	}
+}
+
+// workspaceHandler rejects the requests to the workspaces which are not registered in dynamic mode,
+// and the requests with the client ids which are not the app clients of the workspace before the workspace is used.
+// The connection of the workspace is held while the request is processed not to be evicted.
+func workspaceHandler(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		workspace, _ := r.Context().Value(config.CtxWorkspace).(string)
+		errRes := service.AuthenticateWorkspace(r.Context(), workspace, r.Header.Get(config.HeaderClientID))
+		if errRes != nil {
+			respondError(w, r, errRes)
+			return
+		}
+		defer datastore.Hold(r.Context())()
+		fn(w, r)
+	}
+}
+
+func controlDatabaseHandler(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), config.CtxWorkspace, config.Config().Datastore.Database)
 		fn(w, r.WithContext(ctx))
 	}
 }
@@ -189,6 +250,33 @@ func judgeAppClientHandler(fn http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
+func rateLimitHandler(fn http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+		clientID, _ := ctx.Value(config.CtxClientID).(string)
+		userID, _ := ctx.Value(config.CtxUserID).(string)
+
+		allowed, retryAfter, err := ratelimiter.Provider(ctx).Take(ratelimiter.Key(workspace, clientID, userID, r.RemoteAddr))
+		if err != nil {
+			// Do not block requests when the rate limiter backend is unavailable
+			logger.Error(err.Error())
+			fn(w, r)
+			return
+		}
+
+		if !allowed {
+			seconds := int64(math.Ceil(retryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.FormatInt(seconds, 10))
+			errRes := model.NewErrorResponse(fmt.Sprintf("Too many requests. Please retry after %d seconds.", seconds), http.StatusTooManyRequests)
+			respondError(w, r, errRes)
+			return
+		}
+
+		fn(w, r)
+	}
+}
+
 func adminAuthzHandler(fn http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		clientID := r.Context().Value(config.CtxClientID)
@@ -292,6 +380,8 @@ func respond(w http.ResponseWriter, r *http.Request, status int, contentType str
 }
 
 func respondError(w http.ResponseWriter, r *http.Request, errRes *model.ErrorResponse) {
+	lang, _ := r.Context().Value(config.CtxLang).(string)
+	errRes.Message = i18n.Translate(lang, errRes.Message)
 	if errRes.Error != nil {
 		if config.Config().EnableDeveloperMessage {
 			errRes.DeveloperMessage = errRes.Error.Error()
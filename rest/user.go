@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strconv"
@@ -18,10 +19,12 @@ func setUserMux() {
 	mux.GetFunc("/users/#userId^[a-z0-9-]$", commonHandler(adminAuthzHandler(selfResourceAuthzHandler(getUser))))
 	mux.PutFunc("/users/#userId^[a-z0-9-]$", commonHandler(selfResourceAuthzHandler(putUser)))
 	mux.DeleteFunc("/users/#userId^[a-z0-9-]$", commonHandler(selfResourceAuthzHandler(deleteUser)))
+	mux.GetFunc("/users/#userId^[a-z0-9-]$/data", commonHandler(selfResourceAuthzHandler(getUserData)))
 
 	// mux.GetFunc("/users/#userId^[a-z0-9-]$/unreadCount", commonHandler(selfResourceAuthzHandler(getUserUnreadCount)))
 	mux.GetFunc("/users/#userId^[a-z0-9-]$/rooms", commonHandler(selfResourceAuthzHandler(getUserRooms)))
 	mux.GetFunc("/users/#userId^[a-z0-9-]$/contacts", commonHandler(selfResourceAuthzHandler(getContacts)))
+	mux.GetFunc("/users/#userId^[a-z0-9-]$/mentions", commonHandler(selfResourceAuthzHandler(getUserMentions)))
 	mux.GetFunc("/profiles/#userId^[a-z0-9-]$", commonHandler(contactsAuthzHandler(getProfile)))
 	mux.GetFunc("/roles/#roleId^[0-9]$/users", commonHandler(adminAuthzHandler(getRoleUsers)))
 }
@@ -124,9 +127,31 @@ func deleteUser(w http.ResponseWriter, r *http.Request) {
 	span := tracer.StartSpan(ctx, "deleteUser", "rest")
 	defer tracer.Finish(span)
 
-	req := &model.DeleteUserRequest{}
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
 
 	userID := bone.GetValue(r, "userId")
+
+	if eraseArray, ok := params["erase"]; ok {
+		req := &model.EraseUserRequest{}
+		req.UserID = userID
+		req.Erasure = model.UserErasure(eraseArray[0])
+
+		errRes := service.EraseUser(ctx, req)
+		if errRes != nil {
+			respondError(w, r, errRes)
+			return
+		}
+
+		respond(w, r, http.StatusNoContent, "", nil)
+		return
+	}
+
+	req := &model.DeleteUserRequest{}
 	req.UserID = userID
 
 	errRes := service.DeleteUser(ctx, req)
@@ -138,6 +163,25 @@ func deleteUser(w http.ResponseWriter, r *http.Request) {
 	respond(w, r, http.StatusNoContent, "", nil)
 }
 
+func getUserData(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getUserData", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.RetrieveUserDataRequest{}
+
+	userID := bone.GetValue(r, "userId")
+	req.UserID = userID
+
+	userData, errRes := service.RetrieveUserData(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", userData)
+}
+
 func getUserRooms(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	span := tracer.StartSpan(ctx, "getUserRooms", "rest")
@@ -186,6 +230,36 @@ func getUserRooms(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	includeArchived, errRes := parseBoolParam(params, "includeArchived")
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+	req.IncludeArchived = includeArchived != nil && *includeArchived
+
+	includeHidden, errRes := parseBoolParam(params, "includeHidden")
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+	req.IncludeHidden = includeHidden != nil && *includeHidden
+
+	req.Favorite, errRes = parseBoolParam(params, "favorite")
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.Muted, errRes = parseBoolParam(params, "muted")
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	if sortArray, ok := params["sort"]; ok {
+		req.Sort = model.UserRoomsSort(sortArray[0])
+	}
+
 	roomUsers, errRes := service.RetrieveUserRooms(ctx, req)
 	if errRes != nil {
 		respondError(w, r, errRes)
@@ -195,6 +269,45 @@ func getUserRooms(w http.ResponseWriter, r *http.Request) {
 	respond(w, r, http.StatusOK, "application/json", roomUsers)
 }
 
+func getUserMentions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getUserMentions", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.RetrieveMentionsRequest{}
+
+	userID := bone.GetValue(r, "userId")
+	req.UserID = userID
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req.Limit = limit
+	req.Offset = offset
+
+	if roomIDArray, ok := params["roomId"]; ok {
+		req.RoomID = roomIDArray[0]
+	}
+
+	mentions, errRes := service.RetrieveMentions(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", mentions)
+}
+
 func getContacts(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	span := tracer.StartSpan(ctx, "getContacts", "rest")
@@ -293,3 +406,23 @@ func getRoleUsers(w http.ResponseWriter, r *http.Request) {
 
 // 	respond(w, r, http.StatusOK, "application/json", userUnreadCount)
 // }
+
+// parseBoolParam parses the query parameter of true or false. It returns nil if the parameter is not set.
+func parseBoolParam(params url.Values, name string) (*bool, *model.ErrorResponse) {
+	valueArray, ok := params[name]
+	if !ok {
+		return nil, nil
+	}
+
+	value, err := strconv.ParseBool(valueArray[0])
+	if err != nil {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   name,
+				Reason: fmt.Sprintf("%s must be true or false.", name),
+			},
+		}
+		return nil, model.NewErrorResponse("", http.StatusBadRequest, model.WithInvalidParams(invalidParams))
+	}
+	return &value, nil
+}
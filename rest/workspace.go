@@ -0,0 +1,175 @@
+package rest
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/go-zoo/bone"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/service"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+func setWorkspaceMux() {
+	mux.PostFunc("/workspaces", workspaceAdminHandler(postWorkspace))
+	mux.GetFunc("/workspaces", workspaceAdminHandler(getWorkspaces))
+	mux.GetFunc("/workspaces/#name^[a-z0-9_]$", workspaceAdminHandler(getWorkspace))
+	mux.DeleteFunc("/workspaces/#name^[a-z0-9_]$", workspaceAdminHandler(deleteWorkspace))
+	mux.GetFunc("/workspaces/#name^[a-z0-9_]$/export", workspaceAdminHandler(getWorkspaceExport))
+	mux.PostFunc("/workspaces/#name^[a-z0-9_]$/import", workspaceAdminHandler(postWorkspaceImport))
+}
+
+func postWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postWorkspace", "rest")
+	defer tracer.Finish(span)
+
+	var req model.CreateWorkspaceRequest
+	if err := decodeBody(r, &req); err != nil {
+		respondJSONDecodeError(w, r, "")
+		return
+	}
+
+	workspace, errRes := service.CreateWorkspace(ctx, &req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusCreated, "application/json", workspace)
+}
+
+func getWorkspaces(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getWorkspaces", "rest")
+	defer tracer.Finish(span)
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	limit, offset, _, _, _, errRes := setPagingParams(params)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	req := &model.RetrieveWorkspacesRequest{}
+	req.Limit = limit
+	req.Offset = offset
+
+	workspaces, errRes := service.RetrieveWorkspaces(ctx, req)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", workspaces)
+}
+
+func getWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getWorkspace", "rest")
+	defer tracer.Finish(span)
+
+	workspace, errRes := service.RetrieveWorkspace(ctx, bone.GetValue(r, "name"))
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", workspace)
+}
+
+func deleteWorkspace(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "deleteWorkspace", "rest")
+	defer tracer.Finish(span)
+
+	errRes := service.DeleteWorkspace(ctx, bone.GetValue(r, "name"))
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusNoContent, "", nil)
+}
+
+// exportResponseWriter writes the header of the response when the export starts to be written,
+// so that the errors before it are responded as usual
+type exportResponseWriter struct {
+	http.ResponseWriter
+	started bool
+}
+
+func (erw *exportResponseWriter) Write(b []byte) (int, error) {
+	if !erw.started {
+		erw.started = true
+		erw.Header().Set("Content-Type", "application/x-ndjson")
+		erw.WriteHeader(http.StatusOK)
+	}
+	return erw.ResponseWriter.Write(b)
+}
+
+func getWorkspaceExport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "getWorkspaceExport", "rest")
+	defer tracer.Finish(span)
+
+	params, err := url.ParseQuery(r.URL.RawQuery)
+	if err != nil {
+		errRes := model.NewErrorResponse("", http.StatusBadRequest, model.WithError(err))
+		respondError(w, r, errRes)
+		return
+	}
+
+	req := &model.ExportWorkspaceRequest{}
+	req.Workspace = bone.GetValue(r, "name")
+	if assetsArray, ok := params["assets"]; ok {
+		req.WithAssetData, err = strconv.ParseBool(assetsArray[0])
+		if err != nil {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "assets",
+					Reason: "assets must be true or false.",
+				},
+			}
+			respondError(w, r, model.NewErrorResponse("Failed to export workspace.", http.StatusBadRequest, model.WithInvalidParams(invalidParams)))
+			return
+		}
+	}
+
+	erw := &exportResponseWriter{ResponseWriter: w}
+	errRes := service.ExportWorkspace(ctx, req, erw)
+	if errRes != nil {
+		if erw.started {
+			// The status is already sent, so the export is cut off
+			logger.Error(errRes.Error.Error())
+			return
+		}
+		respondError(w, r, errRes)
+	}
+}
+
+func postWorkspaceImport(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	span := tracer.StartSpan(ctx, "postWorkspaceImport", "rest")
+	defer tracer.Finish(span)
+
+	req := &model.ImportWorkspaceRequest{}
+	req.Workspace = bone.GetValue(r, "name")
+
+	res, errRes := service.ImportWorkspace(ctx, req, r.Body)
+	if errRes != nil {
+		respondError(w, r, errRes)
+		return
+	}
+
+	respond(w, r, http.StatusOK, "application/json", res)
+}
@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
 	"github.com/swagchat/chat-api/storage"
@@ -30,6 +31,7 @@ func PostAsset(ctx context.Context, contentType string, file io.Reader, size int
 	}
 
 	asset.BeforePost()
+	asset.UserID, _ = ctx.Value(config.CtxUserID).(string)
 
 	assetInfo := &storage.AssetInfo{
 		Filename: fmt.Sprintf("%s.%s", asset.AssetID, asset.Extension),
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+// RetrieveAuditLogs retrieves audit logs
+func RetrieveAuditLogs(ctx context.Context, req *model.RetrieveAuditLogsRequest) (*model.AuditLogsResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveAuditLogs", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	opts := []datastore.SelectAuditLogsOption{
+		datastore.SelectAuditLogsOptionFilterByAction(req.Action),
+		datastore.SelectAuditLogsOptionFilterByActorUserID(req.ActorUserID),
+		datastore.SelectAuditLogsOptionFilterByTarget(req.TargetType, req.TargetID),
+		datastore.SelectAuditLogsOptionFilterByCreated(req.FromTimestamp, req.ToTimestamp),
+	}
+
+	auditLogs, err := datastore.Provider(ctx).SelectAuditLogs(req.Limit, req.Offset, opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve audit logs.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	allCount, err := datastore.Provider(ctx).SelectCountAuditLogs(opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve audit logs.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	if auditLogs == nil {
+		auditLogs = make([]*model.AuditLog, 0)
+	}
+
+	res := &model.AuditLogsResponse{}
+	res.AuditLogs = auditLogs
+	res.AllCount = allCount
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+
+	return res, nil
+}
+
+// roomMemberIDs returns the user IDs of the room users loaded with datastore.SelectRoomOptionWithUsers
+func roomMemberIDs(room *model.Room) []string {
+	userIDs := make([]string, 0, len(room.Users))
+	for _, user := range room.Users {
+		userIDs = append(userIDs, user.UserID)
+	}
+	return userIDs
+}
+
+// writeAuditLog records the action by the requester of ctx.
+// The action has already been done, so a failure is only logged and doesn't fail the request.
+func writeAuditLog(ctx context.Context, action model.AuditAction, targetType, targetID string, before, after interface{}) {
+	span := tracer.StartSpan(ctx, "writeAuditLog", "service")
+	defer tracer.Finish(span)
+
+	actorUserID, _ := ctx.Value(config.CtxUserID).(string)
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+
+	auditLog := model.NewAuditLog(action, actorUserID, clientID, workspace, targetType, targetID, before, after)
+	err := datastore.Provider(ctx).InsertAuditLog(auditLog)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+	}
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestServiceSetUpAuditLog       = "[service] set up audit log"
+	TestServiceWriteAuditLog       = "[service] write audit log test"
+	TestServiceRetrieveAuditLogs   = "[service] retrieve audit logs test"
+	TestServiceTearDownAuditLog    = "[service] tear down audit log"
+	testServiceAuditLogUserID      = "audit-log-service-user-id-0001"
+	testServiceAuditLogBlockUserID = "audit-log-service-user-id-0002"
+	testServiceAuditLogActorID     = "audit-log-service-admin-id-0001"
+)
+
+func TestAuditLog(t *testing.T) {
+	actx := context.WithValue(ctx, config.CtxUserID, testServiceAuditLogActorID)
+
+	t.Run(TestServiceSetUpAuditLog, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		for _, userID := range []string{testServiceAuditLogUserID, testServiceAuditLogBlockUserID} {
+			newUser := &model.User{}
+			newUser.UserID = userID
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpAuditLog, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestServiceWriteAuditLog, func(t *testing.T) {
+		roleReq := &model.AddUserRolesRequest{}
+		roleReq.UserID = testServiceAuditLogUserID
+		roleReq.Roles = []int32{7}
+		errRes := AddUserRoles(actx, roleReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceWriteAuditLog, errRes.Message)
+		}
+
+		blockReq := &model.AddBlockUsersRequest{}
+		blockReq.UserID = testServiceAuditLogUserID
+		blockReq.BlockUserIDs = []string{testServiceAuditLogBlockUserID}
+		errRes = AddBlockUsers(actx, blockReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceWriteAuditLog, errRes.Message)
+		}
+	})
+
+	t.Run(TestServiceRetrieveAuditLogs, func(t *testing.T) {
+		res, errRes := RetrieveAuditLogs(ctx, &model.RetrieveAuditLogsRequest{
+			Limit:       10,
+			ActorUserID: testServiceAuditLogActorID,
+			TargetType:  model.AuditTargetTypeUser,
+			TargetID:    testServiceAuditLogUserID,
+		})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveAuditLogs, errRes.Message)
+		}
+		if res.AllCount != 2 || len(res.AuditLogs) != 2 {
+			t.Fatalf("Failed to %s. Expected audit logs count to be 2, but it was %d", TestServiceRetrieveAuditLogs, res.AllCount)
+		}
+
+		res, errRes = RetrieveAuditLogs(ctx, &model.RetrieveAuditLogsRequest{
+			Limit:    10,
+			Action:   string(model.AuditActionAddUserRoles),
+			TargetID: testServiceAuditLogUserID,
+		})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveAuditLogs, errRes.Message)
+		}
+		if len(res.AuditLogs) != 1 {
+			t.Fatalf("Failed to %s. Expected audit logs count to be 1, but it was %d", TestServiceRetrieveAuditLogs, len(res.AuditLogs))
+		}
+		var after map[string][]int32
+		json.Unmarshal(res.AuditLogs[0].After, &after)
+		if len(after["roles"]) == 0 || after["roles"][len(after["roles"])-1] != 7 {
+			t.Fatalf("Failed to %s. Expected roles after the action to include 7, but it was %s", TestServiceRetrieveAuditLogs, string(res.AuditLogs[0].After))
+		}
+
+		_, errRes = RetrieveAuditLogs(ctx, &model.RetrieveAuditLogsRequest{FromTimestamp: 2, ToTimestamp: 1})
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected errRes to be not nil, but it was nil", TestServiceRetrieveAuditLogs)
+		}
+	})
+
+	t.Run(TestServiceTearDownAuditLog, func(t *testing.T) {
+		for _, userID := range []string{testServiceAuditLogUserID, testServiceAuditLogBlockUserID} {
+			delUser := &model.User{}
+			delUser.UserID = userID
+			delUser.DeletedTimestamp = 1
+			err := datastore.Provider(ctx).UpdateUser(delUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownAuditLog, err.Error())
+			}
+		}
+	})
+}
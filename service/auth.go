@@ -2,8 +2,10 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 
+	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
@@ -59,3 +61,51 @@ func RoomAuthz(ctx context.Context, roomID, userID string) *model.ErrorResponse
 
 	return nil
 }
+
+// selectRequestRoomUser returns the room user of the request user to authorize the request by the room roles.
+// It returns nil if the request is not restricted by the room roles, that is the request of an app client or without a user.
+func selectRequestRoomUser(ctx context.Context, roomID, message string) (*model.RoomUser, *model.ErrorResponse) {
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID != "" || userID == "" {
+		return nil, nil
+	}
+
+	ru, err := datastore.Provider(ctx).SelectRoomUser(roomID, userID)
+	if err != nil {
+		return nil, model.NewErrorResponse(message, http.StatusInternalServerError, model.WithError(err))
+	}
+	if ru == nil {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: "You are not this room member.",
+			},
+		}
+		return nil, model.NewErrorResponse(message, http.StatusForbidden, model.WithInvalidParams(invalidParams))
+	}
+
+	return ru, nil
+}
+
+// roomRoleAuthz authorizes the request user if the user has one of the room roles
+func roomRoleAuthz(ctx context.Context, roomID, message string, roomRoles ...model.RoomRole) (*model.RoomUser, *model.ErrorResponse) {
+	ru, errRes := selectRequestRoomUser(ctx, roomID, message)
+	if errRes != nil || ru == nil {
+		return nil, errRes
+	}
+
+	for _, roomRole := range roomRoles {
+		if ru.RoomRole == roomRole {
+			return ru, nil
+		}
+	}
+
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "userId",
+			Reason: fmt.Sprintf("You do not have permission. Your room role is %s.", ru.RoomRole),
+		},
+	}
+	return nil, model.NewErrorResponse(message, http.StatusForbidden, model.WithInvalidParams(invalidParams))
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
 	"github.com/betchi/tracer"
 )
 
@@ -19,7 +20,7 @@ func AddBlockUsers(ctx context.Context, req *model.AddBlockUsersRequest) *model.
 		return errRes
 	}
 
-	_, errRes = confirmUserExist(ctx, req.UserID)
+	user, errRes := confirmUserExist(ctx, req.UserID, datastore.SelectUserOptionWithBlocks(true))
 	if errRes != nil {
 		errRes.Message = "Failed to create block users."
 		return errRes
@@ -37,6 +38,20 @@ func AddBlockUsers(ctx context.Context, req *model.AddBlockUsersRequest) *model.
 		return model.NewErrorResponse("Failed to create block users.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	beforeBlockUserIDs := append([]string{}, user.BlockUsers...)
+	afterBlockUserIDs := append([]string{}, user.BlockUsers...)
+	for _, blockUser := range blockUsers {
+		afterBlockUserIDs = append(afterBlockUserIDs, blockUser.BlockUserID)
+	}
+	writeAuditLog(
+		ctx,
+		model.AuditActionAddBlockUsers,
+		model.AuditTargetTypeUser,
+		req.UserID,
+		map[string][]string{"blockUserIds": beforeBlockUserIDs},
+		map[string][]string{"blockUserIds": utils.RemoveDuplicateString(afterBlockUserIDs)},
+	)
+
 	return nil
 }
 
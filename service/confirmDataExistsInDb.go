@@ -189,3 +189,15 @@ func confirmUserIDsExist(ctx context.Context, requestUserIDs []string, keyName s
 
 	return nil
 }
+
+func confirmWorkspaceExist(ctx context.Context, name string) (*model.Workspace, *model.ErrorResponse) {
+	workspace, err := datastore.Provider(controlContext(ctx)).SelectWorkspace(name)
+	if err != nil {
+		return nil, model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+	if workspace == nil {
+		return nil, model.NewErrorResponse("Workspace is not found.", http.StatusNotFound)
+	}
+
+	return workspace, nil
+}
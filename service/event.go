@@ -0,0 +1,223 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const eventSubscriberBufferSize = 100
+
+type eventSubscriber struct {
+	workspace string
+	userID    string
+	roomID    string
+	eventCh   chan *scpb.EventData
+}
+
+var (
+	eventSubscribersMutex sync.RWMutex
+	eventSubscribers      = make(map[*eventSubscriber]struct{})
+)
+
+func addEventSubscriber(es *eventSubscriber) {
+	eventSubscribersMutex.Lock()
+	defer eventSubscribersMutex.Unlock()
+	eventSubscribers[es] = struct{}{}
+}
+
+func removeEventSubscriber(es *eventSubscriber) {
+	eventSubscribersMutex.Lock()
+	defer eventSubscribersMutex.Unlock()
+	delete(eventSubscribers, es)
+}
+
+func (es *eventSubscriber) match(workspace, roomID string, event *scpb.EventData) bool {
+	if es.workspace != workspace {
+		return false
+	}
+
+	if es.roomID != "" && es.roomID != roomID {
+		return false
+	}
+
+	if es.userID == "" {
+		return true
+	}
+
+	for _, userID := range event.UserIDs {
+		if userID == es.userID {
+			return true
+		}
+	}
+	return false
+}
+
+// broadcastEvent delivers the event to the subscribers of SubscribeEvents in this process.
+// Slow subscribers that cannot keep up lose the event instead of blocking the publisher.
+func broadcastEvent(ctx context.Context, roomID string, event *scpb.EventData) {
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+
+	eventSubscribersMutex.RLock()
+	defer eventSubscribersMutex.RUnlock()
+
+	for es := range eventSubscribers {
+		if !es.match(workspace, roomID, event) {
+			continue
+		}
+
+		select {
+		case es.eventCh <- event:
+		default:
+			logger.Warn(fmt.Sprintf("Event subscriber buffer is full. Event was dropped. userId[%s] roomId[%s]", es.userID, es.roomID))
+		}
+	}
+}
+
+// SubscribeEvents streams events to send until ctx is done
+func SubscribeEvents(ctx context.Context, req *model.SubscribeEventsRequest, send func(*scpb.EventData) error) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "SubscribeEvents", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return errRes
+	}
+
+	var user *model.User
+	if req.UserID != "" {
+		user, errRes = confirmUserExist(ctx, req.UserID, datastore.SelectUserOptionWithRoles(true))
+		if errRes != nil {
+			errRes.Message = "Failed to subscribe events."
+			return errRes
+		}
+	}
+
+	if req.RoomID != "" {
+		_, errRes = confirmRoomExist(ctx, req.RoomID)
+		if errRes != nil {
+			errRes.Message = "Failed to subscribe events."
+			return errRes
+		}
+	}
+
+	var resumeMessage *model.Message
+	if req.ResumeMessageID != "" {
+		resumeMessage, errRes = confirmMessageExist(ctx, req.ResumeMessageID)
+		if errRes != nil {
+			errRes.Message = "Failed to subscribe events."
+			return errRes
+		}
+	}
+
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+	es := &eventSubscriber{
+		workspace: workspace,
+		userID:    req.UserID,
+		roomID:    req.RoomID,
+		eventCh:   make(chan *scpb.EventData, eventSubscriberBufferSize),
+	}
+
+	// Subscribe before replaying so that no event is lost between the replay and the live stream.
+	// Messages published meanwhile may be delivered twice, clients should dedupe by messageId.
+	addEventSubscriber(es)
+	defer removeEventSubscriber(es)
+
+	if resumeMessage != nil {
+		messages, errRes := selectMessagesAfter(ctx, resumeMessage, req.RoomID, user)
+		if errRes != nil {
+			return errRes
+		}
+
+		for _, message := range messages {
+			event := &scpb.EventData{
+				Type: scpb.EventType_MessageEvent,
+				Data: encodeEventData(message),
+			}
+			if req.UserID != "" {
+				event.UserIDs = []string{req.UserID}
+			}
+			if err := send(event); err != nil {
+				return model.NewErrorResponse("Failed to subscribe events.", http.StatusInternalServerError, model.WithError(err))
+			}
+		}
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case event := <-es.eventCh:
+			if err := send(event); err != nil {
+				return model.NewErrorResponse("Failed to subscribe events.", http.StatusInternalServerError, model.WithError(err))
+			}
+		}
+	}
+}
+
+func selectMessagesAfter(ctx context.Context, resumeMessage *model.Message, roomID string, user *model.User) ([]*model.Message, *model.ErrorResponse) {
+	roomIDs := []string{}
+	if roomID != "" {
+		roomIDs = append(roomIDs, roomID)
+	} else {
+		roomUsers, err := datastore.Provider(ctx).SelectRoomUsers(
+			datastore.SelectRoomUsersOptionWithUserIDs([]string{user.UserID}),
+		)
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to subscribe events.", http.StatusInternalServerError, model.WithError(err))
+		}
+		for _, ru := range roomUsers {
+			roomIDs = append(roomIDs, ru.RoomID)
+		}
+	}
+
+	messages := []*model.Message{}
+	for _, rID := range roomIDs {
+		opts := []datastore.SelectMessagesOption{
+			datastore.SelectMessagesOptionFilterByRoomID(rID),
+			datastore.SelectMessagesOptionLimitTimestamp(resumeMessage.CreatedTimestamp),
+		}
+		if user != nil && user.Roles != nil {
+			opts = append(opts, datastore.SelectMessagesOptionFilterByRoleIDs(user.Roles))
+		}
+
+		roomMessages, err := datastore.Provider(ctx).SelectMessages(config.SubscribeEventsResumeLimit, 0, opts...)
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to subscribe events.", http.StatusInternalServerError, model.WithError(err))
+		}
+
+		for _, m := range roomMessages {
+			if m.MessageID == resumeMessage.MessageID {
+				continue
+			}
+			messages = append(messages, m)
+		}
+	}
+
+	sort.SliceStable(messages, func(i, j int) bool {
+		return messages[i].CreatedTimestamp < messages[j].CreatedTimestamp
+	})
+
+	if len(messages) > config.SubscribeEventsResumeLimit {
+		messages = messages[:config.SubscribeEventsResumeLimit]
+	}
+
+	return messages, nil
+}
+
+func encodeEventData(v interface{}) []byte {
+	buffer := new(bytes.Buffer)
+	json.NewEncoder(buffer).Encode(v)
+	return buffer.Bytes()
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/swagchat/chat-api/config"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceBroadcastEvent = "[service] broadcast event test"
+)
+
+func TestEvent(t *testing.T) {
+	t.Run(TestServiceBroadcastEvent, func(t *testing.T) {
+		ctx := context.WithValue(ctx, config.CtxWorkspace, "event-service-workspace")
+
+		roomSubscriber := &eventSubscriber{
+			workspace: "event-service-workspace",
+			roomID:    "event-service-room-id-0001",
+			eventCh:   make(chan *scpb.EventData, 1),
+		}
+		userSubscriber := &eventSubscriber{
+			workspace: "event-service-workspace",
+			userID:    "event-service-user-id-0001",
+			eventCh:   make(chan *scpb.EventData, 1),
+		}
+		otherWorkspaceSubscriber := &eventSubscriber{
+			workspace: "event-service-other-workspace",
+			roomID:    "event-service-room-id-0001",
+			eventCh:   make(chan *scpb.EventData, 1),
+		}
+		addEventSubscriber(roomSubscriber)
+		addEventSubscriber(userSubscriber)
+		addEventSubscriber(otherWorkspaceSubscriber)
+		defer removeEventSubscriber(roomSubscriber)
+		defer removeEventSubscriber(userSubscriber)
+		defer removeEventSubscriber(otherWorkspaceSubscriber)
+
+		event := &scpb.EventData{
+			Type:    scpb.EventType_MessageEvent,
+			UserIDs: []string{"event-service-user-id-0002"},
+		}
+		broadcastEvent(ctx, "event-service-room-id-0001", event)
+
+		if len(roomSubscriber.eventCh) != 1 {
+			t.Fatalf("Failed to %s. Expected room subscriber to receive 1 event, but it received %d", TestServiceBroadcastEvent, len(roomSubscriber.eventCh))
+		}
+		if len(userSubscriber.eventCh) != 0 {
+			t.Fatalf("Failed to %s. Expected user subscriber to receive 0 event, but it received %d", TestServiceBroadcastEvent, len(userSubscriber.eventCh))
+		}
+		if len(otherWorkspaceSubscriber.eventCh) != 0 {
+			t.Fatalf("Failed to %s. Expected other workspace subscriber to receive 0 event, but it received %d", TestServiceBroadcastEvent, len(otherWorkspaceSubscriber.eventCh))
+		}
+
+		event = &scpb.EventData{
+			Type:    scpb.EventType_RoomEvent,
+			UserIDs: []string{"event-service-user-id-0001"},
+		}
+		broadcastEvent(ctx, "event-service-room-id-0002", event)
+
+		if len(userSubscriber.eventCh) != 1 {
+			t.Fatalf("Failed to %s. Expected user subscriber to receive 1 event, but it received %d", TestServiceBroadcastEvent, len(userSubscriber.eventCh))
+		}
+		if len(roomSubscriber.eventCh) != 1 {
+			t.Fatalf("Failed to %s. Expected room subscriber to keep 1 event, but it had %d", TestServiceBroadcastEvent, len(roomSubscriber.eventCh))
+		}
+	})
+}
@@ -0,0 +1,440 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/storage"
+)
+
+const exportPageSize = int32(100)
+
+// The models are exported as the defined types which drop their MarshalJSON,
+// because it formats the timestamps for the API and they can't be restored from it
+type exportUser model.User
+type exportRoom model.Room
+type exportMessage model.Message
+type exportMention model.Mention
+type exportWebhook model.Webhook
+type exportAssetModel model.Asset
+
+type exportAsset struct {
+	exportAssetModel
+	// Data is the binary of the asset encoded in base64. It's empty unless it's exported with the asset data.
+	Data []byte `json:"data,omitempty"`
+}
+
+type exportWriter struct {
+	encoder *json.Encoder
+}
+
+func (ew *exportWriter) write(recordType model.ExportRecordType, data interface{}) error {
+	b, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	return ew.encoder.Encode(&model.ExportRecord{
+		Type: recordType,
+		Data: b,
+	})
+}
+
+// ExportWorkspace writes the users, rooms, room users, messages, mentions, assets, block users, devices and webhooks
+// of the workspace to w as NDJSON. The first line is the header which has the version of the format.
+func ExportWorkspace(ctx context.Context, req *model.ExportWorkspaceRequest, w io.Writer) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "ExportWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := ConfirmWorkspace(ctx, req.Workspace)
+	if errRes != nil {
+		errRes.Message = "Failed to export workspace."
+		return errRes
+	}
+
+	wctx := workspaceContext(ctx, req.Workspace)
+	ew := &exportWriter{encoder: json.NewEncoder(w)}
+
+	err := ew.write(model.ExportRecordTypeHeader, &model.ExportHeader{
+		Version:   model.ExportVersion,
+		Workspace: req.Workspace,
+		Exported:  time.Now().Unix(),
+	})
+	if err == nil {
+		err = exportUsers(wctx, ew)
+	}
+	if err == nil {
+		err = exportRooms(wctx, ew)
+	}
+	if err == nil {
+		err = exportAssets(wctx, ew, req.WithAssetData)
+	}
+	if err == nil {
+		err = exportWebhooks(wctx, ew)
+	}
+	if err != nil {
+		return model.NewErrorResponse("Failed to export workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+func exportUsers(ctx context.Context, ew *exportWriter) error {
+	for offset := int32(0); ; offset += exportPageSize {
+		users, err := datastore.Provider(ctx).SelectExportUsers(exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, user := range users {
+			if err = ew.write(model.ExportRecordTypeUser, (*exportUser)(user)); err != nil {
+				return err
+			}
+
+			blockUserIDs, err := datastore.Provider(ctx).SelectBlockUserIDs(user.UserID)
+			if err != nil {
+				return err
+			}
+			for _, blockUserID := range blockUserIDs {
+				blockUser, err := datastore.Provider(ctx).SelectBlockUser(user.UserID, blockUserID)
+				if err != nil {
+					return err
+				}
+				if blockUser == nil {
+					continue
+				}
+				if err = ew.write(model.ExportRecordTypeBlockUser, blockUser); err != nil {
+					return err
+				}
+			}
+
+			devices, err := datastore.Provider(ctx).SelectDevices(datastore.SelectDevicesOptionFilterByUserID(user.UserID))
+			if err != nil {
+				return err
+			}
+			for _, device := range devices {
+				if err = ew.write(model.ExportRecordTypeDevice, device); err != nil {
+					return err
+				}
+			}
+		}
+
+		if int32(len(users)) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+// exportRooms writes every room followed by its messages including the deleted ones, their mentions and the room users
+func exportRooms(ctx context.Context, ew *exportWriter) error {
+	for offset := int32(0); ; offset += exportPageSize {
+		rooms, err := datastore.Provider(ctx).SelectExportRooms(exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, room := range rooms {
+			if err = ew.write(model.ExportRecordTypeRoom, (*exportRoom)(room)); err != nil {
+				return err
+			}
+
+			for messageOffset := int32(0); ; messageOffset += exportPageSize {
+				messages, err := datastore.Provider(ctx).SelectExportMessages(room.RoomID, exportPageSize, messageOffset)
+				if err != nil {
+					return err
+				}
+				for _, message := range messages {
+					if err = ew.write(model.ExportRecordTypeMessage, (*exportMessage)(message)); err != nil {
+						return err
+					}
+				}
+				if int32(len(messages)) < exportPageSize {
+					break
+				}
+			}
+
+			mentions, err := datastore.Provider(ctx).SelectExportMentions(room.RoomID)
+			if err != nil {
+				return err
+			}
+			for _, mention := range mentions {
+				if err = ew.write(model.ExportRecordTypeMention, (*exportMention)(mention)); err != nil {
+					return err
+				}
+			}
+
+			roomUsers, err := datastore.Provider(ctx).SelectExportRoomUsers(room.RoomID)
+			if err != nil {
+				return err
+			}
+			for _, roomUser := range roomUsers {
+				if err = ew.write(model.ExportRecordTypeRoomUser, roomUser); err != nil {
+					return err
+				}
+			}
+		}
+
+		if int32(len(rooms)) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+func exportAssets(ctx context.Context, ew *exportWriter, withAssetData bool) error {
+	for offset := int32(0); ; offset += exportPageSize {
+		assets, err := datastore.Provider(ctx).SelectAssets(exportPageSize, offset)
+		if err != nil {
+			return err
+		}
+
+		for _, asset := range assets {
+			ea := &exportAsset{exportAssetModel: exportAssetModel(*asset)}
+			if withAssetData {
+				assetInfo := &storage.AssetInfo{
+					Filename: fmt.Sprintf("%s.%s", asset.AssetID, asset.Extension),
+				}
+				ea.Data, err = storage.Provider(ctx).Get(assetInfo)
+				if err != nil {
+					return err
+				}
+			}
+			if err = ew.write(model.ExportRecordTypeAsset, ea); err != nil {
+				return err
+			}
+		}
+
+		if int32(len(assets)) < exportPageSize {
+			return nil
+		}
+	}
+}
+
+func exportWebhooks(ctx context.Context, ew *exportWriter) error {
+	for _, event := range []model.WebhookEventType{model.WebhookEventTypeRoom, model.WebhookEventTypeMessage} {
+		webhooks, err := datastore.Provider(ctx).SelectWebhooks(event)
+		if err != nil {
+			return err
+		}
+		for _, webhook := range webhooks {
+			if err = ew.write(model.ExportRecordTypeWebhook, (*exportWebhook)(webhook)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ImportWorkspace imports the records written by ExportWorkspace into the workspace.
+// The records which already exist in the workspace are skipped, so that it can be retried or merged into a workspace in use.
+func ImportWorkspace(ctx context.Context, req *model.ImportWorkspaceRequest, r io.Reader) (*model.ImportWorkspaceResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "ImportWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := ConfirmWorkspace(ctx, req.Workspace)
+	if errRes != nil {
+		errRes.Message = "Failed to import workspace."
+		return nil, errRes
+	}
+
+	decoder := json.NewDecoder(r)
+	var header model.ExportRecord
+	err := decoder.Decode(&header)
+	if err != nil || header.Type != model.ExportRecordTypeHeader {
+		return nil, model.NewErrorResponse("Failed to import workspace. The header is not found.", http.StatusBadRequest)
+	}
+	var eh model.ExportHeader
+	err = json.Unmarshal(header.Data, &eh)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to import workspace. The header is invalid.", http.StatusBadRequest, model.WithError(err))
+	}
+	if eh.Version != model.ExportVersion {
+		return nil, model.NewErrorResponse(fmt.Sprintf("Failed to import workspace. The version %d is not supported.", eh.Version), http.StatusBadRequest)
+	}
+
+	im := &importer{
+		ctx: workspaceContext(ctx, req.Workspace),
+		res: &model.ImportWorkspaceResponse{
+			Imported: make(map[model.ExportRecordType]int64),
+			Skipped:  make(map[model.ExportRecordType]int64),
+		},
+	}
+
+	for {
+		var record model.ExportRecord
+		err = decoder.Decode(&record)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to import workspace. The record is invalid.", http.StatusBadRequest, model.WithError(err))
+		}
+
+		imported, errRes := im.importRecord(&record)
+		if errRes != nil {
+			return nil, errRes
+		}
+		if imported {
+			im.res.Imported[record.Type]++
+		} else {
+			im.res.Skipped[record.Type]++
+		}
+	}
+
+	return im.res, nil
+}
+
+type importer struct {
+	ctx context.Context
+	res *model.ImportWorkspaceResponse
+}
+
+// importRecord inserts the entity of the record unless it exists, and returns whether it's inserted
+func (im *importer) importRecord(record *model.ExportRecord) (bool, *model.ErrorResponse) {
+	var entity interface{}
+	switch record.Type {
+	case model.ExportRecordTypeUser:
+		entity = &exportUser{}
+	case model.ExportRecordTypeBlockUser:
+		entity = &model.BlockUser{}
+	case model.ExportRecordTypeDevice:
+		entity = &model.Device{}
+	case model.ExportRecordTypeRoom:
+		entity = &exportRoom{}
+	case model.ExportRecordTypeMessage:
+		entity = &exportMessage{}
+	case model.ExportRecordTypeMention:
+		entity = &exportMention{}
+	case model.ExportRecordTypeRoomUser:
+		entity = &model.RoomUser{}
+	case model.ExportRecordTypeAsset:
+		entity = &exportAsset{}
+	case model.ExportRecordTypeWebhook:
+		entity = &exportWebhook{}
+	default:
+		return false, model.NewErrorResponse(fmt.Sprintf("Failed to import workspace. The record type %s is unknown.", record.Type), http.StatusBadRequest)
+	}
+
+	err := json.Unmarshal(record.Data, entity)
+	if err != nil {
+		return false, model.NewErrorResponse(fmt.Sprintf("Failed to import workspace. The %s record is invalid.", record.Type), http.StatusBadRequest, model.WithError(err))
+	}
+
+	imported, err := im.insert(entity)
+	if err != nil {
+		return false, model.NewErrorResponse("Failed to import workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return imported, nil
+}
+
+func (im *importer) insert(entity interface{}) (bool, error) {
+	p := datastore.Provider(im.ctx)
+
+	switch e := entity.(type) {
+	case *exportUser:
+		user := (*model.User)(e)
+		exist, err := p.SelectUser(user.UserID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+
+		var opts []datastore.InsertUserOption
+		if len(user.Roles) > 0 {
+			userRoles := make([]*model.UserRole, len(user.Roles))
+			for i, role := range user.Roles {
+				userRole := &model.UserRole{}
+				userRole.UserID = user.UserID
+				userRole.Role = role
+				userRoles[i] = userRole
+			}
+			opts = append(opts, datastore.InsertUserOptionWithUserRoles(userRoles))
+		}
+		return true, p.InsertUser(user, opts...)
+	case *model.BlockUser:
+		exist, err := p.SelectBlockUser(e.UserID, e.BlockUserID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+		return true, p.InsertBlockUsers([]*model.BlockUser{e})
+	case *model.Device:
+		exist, err := p.SelectDevice(e.UserID, e.Platform)
+		if err != nil || exist != nil {
+			return false, err
+		}
+		return true, p.InsertDevice(e)
+	case *exportRoom:
+		room := (*model.Room)(e)
+		exist, err := p.SelectRoom(room.RoomID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+		return true, p.InsertRoom(room)
+	case *exportMessage:
+		message := (*model.Message)(e)
+		exist, err := p.SelectMessage(message.MessageID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+		// The room and the room users are imported as exported, so the message doesn't update them
+		return true, p.InsertMessage(message, datastore.InsertMessageOptionAsIs(true))
+	case *exportMention:
+		userIDs, err := p.SelectMentionedUserIDs(e.MessageID)
+		if err != nil {
+			return false, err
+		}
+		for _, userID := range userIDs {
+			if userID == e.UserID {
+				return false, nil
+			}
+		}
+		// The room users are imported with their mention count, so it isn't counted up again
+		return true, p.InsertMentionsAsIs([]*model.Mention{(*model.Mention)(e)})
+	case *model.RoomUser:
+		exist, err := p.SelectRoomUser(e.RoomID, e.UserID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+		return true, p.InsertRoomUsers([]*model.RoomUser{e})
+	case *exportAsset:
+		asset := model.Asset(e.exportAssetModel)
+		exist, err := p.SelectAsset(asset.AssetID)
+		if err != nil || exist != nil {
+			return false, err
+		}
+
+		if len(e.Data) > 0 {
+			assetInfo := &storage.AssetInfo{
+				Filename: fmt.Sprintf("%s.%s", asset.AssetID, asset.Extension),
+				Data:     bytes.NewReader(e.Data),
+			}
+			asset.URL, err = storage.Provider(im.ctx).Post(assetInfo)
+			if err != nil {
+				return false, err
+			}
+		}
+		return true, p.InsertAsset(&asset)
+	case *exportWebhook:
+		webhook := (*model.Webhook)(e)
+		webhooks, err := p.SelectWebhooks(webhook.Event, datastore.SelectWebhooksOptionWithRoomID(webhook.RoomID))
+		if err != nil {
+			return false, err
+		}
+		for _, exist := range webhooks {
+			if exist.WebhookID == webhook.WebhookID {
+				return false, nil
+			}
+		}
+		return true, p.InsertWebhook(webhook)
+	}
+
+	return false, nil
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpExport       = "[service] set up export"
+	TestServiceExportWorkspace   = "[service] export workspace test"
+	TestServiceImportWorkspace   = "[service] import workspace test"
+	TestServiceTearDownExport    = "[service] tear down export"
+	testServiceExportUserID      = "export-service-user-id-0001"
+	testServiceExportRoomID      = "export-service-room-id-0001"
+	testServiceExportMessageID   = "export-service-message-id-0001"
+	testServiceExportDeletedID   = "export-service-message-id-0002"
+	testServiceExportLastMessage = "exported last message"
+)
+
+func TestExport(t *testing.T) {
+	name := "service_export"
+	var exported bytes.Buffer
+
+	t.Run(TestServiceSetUpExport, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+
+		newUser := &model.User{}
+		newUser.UserID = testServiceExportUserID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testServiceExportRoomID
+		newRoom.UserID = testServiceExportUserID
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err = datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+
+		newMessage := &model.Message{}
+		newMessage.MessageID = testServiceExportMessageID
+		newMessage.RoomID = testServiceExportRoomID
+		newMessage.UserID = testServiceExportUserID
+		newMessage.Type = model.MessageTypeText
+		newMessage.Payload = []byte(`{"text":"hello"}`)
+		newMessage.CreatedTimestamp = nowTimestamp
+		newMessage.ModifiedTimestamp = nowTimestamp
+		err = datastore.Provider(ctx).InsertMessage(newMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+
+		deletedMessage := &model.Message{}
+		deletedMessage.MessageID = testServiceExportDeletedID
+		deletedMessage.RoomID = testServiceExportRoomID
+		deletedMessage.UserID = testServiceExportUserID
+		deletedMessage.Type = model.MessageTypeText
+		deletedMessage.Payload = []byte(`{"text":"@export-service-user-id-0001"}`)
+		deletedMessage.Mentions = []string{testServiceExportUserID}
+		deletedMessage.CreatedTimestamp = nowTimestamp
+		deletedMessage.ModifiedTimestamp = nowTimestamp
+		deletedMessage.DeletedTimestamp = nowTimestamp
+		err = datastore.Provider(ctx).InsertMessage(deletedMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+
+		newRoomUser := &model.RoomUser{}
+		newRoomUser.RoomID = testServiceExportRoomID
+		newRoomUser.UserID = testServiceExportUserID
+		newRoomUser.UnreadCount = 3
+		newRoomUser.Display = true
+		err = datastore.Provider(ctx).InsertRoomUsers([]*model.RoomUser{newRoomUser})
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+
+		room, err := datastore.Provider(ctx).SelectRoom(testServiceExportRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+		room.LastMessage = testServiceExportLastMessage
+		err = datastore.Provider(ctx).UpdateRoom(room)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpExport, err.Error())
+		}
+	})
+
+	t.Run(TestServiceExportWorkspace, func(t *testing.T) {
+		errRes := ExportWorkspace(ctx, &model.ExportWorkspaceRequest{Workspace: config.Config().Datastore.Database}, &exported)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceExportWorkspace, errRes.Message)
+		}
+
+		counts := make(map[model.ExportRecordType]int)
+		scanner := bufio.NewScanner(bytes.NewReader(exported.Bytes()))
+		for i := 0; scanner.Scan(); i++ {
+			var record model.ExportRecord
+			err := json.Unmarshal(scanner.Bytes(), &record)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected line %d to be JSON [%s]", TestServiceExportWorkspace, i+1, err.Error())
+			}
+			if i == 0 {
+				var header model.ExportHeader
+				json.Unmarshal(record.Data, &header)
+				if record.Type != model.ExportRecordTypeHeader || header.Version != model.ExportVersion {
+					t.Fatalf("Failed to %s. Expected first line to be header of version %d", TestServiceExportWorkspace, model.ExportVersion)
+				}
+			}
+			counts[record.Type]++
+		}
+
+		for _, recordType := range []model.ExportRecordType{
+			model.ExportRecordTypeUser,
+			model.ExportRecordTypeRoom,
+			model.ExportRecordTypeMessage,
+			model.ExportRecordTypeMention,
+			model.ExportRecordTypeRoomUser,
+		} {
+			if counts[recordType] == 0 {
+				t.Fatalf("Failed to %s. Expected %s records to be exported", TestServiceExportWorkspace, recordType)
+			}
+		}
+	})
+
+	cfg := config.Config()
+	cfg.Datastore.Dynamic = true
+	defer func() {
+		cfg.Datastore.Dynamic = false
+	}()
+
+	t.Run(TestServiceImportWorkspace, func(t *testing.T) {
+		_, errRes := ImportWorkspace(ctx, &model.ImportWorkspaceRequest{Workspace: "not_exist_workspace"}, bytes.NewReader(exported.Bytes()))
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status of unregistered workspace to be %d", TestServiceImportWorkspace, http.StatusNotFound)
+		}
+
+		_, errRes = CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: name})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, errRes.Message)
+		}
+
+		_, errRes = ImportWorkspace(ctx, &model.ImportWorkspaceRequest{Workspace: name}, bytes.NewReader([]byte(`{"type":"header","data":{"version":0}}`)))
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status of unsupported version to be %d", TestServiceImportWorkspace, http.StatusBadRequest)
+		}
+
+		res, errRes := ImportWorkspace(ctx, &model.ImportWorkspaceRequest{Workspace: name}, bytes.NewReader(exported.Bytes()))
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, errRes.Message)
+		}
+		if res.Imported[model.ExportRecordTypeMessage] == 0 || res.Skipped[model.ExportRecordTypeMessage] != 0 {
+			t.Fatalf("Failed to %s. Expected messages to be imported", TestServiceImportWorkspace)
+		}
+
+		wctx := workspaceContext(ctx, name)
+		room, err := datastore.Provider(wctx).SelectRoom(testServiceExportRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, err.Error())
+		}
+		if room == nil || room.LastMessage != testServiceExportLastMessage {
+			t.Fatalf("Failed to %s. Expected last message of room to be restored", TestServiceImportWorkspace)
+		}
+		roomUser, err := datastore.Provider(wctx).SelectRoomUser(testServiceExportRoomID, testServiceExportUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, err.Error())
+		}
+		if roomUser == nil || roomUser.UnreadCount != 3 {
+			t.Fatalf("Failed to %s. Expected unread count of room user to be 3", TestServiceImportWorkspace)
+		}
+		if roomUser.MentionCount != 0 {
+			t.Fatalf("Failed to %s. Expected mention count of room user not to be counted up, but it was %d", TestServiceImportWorkspace, roomUser.MentionCount)
+		}
+
+		deletedMessage, err := datastore.Provider(wctx).SelectMessage(testServiceExportDeletedID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, err.Error())
+		}
+		if deletedMessage == nil || deletedMessage.DeletedTimestamp == 0 {
+			t.Fatalf("Failed to %s. Expected deleted message to be imported as deleted", TestServiceImportWorkspace)
+		}
+		userIDs, err := datastore.Provider(wctx).SelectMentionedUserIDs(testServiceExportDeletedID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, err.Error())
+		}
+		if len(userIDs) != 1 || userIDs[0] != testServiceExportUserID {
+			t.Fatalf("Failed to %s. Expected mentions to be imported, but they were %v", TestServiceImportWorkspace, userIDs)
+		}
+
+		res, errRes = ImportWorkspace(ctx, &model.ImportWorkspaceRequest{Workspace: name}, bytes.NewReader(exported.Bytes()))
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceImportWorkspace, errRes.Message)
+		}
+		for recordType, count := range res.Imported {
+			if count != 0 {
+				t.Fatalf("Failed to %s. Expected nothing to be imported again, but %d %s records were", TestServiceImportWorkspace, count, recordType)
+			}
+		}
+	})
+
+	t.Run(TestServiceTearDownExport, func(t *testing.T) {
+		errRes := DeleteWorkspace(ctx, name)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceTearDownExport, errRes.Message)
+		}
+	})
+}
@@ -10,7 +10,6 @@ import (
 	logger "github.com/betchi/zapper"
 	"github.com/swagchat/chat-api/datastore"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/swagchat/chat-api/config"
 )
 
@@ -35,6 +34,9 @@ func TestMain(m *testing.M) {
 
 	tracer.InitGlobalTracer(&tracer.Config{})
 
+	// Background jobs started by the tests keep the workspace even if a test enables dynamic mode
+	ctx = context.WithValue(ctx, config.CtxWorkspace, cfg.Datastore.Database)
+
 	cfg.Datastore.SQLite.OnMemory = true
 	datastore.Provider(ctx).Connect(cfg.Datastore)
 	datastore.Provider(ctx).CreateTables()
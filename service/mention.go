@@ -0,0 +1,45 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+// RetrieveMentions retrieves messages mentioning the user
+func RetrieveMentions(ctx context.Context, req *model.RetrieveMentionsRequest) (*model.MentionsResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveMentions", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmUserExist(ctx, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve mentions."
+		return nil, errRes
+	}
+
+	opts := []datastore.SelectMentionedMessagesOption{}
+	if req.RoomID != "" {
+		opts = append(opts, datastore.SelectMentionedMessagesOptionFilterByRoomID(req.RoomID))
+	}
+
+	messages, err := datastore.Provider(ctx).SelectMentionedMessages(req.Limit, req.Offset, req.UserID, opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve mentions.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	allCount, err := datastore.Provider(ctx).SelectCountMentionedMessages(req.UserID, opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve mentions.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.MentionsResponse{}
+	res.Messages = messages
+	res.AllCount = allCount
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+
+	return res, nil
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpMention           = "[service] set up mention"
+	TestServiceSendMessageWithMention = "[service] send message with mention test"
+	TestServiceRetrieveMentions       = "[service] retrieve mentions test"
+)
+
+func TestMention(t *testing.T) {
+	t.Run(TestServiceSetUpMention, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+
+		for i := 1; i <= 4; i++ {
+			newUser := &model.User{}
+			newUser.UserID = fmt.Sprintf("mention-service-user-id-%04d", i)
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpMention, err.Error())
+			}
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = "mention-service-room-id-0001"
+		newRoom.UserID = "mention-service-user-id-0001"
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpMention, err.Error())
+		}
+
+		roomUsers := []*model.RoomUser{}
+		for i := 1; i <= 3; i++ {
+			ru := &model.RoomUser{}
+			ru.RoomID = "mention-service-room-id-0001"
+			ru.UserID = fmt.Sprintf("mention-service-user-id-%04d", i)
+			roomUsers = append(roomUsers, ru)
+		}
+		err = datastore.Provider(ctx).InsertRoomUsers(roomUsers)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpMention, err.Error())
+		}
+	})
+
+	t.Run(TestServiceSendMessageWithMention, func(t *testing.T) {
+		roomID := "mention-service-room-id-0001"
+		userID := "mention-service-user-id-0001"
+		messageType := model.MessageTypeText
+
+		req := &model.SendMessageRequest{}
+		req.RoomID = &roomID
+		req.UserID = &userID
+		req.Type = &messageType
+		req.Payload = []byte(`{"text":"hello @mention-service-user-id-0002 and @mention-service-user-id-0004"}`)
+		message, errRes := SendMessage(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceSendMessageWithMention, errRes.Message)
+		}
+		if len(message.Mentions) != 1 || message.Mentions[0] != "mention-service-user-id-0002" {
+			t.Fatalf("Failed to %s. Expected message.Mentions to be [mention-service-user-id-0002], but it was %v", TestServiceSendMessageWithMention, message.Mentions)
+		}
+
+		req = &model.SendMessageRequest{}
+		req.RoomID = &roomID
+		req.UserID = &userID
+		req.Type = &messageType
+		req.Payload = []byte(`{"text":"hello all"}`)
+		req.Mentions = []string{model.MentionAll}
+		message, errRes = SendMessage(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceSendMessageWithMention, errRes.Message)
+		}
+		if len(message.Mentions) != 2 {
+			t.Fatalf("Failed to %s. Expected message.Mentions count to be 2, but it was %d", TestServiceSendMessageWithMention, len(message.Mentions))
+		}
+
+		req = &model.SendMessageRequest{}
+		req.RoomID = &roomID
+		req.UserID = &userID
+		req.Type = &messageType
+		req.Payload = []byte(`{"text":"hello"}`)
+		req.Mentions = []string{"mention-service-user-id-0004"}
+		_, errRes = SendMessage(ctx, req)
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected errRes to be not nil, but it was nil", TestServiceSendMessageWithMention)
+		}
+		if errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected errRes.Status to be %d, but it was %d", TestServiceSendMessageWithMention, http.StatusBadRequest, errRes.Status)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(roomID, "mention-service-user-id-0002")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSendMessageWithMention, err.Error())
+		}
+		if ru.MentionCount != 2 {
+			t.Fatalf("Failed to %s. Expected ru.MentionCount to be 2, but it was %d", TestServiceSendMessageWithMention, ru.MentionCount)
+		}
+	})
+
+	t.Run(TestServiceRetrieveMentions, func(t *testing.T) {
+		req := &model.RetrieveMentionsRequest{}
+		req.UserID = "mention-service-user-id-0003"
+		req.Limit = 10
+		res, errRes := RetrieveMentions(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceRetrieveMentions, errRes.Message)
+		}
+		if res.AllCount != 1 {
+			t.Fatalf("Failed to %s. Expected res.AllCount to be 1, but it was %d", TestServiceRetrieveMentions, res.AllCount)
+		}
+		if len(res.Messages) != 1 {
+			t.Fatalf("Failed to %s. Expected res.Messages count to be 1, but it was %d", TestServiceRetrieveMentions, len(res.Messages))
+		}
+	})
+}
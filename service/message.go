@@ -1,20 +1,18 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
+	"time"
 
 	logger "github.com/betchi/zapper"
 	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
-	"github.com/swagchat/chat-api/notification"
 	"github.com/swagchat/chat-api/producer"
 	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/utils"
 	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 )
 
@@ -34,16 +32,42 @@ func SendMessage(ctx context.Context, req *model.SendMessageRequest) (*model.Mes
 		return nil, errRes
 	}
 
+	errRes = confirmRoomNotArchived(room, "Failed to create message.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
 	user, errRes := confirmUserExist(ctx, *req.UserID, datastore.SelectUserOptionWithRoles(true))
 	if errRes != nil {
 		errRes.Message = "Failed to create message."
 		return nil, errRes
 	}
 
+	errRes = req.ValidatePolicy(room, user)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	roomUser, err := datastore.Provider(ctx).SelectRoomUser(room.RoomID, user.UserID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	errRes = req.ValidateRoomRole(roomUser)
+	if errRes != nil {
+		return nil, errRes
+	}
+
 	message := req.GenerateMessage()
 
+	mentions, errRes := resolveMentions(ctx, req, message)
+	if errRes != nil {
+		return nil, errRes
+	}
+	message.Mentions = mentions
+
 	if message.Type == model.MessageTypeIndicatorStart || message.Type == model.MessageTypeIndicatorEnd {
-		publishMessage(ctx, message)
+		publishMessage(ctx, message, true)
 		return nil, nil
 	}
 
@@ -53,28 +77,21 @@ func SendMessage(ctx context.Context, req *model.SendMessageRequest) (*model.Mes
 		return nil, errRes
 	}
 
-	err := datastore.Provider(ctx).InsertMessage(message)
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeMessage,
+		message.RoomID,
+		message.MessageID,
+		model.OutboxDestinationProducer,
+		model.OutboxDestinationWebhook,
+		model.OutboxDestinationNotification,
+	)
+	err = datastore.Provider(ctx).InsertMessage(message, datastore.InsertMessageOptionWithOutboxEvents(outboxEvents))
 	if err != nil {
 		errRes := model.NewErrorResponse("Failed to create message.", http.StatusInternalServerError, model.WithError(err))
 		return nil, errRes
 	}
 
-	// notification
-	lastMessage := "" // TODO
-	mi := &notification.MessageInfo{
-		Text: fmt.Sprintf("[%s]%s", room.Name, lastMessage),
-	}
-	cfg := config.Config()
-	if cfg.Notification.DefaultBadgeCount != "" {
-		dBadgeCount, err := strconv.Atoi(cfg.Notification.DefaultBadgeCount)
-		if err == nil {
-			mi.Badge = dBadgeCount
-		}
-	}
-	go notification.Provider(ctx).Publish(room.NotificationTopicID, room.RoomID, mi)
-
-	publishMessage(ctx, message)
-	webhookMessage(ctx, message, user)
+	relayOutboxEventsInBackground(ctx, outboxEvents)
 
 	return message, nil
 }
@@ -105,26 +122,131 @@ func RetrieveMessage(ctx context.Context, messageID string) (*model.Message, *mo
 	return message, nil
 }
 
-func publishMessage(ctx context.Context, message *model.Message) {
+// DeleteMessage deletes the message logically.
+// The senders can delete their own messages, and the owner and the admins of the room can delete the others.
+func DeleteMessage(ctx context.Context, req *model.DeleteMessageRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "DeleteMessage", "service")
+	defer tracer.Finish(span)
+
+	message, errRes := RetrieveMessage(ctx, req.MessageID)
+	if errRes != nil {
+		errRes.Message = "Failed to delete message."
+		return errRes
+	}
+
+	requestRoomUser, errRes := selectRequestRoomUser(ctx, message.RoomID, "Failed to delete message.")
+	if errRes != nil {
+		return errRes
+	}
+
+	if requestRoomUser != nil && requestRoomUser.UserID != message.UserID {
+		senderRoomUser, err := datastore.Provider(ctx).SelectRoomUser(message.RoomID, message.UserID)
+		if err != nil {
+			return model.NewErrorResponse("Failed to delete message.", http.StatusInternalServerError, model.WithError(err))
+		}
+
+		errRes = req.ValidateRoomRole(message, requestRoomUser, senderRoomUser)
+		if errRes != nil {
+			return errRes
+		}
+	}
+
+	err := datastore.Provider(ctx).DeleteMessages(
+		datastore.DeleteMessagesOptionWithLogicalDeleted(time.Now().Unix()),
+		datastore.DeleteMessagesOptionFilterByMessageIDs([]string{req.MessageID}),
+	)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	// The deleted message can't be shown as the last message of the room
+	err = datastore.Provider(ctx).UpdateRoomLastMessage(message.RoomID)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+
+	requestUserID, _ := ctx.Value(config.CtxUserID).(string)
+	if requestUserID != message.UserID {
+		writeAuditLog(ctx, model.AuditActionDeleteMessage, model.AuditTargetTypeMessage, message.MessageID, message, nil)
+	}
+
+	return nil
+}
+
+// resolveMentions converts the mentions of the request to userIds of the room users.
+// Explicit mentions must be room users, but mentions written in the text are ignored if they are not.
+func resolveMentions(ctx context.Context, req *model.SendMessageRequest, message *model.Message) ([]string, *model.ErrorResponse) {
+	candidates, explicit := req.MentionCandidates()
+	if len(candidates) == 0 {
+		return []string{}, nil
+	}
+
+	roomUserIDs, err := datastore.Provider(ctx).SelectUserIDsOfRoomUser(
+		datastore.SelectUserIDsOfRoomUserOptionWithRoomID(message.RoomID),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	mentions := []string{}
+	for _, candidate := range candidates {
+		if candidate == model.MentionAll {
+			mentions = append(mentions, roomUserIDs...)
+			continue
+		}
+
+		if !utils.SearchStringValueInSlice(roomUserIDs, candidate) {
+			if !explicit {
+				continue
+			}
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "mentions",
+					Reason: fmt.Sprintf("%s is not a member of the room.", candidate),
+				},
+			}
+			return nil, model.NewErrorResponse("Failed to create message.", http.StatusBadRequest, model.WithInvalidParams(invalidParams))
+		}
+		mentions = append(mentions, candidate)
+	}
+
+	resolved := []string{}
+	for _, userID := range utils.RemoveDuplicateString(mentions) {
+		if userID != message.UserID {
+			resolved = append(resolved, userID)
+		}
+	}
+
+	return resolved, nil
+}
+
+// publishMessage publishes the message event to the producer.
+// It's broadcast to the event subscribers of this process as well if broadcast is true, which is false for the retries
+// of the outbox events not to broadcast the same event again.
+func publishMessage(ctx context.Context, message *model.Message, broadcast bool, opts ...producer.PublishMessageOption) error {
 	userIDs, err := datastore.Provider(ctx).SelectUserIDsOfRoomUser(
 		datastore.SelectUserIDsOfRoomUserOptionWithRoomID(message.RoomID),
 		datastore.SelectUserIDsOfRoomUserOptionWithRoles([]int32{message.Role}),
 	)
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
 
-	buffer := new(bytes.Buffer)
-	json.NewEncoder(buffer).Encode(message)
 	event := &scpb.EventData{
 		Type:    scpb.EventType_MessageEvent,
-		Data:    buffer.Bytes(),
+		Data:    encodeEventData(message),
 		UserIDs: userIDs,
 	}
-	err = producer.Provider(ctx).PublishMessage(event)
+	if broadcast {
+		broadcastEvent(ctx, message.RoomID, event)
+	}
+
+	err = producer.Provider(ctx).PublishMessage(event, opts...)
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
+
+	return nil
 }
@@ -1,16 +1,17 @@
 package service
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
+	"fmt"
 	"net/http"
+	"strconv"
 	"sync"
 	"time"
 
 	logger "github.com/betchi/zapper"
 	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/i18n"
 	"github.com/swagchat/chat-api/model"
 	"github.com/swagchat/chat-api/notification"
 	"github.com/swagchat/chat-api/producer"
@@ -31,12 +32,20 @@ func subscribe(ctx context.Context, roomUsers []*model.RoomUser, device *model.D
 
 	d := utils.NewDispatcher(10)
 	for _, roomUser := range roomUsers {
+		// The muted rooms push only the mentions, directly to the devices
+		if roomUser.Muted {
+			continue
+		}
+
 		ctx = context.WithValue(ctx, config.CtxRoomUser, roomUser)
 		d.Work(ctx, func(ctx context.Context) {
 			ru := ctx.Value(config.CtxRoomUser).(*model.RoomUser)
 			room, errRes := confirmRoomExist(ctx, ru.RoomID)
 			if errRes != nil {
 				errCh <- errRes.Error
+			} else if room.ArchivedTimestamp != 0 {
+				// The archived rooms have no notification topic until they are unarchived
+				doneCh <- true
 			} else {
 				if room.NotificationTopicID == "" {
 					notificationTopicID, errRes := createTopic(ctx, room.RoomID)
@@ -244,6 +253,20 @@ func unsubscribeByRoomID(ctx context.Context, roomID string, wg *sync.WaitGroup)
 	}
 }
 
+// unsubscribeMutedRoomUser unsubscribes the devices of the room user from the room topic.
+// The room user stays in the room.
+func unsubscribeMutedRoomUser(ctx context.Context, ru *model.RoomUser) {
+	subscriptions, err := datastore.Provider(ctx).SelectDeletedSubscriptions(
+		datastore.SelectDeletedSubscriptionsOptionFilterByRoomID(ru.RoomID),
+		datastore.SelectDeletedSubscriptionsOptionFilterByUserID(ru.UserID),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	<-unsubscribe(ctx, subscriptions)
+}
+
 func unsubscribeByRoomUsers(ctx context.Context, roomUsers []*model.RoomUser) {
 	span := tracer.StartSpan(ctx, "unsubscribeByRoomUsers", "service")
 	defer tracer.Finish(span)
@@ -296,37 +319,157 @@ func unsubscribeByRoomUsers(ctx context.Context, roomUsers []*model.RoomUser) {
 	return
 }
 
-func publishUserJoin(ctx context.Context, roomID string) {
-	go func() {
-		userIDs, err := datastore.Provider(ctx).SelectUserIDsOfRoomUser(
-			datastore.SelectUserIDsOfRoomUserOptionWithRoomID(roomID),
-		)
+// publishUserJoin publishes the room events to the room users.
+// They are broadcast to the event subscribers of this process as well if broadcast is true as publishMessage,
+// so the events are published to all of the users even if some of them fail.
+func publishUserJoin(ctx context.Context, roomID string, broadcast bool, opts ...producer.PublishMessageOption) error {
+	userIDs, err := datastore.Provider(ctx).SelectUserIDsOfRoomUser(
+		datastore.SelectUserIDsOfRoomUserOptionWithRoomID(roomID),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+		return err
+	}
+
+	var lastErr error
+	for _, userID := range userIDs {
+		miniRoom, err := datastore.Provider(ctx).SelectMiniRoom(roomID, userID)
 		if err != nil {
 			logger.Error(err.Error())
-			return
+			continue
 		}
 
-		for _, userID := range userIDs {
-			miniRoom, err := datastore.Provider(ctx).SelectMiniRoom(roomID, userID)
-			if err != nil {
-				logger.Error(err.Error())
-				continue
+		event := &scpb.EventData{
+			Type:    scpb.EventType_RoomEvent,
+			Data:    encodeEventData(miniRoom),
+			UserIDs: []string{userID},
+		}
+		if broadcast {
+			broadcastEvent(ctx, roomID, event)
+		}
+
+		err = producer.Provider(ctx).PublishMessage(event, opts...)
+		if err != nil {
+			logger.Error(err.Error())
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// publishRoomEvent publishes the room event of the data to the users.
+// It's used for the events about the room which the users have not joined yet, such as invitations.
+// It's dispatched from the outbox, so it's broadcast only at the first attempt.
+func publishRoomEvent(ctx context.Context, roomID string, data []byte, userIDs []string, broadcast bool, opts ...producer.PublishMessageOption) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+
+	event := &scpb.EventData{
+		Type:    scpb.EventType_RoomEvent,
+		Data:    data,
+		UserIDs: userIDs,
+	}
+	if broadcast {
+		broadcastEvent(ctx, roomID, event)
+	}
+
+	return producer.Provider(ctx).PublishMessage(event, opts...)
+}
+
+// publishMessageNotification pushes the message to the room topic and to the mentioned users.
+// Failures of the pushes to the mentioned users are only logged not to push to the room topic again on retry.
+func publishMessageNotification(ctx context.Context, room *model.Room, user *model.User, message *model.Message) error {
+	span := tracer.StartSpan(ctx, "publishMessageNotification", "service")
+	defer tracer.Finish(span)
+
+	if room.NotificationTopicID != "" {
+		// The push to the room topic is shared by the room users, so it's in locale.defaultLang
+		preview := model.MessagePreview(message.Type, message.LastMessageText(), "")
+		mi := &notification.MessageInfo{
+			Text: fmt.Sprintf("[%s]%s", room.Name, preview),
+		}
+		cfg := config.Config()
+		if cfg.Notification.DefaultBadgeCount != "" {
+			dBadgeCount, err := strconv.Atoi(cfg.Notification.DefaultBadgeCount)
+			if err == nil {
+				mi.Badge = dBadgeCount
 			}
+		}
+		nRes := <-notification.Provider(ctx).Publish(room.NotificationTopicID, room.RoomID, mi)
+		if nRes.Error != nil {
+			logger.Error(nRes.Error.Error())
+			tracer.SetError(span, nRes.Error)
+			return nRes.Error
+		}
+	}
+
+	publishMentionNotification(ctx, room, user, message)
+	return nil
+}
+
+// publishMentionNotification pushes to the devices of mentioned users directly.
+// Devices subscribing the room topic receive the push of the room, so only devices
+// not subscribing it (e.g. the room is muted) are targeted.
+func publishMentionNotification(ctx context.Context, room *model.Room, user *model.User, message *model.Message) {
+	span := tracer.StartSpan(ctx, "publishMentionNotification", "service")
+	defer tracer.Finish(span)
+
+	if len(message.Mentions) == 0 {
+		return
+	}
+
+	badgeCount := 0
+	cfg := config.Config()
+	if cfg.Notification.DefaultBadgeCount != "" {
+		dBadgeCount, err := strconv.Atoi(cfg.Notification.DefaultBadgeCount)
+		if err == nil {
+			badgeCount = dBadgeCount
+		}
+	}
+
+	dp := datastore.Provider(ctx)
+	for _, userID := range message.Mentions {
+		devices, err := dp.SelectDevices(datastore.SelectDevicesOptionFilterByUserID(userID))
+		if err != nil {
+			logger.Error(err.Error())
+			continue
+		}
 
-			buffer := new(bytes.Buffer)
-			json.NewEncoder(buffer).Encode(miniRoom)
-			event := &scpb.EventData{
-				Type:    scpb.EventType_RoomEvent,
-				Data:    buffer.Bytes(),
-				UserIDs: []string{userID},
+		// The push to each mentioned user is in the language of the user
+		lang := ""
+		mentionedUser, err := dp.SelectUser(userID)
+		if err != nil {
+			logger.Error(err.Error())
+		} else if mentionedUser != nil {
+			lang = mentionedUser.Lang
+		}
+		mi := &notification.MessageInfo{
+			Text:  fmt.Sprintf("[%s]%s", room.Name, i18n.Translate(lang, "%s mentioned you", user.Name)),
+			Badge: badgeCount,
+		}
+
+		for _, d := range devices {
+			if d.NotificationDeviceID == "" {
+				continue
 			}
-			err = producer.Provider(ctx).PublishMessage(event)
+
+			subscription, err := dp.SelectSubscription(room.RoomID, userID, d.Platform)
 			if err != nil {
 				logger.Error(err.Error())
-				return
+				continue
+			}
+			if subscription != nil {
+				continue
+			}
+
+			nRes := <-notification.Provider(ctx).PublishEndpoint(d.NotificationDeviceID, room.RoomID, mi)
+			if nRes.Error != nil {
+				logger.Error(nRes.Error.Error())
 			}
 		}
-	}()
+	}
 }
 
 func createTopic(ctx context.Context, roomID string) (string, *model.ErrorResponse) {
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/producer"
+)
+
+// RunOutboxRelay relays the outbox events until ctx is done.
+// Events are relayed right after they are committed as well, so the relay delivers the ones which failed
+// or were left behind when the process died. Every event is delivered at least once.
+func RunOutboxRelay(ctx context.Context) {
+	ticker := time.NewTicker(config.OutboxRelayIntervalSecond * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, workspace := range backgroundJobWorkspaces(ctx) {
+				relayDueOutboxEvents(context.WithValue(ctx, config.CtxWorkspace, workspace))
+			}
+		}
+	}
+}
+
+func relayDueOutboxEvents(ctx context.Context) {
+	span := tracer.StartSpan(ctx, "relayDueOutboxEvents", "service")
+	defer tracer.Finish(span)
+
+	nowTimestamp := time.Now().Unix()
+	outboxEvents, err := datastore.Provider(ctx).SelectOutboxEvents(
+		config.OutboxRelayLimit,
+		datastore.SelectOutboxEventsOptionFilterByDueTimestamp(nowTimestamp),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	relayOutboxEvents(ctx, outboxEvents)
+
+	err = datastore.Provider(ctx).DeleteOutboxEvents(
+		datastore.DeleteOutboxEventsOptionFilterByDispatchedTimestamp(nowTimestamp - config.OutboxEventRetentionSecond),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// relayOutboxEventsInBackground relays the events committed by the request without waiting for them.
+// The context of the request is cancelled and the connection of the workspace is released when the request returns,
+// so the events are relayed with a context of their own which has only the workspace and the tracing,
+// and the connection is held until they are relayed.
+func relayOutboxEventsInBackground(ctx context.Context, outboxEvents []*model.OutboxEvent) {
+	if len(outboxEvents) == 0 {
+		return
+	}
+
+	workspace, _ := ctx.Value(config.CtxWorkspace).(string)
+	rctx := context.WithValue(context.Background(), config.CtxWorkspace, workspace)
+	release := datastore.Hold(rctx)
+	go func() {
+		defer release()
+
+		tctx, _ := tracer.StartTransaction(rctx, "relayOutboxEvents", "service")
+		defer tracer.CloseTransaction(tctx)
+
+		relayOutboxEvents(tctx, outboxEvents)
+	}()
+}
+
+// relayOutboxEvents delivers the events one by one.
+// They are not always delivered in order, because the failed ones are retried later and the relays of the processes run concurrently.
+func relayOutboxEvents(ctx context.Context, outboxEvents []*model.OutboxEvent) {
+	for _, outboxEvent := range outboxEvents {
+		relayOutboxEvent(ctx, outboxEvent)
+	}
+}
+
+func relayOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) {
+	span := tracer.StartSpan(ctx, "relayOutboxEvent", "service")
+	defer tracer.Finish(span)
+
+	// Claim the event not to be delivered by another relay at the same time
+	claimed, err := datastore.Provider(ctx).ClaimOutboxEvent(outboxEvent, time.Now().Unix()+config.OutboxEventLeaseSecond)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	err = dispatchOutboxEvent(ctx, outboxEvent)
+
+	nowTimestamp := time.Now().Unix()
+	outboxEvent.Attempts++
+	if err == nil {
+		outboxEvent.Dispatched = nowTimestamp
+		outboxEvent.LastError = ""
+	} else {
+		outboxEvent.LastError = err.Error()
+		if len(outboxEvent.LastError) > 255 {
+			outboxEvent.LastError = outboxEvent.LastError[:255]
+		}
+		if outboxEvent.Attempts >= config.OutboxEventMaxAttempts {
+			outboxEvent.Failed = nowTimestamp
+			logger.Error(fmt.Sprintf("Outbox event was given up. eventId[%s] destination[%s] attempts[%d] %s", outboxEvent.EventID, outboxEvent.Destination, outboxEvent.Attempts, err.Error()))
+		} else {
+			outboxEvent.NextAttempt = nowTimestamp + outboxEventBackoff(outboxEvent.Attempts)
+			logger.Warn(fmt.Sprintf("Outbox event will be retried. eventId[%s] destination[%s] attempts[%d] %s", outboxEvent.EventID, outboxEvent.Destination, outboxEvent.Attempts, err.Error()))
+		}
+		tracer.SetError(span, err)
+	}
+
+	err = datastore.Provider(ctx).UpdateOutboxEvent(outboxEvent)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+// outboxEventBackoff returns the seconds to wait for the next attempt, it's doubled every attempt
+func outboxEventBackoff(attempts int32) int64 {
+	backoff := int64(1) << uint(attempts)
+	if backoff > config.OutboxEventMaxBackoffSecond {
+		return config.OutboxEventMaxBackoffSecond
+	}
+	return backoff
+}
+
+// dispatchOutboxEvent delivers the event to the destination.
+// The data of the event is read when it's delivered, and the event is discarded if the data no longer exists.
+// The events to the producer are broadcast to the event subscribers only at the first attempt.
+// The event types which are unknown are retried, because they may be the ones of the newer processes which can deliver them.
+func dispatchOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error {
+	switch outboxEvent.EventType {
+	case model.OutboxEventTypeMessage:
+		return dispatchMessageOutboxEvent(ctx, outboxEvent)
+	case model.OutboxEventTypeRoom:
+		return dispatchRoomOutboxEvent(ctx, outboxEvent)
+	case model.OutboxEventTypeRoomUser:
+		if outboxEvent.Destination == model.OutboxDestinationProducer {
+			return publishUserJoin(ctx, outboxEvent.RoomID, outboxEvent.Attempts == 0, producer.PublishMessageOptionWithEventID(outboxEvent.EventID))
+		}
+		return discardUnsupportedOutboxEvent(outboxEvent)
+	case model.OutboxEventTypeRoomEvent:
+		if outboxEvent.Destination == model.OutboxDestinationProducer {
+			return dispatchRoomEventOutboxEvent(ctx, outboxEvent)
+		}
+		return discardUnsupportedOutboxEvent(outboxEvent)
+	case model.OutboxEventTypePinnedMessage:
+		if outboxEvent.Destination == model.OutboxDestinationProducer {
+			return dispatchPinnedMessageOutboxEvent(ctx, outboxEvent)
+		}
+		return discardUnsupportedOutboxEvent(outboxEvent)
+	}
+
+	return fmt.Errorf("Unknown outbox event. eventType[%s] destination[%s]", outboxEvent.EventType, outboxEvent.Destination)
+}
+
+// discardUnsupportedOutboxEvent marks the event done without delivering it,
+// because the event type has no delivery to the destination and retrying it never succeeds
+func discardUnsupportedOutboxEvent(outboxEvent *model.OutboxEvent) error {
+	logger.Warn(fmt.Sprintf("Outbox event was discarded because the destination is not supported. eventId[%s] eventType[%s] destination[%s]", outboxEvent.EventID, outboxEvent.EventType, outboxEvent.Destination))
+	return nil
+}
+
+func dispatchMessageOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error {
+	message, err := datastore.Provider(ctx).SelectMessage(outboxEvent.MessageID)
+	if err != nil {
+		return err
+	}
+	if message == nil || message.DeletedTimestamp != 0 {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the message is not found. eventId[%s] messageId[%s]", outboxEvent.EventID, outboxEvent.MessageID))
+		return nil
+	}
+
+	if outboxEvent.Destination == model.OutboxDestinationProducer {
+		return publishMessage(ctx, message, outboxEvent.Attempts == 0, producer.PublishMessageOptionWithEventID(outboxEvent.EventID))
+	}
+
+	user, err := datastore.Provider(ctx).SelectUser(message.UserID, datastore.SelectUserOptionWithRoles(true))
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the user is not found. eventId[%s] userId[%s]", outboxEvent.EventID, message.UserID))
+		return nil
+	}
+
+	switch outboxEvent.Destination {
+	case model.OutboxDestinationWebhook:
+		return webhookMessage(ctx, message, user, outboxEvent.EventID)
+	case model.OutboxDestinationNotification:
+		room, err := datastore.Provider(ctx).SelectRoom(message.RoomID)
+		if err != nil {
+			return err
+		}
+		if room == nil {
+			logger.Warn(fmt.Sprintf("Outbox event was discarded because the room is not found. eventId[%s] roomId[%s]", outboxEvent.EventID, message.RoomID))
+			return nil
+		}
+
+		mentions, err := datastore.Provider(ctx).SelectMentionedUserIDs(message.MessageID)
+		if err != nil {
+			return err
+		}
+		message.Mentions = mentions
+
+		return publishMessageNotification(ctx, room, user, message)
+	}
+
+	return discardUnsupportedOutboxEvent(outboxEvent)
+}
+
+func dispatchRoomOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error {
+	room, err := datastore.Provider(ctx).SelectRoom(outboxEvent.RoomID)
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the room is not found. eventId[%s] roomId[%s]", outboxEvent.EventID, outboxEvent.RoomID))
+		return nil
+	}
+
+	switch outboxEvent.Destination {
+	case model.OutboxDestinationWebhook:
+		return webhookRoom(ctx, room, outboxEvent.EventID)
+	case model.OutboxDestinationProducer:
+		return publishUserJoin(ctx, room.RoomID, outboxEvent.Attempts == 0, producer.PublishMessageOptionWithEventID(outboxEvent.EventID))
+	}
+
+	return discardUnsupportedOutboxEvent(outboxEvent)
+}
+
+// dispatchRoomEventOutboxEvent publishes the room event of the payload as it was when the change was committed
+func dispatchRoomEventOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error {
+	payload := &model.OutboxRoomEventPayload{}
+	err := json.Unmarshal([]byte(outboxEvent.Payload), payload)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the payload is invalid. eventId[%s] %s", outboxEvent.EventID, err.Error()))
+		return nil
+	}
+
+	return publishRoomEvent(ctx, outboxEvent.RoomID, []byte(payload.Data), payload.UserIDs, outboxEvent.Attempts == 0, producer.PublishMessageOptionWithEventID(outboxEvent.EventID))
+}
+
+// dispatchPinnedMessageOutboxEvent notifies the room users of the pinned messages when the event is delivered
+func dispatchPinnedMessageOutboxEvent(ctx context.Context, outboxEvent *model.OutboxEvent) error {
+	payload := &model.OutboxPinnedMessagePayload{}
+	err := json.Unmarshal([]byte(outboxEvent.Payload), payload)
+	if err != nil {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the payload is invalid. eventId[%s] %s", outboxEvent.EventID, err.Error()))
+		return nil
+	}
+
+	room, err := datastore.Provider(ctx).SelectRoom(outboxEvent.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if err != nil {
+		return err
+	}
+	if room == nil {
+		logger.Warn(fmt.Sprintf("Outbox event was discarded because the room is not found. eventId[%s] roomId[%s]", outboxEvent.EventID, outboxEvent.RoomID))
+		return nil
+	}
+
+	pinnedMessages, err := datastore.Provider(ctx).SelectPinnedMessages(room.RoomID)
+	if err != nil {
+		return err
+	}
+
+	event := model.NewPinnedMessageEvent(payload.Action, room.RoomID, outboxEvent.MessageID, payload.UserID, pinnedMessages)
+	return publishRoomEvent(ctx, room.RoomID, encodeEventData(event), roomMemberIDs(room), outboxEvent.Attempts == 0, producer.PublishMessageOptionWithEventID(outboxEvent.EventID))
+}
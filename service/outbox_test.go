@@ -0,0 +1,133 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpOutbox         = "[service] set up outbox"
+	TestServiceRelayOutboxEvents   = "[service] relay outbox events test"
+	TestServiceGiveUpOutboxEvent   = "[service] give up outbox event test"
+	TestServiceOutboxEventBackoff  = "[service] outbox event backoff test"
+	TestServiceTearDownOutbox      = "[service] tear down outbox"
+	testServiceOutboxUnknownTarget = "unknown"
+)
+
+func TestOutbox(t *testing.T) {
+	roomID := "outbox-service-room-id-0001"
+
+	t.Run(TestServiceSetUpOutbox, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		newRoom := &model.Room{}
+		newRoom.RoomID = roomID
+		newRoom.UserID = "outbox-service-user-id-0001"
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+
+		outboxEvents := model.NewOutboxEvents(
+			model.OutboxEventTypeRoom,
+			roomID,
+			"",
+			model.OutboxDestinationWebhook,
+			model.OutboxDestinationProducer,
+			testServiceOutboxUnknownTarget,
+		)
+		// The room events and the pinned messages events are delivered as well
+		outboxEvents = append(outboxEvents, model.NewRoomEventOutboxEvents(roomID, newRoom, []string{newRoom.UserID})...)
+		outboxEvents = append(outboxEvents, model.NewPinnedMessageOutboxEvents(roomID, model.PinnedMessageActionReorder, "", newRoom.UserID)...)
+		// The destination which the event type has no delivery to is discarded, and the unknown event type is retried
+		outboxEvents = append(outboxEvents, model.NewOutboxEvents(model.OutboxEventTypeRoomUser, roomID, "", testServiceOutboxUnknownTarget)...)
+		outboxEvents = append(outboxEvents, model.NewOutboxEvents(testServiceOutboxUnknownTarget, roomID, "", model.OutboxDestinationProducer)...)
+		err := datastore.Provider(ctx).InsertRoom(newRoom, datastore.InsertRoomOptionWithOutboxEvents(outboxEvents))
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceSetUpOutbox, err.Error())
+		}
+	})
+
+	t.Run(TestServiceRelayOutboxEvents, func(t *testing.T) {
+		relayDueOutboxEvents(ctx)
+
+		pendingEvents, err := datastore.Provider(ctx).SelectOutboxEvents(
+			10,
+			datastore.SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()+config.OutboxEventMaxBackoffSecond),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRelayOutboxEvents, err.Error())
+		}
+		if len(pendingEvents) != 1 {
+			t.Fatalf("Failed to %s. Expected pending outbox events count to be 1, but it was %d", TestServiceRelayOutboxEvents, len(pendingEvents))
+		}
+
+		pendingEvent := pendingEvents[0]
+		if pendingEvent.EventType != testServiceOutboxUnknownTarget {
+			t.Fatalf("Failed to %s. Expected event type to be %s, but it was %s", TestServiceRelayOutboxEvents, testServiceOutboxUnknownTarget, pendingEvent.EventType)
+		}
+		if pendingEvent.Attempts != 1 {
+			t.Fatalf("Failed to %s. Expected attempts to be 1, but it was %d", TestServiceRelayOutboxEvents, pendingEvent.Attempts)
+		}
+		if pendingEvent.LastError == "" {
+			t.Fatalf("Failed to %s. Expected lastError to be set", TestServiceRelayOutboxEvents)
+		}
+		if pendingEvent.NextAttempt <= time.Now().Unix() {
+			t.Fatalf("Failed to %s. Expected nextAttempt to be postponed", TestServiceRelayOutboxEvents)
+		}
+	})
+
+	t.Run(TestServiceGiveUpOutboxEvent, func(t *testing.T) {
+		pendingEvents, err := datastore.Provider(ctx).SelectOutboxEvents(
+			10,
+			datastore.SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()+config.OutboxEventMaxBackoffSecond),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceGiveUpOutboxEvent, err.Error())
+		}
+
+		pendingEvent := pendingEvents[0]
+		pendingEvent.Attempts = config.OutboxEventMaxAttempts - 1
+		pendingEvent.NextAttempt = time.Now().Unix()
+		err = datastore.Provider(ctx).UpdateOutboxEvent(pendingEvent)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceGiveUpOutboxEvent, err.Error())
+		}
+
+		relayDueOutboxEvents(ctx)
+
+		pendingEvents, err = datastore.Provider(ctx).SelectOutboxEvents(
+			10,
+			datastore.SelectOutboxEventsOptionFilterByDueTimestamp(time.Now().Unix()+config.OutboxEventMaxBackoffSecond),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceGiveUpOutboxEvent, err.Error())
+		}
+		if len(pendingEvents) != 0 {
+			t.Fatalf("Failed to %s. Expected outbox event to be given up", TestServiceGiveUpOutboxEvent)
+		}
+	})
+
+	t.Run(TestServiceOutboxEventBackoff, func(t *testing.T) {
+		if outboxEventBackoff(1) != 2 || outboxEventBackoff(3) != 8 {
+			t.Fatalf("Failed to %s. Expected backoff to be doubled every attempt", TestServiceOutboxEventBackoff)
+		}
+		if outboxEventBackoff(config.OutboxEventMaxAttempts*2) != config.OutboxEventMaxBackoffSecond {
+			t.Fatalf("Failed to %s. Expected backoff to be limited to %d", TestServiceOutboxEventBackoff, config.OutboxEventMaxBackoffSecond)
+		}
+	})
+
+	t.Run(TestServiceTearDownOutbox, func(t *testing.T) {
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = roomID
+		deleteRoom.DeletedTimestamp = 1
+		err := datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceTearDownOutbox, err.Error())
+		}
+	})
+}
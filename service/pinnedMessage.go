@@ -0,0 +1,198 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+// PinMessage pins the message to the end of the pinned messages of the room
+func PinMessage(ctx context.Context, req *model.PinMessageRequest) (*model.PinnedMessage, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "PinMessage", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to pin message."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to pin message.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to pin message.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	message, errRes := confirmMessageExist(ctx, req.MessageID)
+	if errRes != nil {
+		errRes.Message = "Failed to pin message."
+		return nil, errRes
+	}
+
+	req.Message = message
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	pinnedMessage, err := datastore.Provider(ctx).SelectPinnedMessage(req.RoomID, req.MessageID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to pin message.", http.StatusInternalServerError, model.WithError(err))
+	}
+	// Pinning the message again doesn't change the order
+	if pinnedMessage != nil {
+		pinnedMessage.Message = message
+		return pinnedMessage, nil
+	}
+
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	pinnedMessage = req.GeneratePinnedMessage(userID)
+	outboxEvents := model.NewPinnedMessageOutboxEvents(req.RoomID, model.PinnedMessageActionPin, req.MessageID, userID)
+	err = datastore.Provider(ctx).InsertPinnedMessage(pinnedMessage, datastore.PinnedMessageOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to pin message.", http.StatusInternalServerError, model.WithError(err))
+	}
+	pinnedMessage.Message = message
+
+	writeAuditLog(ctx, model.AuditActionPinMessage, model.AuditTargetTypeMessage, message.MessageID, nil, pinnedMessage)
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	return pinnedMessage, nil
+}
+
+// UnpinMessage unpins the message from the room
+func UnpinMessage(ctx context.Context, req *model.UnpinMessageRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "UnpinMessage", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to unpin message."
+		return errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to unpin message.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return errRes
+	}
+
+	pinnedMessage, err := datastore.Provider(ctx).SelectPinnedMessage(req.RoomID, req.MessageID)
+	if err != nil {
+		return model.NewErrorResponse("Failed to unpin message.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if pinnedMessage == nil {
+		return model.NewErrorResponse("Failed to unpin message.", http.StatusNotFound)
+	}
+
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	outboxEvents := model.NewPinnedMessageOutboxEvents(req.RoomID, model.PinnedMessageActionUnpin, req.MessageID, userID)
+	err = datastore.Provider(ctx).DeletePinnedMessage(req.RoomID, req.MessageID, datastore.PinnedMessageOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return model.NewErrorResponse("Failed to unpin message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	writeAuditLog(ctx, model.AuditActionUnpinMessage, model.AuditTargetTypeMessage, req.MessageID, pinnedMessage, nil)
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	return nil
+}
+
+// RetrievePinnedMessages retrieves the pinned messages of the room in the order.
+// The messages which are not sent to the roles of the request user are excluded.
+func RetrievePinnedMessages(ctx context.Context, roomID string) (*model.PinnedMessagesResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrievePinnedMessages", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmRoomExist(ctx, roomID)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve pinned messages."
+		return nil, errRes
+	}
+
+	pinnedMessages, errRes := selectReadablePinnedMessages(ctx, roomID)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve pinned messages."
+		return nil, errRes
+	}
+
+	res := &model.PinnedMessagesResponse{}
+	res.PinnedMessages = pinnedMessages
+	return res, nil
+}
+
+// UpdatePinnedMessagesOrder reorders the pinned messages of the room
+func UpdatePinnedMessagesOrder(ctx context.Context, req *model.UpdatePinnedMessagesOrderRequest) (*model.PinnedMessagesResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "UpdatePinnedMessagesOrder", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to update pinned messages order."
+		return nil, errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to update pinned messages order.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	pinnedMessages, err := datastore.Provider(ctx).SelectPinnedMessages(req.RoomID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to update pinned messages order.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	req.Pinned = pinnedMessages
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	outboxEvents := model.NewPinnedMessageOutboxEvents(req.RoomID, model.PinnedMessageActionReorder, "", userID)
+	err = datastore.Provider(ctx).UpdatePinnedMessagesOrder(req.RoomID, req.MessageIDs, datastore.PinnedMessageOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to update pinned messages order.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	pinnedMessages, errRes = selectReadablePinnedMessages(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to update pinned messages order."
+		return nil, errRes
+	}
+
+	res := &model.PinnedMessagesResponse{}
+	res.PinnedMessages = pinnedMessages
+	return res, nil
+}
+
+// selectReadablePinnedMessages selects the pinned messages of the room which the request user can read by the roles
+func selectReadablePinnedMessages(ctx context.Context, roomID string) ([]*model.PinnedMessage, *model.ErrorResponse) {
+	pinnedMessages, err := datastore.Provider(ctx).SelectPinnedMessages(roomID)
+	if err != nil {
+		return nil, model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if userID == "" {
+		return pinnedMessages, nil
+	}
+
+	user, errRes := confirmUserExist(ctx, userID, datastore.SelectUserOptionWithRoles(true))
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	return model.FilterPinnedMessagesByRoles(pinnedMessages, user.Roles), nil
+}
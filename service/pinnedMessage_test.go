@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpPinnedMessage         = "[service] set up pinned message"
+	TestServicePinMessage                 = "[service] pin message test"
+	TestServiceUpdatePinnedMessagesOrder  = "[service] update pinned messages order test"
+	TestServiceRetrieveRoomPinnedMessages = "[service] retrieve room with pinned messages test"
+	TestServiceUnpinMessage               = "[service] unpin message test"
+	TestServiceTearDownPinnedMessage      = "[service] tear down pinned message"
+	testServicePinnedMessageRoomID        = "pinned-message-service-room-id-0001"
+	testServicePinnedMessageOwnerID       = "pinned-message-service-user-id-0001"
+	testServicePinnedMessageMemberID      = "pinned-message-service-user-id-0002"
+	testServicePinnedMessageMessageID1    = "pinned-message-service-message-id-0001"
+	testServicePinnedMessageMessageID2    = "pinned-message-service-message-id-0002"
+)
+
+func TestPinnedMessage(t *testing.T) {
+	ownerCtx := context.WithValue(ctx, config.CtxUserID, testServicePinnedMessageOwnerID)
+	memberCtx := context.WithValue(ctx, config.CtxUserID, testServicePinnedMessageMemberID)
+
+	t.Run(TestServiceSetUpPinnedMessage, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		for i := 1; i <= 2; i++ {
+			newUser := &model.User{}
+			newUser.UserID = fmt.Sprintf("pinned-message-service-user-id-%04d", i)
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			ur := &model.UserRole{}
+			ur.UserID = newUser.UserID
+			ur.Role = config.RoleGeneral
+			err := datastore.Provider(ctx).InsertUser(newUser, datastore.InsertUserOptionWithUserRoles([]*model.UserRole{ur}))
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpPinnedMessage, err.Error())
+			}
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testServicePinnedMessageRoomID
+		newRoom.UserID = testServicePinnedMessageOwnerID
+		newRoom.Type = scpb.RoomType_NoticeRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		owner := &model.RoomUser{}
+		owner.RoomID = testServicePinnedMessageRoomID
+		owner.UserID = testServicePinnedMessageOwnerID
+		owner.Display = true
+		owner.RoomRole = model.RoomRoleOwner
+		member := &model.RoomUser{}
+		member.RoomID = testServicePinnedMessageRoomID
+		member.UserID = testServicePinnedMessageMemberID
+		member.Display = true
+		member.RoomRole = model.RoomRoleMember
+		err := datastore.Provider(ctx).InsertRoom(newRoom, datastore.InsertRoomOptionWithRoomUser([]*model.RoomUser{owner, member}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpPinnedMessage, err.Error())
+		}
+
+		for _, messageID := range []string{testServicePinnedMessageMessageID1, testServicePinnedMessageMessageID2} {
+			newMessage := &model.Message{}
+			newMessage.MessageID = messageID
+			newMessage.RoomID = testServicePinnedMessageRoomID
+			newMessage.UserID = testServicePinnedMessageOwnerID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(`{"text":"announcement"}`)
+			newMessage.Role = config.RoleGeneral
+			newMessage.CreatedTimestamp = nowTimestamp
+			newMessage.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpPinnedMessage, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestServicePinMessage, func(t *testing.T) {
+		req := &model.PinMessageRequest{}
+		req.RoomID = testServicePinnedMessageRoomID
+		req.MessageID = testServicePinnedMessageMessageID1
+		_, errRes := PinMessage(memberCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to pin messages", TestServicePinMessage)
+		}
+
+		for i, messageID := range []string{testServicePinnedMessageMessageID1, testServicePinnedMessageMessageID2} {
+			req := &model.PinMessageRequest{}
+			req.RoomID = testServicePinnedMessageRoomID
+			req.MessageID = messageID
+			pinnedMessage, errRes := PinMessage(ownerCtx, req)
+			if errRes != nil {
+				t.Fatalf("Failed to %s. %s", TestServicePinMessage, errRes.Message)
+			}
+			if pinnedMessage.Order != int32(i+1) {
+				t.Fatalf("Failed to %s. Expected order to be %d, but it was %d", TestServicePinMessage, i+1, pinnedMessage.Order)
+			}
+		}
+	})
+
+	t.Run(TestServiceUpdatePinnedMessagesOrder, func(t *testing.T) {
+		req := &model.UpdatePinnedMessagesOrderRequest{}
+		req.RoomID = testServicePinnedMessageRoomID
+		req.MessageIDs = []string{testServicePinnedMessageMessageID2}
+		_, errRes := UpdatePinnedMessagesOrder(ownerCtx, req)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected all of the pinned messages to be required", TestServiceUpdatePinnedMessagesOrder)
+		}
+
+		req = &model.UpdatePinnedMessagesOrderRequest{}
+		req.RoomID = testServicePinnedMessageRoomID
+		req.MessageIDs = []string{testServicePinnedMessageMessageID2, testServicePinnedMessageMessageID1}
+		res, errRes := UpdatePinnedMessagesOrder(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUpdatePinnedMessagesOrder, errRes.Message)
+		}
+		if len(res.PinnedMessages) != 2 || res.PinnedMessages[0].MessageID != testServicePinnedMessageMessageID2 {
+			t.Fatalf("Failed to %s. Expected the pinned messages to be reordered", TestServiceUpdatePinnedMessagesOrder)
+		}
+	})
+
+	t.Run(TestServiceRetrieveRoomPinnedMessages, func(t *testing.T) {
+		req := &model.RetrieveRoomRequest{}
+		req.RoomID = testServicePinnedMessageRoomID
+		room, errRes := RetrieveRoom(memberCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveRoomPinnedMessages, errRes.Message)
+		}
+		if len(room.PinnedMessages) != 2 || room.PinnedMessages[0].Message == nil {
+			t.Fatalf("Failed to %s. Expected the room to have the pinned messages", TestServiceRetrieveRoomPinnedMessages)
+		}
+	})
+
+	t.Run(TestServiceUnpinMessage, func(t *testing.T) {
+		req := &model.UnpinMessageRequest{}
+		req.RoomID = testServicePinnedMessageRoomID
+		req.MessageID = testServicePinnedMessageMessageID2
+		errRes := UnpinMessage(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUnpinMessage, errRes.Message)
+		}
+
+		errRes = UnpinMessage(ownerCtx, req)
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected the unpinned message not to be found", TestServiceUnpinMessage)
+		}
+
+		res, errRes := RetrievePinnedMessages(memberCtx, testServicePinnedMessageRoomID)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUnpinMessage, errRes.Message)
+		}
+		if len(res.PinnedMessages) != 1 || res.PinnedMessages[0].MessageID != testServicePinnedMessageMessageID1 {
+			t.Fatalf("Failed to %s. Expected only the pinned message 1 to be left", TestServiceUnpinMessage)
+		}
+	})
+
+	t.Run(TestServiceTearDownPinnedMessage, func(t *testing.T) {
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testServicePinnedMessageRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err := datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownPinnedMessage, err.Error())
+		}
+	})
+}
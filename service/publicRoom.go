@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
+)
+
+// RetrievePublicRooms searches the public rooms in the room directory
+func RetrievePublicRooms(ctx context.Context, req *model.RetrievePublicRoomsRequest) (*model.PublicRoomsResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrievePublicRooms", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	opts := []datastore.SelectPublicRoomsOption{
+		datastore.SelectPublicRoomsOptionFilterByName(req.Name),
+		datastore.SelectPublicRoomsOptionFilterByTags(req.Tags),
+	}
+
+	rooms, err := datastore.Provider(ctx).SelectPublicRooms(req.Limit, req.Offset, opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve public rooms.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	count, err := datastore.Provider(ctx).SelectCountPublicRooms(opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve public rooms.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.PublicRoomsResponse{}
+	res.Rooms = rooms
+	res.AllCount = count
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+	return res, nil
+}
+
+// JoinRoom makes the user join the public room by themselves
+func JoinRoom(ctx context.Context, req *model.JoinRoomRequest) (*model.Room, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "JoinRoom", "service")
+	defer tracer.Finish(span)
+
+	// Users can only join by themselves
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID == "" && userID != "" {
+		req.UserID = userID
+	}
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	errRes = confirmRoomNotArchived(room, "Failed to join room.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	_, errRes = confirmUserExist(ctx, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	if utils.SearchStringValueInSlice(roomMemberIDs(room), req.UserID) {
+		return room, nil
+	}
+
+	errRes = prepareRoomTopic(ctx, room)
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	ru := &model.RoomUser{}
+	ru.RoomID = req.RoomID
+	ru.UserID = req.UserID
+	ru.Display = true
+	ru.RoomRole = model.RoomRoleMember
+	roomUsers := []*model.RoomUser{ru}
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeRoomUser,
+		req.RoomID,
+		"",
+		model.OutboxDestinationProducer,
+	)
+	err := datastore.Provider(ctx).InsertRoomUsers(roomUsers, datastore.InsertRoomUsersOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to join room.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	completeRoomUsersJoin(ctx, room, roomUsers, outboxEvents)
+
+	room, errRes = confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	return room, nil
+}
+
+// LeaveRoom makes the user leave the room by themselves.
+// The owner has to transfer the ownership before leaving.
+func LeaveRoom(ctx context.Context, req *model.LeaveRoomRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "LeaveRoom", "service")
+	defer tracer.Finish(span)
+
+	// Users can only leave by themselves
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID == "" && userID != "" {
+		req.UserID = userID
+	}
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return errRes
+	}
+
+	ru, errRes := confirmRoomUserExist(ctx, req.RoomID, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to leave room."
+		return errRes
+	}
+	if ru.RoomRole == model.RoomRoleOwner {
+		return model.NewErrorResponse("Failed to leave room. The owner has to transfer the ownership before leaving.", http.StatusBadRequest)
+	}
+
+	deleteReq := &model.DeleteRoomUsersRequest{}
+	deleteReq.RoomID = req.RoomID
+	deleteReq.UserIDs = []string{req.UserID}
+	errRes = DeleteRoomUsers(ctx, deleteReq)
+	if errRes != nil {
+		errRes.Message = "Failed to leave room."
+		return errRes
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpPublicRoom     = "[service] set up public room"
+	TestServiceRetrievePublicRooms = "[service] retrieve public rooms test"
+	TestServiceJoinRoom            = "[service] join room test"
+	TestServiceLeaveRoom           = "[service] leave room test"
+	TestServiceTearDownPublicRoom  = "[service] tear down public room"
+	testServicePublicRoomRoomID    = "public-room-service-room-id-0001"
+	testServicePrivateRoomRoomID   = "public-room-service-room-id-0002"
+	testServicePublicRoomOwnerID   = "public-room-service-user-id-0001"
+	testServicePublicRoomMemberID  = "public-room-service-user-id-0002"
+)
+
+func TestPublicRoom(t *testing.T) {
+	ownerCtx := context.WithValue(ctx, config.CtxUserID, testServicePublicRoomOwnerID)
+	memberCtx := context.WithValue(ctx, config.CtxUserID, testServicePublicRoomMemberID)
+
+	t.Run(TestServiceSetUpPublicRoom, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		for i := 1; i <= 2; i++ {
+			newUser := &model.User{}
+			newUser.UserID = fmt.Sprintf("public-room-service-user-id-%04d", i)
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpPublicRoom, err.Error())
+			}
+		}
+
+		for roomID, roomType := range map[string]scpb.RoomType{
+			testServicePublicRoomRoomID:  scpb.RoomType_PublicRoom,
+			testServicePrivateRoomRoomID: scpb.RoomType_PrivateRoom,
+		} {
+			newRoom := &model.Room{}
+			newRoom.RoomID = roomID
+			newRoom.UserID = testServicePublicRoomOwnerID
+			newRoom.Name = "public-room-service"
+			newRoom.Type = roomType
+			newRoom.MetaData = []byte(`{"tags":["public-room-service"]}`)
+			newRoom.CreatedTimestamp = nowTimestamp
+			newRoom.ModifiedTimestamp = nowTimestamp
+			ru := &model.RoomUser{}
+			ru.RoomID = roomID
+			ru.UserID = testServicePublicRoomOwnerID
+			ru.Display = true
+			ru.RoomRole = model.RoomRoleOwner
+			err := datastore.Provider(ctx).InsertRoom(newRoom, datastore.InsertRoomOptionWithRoomUser([]*model.RoomUser{ru}))
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpPublicRoom, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestServiceRetrievePublicRooms, func(t *testing.T) {
+		req := &model.RetrievePublicRoomsRequest{}
+		req.Tags = []string{"public-room-service"}
+		req.Limit = 10
+		res, errRes := RetrievePublicRooms(memberCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrievePublicRooms, errRes.Message)
+		}
+		if len(res.Rooms) != 1 || res.Rooms[0].RoomID != testServicePublicRoomRoomID {
+			t.Fatalf("Failed to %s. Expected only the public room to be listed", TestServiceRetrievePublicRooms)
+		}
+		if res.Rooms[0].MemberCount != 1 {
+			t.Fatalf("Failed to %s. Expected member count to be 1, but it was %d", TestServiceRetrievePublicRooms, res.Rooms[0].MemberCount)
+		}
+	})
+
+	t.Run(TestServiceJoinRoom, func(t *testing.T) {
+		req := &model.JoinRoomRequest{}
+		req.RoomID = testServicePrivateRoomRoomID
+		_, errRes := JoinRoom(memberCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the private room not to be joined", TestServiceJoinRoom)
+		}
+
+		req = &model.JoinRoomRequest{}
+		req.RoomID = testServicePublicRoomRoomID
+		room, errRes := JoinRoom(memberCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceJoinRoom, errRes.Message)
+		}
+		if len(room.Users) != 2 {
+			t.Fatalf("Failed to %s. Expected room users count to be 2, but it was %d", TestServiceJoinRoom, len(room.Users))
+		}
+	})
+
+	t.Run(TestServiceLeaveRoom, func(t *testing.T) {
+		req := &model.LeaveRoomRequest{}
+		req.RoomID = testServicePublicRoomRoomID
+		errRes := LeaveRoom(ownerCtx, req)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the owner not to be able to leave the room", TestServiceLeaveRoom)
+		}
+
+		room, err := datastore.Provider(ctx).SelectRoom(testServicePublicRoomRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceLeaveRoom, err.Error())
+		}
+		room.CanLeft = true
+		err = datastore.Provider(ctx).UpdateRoom(room)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceLeaveRoom, err.Error())
+		}
+
+		req = &model.LeaveRoomRequest{}
+		req.RoomID = testServicePublicRoomRoomID
+		errRes = LeaveRoom(memberCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceLeaveRoom, errRes.Message)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServicePublicRoomRoomID, testServicePublicRoomMemberID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceLeaveRoom, err.Error())
+		}
+		if ru != nil {
+			t.Fatalf("Failed to %s. Expected the user to leave the room", TestServiceLeaveRoom)
+		}
+	})
+
+	t.Run(TestServiceTearDownPublicRoom, func(t *testing.T) {
+		for _, roomID := range []string{testServicePublicRoomRoomID, testServicePrivateRoomRoomID} {
+			deleteRoom := &model.Room{}
+			deleteRoom.RoomID = roomID
+			deleteRoom.DeletedTimestamp = 1
+			err := datastore.Provider(ctx).UpdateRoom(deleteRoom)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownPublicRoom, err.Error())
+			}
+		}
+	})
+}
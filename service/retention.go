@@ -0,0 +1,270 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/storage"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// RunMessagePurger purges expired messages and messages over the retention of rooms until ctx is done
+func RunMessagePurger(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(config.Config().Retention.PurgeInterval) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, workspace := range backgroundJobWorkspaces(ctx) {
+				workspaceCtx := context.WithValue(ctx, config.CtxWorkspace, workspace)
+				purgeMessages(workspaceCtx)
+				purgeDeletedRoomUsers(workspaceCtx)
+			}
+		}
+	}
+}
+
+func purgeMessages(ctx context.Context) {
+	span := tracer.StartSpan(ctx, "purgeMessages", "service")
+	defer tracer.Finish(span)
+
+	nowTimestamp := time.Now().Unix()
+	roomMessages := make(map[string][]*model.Message)
+
+	expiredMessages, err := datastore.Provider(ctx).SelectMessages(
+		config.PurgeMessagesLimit,
+		0,
+		datastore.SelectMessagesOptionFilterByExpiredTimestamp(nowTimestamp),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+	for _, message := range expiredMessages {
+		roomMessages[message.RoomID] = append(roomMessages[message.RoomID], message)
+	}
+
+	var offset int32
+	for {
+		rooms, err := datastore.Provider(ctx).SelectRooms(
+			config.PurgeMessagesLimit,
+			offset,
+			datastore.SelectRoomsOptionFilterByRetentionEnabled(true),
+		)
+		if err != nil {
+			logger.Error(err.Error())
+			return
+		}
+
+		for _, room := range rooms {
+			messages, err := selectMessagesOverRetention(ctx, room, nowTimestamp)
+			if err != nil {
+				logger.Error(err.Error())
+				continue
+			}
+			roomMessages[room.RoomID] = append(roomMessages[room.RoomID], messages...)
+		}
+
+		if len(rooms) < config.PurgeMessagesLimit {
+			break
+		}
+		offset += config.PurgeMessagesLimit
+	}
+
+	for roomID, messages := range roomMessages {
+		purgeRoomMessages(ctx, roomID, messages, nowTimestamp)
+	}
+}
+
+// purgeDeletedRoomUsers deletes the room users kept to restore the deleted rooms whose restore period has passed
+func purgeDeletedRoomUsers(ctx context.Context) {
+	span := tracer.StartSpan(ctx, "purgeDeletedRoomUsers", "service")
+	defer tracer.Finish(span)
+
+	deletedTimestamp := time.Now().Unix() - int64(config.Config().Retention.RoomRestorePeriod)
+	err := datastore.Provider(ctx).PurgeDeletedRoomUsers(deletedTimestamp)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+func selectMessagesOverRetention(ctx context.Context, room *model.Room, nowTimestamp int64) ([]*model.Message, error) {
+	messages := []*model.Message{}
+
+	if room.RetentionMaxAge > 0 {
+		oldMessages, err := datastore.Provider(ctx).SelectMessages(
+			config.PurgeMessagesLimit,
+			0,
+			datastore.SelectMessagesOptionFilterByRoomID(room.RoomID),
+			datastore.SelectMessagesOptionOffsetTimestamp(nowTimestamp-room.RetentionMaxAge),
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, oldMessages...)
+	}
+
+	if room.RetentionMaxCount > 0 {
+		overflowMessages, err := datastore.Provider(ctx).SelectMessages(
+			config.PurgeMessagesLimit,
+			int32(room.RetentionMaxCount),
+			datastore.SelectMessagesOptionFilterByRoomID(room.RoomID),
+			datastore.SelectMessagesOptionOrders([]*scpb.OrderInfo{
+				&scpb.OrderInfo{Field: "created", Order: scpb.Order_Desc},
+				&scpb.OrderInfo{Field: "id", Order: scpb.Order_Desc},
+			}),
+		)
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, overflowMessages...)
+	}
+
+	return messages, nil
+}
+
+// purgeRoomMessages deletes the messages and their assets, refreshes the last message of the room
+// and tells the room users that the messages were deleted.
+// The messages are deleted before the assets so that no message is left referring to a deleted asset.
+func purgeRoomMessages(ctx context.Context, roomID string, messages []*model.Message, nowTimestamp int64) {
+	span := tracer.StartSpan(ctx, "purgeRoomMessages", "service")
+	defer tracer.Finish(span)
+
+	hardDelete := config.Config().Retention.HardDelete
+
+	messageIDs := []string{}
+	purgedMessages := []*model.Message{}
+	purged := make(map[string]struct{})
+	for _, message := range messages {
+		if _, ok := purged[message.MessageID]; ok {
+			continue
+		}
+		purged[message.MessageID] = struct{}{}
+		messageIDs = append(messageIDs, message.MessageID)
+		purgedMessages = append(purgedMessages, message)
+	}
+	if len(messageIDs) == 0 {
+		return
+	}
+
+	opts := []datastore.DeleteMessagesOption{
+		datastore.DeleteMessagesOptionFilterByMessageIDs(messageIDs),
+	}
+	if !hardDelete {
+		opts = append(opts, datastore.DeleteMessagesOptionWithLogicalDeleted(nowTimestamp))
+	}
+	err := datastore.Provider(ctx).DeleteMessages(opts...)
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return
+	}
+
+	for _, message := range purgedMessages {
+		err = purgeMessageAssets(ctx, message, hardDelete, nowTimestamp)
+		if err != nil {
+			logger.Error(fmt.Sprintf("Failed to purge the assets of the message. messageId[%s] %s", message.MessageID, err.Error()))
+			tracer.SetError(span, err)
+		}
+	}
+
+	err = datastore.Provider(ctx).UpdateRoomLastMessage(roomID)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+
+	for _, message := range purgedMessages {
+		message.DeletedTimestamp = nowTimestamp
+		publishMessage(ctx, message, true)
+	}
+
+	logger.Info(fmt.Sprintf("Purged %d messages. roomId[%s]", len(purgedMessages), roomID))
+}
+
+// purgeMessageAssets deletes the assets of the message from the storage and the datastore.
+// The assets which failed to be deleted from the storage are kept in the datastore, and the last error is returned.
+func purgeMessageAssets(ctx context.Context, message *model.Message, hardDelete bool, nowTimestamp int64) error {
+	if message.Type != model.MessageTypeImage && message.Type != model.MessageTypeFile {
+		return nil
+	}
+
+	var payload model.PayloadImage
+	err := message.Payload.Unmarshal(&payload)
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+	assetIDs := []string{}
+	for _, assetURL := range []string{payload.SourceUrl, payload.ThumbnailUrl} {
+		assetID := assetIDFromURL(assetURL)
+		if assetID == "" {
+			continue
+		}
+
+		asset, err := datastore.Provider(ctx).SelectAsset(assetID)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if asset == nil {
+			continue
+		}
+
+		err = storage.Provider(ctx).Delete(&storage.AssetInfo{
+			Filename: fmt.Sprintf("%s.%s", asset.AssetID, asset.Extension),
+		})
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		assetIDs = append(assetIDs, asset.AssetID)
+	}
+	if len(assetIDs) == 0 {
+		return lastErr
+	}
+
+	opts := []datastore.DeleteAssetsOption{
+		datastore.DeleteAssetsOptionFilterByAssetIDs(assetIDs),
+	}
+	if !hardDelete {
+		opts = append(opts, datastore.DeleteAssetsOptionWithLogicalDeleted(nowTimestamp))
+	}
+	err = datastore.Provider(ctx).DeleteAssets(opts...)
+	if err != nil {
+		return err
+	}
+
+	return lastErr
+}
+
+// assetIDFromURL returns the assetId from the url of the asset, which ends with {assetId}.{extension}
+func assetIDFromURL(assetURL string) string {
+	if assetURL == "" {
+		return ""
+	}
+
+	u, err := url.Parse(assetURL)
+	if err != nil {
+		return ""
+	}
+
+	filename := path.Base(u.Path)
+	if filename == "." || filename == "/" {
+		return ""
+	}
+
+	return strings.TrimSuffix(filename, path.Ext(filename))
+}
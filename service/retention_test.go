@@ -0,0 +1,133 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpRetention = "[service] set up retention"
+	TestServicePurgeMessages  = "[service] purge messages test"
+	TestServiceAssetIDFromURL = "[service] asset id from url test"
+)
+
+func TestRetention(t *testing.T) {
+	userID := "retention-service-user-id-0001"
+
+	t.Run(TestServiceSetUpRetention, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+
+		newUser := &model.User{}
+		newUser.UserID = userID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRetention, err.Error())
+		}
+
+		for i := 1; i <= 2; i++ {
+			newRoom := &model.Room{}
+			newRoom.RoomID = fmt.Sprintf("retention-service-room-id-%04d", i)
+			newRoom.UserID = userID
+			newRoom.Type = scpb.RoomType_PublicRoom
+			newRoom.MetaData = []byte(`{"key":"value"}`)
+			newRoom.CreatedTimestamp = nowTimestamp
+			newRoom.ModifiedTimestamp = nowTimestamp
+			if i == 2 {
+				newRoom.RetentionMaxCount = 2
+			}
+			err = datastore.Provider(ctx).InsertRoom(newRoom)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRetention, err.Error())
+			}
+		}
+
+		for i := 1; i <= 4; i++ {
+			newMessage := &model.Message{}
+			newMessage.MessageID = fmt.Sprintf("retention-service-message-id-%04d", i)
+			newMessage.RoomID = "retention-service-room-id-0001"
+			newMessage.UserID = userID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(fmt.Sprintf(`{"text":"message %d"}`, i))
+			newMessage.CreatedTimestamp = nowTimestamp - int64(10-i)
+			newMessage.ModifiedTimestamp = nowTimestamp
+			if i%2 == 0 {
+				newMessage.ExpiresTimestamp = nowTimestamp - 1
+			}
+			err = datastore.Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRetention, err.Error())
+			}
+		}
+
+		for i := 5; i <= 9; i++ {
+			newMessage := &model.Message{}
+			newMessage.MessageID = fmt.Sprintf("retention-service-message-id-%04d", i)
+			newMessage.RoomID = "retention-service-room-id-0002"
+			newMessage.UserID = userID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(fmt.Sprintf(`{"text":"message %d"}`, i))
+			newMessage.CreatedTimestamp = nowTimestamp - int64(10-i)
+			newMessage.ModifiedTimestamp = nowTimestamp
+			err = datastore.Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRetention, err.Error())
+			}
+		}
+	})
+
+	t.Run(TestServicePurgeMessages, func(t *testing.T) {
+		purgeMessages(ctx)
+
+		count, err := datastore.Provider(ctx).SelectCountMessages(datastore.SelectMessagesOptionFilterByRoomID("retention-service-room-id-0001"))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServicePurgeMessages, err.Error())
+		}
+		if count != 2 {
+			t.Fatalf("Failed to %s. Expected count to be 2, but it was %d", TestServicePurgeMessages, count)
+		}
+
+		room, err := datastore.Provider(ctx).SelectRoom("retention-service-room-id-0001")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServicePurgeMessages, err.Error())
+		}
+		if room.LastMessage != "message 3" {
+			t.Fatalf("Failed to %s. Expected room.LastMessage to be \"message 3\", but it was \"%s\"", TestServicePurgeMessages, room.LastMessage)
+		}
+
+		messages, err := datastore.Provider(ctx).SelectMessages(10, 0, datastore.SelectMessagesOptionFilterByRoomID("retention-service-room-id-0002"))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServicePurgeMessages, err.Error())
+		}
+		if len(messages) != 2 {
+			t.Fatalf("Failed to %s. Expected messages count to be 2, but it was %d", TestServicePurgeMessages, len(messages))
+		}
+		if messages[0].MessageID != "retention-service-message-id-0008" || messages[1].MessageID != "retention-service-message-id-0009" {
+			t.Fatalf("Failed to %s. Expected the newest 2 messages to be kept, but they were %s and %s", TestServicePurgeMessages, messages[0].MessageID, messages[1].MessageID)
+		}
+	})
+
+	t.Run(TestServiceAssetIDFromURL, func(t *testing.T) {
+		assetID := assetIDFromURL("https://s3-ap-northeast-1.amazonaws.com/bucket/dir/asset-id-0001.png")
+		if assetID != "asset-id-0001" {
+			t.Fatalf("Failed to %s. Expected assetID to be \"asset-id-0001\", but it was \"%s\"", TestServiceAssetIDFromURL, assetID)
+		}
+
+		assetID = assetIDFromURL("https://www.googleapis.com/download/storage/v1/b/bucket/o/dir%2Fasset-id-0002.jpg?generation=1&alt=media")
+		if assetID != "asset-id-0002" {
+			t.Fatalf("Failed to %s. Expected assetID to be \"asset-id-0002\", but it was \"%s\"", TestServiceAssetIDFromURL, assetID)
+		}
+
+		assetID = assetIDFromURL("")
+		if assetID != "" {
+			t.Fatalf("Failed to %s. Expected assetID to be empty, but it was \"%s\"", TestServiceAssetIDFromURL, assetID)
+		}
+	})
+}
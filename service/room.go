@@ -3,7 +3,6 @@ package service
 import (
 	"context"
 	"net/http"
-	"sync"
 	"time"
 
 	"github.com/swagchat/chat-api/config"
@@ -66,7 +65,18 @@ func CreateRoom(ctx context.Context, req *model.CreateRoomRequest) (*model.Room,
 		r.NotificationTopicID = notificationTopicID
 	}
 
-	err := datastore.Provider(ctx).InsertRoom(r, datastore.InsertRoomOptionWithRoomUser(rus))
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeRoom,
+		r.RoomID,
+		"",
+		model.OutboxDestinationWebhook,
+		model.OutboxDestinationProducer,
+	)
+	err := datastore.Provider(ctx).InsertRoom(
+		r,
+		datastore.InsertRoomOptionWithRoomUser(rus),
+		datastore.InsertRoomOptionWithOutboxEvents(outboxEvents),
+	)
 	if err != nil {
 		return nil, model.NewErrorResponse("Failed to create room.", http.StatusInternalServerError, model.WithError(err))
 	}
@@ -78,9 +88,8 @@ func CreateRoom(ctx context.Context, req *model.CreateRoomRequest) (*model.Room,
 		return nil, model.NewErrorResponse("Failed to create room.", http.StatusInternalServerError, model.WithError(err))
 	}
 
-	go webhookRoom(ctx, r)
 	go subscribeByRoomUsers(ctx, roomUsers)
-	go publishUserJoin(ctx, r.RoomID)
+	relayOutboxEventsInBackground(ctx, outboxEvents)
 
 	room, err := datastore.Provider(ctx).SelectRoom(
 		r.RoomID,
@@ -90,6 +99,8 @@ func CreateRoom(ctx context.Context, req *model.CreateRoomRequest) (*model.Room,
 		return nil, model.NewErrorResponse("Failed to create room.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	writeAuditLog(ctx, model.AuditActionCreateRoom, model.AuditTargetTypeRoom, room.RoomID, nil, room)
+
 	return room, nil
 }
 
@@ -154,6 +165,15 @@ func RetrieveRoom(ctx context.Context, req *model.RetrieveRoomRequest) (*model.R
 	}
 	room.MessageCount = count
 
+	pinnedMessages, err := datastore.Provider(ctx).SelectPinnedMessages(req.RoomID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve room.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if userID != "" {
+		pinnedMessages = model.FilterPinnedMessagesByRoles(pinnedMessages, roles)
+	}
+	room.PinnedMessages = pinnedMessages
+
 	return room, nil
 }
 
@@ -168,11 +188,22 @@ func UpdateRoom(ctx context.Context, req *model.UpdateRoomRequest) (*model.Room,
 		return nil, errRes
 	}
 
+	errRes = confirmRoomNotArchived(room, "Failed to update room.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to update room.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
 	errRes = req.Validate(room)
 	if errRes != nil {
 		return nil, errRes
 	}
 
+	beforeRoom := *room
 	room.UpdateRoom(req)
 
 	if len(req.UserIDs) > 0 {
@@ -182,7 +213,12 @@ func UpdateRoom(ctx context.Context, req *model.UpdateRoomRequest) (*model.Room,
 			return nil, errRes
 		}
 	}
-	rus := req.GenerateRoomUsers(room)
+
+	// The members are replaced only when userIds is in the request
+	var rus []*model.RoomUser
+	if req.UserIDs != nil {
+		rus = req.GenerateRoomUsers(room)
+	}
 
 	err := datastore.Provider(ctx).UpdateRoom(
 		room,
@@ -192,6 +228,24 @@ func UpdateRoom(ctx context.Context, req *model.UpdateRoomRequest) (*model.Room,
 		return nil, model.NewErrorResponse("Failed to update room.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	afterUserIDs := roomMemberIDs(&beforeRoom)
+	if rus != nil {
+		afterUserIDs = make([]string, 0, len(rus))
+		for _, ru := range rus {
+			afterUserIDs = append(afterUserIDs, ru.UserID)
+		}
+	}
+	requestUserID, _ := ctx.Value(config.CtxUserID).(string)
+	systemMessages := model.NewUpdateRoomSystemMessages(&beforeRoom, room, requestUserID)
+	joinedUserIDs, leftUserIDs := model.DiffUserIDs(roomMemberIDs(&beforeRoom), afterUserIDs)
+	if len(joinedUserIDs) > 0 {
+		systemMessages = append(systemMessages, model.NewJoinSystemMessage(room.RoomID, requestUserID, joinedUserIDs))
+	}
+	if len(leftUserIDs) > 0 {
+		systemMessages = append(systemMessages, model.NewLeaveSystemMessage(room.RoomID, requestUserID, leftUserIDs))
+	}
+	sendSystemMessages(ctx, room, systemMessages...)
+
 	return room, nil
 }
 
@@ -206,6 +260,11 @@ func DeleteRoom(ctx context.Context, req *model.DeleteRoomRequest) *model.ErrorR
 		return errRes
 	}
 
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to delete room.", model.RoomRoleOwner)
+	if errRes != nil {
+		return errRes
+	}
+
 	if room.NotificationTopicID != "" {
 		nRes := <-notification.Provider(ctx).DeleteTopic(room.NotificationTopicID)
 		if nRes.Error != nil {
@@ -219,18 +278,247 @@ func DeleteRoom(ctx context.Context, req *model.DeleteRoomRequest) *model.ErrorR
 		return model.NewErrorResponse("Failed to delete room.", http.StatusInternalServerError, model.WithError(err))
 	}
 
-	go func() {
-		wg := &sync.WaitGroup{}
-		wg.Add(1)
-		go unsubscribeByRoomID(ctx, req.RoomID, wg)
-		wg.Wait()
-		room.NotificationTopicID = ""
-		datastore.Provider(ctx).UpdateRoom(room)
-	}()
+	writeAuditLog(ctx, model.AuditActionDeleteRoom, model.AuditTargetTypeRoom, room.RoomID, room, nil)
+
+	// The notification topic ID is cleared when the room is restored.
+	// Updating the room here would delete the room again if it had been restored in the meantime.
+	go unsubscribeByRoomID(ctx, req.RoomID, nil)
+
+	return nil
+}
+
+// TransferRoomOwner transfers the ownership of the room to the other room user.
+// The previous owner becomes an admin of the room.
+func TransferRoomOwner(ctx context.Context, req *model.TransferRoomOwnerRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "TransferRoomOwner", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to transfer room owner."
+		return errRes
+	}
+
+	req.Room = room
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to transfer room owner.", model.RoomRoleOwner)
+	if errRes != nil {
+		return errRes
+	}
+
+	_, errRes = confirmRoomUserExist(ctx, req.RoomID, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to transfer room owner."
+		return errRes
+	}
+
+	beforeUserID := room.UserID
+	room.ModifiedTimestamp = time.Now().Unix()
+	err := datastore.Provider(ctx).UpdateRoomOwner(room, req.UserID)
+	if err != nil {
+		return model.NewErrorResponse("Failed to transfer room owner.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	writeAuditLog(
+		ctx,
+		model.AuditActionTransferRoomOwner,
+		model.AuditTargetTypeRoom,
+		req.RoomID,
+		map[string]string{"userId": beforeUserID},
+		map[string]string{"userId": req.UserID},
+	)
 
 	return nil
 }
 
+// ArchiveRoom archives the room. The archived room is read-only and is hidden from the rooms of the users by default.
+// The notification topic of the room is deleted while it's archived.
+func ArchiveRoom(ctx context.Context, req *model.ArchiveRoomRequest) (*model.Room, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "ArchiveRoom", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to archive room."
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to archive room.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if room.ArchivedTimestamp != 0 {
+		return room, nil
+	}
+
+	if room.NotificationTopicID != "" {
+		nRes := <-notification.Provider(ctx).DeleteTopic(room.NotificationTopicID)
+		if nRes.Error != nil {
+			return nil, model.NewErrorResponse("Failed to archive room.", http.StatusInternalServerError, model.WithError(nRes.Error))
+		}
+	}
+
+	nowTimestamp := time.Now().Unix()
+	room.ArchivedTimestamp = nowTimestamp
+	room.ModifiedTimestamp = nowTimestamp
+	room.NotificationTopicID = ""
+	err := datastore.Provider(ctx).UpdateRoom(room)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to archive room.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	err = datastore.Provider(ctx).DeleteSubscriptions(
+		datastore.DeleteSubscriptionsOptionWithLogicalDeleted(nowTimestamp),
+		datastore.DeleteSubscriptionsOptionFilterByRoomID(req.RoomID),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to archive room.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	writeAuditLog(ctx, model.AuditActionArchiveRoom, model.AuditTargetTypeRoom, room.RoomID, nil, nil)
+
+	go unsubscribeByRoomID(ctx, req.RoomID, nil)
+
+	return room, nil
+}
+
+// UnarchiveRoom unarchives the archived room.
+// The notification topic of the room is created again and the room users are subscribed to it.
+func UnarchiveRoom(ctx context.Context, req *model.ArchiveRoomRequest) (*model.Room, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "UnarchiveRoom", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to unarchive room."
+		return nil, errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to unarchive room.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if room.ArchivedTimestamp == 0 {
+		return room, nil
+	}
+
+	room.ArchivedTimestamp = 0
+	room.ModifiedTimestamp = time.Now().Unix()
+	err := datastore.Provider(ctx).UpdateRoom(room)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to unarchive room.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	writeAuditLog(ctx, model.AuditActionUnarchiveRoom, model.AuditTargetTypeRoom, room.RoomID, nil, nil)
+
+	errRes = resubscribeRoom(ctx, room)
+	if errRes != nil {
+		errRes.Message = "Failed to unarchive room."
+		return nil, errRes
+	}
+
+	return room, nil
+}
+
+// RestoreRoom restores the deleted room with its room users within the restore period
+func RestoreRoom(ctx context.Context, req *model.RestoreRoomRequest) (*model.Room, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RestoreRoom", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithDeleted(true))
+	if errRes != nil {
+		errRes.Message = "Failed to restore room."
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	errRes = req.Validate(time.Now().Unix(), int64(config.Config().Retention.RoomRestorePeriod))
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	room.ModifiedTimestamp = time.Now().Unix()
+	restored, err := datastore.Provider(ctx).RestoreRoom(room)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to restore room.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if !restored {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "roomId",
+				Reason: "The room users of the room are not kept any more.",
+			},
+		}
+		return nil, model.NewErrorResponse("Failed to restore room.", http.StatusGone, model.WithInvalidParams(invalidParams))
+	}
+
+	writeAuditLog(ctx, model.AuditActionRestoreRoom, model.AuditTargetTypeRoom, room.RoomID, nil, room)
+
+	// The archived room stays without the notification topic until it's unarchived
+	if room.ArchivedTimestamp == 0 {
+		errRes = resubscribeRoom(ctx, room)
+		if errRes != nil {
+			errRes.Message = "Failed to restore room."
+			return nil, errRes
+		}
+	}
+
+	room, errRes = confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to restore room."
+		return nil, errRes
+	}
+
+	return room, nil
+}
+
+// resubscribeRoom creates the notification topic of the room again and subscribes the devices of the room users to it
+func resubscribeRoom(ctx context.Context, room *model.Room) *model.ErrorResponse {
+	errRes := prepareRoomTopic(ctx, room)
+	if errRes != nil {
+		return errRes
+	}
+
+	roomUsers, err := datastore.Provider(ctx).SelectRoomUsers(datastore.SelectRoomUsersOptionWithRoomID(room.RoomID))
+	if err != nil {
+		return model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	go subscribeByRoomUsers(ctx, roomUsers)
+
+	return nil
+}
+
+// confirmRoomNotArchived returns the error if the room is archived, which is read-only
+func confirmRoomNotArchived(room *model.Room, message string) *model.ErrorResponse {
+	if room.ArchivedTimestamp == 0 {
+		return nil
+	}
+
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "roomId",
+			Reason: "The room has been archived. It's read-only until it's unarchived.",
+		},
+	}
+	return model.NewErrorResponse(message, http.StatusForbidden, model.WithInvalidParams(invalidParams))
+}
+
 // RetrieveRoomMessages retrieves room messages
 func RetrieveRoomMessages(ctx context.Context, req *model.RetrieveRoomMessagesRequest) (*model.RoomMessagesResponse, *model.ErrorResponse) {
 	span := tracer.StartSpan(ctx, "RetrieveRoomMessages", "service")
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// CreateRoomInvitations invites the users to the room
+func CreateRoomInvitations(ctx context.Context, req *model.CreateRoomInvitationsRequest) (*model.RoomInvitationsResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "CreateRoomInvitations", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to create room invitations."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to create room invitations.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to create room invitations.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = confirmUserIDsExist(ctx, req.UserIDs, "userIds")
+	if errRes != nil {
+		errRes.Message = "Failed to create room invitations."
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	for _, userID := range utils.RemoveDuplicateString(req.UserIDs) {
+		errRes = confirmNoPendingRoomInvitation(ctx, req.RoomID, userID, model.RoomInvitationTypeInvitation, "Failed to create room invitations.")
+		if errRes != nil {
+			return nil, errRes
+		}
+	}
+
+	inviterUserID, _ := ctx.Value(config.CtxUserID).(string)
+	invitations := req.GenerateRoomInvitations(inviterUserID)
+	outboxEvents := []*model.OutboxEvent{}
+	for _, invitation := range invitations {
+		outboxEvents = append(outboxEvents, model.NewRoomEventOutboxEvents(req.RoomID, invitation, []string{invitation.UserID})...)
+	}
+	err := datastore.Provider(ctx).InsertRoomInvitations(invitations, datastore.InsertRoomInvitationsOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create room invitations.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	res := &model.RoomInvitationsResponse{}
+	res.RoomInvitations = invitations
+	res.AllCount = int64(len(invitations))
+	res.Limit = int32(len(invitations))
+	return res, nil
+}
+
+// CreateRoomJoinRequest requests to join the private room.
+// The request is notified to the owner and the admins of the room.
+func CreateRoomJoinRequest(ctx context.Context, req *model.CreateRoomJoinRequestRequest) (*model.RoomInvitation, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "CreateRoomJoinRequest", "service")
+	defer tracer.Finish(span)
+
+	// Users can only request to join by themselves
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID == "" && userID != "" {
+		req.UserID = userID
+	}
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to create room join request."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to create room join request.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	_, errRes = confirmUserExist(ctx, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to create room join request."
+		return nil, errRes
+	}
+
+	errRes = confirmNoPendingRoomInvitation(ctx, req.RoomID, req.UserID, model.RoomInvitationTypeJoinRequest, "Failed to create room join request.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	invitation := req.GenerateRoomInvitation()
+	outboxEvents := model.NewRoomEventOutboxEvents(req.RoomID, invitation, roomManagerIDs(room))
+	err := datastore.Provider(ctx).InsertRoomInvitations(
+		[]*model.RoomInvitation{invitation},
+		datastore.InsertRoomInvitationsOptionWithOutboxEvents(outboxEvents),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create room join request.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	return invitation, nil
+}
+
+// RetrieveRoomInvitations retrieves the invitations and the join requests of the room or of the user
+func RetrieveRoomInvitations(ctx context.Context, req *model.RetrieveRoomInvitationsRequest) (*model.RoomInvitationsResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveRoomInvitations", "service")
+	defer tracer.Finish(span)
+
+	opts := []datastore.SelectRoomInvitationsOption{}
+
+	if req.RoomID != "" {
+		_, errRes := confirmRoomExist(ctx, req.RoomID)
+		if errRes != nil {
+			errRes.Message = "Failed to retrieve room invitations."
+			return nil, errRes
+		}
+
+		_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to retrieve room invitations.", model.RoomRoleOwner, model.RoomRoleAdmin)
+		if errRes != nil {
+			return nil, errRes
+		}
+
+		opts = append(opts, datastore.SelectRoomInvitationsOptionFilterByRoomID(req.RoomID))
+	}
+
+	if req.UserID != "" {
+		opts = append(opts, datastore.SelectRoomInvitationsOptionFilterByUserID(req.UserID))
+	}
+
+	if req.Type != "" {
+		opts = append(opts, datastore.SelectRoomInvitationsOptionFilterByType(req.Type))
+	}
+
+	if req.Status != "" {
+		opts = append(opts, datastore.SelectRoomInvitationsOptionFilterByStatus(req.Status))
+	}
+
+	invitations, err := datastore.Provider(ctx).SelectRoomInvitations(req.Limit, req.Offset, opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve room invitations.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	count, err := datastore.Provider(ctx).SelectCountRoomInvitations(opts...)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve room invitations.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.RoomInvitationsResponse{}
+	res.RoomInvitations = invitations
+	res.AllCount = count
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+	return res, nil
+}
+
+// UpdateRoomInvitation accepts or declines the invitation or the join request.
+// The invitation is answered by the invited user, and the join request is answered by the owner or the admins of the room.
+// The user joins the room when it's accepted.
+func UpdateRoomInvitation(ctx context.Context, req *model.UpdateRoomInvitationRequest) (*model.RoomInvitation, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "UpdateRoomInvitation", "service")
+	defer tracer.Finish(span)
+
+	invitation, err := datastore.Provider(ctx).SelectRoomInvitation(req.InvitationID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if invitation == nil {
+		return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusNotFound)
+	}
+
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	switch invitation.Type {
+	case model.RoomInvitationTypeInvitation:
+		if clientID == "" && userID != "" && userID != invitation.UserID {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "invitationId",
+					Reason: "You are not invited by this invitation.",
+				},
+			}
+			return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusForbidden, model.WithInvalidParams(invalidParams))
+		}
+	case model.RoomInvitationTypeJoinRequest:
+		_, errRes := roomRoleAuthz(ctx, invitation.RoomID, "Failed to update room invitation.", model.RoomRoleOwner, model.RoomRoleAdmin)
+		if errRes != nil {
+			return nil, errRes
+		}
+		invitation.ReviewerUserID = userID
+	}
+
+	nowTimestamp := time.Now().Unix()
+	if invitation.IsExpired(nowTimestamp) {
+		expired := *invitation
+		expired.Status = model.RoomInvitationStatusExpired
+		expired.Modified = nowTimestamp
+		_, err := datastore.Provider(ctx).UpdateRoomInvitation(&expired)
+		if err != nil {
+			logger.Error(err.Error())
+		}
+	}
+
+	errRes := req.Validate(invitation)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	invitation.Status = req.Status
+	invitation.Modified = nowTimestamp
+
+	if invitation.Status == model.RoomInvitationStatusDeclined {
+		outboxEvents := model.NewRoomEventOutboxEvents(invitation.RoomID, invitation, roomInvitationNotifiedUserIDs(invitation))
+		updated, err := datastore.Provider(ctx).UpdateRoomInvitation(
+			invitation,
+			datastore.UpdateRoomInvitationOptionWithOutboxEvents(outboxEvents),
+		)
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusInternalServerError, model.WithError(err))
+		}
+		if !updated {
+			return nil, roomInvitationAnsweredError()
+		}
+
+		relayOutboxEventsInBackground(ctx, outboxEvents)
+		return invitation, nil
+	}
+
+	room, errRes := confirmRoomExist(ctx, invitation.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to update room invitation."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to update room invitation.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	// The user may have joined the room in another way after the invitation
+	if utils.SearchStringValueInSlice(roomMemberIDs(room), invitation.UserID) {
+		updated, err := datastore.Provider(ctx).UpdateRoomInvitation(invitation)
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusInternalServerError, model.WithError(err))
+		}
+		if !updated {
+			return nil, roomInvitationAnsweredError()
+		}
+		return invitation, nil
+	}
+
+	errRes = prepareRoomTopic(ctx, room)
+	if errRes != nil {
+		errRes.Message = "Failed to update room invitation."
+		return nil, errRes
+	}
+
+	ru := &model.RoomUser{}
+	ru.RoomID = invitation.RoomID
+	ru.UserID = invitation.UserID
+	ru.Display = true
+	ru.RoomRole = model.RoomRoleMember
+	roomUsers := []*model.RoomUser{ru}
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeRoomUser,
+		invitation.RoomID,
+		"",
+		model.OutboxDestinationProducer,
+	)
+	outboxEvents = append(outboxEvents, model.NewRoomEventOutboxEvents(invitation.RoomID, invitation, roomInvitationNotifiedUserIDs(invitation))...)
+	updated, err := datastore.Provider(ctx).UpdateRoomInvitation(
+		invitation,
+		datastore.UpdateRoomInvitationOptionWithRoomUsers(roomUsers),
+		datastore.UpdateRoomInvitationOptionWithOutboxEvents(outboxEvents),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to update room invitation.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if !updated {
+		return nil, roomInvitationAnsweredError()
+	}
+
+	completeRoomUsersJoin(ctx, room, roomUsers, outboxEvents)
+
+	return invitation, nil
+}
+
+// RunRoomInvitationExpirer expires the pending invitations over the expiration periodically until ctx is done
+func RunRoomInvitationExpirer(ctx context.Context) {
+	ticker := time.NewTicker(config.RoomInvitationExpireIntervalSecond * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, workspace := range backgroundJobWorkspaces(ctx) {
+				expireRoomInvitations(context.WithValue(ctx, config.CtxWorkspace, workspace))
+			}
+		}
+	}
+}
+
+func expireRoomInvitations(ctx context.Context) {
+	span := tracer.StartSpan(ctx, "expireRoomInvitations", "service")
+	defer tracer.Finish(span)
+
+	err := datastore.Provider(ctx).ExpireRoomInvitations(time.Now().Unix())
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
+
+func confirmNoPendingRoomInvitation(ctx context.Context, roomID, userID, invitationType, message string) *model.ErrorResponse {
+	count, err := datastore.Provider(ctx).SelectCountRoomInvitations(
+		datastore.SelectRoomInvitationsOptionFilterByRoomID(roomID),
+		datastore.SelectRoomInvitationsOptionFilterByUserID(userID),
+		datastore.SelectRoomInvitationsOptionFilterByType(invitationType),
+		datastore.SelectRoomInvitationsOptionFilterByStatus(model.RoomInvitationStatusPending),
+	)
+	if err != nil {
+		return model.NewErrorResponse(message, http.StatusInternalServerError, model.WithError(err))
+	}
+	if count > 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "userId",
+				Reason: fmt.Sprintf("There is already a pending %s. userId[%s]", invitationType, userID),
+			},
+		}
+		return model.NewErrorResponse(message, http.StatusConflict, model.WithInvalidParams(invalidParams))
+	}
+
+	return nil
+}
+
+// roomManagerIDs returns the userIds of the owner and the admins of the room
+func roomManagerIDs(room *model.Room) []string {
+	userIDs := []string{}
+	for _, user := range room.Users {
+		if user.RuRoomRole == model.RoomRoleOwner || user.RuRoomRole == model.RoomRoleAdmin {
+			userIDs = append(userIDs, user.UserID)
+		}
+	}
+	return userIDs
+}
+
+// roomInvitationNotifiedUserIDs returns the users who are notified of the answer to the invitation
+func roomInvitationNotifiedUserIDs(invitation *model.RoomInvitation) []string {
+	if invitation.Type == model.RoomInvitationTypeJoinRequest {
+		return []string{invitation.UserID}
+	}
+	if invitation.InviterUserID == "" {
+		return []string{}
+	}
+	return []string{invitation.InviterUserID}
+}
+
+// roomInvitationAnsweredError is the error of the invitation answered by another request at the same time
+func roomInvitationAnsweredError() *model.ErrorResponse {
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "invitationId",
+			Reason: "The invitation has already been answered.",
+		},
+	}
+	return model.NewErrorResponse("Failed to update room invitation.", http.StatusConflict, model.WithInvalidParams(invalidParams))
+}
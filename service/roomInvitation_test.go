@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpRoomInvitation       = "[service] set up room invitation"
+	TestServiceRoomInvitation            = "[service] room invitation test"
+	TestServiceRoomJoinRequest           = "[service] room join request test"
+	TestServiceJoinRoomByInviteLink      = "[service] join room by invite link test"
+	TestServiceTearDownRoomInvitation    = "[service] tear down room invitation"
+	testServiceRoomInvitationRoomID      = "room-invitation-service-room-id-0001"
+	testServiceRoomInvitationOwnerID     = "room-invitation-service-user-id-0001"
+	testServiceRoomInvitationInviteeID   = "room-invitation-service-user-id-0002"
+	testServiceRoomInvitationRequesterID = "room-invitation-service-user-id-0003"
+	testServiceRoomInvitationLinkUserID  = "room-invitation-service-user-id-0004"
+	testServiceRoomInvitationOtherID     = "room-invitation-service-user-id-0005"
+)
+
+func TestRoomInvitation(t *testing.T) {
+	ownerCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomInvitationOwnerID)
+	inviteeCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomInvitationInviteeID)
+	requesterCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomInvitationRequesterID)
+	linkUserCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomInvitationLinkUserID)
+	otherCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomInvitationOtherID)
+
+	t.Run(TestServiceSetUpRoomInvitation, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		for i := 1; i <= 5; i++ {
+			newUser := &model.User{}
+			newUser.UserID = fmt.Sprintf("room-invitation-service-user-id-%04d", i)
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomInvitation, err.Error())
+			}
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testServiceRoomInvitationRoomID
+		newRoom.UserID = testServiceRoomInvitationOwnerID
+		newRoom.Type = scpb.RoomType_PrivateRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomInvitation, err.Error())
+		}
+
+		ru := &model.RoomUser{}
+		ru.RoomID = testServiceRoomInvitationRoomID
+		ru.UserID = testServiceRoomInvitationOwnerID
+		ru.Display = true
+		ru.RoomRole = model.RoomRoleOwner
+		err = datastore.Provider(ctx).InsertRoomUsers([]*model.RoomUser{ru})
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomInvitation, err.Error())
+		}
+	})
+
+	t.Run(TestServiceRoomInvitation, func(t *testing.T) {
+		req := &model.CreateRoomInvitationsRequest{}
+		req.RoomID = testServiceRoomInvitationRoomID
+		req.UserIDs = []string{testServiceRoomInvitationInviteeID}
+		res, errRes := CreateRoomInvitations(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomInvitation, errRes.Message)
+		}
+		if len(res.RoomInvitations) != 1 {
+			t.Fatalf("Failed to %s. Expected invitations count to be 1, but it was %d", TestServiceRoomInvitation, len(res.RoomInvitations))
+		}
+		invitation := res.RoomInvitations[0]
+		if invitation.InviterUserID != testServiceRoomInvitationOwnerID {
+			t.Fatalf("Failed to %s. Expected inviterUserId to be %s, but it was %s", TestServiceRoomInvitation, testServiceRoomInvitationOwnerID, invitation.InviterUserID)
+		}
+
+		_, errRes = CreateRoomInvitations(ownerCtx, req)
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected the pending invitation not to be duplicated", TestServiceRoomInvitation)
+		}
+
+		updateReq := &model.UpdateRoomInvitationRequest{}
+		updateReq.InvitationID = invitation.InvitationID
+		updateReq.Status = model.RoomInvitationStatusAccepted
+		_, errRes = UpdateRoomInvitation(otherCtx, updateReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the other user not to be able to accept the invitation", TestServiceRoomInvitation)
+		}
+
+		accepted, errRes := UpdateRoomInvitation(inviteeCtx, updateReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomInvitation, errRes.Message)
+		}
+		if accepted.Status != model.RoomInvitationStatusAccepted {
+			t.Fatalf("Failed to %s. Expected status to be %s, but it was %s", TestServiceRoomInvitation, model.RoomInvitationStatusAccepted, accepted.Status)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomInvitationRoomID, testServiceRoomInvitationInviteeID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomInvitation, err.Error())
+		}
+		if ru == nil || ru.RoomRole != model.RoomRoleMember {
+			t.Fatalf("Failed to %s. Expected the invited user to join the room as a member", TestServiceRoomInvitation)
+		}
+
+		_, errRes = UpdateRoomInvitation(inviteeCtx, updateReq)
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected the accepted invitation not to be answered again", TestServiceRoomInvitation)
+		}
+	})
+
+	t.Run(TestServiceRoomJoinRequest, func(t *testing.T) {
+		req := &model.CreateRoomJoinRequestRequest{}
+		req.RoomID = testServiceRoomInvitationRoomID
+		joinRequest, errRes := CreateRoomJoinRequest(requesterCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomJoinRequest, errRes.Message)
+		}
+		if joinRequest.UserID != testServiceRoomInvitationRequesterID {
+			t.Fatalf("Failed to %s. Expected userId to be %s, but it was %s", TestServiceRoomJoinRequest, testServiceRoomInvitationRequesterID, joinRequest.UserID)
+		}
+
+		updateReq := &model.UpdateRoomInvitationRequest{}
+		updateReq.InvitationID = joinRequest.InvitationID
+		updateReq.Status = model.RoomInvitationStatusAccepted
+		_, errRes = UpdateRoomInvitation(inviteeCtx, updateReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to approve the join request", TestServiceRoomJoinRequest)
+		}
+
+		approved, errRes := UpdateRoomInvitation(ownerCtx, updateReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomJoinRequest, errRes.Message)
+		}
+		if approved.ReviewerUserID != testServiceRoomInvitationOwnerID {
+			t.Fatalf("Failed to %s. Expected reviewerUserId to be %s, but it was %s", TestServiceRoomJoinRequest, testServiceRoomInvitationOwnerID, approved.ReviewerUserID)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomInvitationRoomID, testServiceRoomInvitationRequesterID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRoomJoinRequest, err.Error())
+		}
+		if ru == nil {
+			t.Fatalf("Failed to %s. Expected the requester to join the room", TestServiceRoomJoinRequest)
+		}
+	})
+
+	t.Run(TestServiceJoinRoomByInviteLink, func(t *testing.T) {
+		req := &model.CreateRoomInviteLinkRequest{}
+		req.RoomID = testServiceRoomInvitationRoomID
+		req.MaxUses = 1
+		_, errRes := CreateRoomInviteLink(inviteeCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to create invite links", TestServiceJoinRoomByInviteLink)
+		}
+
+		inviteLink, errRes := CreateRoomInviteLink(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceJoinRoomByInviteLink, errRes.Message)
+		}
+
+		joinReq := &model.JoinRoomByInviteLinkRequest{}
+		joinReq.InviteLinkID = inviteLink.InviteLinkID
+		_, errRes = JoinRoomByInviteLink(linkUserCtx, joinReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceJoinRoomByInviteLink, errRes.Message)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomInvitationRoomID, testServiceRoomInvitationLinkUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceJoinRoomByInviteLink, err.Error())
+		}
+		if ru == nil {
+			t.Fatalf("Failed to %s. Expected the user to join the room by the invite link", TestServiceJoinRoomByInviteLink)
+		}
+
+		joinReq = &model.JoinRoomByInviteLinkRequest{}
+		joinReq.InviteLinkID = inviteLink.InviteLinkID
+		_, errRes = JoinRoomByInviteLink(otherCtx, joinReq)
+		if errRes == nil || errRes.Status != http.StatusGone {
+			t.Fatalf("Failed to %s. Expected the invite link over the max uses not to be used", TestServiceJoinRoomByInviteLink)
+		}
+	})
+
+	t.Run(TestServiceTearDownRoomInvitation, func(t *testing.T) {
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testServiceRoomInvitationRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err := datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownRoomInvitation, err.Error())
+		}
+	})
+}
@@ -0,0 +1,205 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/betchi/tracer"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// CreateRoomInviteLink creates the shareable link to join the room
+func CreateRoomInviteLink(ctx context.Context, req *model.CreateRoomInviteLinkRequest) (*model.RoomInviteLink, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "CreateRoomInviteLink", "service")
+	defer tracer.Finish(span)
+
+	room, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to create room invite link."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to create room invite link.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	req.Room = room
+
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to create room invite link.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	inviteLink := req.GenerateRoomInviteLink(userID)
+	err := datastore.Provider(ctx).InsertRoomInviteLink(inviteLink)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create room invite link.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return inviteLink, nil
+}
+
+// RetrieveRoomInviteLinks retrieves the invite links of the room
+func RetrieveRoomInviteLinks(ctx context.Context, roomID string) (*model.RoomInviteLinksResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveRoomInviteLinks", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmRoomExist(ctx, roomID)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve room invite links."
+		return nil, errRes
+	}
+
+	_, errRes = roomRoleAuthz(ctx, roomID, "Failed to retrieve room invite links.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	inviteLinks, err := datastore.Provider(ctx).SelectRoomInviteLinks(roomID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve room invite links.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.RoomInviteLinksResponse{}
+	res.RoomInviteLinks = inviteLinks
+	return res, nil
+}
+
+// DeleteRoomInviteLink revokes the invite link
+func DeleteRoomInviteLink(ctx context.Context, req *model.DeleteRoomInviteLinkRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "DeleteRoomInviteLink", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := roomRoleAuthz(ctx, req.RoomID, "Failed to delete room invite link.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return errRes
+	}
+
+	inviteLink, err := datastore.Provider(ctx).SelectRoomInviteLink(req.InviteLinkID)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete room invite link.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if inviteLink == nil || inviteLink.RoomID != req.RoomID {
+		return model.NewErrorResponse("Failed to delete room invite link.", http.StatusNotFound)
+	}
+
+	nowTimestamp := time.Now().Unix()
+	inviteLink.Modified = nowTimestamp
+	inviteLink.Deleted = nowTimestamp
+	err = datastore.Provider(ctx).DeleteRoomInviteLink(inviteLink)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete room invite link.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+// JoinRoomByInviteLink makes the user join the room of the invite link
+func JoinRoomByInviteLink(ctx context.Context, req *model.JoinRoomByInviteLinkRequest) (*model.Room, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "JoinRoomByInviteLink", "service")
+	defer tracer.Finish(span)
+
+	// Users can only join by themselves
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID == "" && userID != "" {
+		req.UserID = userID
+	}
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	inviteLink, err := datastore.Provider(ctx).SelectRoomInviteLink(req.InviteLinkID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to join room.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if inviteLink == nil {
+		return nil, model.NewErrorResponse("Failed to join room.", http.StatusNotFound)
+	}
+
+	_, errRes = confirmUserExist(ctx, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	room, errRes := confirmRoomExist(ctx, inviteLink.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	errRes = confirmRoomNotArchived(room, "Failed to join room.")
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	// Joining again with the link doesn't consume it
+	if utils.SearchStringValueInSlice(roomMemberIDs(room), req.UserID) {
+		return room, nil
+	}
+
+	if !inviteLink.IsAvailable(time.Now().Unix()) {
+		return nil, inviteLinkUnavailableError()
+	}
+
+	errRes = prepareRoomTopic(ctx, room)
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	ru := &model.RoomUser{}
+	ru.RoomID = inviteLink.RoomID
+	ru.UserID = req.UserID
+	ru.Display = true
+	ru.RoomRole = model.RoomRoleMember
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeRoomUser,
+		inviteLink.RoomID,
+		"",
+		model.OutboxDestinationProducer,
+	)
+	used, err := datastore.Provider(ctx).UseRoomInviteLink(inviteLink, ru, datastore.UseRoomInviteLinkOptionWithOutboxEvents(outboxEvents))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to join room.", http.StatusInternalServerError, model.WithError(err))
+	}
+	// The link was used up or expired by another request in the meantime
+	if !used {
+		return nil, inviteLinkUnavailableError()
+	}
+
+	completeRoomUsersJoin(ctx, room, []*model.RoomUser{ru}, outboxEvents)
+
+	room, errRes = confirmRoomExist(ctx, inviteLink.RoomID, datastore.SelectRoomOptionWithUsers(true))
+	if errRes != nil {
+		errRes.Message = "Failed to join room."
+		return nil, errRes
+	}
+
+	return room, nil
+}
+
+func inviteLinkUnavailableError() *model.ErrorResponse {
+	invalidParams := []*scpb.InvalidParam{
+		&scpb.InvalidParam{
+			Name:   "inviteLinkId",
+			Reason: "The invite link has expired or reached the max uses.",
+		},
+	}
+	return model.NewErrorResponse("Failed to join room.", http.StatusGone, model.WithInvalidParams(invalidParams))
+}
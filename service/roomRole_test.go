@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpRoomRole       = "[service] set up room role"
+	TestServiceRoomRoleAuthz       = "[service] room role authorization test"
+	TestServiceDeleteRoomUsersRole = "[service] delete room users role test"
+	TestServiceUpdateRoomUserRole  = "[service] update room user role test"
+	TestServiceTransferRoomOwner   = "[service] transfer room owner test"
+	TestServiceDeleteMessage       = "[service] delete message test"
+	TestServiceTearDownRoomRole    = "[service] tear down room role"
+	testServiceRoomRoleRoomID      = "room-role-service-room-id-0001"
+	testServiceRoomRoleMessageID   = "room-role-service-message-id-0001"
+	testServiceRoomRoleOwnerID     = "room-role-service-user-id-0001"
+	testServiceRoomRoleAdminID     = "room-role-service-user-id-0002"
+	testServiceRoomRoleMemberID    = "room-role-service-user-id-0003"
+	testServiceRoomRoleNonMemberID = "room-role-service-user-id-0004"
+)
+
+func TestRoomRole(t *testing.T) {
+	ownerCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomRoleOwnerID)
+	adminCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomRoleAdminID)
+	memberCtx := context.WithValue(ctx, config.CtxUserID, testServiceRoomRoleMemberID)
+
+	t.Run(TestServiceSetUpRoomRole, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+		for i := 1; i <= 4; i++ {
+			newUser := &model.User{}
+			newUser.UserID = fmt.Sprintf("room-role-service-user-id-%04d", i)
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomRole, err.Error())
+			}
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testServiceRoomRoleRoomID
+		newRoom.UserID = testServiceRoomRoleOwnerID
+		newRoom.Type = scpb.RoomType_PrivateRoom
+		newRoom.CanLeft = true
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomRole, err.Error())
+		}
+
+		roomUsers := []*model.RoomUser{}
+		for userID, roomRole := range map[string]model.RoomRole{
+			testServiceRoomRoleOwnerID:  model.RoomRoleOwner,
+			testServiceRoomRoleAdminID:  model.RoomRoleAdmin,
+			testServiceRoomRoleMemberID: model.RoomRoleMember,
+		} {
+			ru := &model.RoomUser{}
+			ru.RoomID = testServiceRoomRoleRoomID
+			ru.UserID = userID
+			ru.Display = true
+			ru.RoomRole = roomRole
+			roomUsers = append(roomUsers, ru)
+		}
+		err = datastore.Provider(ctx).InsertRoomUsers(roomUsers)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomRole, err.Error())
+		}
+
+		newMessage := &model.Message{}
+		newMessage.MessageID = testServiceRoomRoleMessageID
+		newMessage.RoomID = testServiceRoomRoleRoomID
+		newMessage.UserID = testServiceRoomRoleMemberID
+		newMessage.Type = model.MessageTypeText
+		newMessage.Payload = []byte(`{"text":"hello"}`)
+		newMessage.CreatedTimestamp = nowTimestamp
+		newMessage.ModifiedTimestamp = nowTimestamp
+		err = datastore.Provider(ctx).InsertMessage(newMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpRoomRole, err.Error())
+		}
+	})
+
+	t.Run(TestServiceRoomRoleAuthz, func(t *testing.T) {
+		name := "name-update"
+		updateReq := &model.UpdateRoomRequest{}
+		updateReq.RoomID = testServiceRoomRoleRoomID
+		updateReq.Name = &name
+		_, errRes := UpdateRoom(memberCtx, updateReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to update the room", TestServiceRoomRoleAuthz)
+		}
+
+		deleteReq := &model.DeleteRoomRequest{}
+		deleteReq.RoomID = testServiceRoomRoleRoomID
+		errRes = DeleteRoom(adminCtx, deleteReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the admin not to be able to delete the room", TestServiceRoomRoleAuthz)
+		}
+
+		addReq := &model.AddRoomUsersRequest{}
+		addReq.RoomID = testServiceRoomRoleRoomID
+		addReq.UserIDs = []string{testServiceRoomRoleNonMemberID}
+		errRes = AddRoomUsers(memberCtx, addReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to add room users", TestServiceRoomRoleAuthz)
+		}
+
+		removeReq := &model.DeleteRoomUsersRequest{}
+		removeReq.RoomID = testServiceRoomRoleRoomID
+		removeReq.UserIDs = []string{testServiceRoomRoleAdminID}
+		errRes = DeleteRoomUsers(memberCtx, removeReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the member not to be able to remove the admin", TestServiceRoomRoleAuthz)
+		}
+	})
+
+	t.Run(TestServiceDeleteRoomUsersRole, func(t *testing.T) {
+		req := &model.DeleteRoomUsersRequest{}
+		req.RoomID = testServiceRoomRoleRoomID
+		req.UserIDs = []string{testServiceRoomRoleOwnerID}
+		errRes := DeleteRoomUsers(adminCtx, req)
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected the admin not to be able to remove the owner", TestServiceDeleteRoomUsersRole)
+		}
+
+		errRes = DeleteRoomUsers(ownerCtx, req)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the owner not to be able to leave the room", TestServiceDeleteRoomUsersRole)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomRoleRoomID, testServiceRoomRoleOwnerID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteRoomUsersRole, err.Error())
+		}
+		if ru == nil {
+			t.Fatalf("Failed to %s. Expected the owner to be a room user", TestServiceDeleteRoomUsersRole)
+		}
+
+		otherAdmin := &model.RoomUser{}
+		otherAdmin.RoomID = testServiceRoomRoleRoomID
+		otherAdmin.UserID = testServiceRoomRoleNonMemberID
+		otherAdmin.Display = true
+		otherAdmin.RoomRole = model.RoomRoleAdmin
+		err = datastore.Provider(ctx).InsertRoomUsers([]*model.RoomUser{otherAdmin})
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteRoomUsersRole, err.Error())
+		}
+
+		req.UserIDs = []string{testServiceRoomRoleNonMemberID}
+		errRes = DeleteRoomUsers(adminCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the admin not to be able to remove the other admin", TestServiceDeleteRoomUsersRole)
+		}
+
+		errRes = DeleteRoomUsers(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteRoomUsersRole, errRes.Message)
+		}
+	})
+
+	t.Run(TestServiceUpdateRoomUserRole, func(t *testing.T) {
+		readOnly := model.RoomRoleReadOnly
+		req := &model.UpdateRoomUserRequest{}
+		req.RoomID = testServiceRoomRoleRoomID
+		req.UserID = testServiceRoomRoleMemberID
+		req.RoomRole = &readOnly
+		errRes := UpdateRoomUser(adminCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUpdateRoomUserRole, errRes.Message)
+		}
+
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomRoleRoomID, testServiceRoomRoleMemberID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUpdateRoomUserRole, err.Error())
+		}
+		if ru.RoomRole != model.RoomRoleReadOnly {
+			t.Fatalf("Failed to %s. Expected room role to be %s, but it was %s", TestServiceUpdateRoomUserRole, model.RoomRoleReadOnly, ru.RoomRole)
+		}
+
+		sendReq := &model.SendMessageRequest{}
+		roomID := testServiceRoomRoleRoomID
+		userID := testServiceRoomRoleMemberID
+		messageType := model.MessageTypeText
+		sendReq.RoomID = &roomID
+		sendReq.UserID = &userID
+		sendReq.Type = &messageType
+		sendReq.Payload = []byte(`{"text":"hello"}`)
+		_, errRes = SendMessage(memberCtx, sendReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the read-only user not to be able to send messages", TestServiceUpdateRoomUserRole)
+		}
+	})
+
+	t.Run(TestServiceTransferRoomOwner, func(t *testing.T) {
+		req := &model.TransferRoomOwnerRequest{}
+		req.RoomID = testServiceRoomRoleRoomID
+		req.UserID = testServiceRoomRoleAdminID
+		errRes := TransferRoomOwner(adminCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the admin not to be able to transfer the ownership", TestServiceTransferRoomOwner)
+		}
+
+		errRes = TransferRoomOwner(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceTransferRoomOwner, errRes.Message)
+		}
+
+		room, err := datastore.Provider(ctx).SelectRoom(testServiceRoomRoleRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceTransferRoomOwner, err.Error())
+		}
+		if room.UserID != testServiceRoomRoleAdminID {
+			t.Fatalf("Failed to %s. Expected the owner to be %s, but it was %s", TestServiceTransferRoomOwner, testServiceRoomRoleAdminID, room.UserID)
+		}
+		ru, err := datastore.Provider(ctx).SelectRoomUser(testServiceRoomRoleRoomID, testServiceRoomRoleOwnerID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceTransferRoomOwner, err.Error())
+		}
+		if ru.RoomRole != model.RoomRoleAdmin {
+			t.Fatalf("Failed to %s. Expected the previous owner to be %s, but it was %s", TestServiceTransferRoomOwner, model.RoomRoleAdmin, ru.RoomRole)
+		}
+	})
+
+	t.Run(TestServiceDeleteMessage, func(t *testing.T) {
+		req := &model.DeleteMessageRequest{}
+		req.MessageID = testServiceRoomRoleMessageID
+		errRes := DeleteMessage(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteMessage, errRes.Message)
+		}
+
+		message, err := datastore.Provider(ctx).SelectMessage(testServiceRoomRoleMessageID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteMessage, err.Error())
+		}
+		if message.DeletedTimestamp == 0 {
+			t.Fatalf("Failed to %s. Expected the message to be deleted", TestServiceDeleteMessage)
+		}
+	})
+
+	t.Run(TestServiceTearDownRoomRole, func(t *testing.T) {
+		err := datastore.Provider(ctx).DeleteMessages(datastore.DeleteMessagesOptionFilterByMessageIDs([]string{testServiceRoomRoleMessageID}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownRoomRole, err.Error())
+		}
+
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testServiceRoomRoleRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err = datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownRoomRole, err.Error())
+		}
+	})
+}
@@ -6,8 +6,11 @@ import (
 	"time"
 
 	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
 	"github.com/betchi/tracer"
 )
 
@@ -22,8 +25,18 @@ func AddRoomUsers(ctx context.Context, req *model.AddRoomUsersRequest) *model.Er
 		return errRes
 	}
 
+	errRes = confirmRoomNotArchived(room, "Failed to create room users.")
+	if errRes != nil {
+		return errRes
+	}
+
 	req.Room = room
 
+	_, errRes = roomRoleAuthz(ctx, req.RoomID, "Failed to create room users.", model.RoomRoleOwner, model.RoomRoleAdmin)
+	if errRes != nil {
+		return errRes
+	}
+
 	errRes = confirmUserIDsExist(ctx, req.UserIDs, "userIds")
 	if errRes != nil {
 		errRes.Message = "Failed to create room users."
@@ -35,33 +48,79 @@ func AddRoomUsers(ctx context.Context, req *model.AddRoomUsersRequest) *model.Er
 		return errRes
 	}
 
-	if room.NotificationTopicID == "" {
-		notificationTopicID, errRes := createTopic(ctx, room.RoomID)
-		if errRes != nil {
-			errRes.Message = "Failed to create room users."
-			return errRes
-		}
-
-		room.NotificationTopicID = notificationTopicID
-		room.ModifiedTimestamp = time.Now().Unix()
-		err := datastore.Provider(ctx).UpdateRoom(room)
-		if err != nil {
-			return model.NewErrorResponse("Failed to create room users.", http.StatusInternalServerError, model.WithError(err))
-		}
+	errRes = prepareRoomTopic(ctx, room)
+	if errRes != nil {
+		errRes.Message = "Failed to create room users."
+		return errRes
 	}
 
 	roomUsers := req.GenerateRoomUsers()
-	err := datastore.Provider(ctx).InsertRoomUsers(roomUsers)
+	outboxEvents := model.NewOutboxEvents(
+		model.OutboxEventTypeRoomUser,
+		req.RoomID,
+		"",
+		model.OutboxDestinationProducer,
+	)
+	err := datastore.Provider(ctx).InsertRoomUsers(roomUsers, datastore.InsertRoomUsersOptionWithOutboxEvents(outboxEvents))
 	if err != nil {
 		return model.NewErrorResponse("Failed to create room users.", http.StatusInternalServerError, model.WithError(err))
 	}
 
-	go subscribeByRoomUsers(ctx, roomUsers)
-	go publishUserJoin(ctx, req.RoomID)
+	completeRoomUsersJoin(ctx, room, roomUsers, outboxEvents)
 
 	return nil
 }
 
+// prepareRoomTopic creates the notification topic of the room if it hasn't been created yet
+func prepareRoomTopic(ctx context.Context, room *model.Room) *model.ErrorResponse {
+	if room.NotificationTopicID != "" {
+		return nil
+	}
+
+	notificationTopicID, errRes := createTopic(ctx, room.RoomID)
+	if errRes != nil {
+		return errRes
+	}
+
+	room.NotificationTopicID = notificationTopicID
+	room.ModifiedTimestamp = time.Now().Unix()
+	err := datastore.Provider(ctx).UpdateRoom(room)
+	if err != nil {
+		return model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+// completeRoomUsersJoin does the rest of the join after the room users were inserted.
+// It records the audit log, subscribes the devices of the users to the room topic, relays the outbox events
+// and sends the system message of the users who joined.
+func completeRoomUsersJoin(ctx context.Context, room *model.Room, roomUsers []*model.RoomUser, outboxEvents []*model.OutboxEvent) {
+	beforeUserIDs := roomMemberIDs(room)
+	afterUserIDs := append([]string{}, beforeUserIDs...)
+	for _, roomUser := range roomUsers {
+		afterUserIDs = append(afterUserIDs, roomUser.UserID)
+	}
+	writeAuditLog(
+		ctx,
+		model.AuditActionAddRoomUsers,
+		model.AuditTargetTypeRoom,
+		room.RoomID,
+		map[string][]string{"userIds": beforeUserIDs},
+		map[string][]string{"userIds": utils.RemoveDuplicateString(afterUserIDs)},
+	)
+
+	go subscribeByRoomUsers(ctx, roomUsers)
+	relayOutboxEventsInBackground(ctx, outboxEvents)
+
+	// The users who were already in the room are not announced again
+	joinedUserIDs, _ := model.DiffUserIDs(beforeUserIDs, afterUserIDs)
+	if len(joinedUserIDs) > 0 {
+		requestUserID, _ := ctx.Value(config.CtxUserID).(string)
+		sendSystemMessages(ctx, room, model.NewJoinSystemMessage(room.RoomID, requestUserID, joinedUserIDs))
+	}
+}
+
 // RetrieveRoomUsers retrieves room users
 func RetrieveRoomUsers(ctx context.Context, req *model.RetrieveRoomUsersRequest) (*model.RoomUsersResponse, *model.ErrorResponse) {
 	span := tracer.StartSpan(ctx, "RetrieveRoomUsers", "service")
@@ -117,12 +176,42 @@ func UpdateRoomUser(ctx context.Context, req *model.UpdateRoomUserRequest) *mode
 	span := tracer.StartSpan(ctx, "UpdateRoomUser", "service")
 	defer tracer.Finish(span)
 
+	errRes := req.Validate()
+	if errRes != nil {
+		return errRes
+	}
+
 	ru, errRes := confirmRoomUserExist(ctx, req.RoomID, req.UserID)
 	if errRes != nil {
 		errRes.Message = "Failed to update room user."
 		return errRes
 	}
 
+	// The personal settings are updated only by the room user themselves
+	requestUserID := ""
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	if clientID == "" {
+		requestUserID, _ = ctx.Value(config.CtxUserID).(string)
+	}
+	errRes = req.ValidatePersonalSettings(requestUserID)
+	if errRes != nil {
+		return errRes
+	}
+
+	if req.RoomRole != nil {
+		requestRoomUser, errRes := selectRequestRoomUser(ctx, req.RoomID, "Failed to update room user.")
+		if errRes != nil {
+			return errRes
+		}
+
+		errRes = req.ValidateRoomRole(requestRoomUser, ru)
+		if errRes != nil {
+			return errRes
+		}
+	}
+
+	beforeRoomRole := ru.RoomRole
+	beforeMuted := ru.Muted
 	ru.UpdateRoomUser(req)
 
 	err := datastore.Provider(ctx).UpdateRoomUser(ru)
@@ -130,6 +219,34 @@ func UpdateRoomUser(ctx context.Context, req *model.UpdateRoomUserRequest) *mode
 		return model.NewErrorResponse("Failed to update room user.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	// The devices of the user subscribe the room topic only while the room is not muted
+	if ru.Muted != beforeMuted {
+		if ru.Muted {
+			err = datastore.Provider(ctx).DeleteSubscriptions(
+				datastore.DeleteSubscriptionsOptionWithLogicalDeleted(time.Now().Unix()),
+				datastore.DeleteSubscriptionsOptionFilterByRoomID(ru.RoomID),
+				datastore.DeleteSubscriptionsOptionFilterByUserID(ru.UserID),
+			)
+			if err != nil {
+				return model.NewErrorResponse("Failed to update room user.", http.StatusInternalServerError, model.WithError(err))
+			}
+			go unsubscribeMutedRoomUser(ctx, ru)
+		} else {
+			go subscribeByRoomUsers(ctx, []*model.RoomUser{ru})
+		}
+	}
+
+	if ru.RoomRole != beforeRoomRole {
+		writeAuditLog(
+			ctx,
+			model.AuditActionUpdateRoomUserRole,
+			model.AuditTargetTypeRoom,
+			req.RoomID,
+			map[string]string{"userId": ru.UserID, "roomRole": string(beforeRoomRole)},
+			map[string]string{"userId": ru.UserID, "roomRole": string(ru.RoomRole)},
+		)
+	}
+
 	// var p json.RawMessage
 	// err = json.Unmarshal([]byte("{}"), &p)
 	// m := &model.Message{
@@ -157,7 +274,44 @@ func DeleteRoomUsers(ctx context.Context, req *model.DeleteRoomUsersRequest) *mo
 
 	req.Room = room
 
-	err := datastore.Provider(ctx).DeleteRoomUsers(
+	requestUserID, _ := ctx.Value(config.CtxUserID).(string)
+	errRes = req.ValidateCanLeft(requestUserID)
+	if errRes != nil {
+		return errRes
+	}
+
+	requestRoomUser, errRes := selectRequestRoomUser(ctx, req.RoomID, "Failed to delete room users.")
+	if errRes != nil {
+		return errRes
+	}
+
+	roomUsers, err := datastore.Provider(ctx).SelectRoomUsers(
+		datastore.SelectRoomUsersOptionWithRoomID(req.RoomID),
+		datastore.SelectRoomUsersOptionWithUserIDs(req.UserIDs),
+	)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete room users.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	// The room must not be left without the owner even if the owner leaves by themselves
+	for _, ru := range roomUsers {
+		if ru.RoomRole == model.RoomRoleOwner {
+			invalidParams := []*scpb.InvalidParam{
+				&scpb.InvalidParam{
+					Name:   "userIds",
+					Reason: "The owner can not be removed from the room. Transfer the ownership of the room first.",
+				},
+			}
+			return model.NewErrorResponse("Failed to delete room users.", http.StatusBadRequest, model.WithInvalidParams(invalidParams))
+		}
+	}
+
+	errRes = req.ValidateRoomRole(requestRoomUser, roomUsers)
+	if errRes != nil {
+		return errRes
+	}
+
+	err = datastore.Provider(ctx).DeleteRoomUsers(
 		datastore.DeleteRoomUsersOptionFilterByRoomIDs([]string{req.RoomID}),
 		datastore.DeleteRoomUsersOptionFilterByUserIDs(req.UserIDs),
 	)
@@ -165,6 +319,31 @@ func DeleteRoomUsers(ctx context.Context, req *model.DeleteRoomUsersRequest) *mo
 		return model.NewErrorResponse("Failed to delete room users.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	deletedUserIDs := make(map[string]struct{}, len(req.UserIDs))
+	for _, userID := range req.UserIDs {
+		deletedUserIDs[userID] = struct{}{}
+	}
+	beforeUserIDs := roomMemberIDs(room)
+	afterUserIDs := make([]string, 0, len(beforeUserIDs))
+	for _, userID := range beforeUserIDs {
+		if _, ok := deletedUserIDs[userID]; !ok {
+			afterUserIDs = append(afterUserIDs, userID)
+		}
+	}
+	writeAuditLog(
+		ctx,
+		model.AuditActionDeleteRoomUsers,
+		model.AuditTargetTypeRoom,
+		req.RoomID,
+		map[string][]string{"userIds": beforeUserIDs},
+		map[string][]string{"userIds": afterUserIDs},
+	)
+
+	_, leftUserIDs := model.DiffUserIDs(beforeUserIDs, afterUserIDs)
+	if len(leftUserIDs) > 0 {
+		sendSystemMessages(ctx, room, model.NewLeaveSystemMessage(req.RoomID, requestUserID, leftUserIDs))
+	}
+
 	go func() {
 		rus, err := datastore.Provider(ctx).SelectRoomUsers(
 			datastore.SelectRoomUsersOptionWithRoomID(req.RoomID),
@@ -3,10 +3,10 @@ package service
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"testing"
 	"time"
 
-	_ "github.com/mattn/go-sqlite3"
 	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
@@ -22,6 +22,11 @@ const (
 	TestServiceDeleteRoom           = "[service] delete room test"
 	TestServiceRetrieveRoomMessages = "[service] retrieve room messages test"
 	TestServiceTearDownRoom         = "[service] tear down room"
+	TestServiceArchiveRoom          = "[service] archive room test"
+	TestServiceUnarchiveRoom        = "[service] unarchive room test"
+	TestServiceRestoreRoom          = "[service] restore room test"
+	testServiceArchiveRoomRoomID    = "archive-room-service-room-id-0001"
+	testServiceArchiveRoomOwnerID   = "archive-room-service-user-id-0001"
 )
 
 func TestRoom(t *testing.T) {
@@ -178,6 +183,14 @@ func TestRoom(t *testing.T) {
 		if res == nil {
 			t.Fatalf("Failed to %s. Expected res to be not nil, but it was nil", TestServiceUpdateRoom)
 		}
+
+		roomUser, err := datastore.Provider(ctx).SelectRoomUser(req.RoomID, "room-service-user-id-0002")
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceUpdateRoom, err.Error())
+		}
+		if roomUser == nil {
+			t.Fatalf("Failed to %s. Expected the room users to be kept without userIds, but they were replaced", TestServiceUpdateRoom)
+		}
 	})
 
 	t.Run(TestServiceDeleteRoom, func(t *testing.T) {
@@ -234,3 +247,126 @@ func TestRoom(t *testing.T) {
 		}
 	})
 }
+
+func TestArchiveRoom(t *testing.T) {
+	ownerCtx := context.WithValue(ctx, config.CtxUserID, testServiceArchiveRoomOwnerID)
+
+	nowTimestamp := time.Now().Unix()
+	newUser := &model.User{}
+	newUser.UserID = testServiceArchiveRoomOwnerID
+	newUser.MetaData = []byte(`{"key":"value"}`)
+	newUser.CreatedTimestamp = nowTimestamp
+	newUser.ModifiedTimestamp = nowTimestamp
+	err := datastore.Provider(ctx).InsertUser(newUser)
+	if err != nil {
+		t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceArchiveRoom, err.Error())
+	}
+
+	newRoom := &model.Room{}
+	newRoom.RoomID = testServiceArchiveRoomRoomID
+	newRoom.UserID = testServiceArchiveRoomOwnerID
+	newRoom.Type = scpb.RoomType_PrivateRoom
+	newRoom.MetaData = []byte(`{"key":"value"}`)
+	newRoom.CreatedTimestamp = nowTimestamp
+	newRoom.ModifiedTimestamp = nowTimestamp
+	ru := &model.RoomUser{}
+	ru.RoomID = testServiceArchiveRoomRoomID
+	ru.UserID = testServiceArchiveRoomOwnerID
+	ru.Display = true
+	ru.RoomRole = model.RoomRoleOwner
+	err = datastore.Provider(ctx).InsertRoom(newRoom, datastore.InsertRoomOptionWithRoomUser([]*model.RoomUser{ru}))
+	if err != nil {
+		t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceArchiveRoom, err.Error())
+	}
+
+	retrieveUserRoomIDs := func(includeArchived bool) []string {
+		req := &model.RetrieveUserRoomsRequest{}
+		req.UserID = testServiceArchiveRoomOwnerID
+		req.Limit = 10
+		req.IncludeArchived = includeArchived
+		res, errRes := RetrieveUserRooms(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to retrieve user rooms. %s", errRes.Message)
+		}
+		roomIDs := make([]string, len(res.Rooms))
+		for i, room := range res.Rooms {
+			roomIDs[i] = room.RoomID
+		}
+		return roomIDs
+	}
+
+	t.Run(TestServiceArchiveRoom, func(t *testing.T) {
+		req := &model.ArchiveRoomRequest{}
+		req.RoomID = testServiceArchiveRoomRoomID
+		room, errRes := ArchiveRoom(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceArchiveRoom, errRes.Message)
+		}
+		if room.ArchivedTimestamp == 0 {
+			t.Fatalf("Failed to %s. Expected the room to be archived", TestServiceArchiveRoom)
+		}
+
+		addReq := &model.AddRoomUsersRequest{}
+		addReq.RoomID = testServiceArchiveRoomRoomID
+		addReq.UserIDs = []string{testServiceArchiveRoomOwnerID}
+		errRes = AddRoomUsers(ownerCtx, addReq)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected the archived room to be read-only", TestServiceArchiveRoom)
+		}
+
+		if len(retrieveUserRoomIDs(false)) != 0 {
+			t.Fatalf("Failed to %s. Expected the archived room to be hidden", TestServiceArchiveRoom)
+		}
+		if len(retrieveUserRoomIDs(true)) != 1 {
+			t.Fatalf("Failed to %s. Expected the archived room to be included", TestServiceArchiveRoom)
+		}
+	})
+
+	t.Run(TestServiceUnarchiveRoom, func(t *testing.T) {
+		req := &model.ArchiveRoomRequest{}
+		req.RoomID = testServiceArchiveRoomRoomID
+		room, errRes := UnarchiveRoom(ownerCtx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceUnarchiveRoom, errRes.Message)
+		}
+		if room.ArchivedTimestamp != 0 {
+			t.Fatalf("Failed to %s. Expected the room to be unarchived", TestServiceUnarchiveRoom)
+		}
+
+		if len(retrieveUserRoomIDs(false)) != 1 {
+			t.Fatalf("Failed to %s. Expected the unarchived room to be listed", TestServiceUnarchiveRoom)
+		}
+	})
+
+	t.Run(TestServiceRestoreRoom, func(t *testing.T) {
+		req := &model.RestoreRoomRequest{}
+		req.RoomID = testServiceArchiveRoomRoomID
+		_, errRes := RestoreRoom(ctx, req)
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected the room not deleted not to be restored", TestServiceRestoreRoom)
+		}
+
+		deleteReq := &model.DeleteRoomRequest{}
+		deleteReq.RoomID = testServiceArchiveRoomRoomID
+		errRes = DeleteRoom(ownerCtx, deleteReq)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRestoreRoom, errRes.Message)
+		}
+
+		room, errRes := RestoreRoom(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRestoreRoom, errRes.Message)
+		}
+		if len(room.Users) != 1 {
+			t.Fatalf("Failed to %s. Expected the room to be restored with the room user", TestServiceRestoreRoom)
+		}
+
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testServiceArchiveRoomRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err := datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceRestoreRoom, err.Error())
+		}
+	})
+}
@@ -0,0 +1,268 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+// ScheduleMessage creates message sent at the scheduled time
+func ScheduleMessage(ctx context.Context, req *model.SendMessageRequest) (*model.ScheduledMessage, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "ScheduleMessage", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if req.ScheduledTimestamp == 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "scheduledTimestamp",
+				Reason: "scheduledTimestamp is required, but it's empty.",
+			},
+		}
+		return nil, model.NewErrorResponse("Failed to schedule message.", http.StatusBadRequest, model.WithInvalidParams(invalidParams))
+	}
+
+	_, errRes = confirmRoomExist(ctx, *req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to schedule message."
+		return nil, errRes
+	}
+
+	_, errRes = confirmUserExist(ctx, *req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to schedule message."
+		return nil, errRes
+	}
+
+	scheduledMessage := req.GenerateScheduledMessage()
+
+	_, errRes = confirmMessageNotExist(ctx, scheduledMessage.ScheduledMessageID)
+	if errRes != nil {
+		errRes.Message = "Failed to schedule message."
+		return nil, errRes
+	}
+
+	err := datastore.Provider(ctx).InsertScheduledMessage(scheduledMessage)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to schedule message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return scheduledMessage, nil
+}
+
+// RetrieveScheduledMessages retrieves pending scheduled messages of the room
+func RetrieveScheduledMessages(ctx context.Context, req *model.RetrieveScheduledMessagesRequest) (*model.ScheduledMessagesResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveScheduledMessages", "service")
+	defer tracer.Finish(span)
+
+	_, errRes := confirmRoomExist(ctx, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve scheduled messages."
+		return nil, errRes
+	}
+
+	scheduledMessages, err := datastore.Provider(ctx).SelectScheduledMessages(
+		req.Limit,
+		req.Offset,
+		datastore.SelectScheduledMessagesOptionFilterByRoomID(req.RoomID),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve scheduled messages.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	allCount, err := datastore.Provider(ctx).SelectCountScheduledMessages(
+		datastore.SelectScheduledMessagesOptionFilterByRoomID(req.RoomID),
+	)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve scheduled messages.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.ScheduledMessagesResponse{}
+	res.ScheduledMessages = scheduledMessages
+	res.AllCount = allCount
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+
+	return res, nil
+}
+
+// UpdateScheduledMessage reschedules the scheduled message
+func UpdateScheduledMessage(ctx context.Context, req *model.UpdateScheduledMessageRequest) (*model.ScheduledMessage, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "UpdateScheduledMessage", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	scheduledMessage, errRes := confirmPendingScheduledMessageExist(ctx, req.ScheduledMessageID, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to update scheduled message."
+		return nil, errRes
+	}
+
+	scheduledMessage.Scheduled = req.ScheduledTimestamp
+	scheduledMessage.Modified = time.Now().Unix()
+	err := datastore.Provider(ctx).UpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to update scheduled message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return scheduledMessage, nil
+}
+
+// DeleteScheduledMessage cancels the scheduled message
+func DeleteScheduledMessage(ctx context.Context, req *model.DeleteScheduledMessageRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "DeleteScheduledMessage", "service")
+	defer tracer.Finish(span)
+
+	scheduledMessage, errRes := confirmPendingScheduledMessageExist(ctx, req.ScheduledMessageID, req.RoomID)
+	if errRes != nil {
+		errRes.Message = "Failed to delete scheduled message."
+		return errRes
+	}
+
+	nowTimestamp := time.Now().Unix()
+	scheduledMessage.Modified = nowTimestamp
+	scheduledMessage.Deleted = nowTimestamp
+	err := datastore.Provider(ctx).UpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete scheduled message.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+// confirmPendingScheduledMessageExist confirms the scheduled message is not sent yet, belongs to the room
+// and is operated by its sender or by an admin client
+func confirmPendingScheduledMessageExist(ctx context.Context, scheduledMessageID, roomID string) (*model.ScheduledMessage, *model.ErrorResponse) {
+	scheduledMessage, err := datastore.Provider(ctx).SelectScheduledMessage(scheduledMessageID)
+	if err != nil {
+		return nil, model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+	if scheduledMessage == nil || scheduledMessage.RoomID != roomID {
+		return nil, model.NewErrorResponse("", http.StatusNotFound)
+	}
+	if scheduledMessage.Sent != 0 {
+		invalidParams := []*scpb.InvalidParam{
+			&scpb.InvalidParam{
+				Name:   "scheduledMessageId",
+				Reason: fmt.Sprintf("That scheduled message is already sent. scheduledMessageId[%s]", scheduledMessageID),
+			},
+		}
+		return nil, model.NewErrorResponse("", http.StatusBadRequest, model.WithInvalidParams(invalidParams))
+	}
+
+	clientID, _ := ctx.Value(config.CtxClientID).(string)
+	userID, _ := ctx.Value(config.CtxUserID).(string)
+	if clientID == "" && userID != scheduledMessage.UserID {
+		return nil, model.NewErrorResponse("", http.StatusForbidden)
+	}
+
+	return scheduledMessage, nil
+}
+
+// RunScheduledMessageDispatcher sends the scheduled messages at the due time until ctx is done.
+// Schedules are persisted, so the messages which came due while the server was down are sent on the next tick.
+func RunScheduledMessageDispatcher(ctx context.Context) {
+	ticker := time.NewTicker(config.ScheduledMessageDispatchIntervalSecond * time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			for _, workspace := range backgroundJobWorkspaces(ctx) {
+				dispatchScheduledMessages(context.WithValue(ctx, config.CtxWorkspace, workspace))
+			}
+		}
+	}
+}
+
+// backgroundJobWorkspaces returns the workspaces background jobs run for.
+// In dynamic mode they are all of the registered workspaces.
+func backgroundJobWorkspaces(ctx context.Context) []string {
+	cfg := config.Config()
+	if !cfg.Datastore.Dynamic {
+		return []string{cfg.Datastore.Database}
+	}
+	return registeredWorkspaces(ctx)
+}
+
+func dispatchScheduledMessages(ctx context.Context) {
+	span := tracer.StartSpan(ctx, "dispatchScheduledMessages", "service")
+	defer tracer.Finish(span)
+
+	scheduledMessages, err := datastore.Provider(ctx).SelectScheduledMessages(
+		config.ScheduledMessageDispatchLimit,
+		0,
+		datastore.SelectScheduledMessagesOptionFilterByDueTimestamp(time.Now().Unix()),
+	)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	d := utils.NewDispatcher(10)
+	for _, scheduledMessage := range scheduledMessages {
+		ctx = context.WithValue(ctx, config.CtxScheduledMessage, scheduledMessage)
+		d.Work(ctx, func(ctx context.Context) {
+			sm := ctx.Value(config.CtxScheduledMessage).(*model.ScheduledMessage)
+			sendScheduledMessage(ctx, sm)
+		})
+	}
+	d.Wait()
+}
+
+func sendScheduledMessage(ctx context.Context, scheduledMessage *model.ScheduledMessage) {
+	span := tracer.StartSpan(ctx, "sendScheduledMessage", "service")
+	defer tracer.Finish(span)
+
+	ctx = context.WithValue(ctx, config.CtxUserID, scheduledMessage.UserID)
+
+	// The previous dispatch may have sent the message but failed to mark it as sent
+	message, err := datastore.Provider(ctx).SelectMessage(scheduledMessage.ScheduledMessageID)
+	if err != nil {
+		logger.Error(err.Error())
+		return
+	}
+
+	nowTimestamp := time.Now().Unix()
+	if message == nil {
+		_, errRes := SendMessage(ctx, scheduledMessage.ConvertToSendMessageRequest())
+		if errRes != nil {
+			if errRes.Status >= http.StatusInternalServerError {
+				// Leave it pending, it's retried on the next tick
+				logger.Error(fmt.Sprintf("Failed to send scheduled message. scheduledMessageId[%s] %s", scheduledMessage.ScheduledMessageID, errRes.Message))
+				return
+			}
+
+			logger.Warn(fmt.Sprintf("Scheduled message was discarded because it can not be sent. scheduledMessageId[%s] %s", scheduledMessage.ScheduledMessageID, errRes.Message))
+			scheduledMessage.Deleted = nowTimestamp
+		}
+	}
+
+	if scheduledMessage.Deleted == 0 {
+		scheduledMessage.Sent = nowTimestamp
+	}
+	scheduledMessage.Modified = nowTimestamp
+	err = datastore.Provider(ctx).UpdateScheduledMessage(scheduledMessage)
+	if err != nil {
+		logger.Error(err.Error())
+	}
+}
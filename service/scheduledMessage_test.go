@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpScheduledMessage     = "[service] set up scheduled message"
+	TestServiceScheduleMessage           = "[service] schedule message test"
+	TestServiceUpdateScheduledMessage    = "[service] update scheduled message test"
+	TestServiceDeleteScheduledMessage    = "[service] delete scheduled message test"
+	TestServiceDispatchScheduledMessages = "[service] dispatch scheduled messages test"
+)
+
+func TestScheduledMessage(t *testing.T) {
+	roomID := "scheduled-message-service-room-id-0001"
+	userID := "scheduled-message-service-user-id-0001"
+	ctx := context.WithValue(ctx, config.CtxUserID, userID)
+
+	t.Run(TestServiceSetUpScheduledMessage, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+
+		newUser := &model.User{}
+		newUser.UserID = userID
+		newUser.MetaData = []byte(`{"key":"value"}`)
+		newUser.CreatedTimestamp = nowTimestamp
+		newUser.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertUser(newUser)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpScheduledMessage, err.Error())
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = roomID
+		newRoom.UserID = userID
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err = datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpScheduledMessage, err.Error())
+		}
+
+		ru := &model.RoomUser{}
+		ru.RoomID = roomID
+		ru.UserID = userID
+		err = datastore.Provider(ctx).InsertRoomUsers([]*model.RoomUser{ru})
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpScheduledMessage, err.Error())
+		}
+	})
+
+	scheduledMessageIDs := []string{}
+	t.Run(TestServiceScheduleMessage, func(t *testing.T) {
+		messageType := model.MessageTypeText
+
+		for i := 0; i < 2; i++ {
+			req := &model.SendMessageRequest{}
+			req.RoomID = &roomID
+			req.UserID = &userID
+			req.Type = &messageType
+			req.Payload = []byte(`{"text":"scheduled"}`)
+			req.ScheduledTimestamp = time.Now().Unix() + 3600
+			scheduledMessage, errRes := ScheduleMessage(ctx, req)
+			if errRes != nil {
+				t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceScheduleMessage, errRes.Message)
+			}
+			scheduledMessageIDs = append(scheduledMessageIDs, scheduledMessage.ScheduledMessageID)
+		}
+
+		req := &model.SendMessageRequest{}
+		req.RoomID = &roomID
+		req.UserID = &userID
+		req.Type = &messageType
+		req.Payload = []byte(`{"text":"scheduled"}`)
+		req.ScheduledTimestamp = time.Now().Unix() - 60
+		_, errRes := ScheduleMessage(ctx, req)
+		if errRes == nil {
+			t.Fatalf("Failed to %s. Expected errRes to be not nil, but it was nil", TestServiceScheduleMessage)
+		}
+
+		res, errRes := RetrieveScheduledMessages(ctx, &model.RetrieveScheduledMessagesRequest{RoomID: roomID, Limit: 10})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceScheduleMessage, errRes.Message)
+		}
+		if res.AllCount != 2 {
+			t.Fatalf("Failed to %s. Expected res.AllCount to be 2, but it was %d", TestServiceScheduleMessage, res.AllCount)
+		}
+	})
+
+	t.Run(TestServiceUpdateScheduledMessage, func(t *testing.T) {
+		req := &model.UpdateScheduledMessageRequest{}
+		req.ScheduledMessageID = scheduledMessageIDs[0]
+		req.RoomID = roomID
+		req.ScheduledTimestamp = time.Now().Unix() + 7200
+		scheduledMessage, errRes := UpdateScheduledMessage(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceUpdateScheduledMessage, errRes.Message)
+		}
+		if scheduledMessage.Scheduled != req.ScheduledTimestamp {
+			t.Fatalf("Failed to %s. Expected scheduledMessage.Scheduled to be %d, but it was %d", TestServiceUpdateScheduledMessage, req.ScheduledTimestamp, scheduledMessage.Scheduled)
+		}
+
+		otherUserCtx := context.WithValue(ctx, config.CtxUserID, "scheduled-message-service-user-id-0002")
+		_, errRes = UpdateScheduledMessage(otherUserCtx, req)
+		if errRes == nil || errRes.Status != http.StatusForbidden {
+			t.Fatalf("Failed to %s. Expected errRes.Status to be %d", TestServiceUpdateScheduledMessage, http.StatusForbidden)
+		}
+	})
+
+	t.Run(TestServiceDeleteScheduledMessage, func(t *testing.T) {
+		req := &model.DeleteScheduledMessageRequest{}
+		req.ScheduledMessageID = scheduledMessageIDs[1]
+		req.RoomID = roomID
+		errRes := DeleteScheduledMessage(ctx, req)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. Expected errRes to be nil, but it was not nil [%s]", TestServiceDeleteScheduledMessage, errRes.Message)
+		}
+
+		errRes = DeleteScheduledMessage(ctx, req)
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected errRes.Status to be %d", TestServiceDeleteScheduledMessage, http.StatusNotFound)
+		}
+	})
+
+	t.Run(TestServiceDispatchScheduledMessages, func(t *testing.T) {
+		scheduledMessage, err := datastore.Provider(ctx).SelectScheduledMessage(scheduledMessageIDs[0])
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceDispatchScheduledMessages, err.Error())
+		}
+		scheduledMessage.Scheduled = time.Now().Unix() - 1
+		err = datastore.Provider(ctx).UpdateScheduledMessage(scheduledMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceDispatchScheduledMessages, err.Error())
+		}
+
+		dispatchScheduledMessages(ctx)
+
+		message, err := datastore.Provider(ctx).SelectMessage(scheduledMessageIDs[0])
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceDispatchScheduledMessages, err.Error())
+		}
+		if message == nil {
+			t.Fatalf("Failed to %s. Expected message to be not nil, but it was nil", TestServiceDispatchScheduledMessages)
+		}
+
+		count, err := datastore.Provider(ctx).SelectCountScheduledMessages(datastore.SelectScheduledMessagesOptionFilterByRoomID(roomID))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceDispatchScheduledMessages, err.Error())
+		}
+		if count != 0 {
+			t.Fatalf("Failed to %s. Expected count to be 0, but it was %d", TestServiceDispatchScheduledMessages, count)
+		}
+	})
+}
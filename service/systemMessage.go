@@ -0,0 +1,48 @@
+package service
+
+import (
+	"context"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+// sendSystemMessages inserts the system messages into the room history and relays them to the room users.
+// They are not pushed to the devices, and the errors are only logged not to fail the change which has already been done.
+func sendSystemMessages(ctx context.Context, room *model.Room, messages ...*model.Message) {
+	span := tracer.StartSpan(ctx, "sendSystemMessages", "service")
+	defer tracer.Finish(span)
+
+	// The archived rooms are read-only
+	if room.ArchivedTimestamp != 0 {
+		return
+	}
+
+	relayedOutboxEvents := []*model.OutboxEvent{}
+	for _, message := range messages {
+		outboxEvents := model.NewOutboxEvents(
+			model.OutboxEventTypeMessage,
+			message.RoomID,
+			message.MessageID,
+			model.OutboxDestinationProducer,
+		)
+		// The system messages are inserted as they are not to replace the last message of the room
+		// and not to count up the unread counts, because they are not the messages sent by the users
+		err := datastore.Provider(ctx).InsertMessage(
+			message,
+			datastore.InsertMessageOptionAsIs(true),
+			datastore.InsertMessageOptionWithOutboxEvents(outboxEvents),
+		)
+		if err != nil {
+			logger.Error(err.Error())
+			tracer.SetError(span, err)
+			continue
+		}
+
+		relayedOutboxEvents = append(relayedOutboxEvents, outboxEvents...)
+	}
+
+	relayOutboxEventsInBackground(ctx, relayedOutboxEvents)
+}
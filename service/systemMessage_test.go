@@ -0,0 +1,97 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpSystemMessage = "[service] set up systemMessage"
+	TestServiceSendSystemMessages = "[service] send system messages test"
+)
+
+func TestSystemMessage(t *testing.T) {
+	testRoomID := "system-message-service-room-id-0001"
+	testUserIDs := []string{"system-message-service-user-id-0001", "system-message-service-user-id-0002"}
+	nowTimestamp := time.Now().Unix()
+
+	t.Run(TestServiceSetUpSystemMessage, func(t *testing.T) {
+		roomUsers := make([]*model.RoomUser, 0, len(testUserIDs))
+		for _, userID := range testUserIDs {
+			newUser := &model.User{}
+			newUser.UserID = userID
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpSystemMessage, err.Error())
+			}
+
+			ru := &model.RoomUser{}
+			ru.RoomID = testRoomID
+			ru.UserID = userID
+			ru.Display = true
+			roomUsers = append(roomUsers, ru)
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testRoomID
+		newRoom.UserID = testUserIDs[0]
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.LastMessage = "system-message-service-last-message"
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertRoom(newRoom, datastore.InsertRoomOptionWithRoomUser(roomUsers))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpSystemMessage, err.Error())
+		}
+	})
+
+	t.Run(TestServiceSendSystemMessages, func(t *testing.T) {
+		room, err := datastore.Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSendSystemMessages, err.Error())
+		}
+
+		message := model.NewJoinSystemMessage(testRoomID, testUserIDs[0], testUserIDs[1:])
+		sendSystemMessages(ctx, room, message)
+
+		insertedMessage, err := datastore.Provider(ctx).SelectMessage(message.MessageID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSendSystemMessages, err.Error())
+		}
+		if insertedMessage == nil {
+			t.Fatalf("Failed to %s. Expected the system message to be inserted, but it was not", TestServiceSendSystemMessages)
+		}
+		if insertedMessage.Type != model.MessageTypeUpdateRoomUser {
+			t.Fatalf("Failed to %s. Expected message.Type to be \"%s\", but it was \"%s\"", TestServiceSendSystemMessages, model.MessageTypeUpdateRoomUser, insertedMessage.Type)
+		}
+
+		room, err = datastore.Provider(ctx).SelectRoom(testRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSendSystemMessages, err.Error())
+		}
+		if room.LastMessage != "system-message-service-last-message" {
+			t.Fatalf("Failed to %s. Expected room.LastMessage not to be updated, but it was \"%s\"", TestServiceSendSystemMessages, room.LastMessage)
+		}
+		if room.LastMessageID == message.MessageID {
+			t.Fatalf("Failed to %s. Expected room.LastMessageID not to be the system message", TestServiceSendSystemMessages)
+		}
+
+		for _, userID := range testUserIDs {
+			roomUser, err := datastore.Provider(ctx).SelectRoomUser(testRoomID, userID)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSendSystemMessages, err.Error())
+			}
+			if roomUser.UnreadCount != 0 {
+				t.Fatalf("Failed to %s. Expected roomUser.UnreadCount not to be counted up, but it was %d", TestServiceSendSystemMessages, roomUser.UnreadCount)
+			}
+		}
+	})
+}
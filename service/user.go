@@ -4,12 +4,15 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"time"
 
+	"github.com/swagchat/chat-api/config"
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
 	"github.com/swagchat/chat-api/notification"
+	"github.com/swagchat/chat-api/storage"
 	"github.com/betchi/tracer"
 )
 
@@ -200,33 +203,213 @@ func DeleteUser(ctx context.Context, req *model.DeleteUserRequest) *model.ErrorR
 // 	return userUnreadCount, nil
 // }
 
+// RetrieveUserData retrieves all of the data of the user
+func RetrieveUserData(ctx context.Context, req *model.RetrieveUserDataRequest) (*model.UserDataResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveUserData", "service")
+	defer tracer.Finish(span)
+
+	user, errRes := confirmUserExist(
+		ctx,
+		req.UserID,
+		datastore.SelectUserOptionWithBlocks(true),
+		datastore.SelectUserOptionWithDevices(true),
+		datastore.SelectUserOptionWithRoles(true),
+	)
+	if errRes != nil {
+		errRes.Message = "Failed to get user data."
+		return nil, errRes
+	}
+
+	dsp := datastore.Provider(ctx)
+	roomUsers, err := dsp.SelectRoomUsers(datastore.SelectRoomUsersOptionWithUserIDs([]string{req.UserID}))
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to get user data.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	messages := make([]*model.Message, 0)
+	for offset := int32(0); ; offset += exportPageSize {
+		page, err := dsp.SelectMessages(exportPageSize, offset, datastore.SelectMessagesOptionFilterByUserID(req.UserID))
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to get user data.", http.StatusInternalServerError, model.WithError(err))
+		}
+		messages = append(messages, page...)
+		if int32(len(page)) < exportPageSize {
+			break
+		}
+	}
+
+	scheduledMessages := make([]*model.ScheduledMessage, 0)
+	for offset := int32(0); ; offset += exportPageSize {
+		page, err := dsp.SelectScheduledMessages(exportPageSize, offset, datastore.SelectScheduledMessagesOptionFilterByUserID(req.UserID))
+		if err != nil {
+			return nil, model.NewErrorResponse("Failed to get user data.", http.StatusInternalServerError, model.WithError(err))
+		}
+		scheduledMessages = append(scheduledMessages, page...)
+		if int32(len(page)) < exportPageSize {
+			break
+		}
+	}
+
+	assets, err := selectUserAssets(ctx, req.UserID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to get user data.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	if roomUsers == nil {
+		roomUsers = make([]*model.RoomUser, 0)
+	}
+
+	return &model.UserDataResponse{
+		User:              user,
+		RoomUsers:         roomUsers,
+		Messages:          messages,
+		ScheduledMessages: scheduledMessages,
+		Assets:            assets,
+		Exported:          time.Now().UTC().Format(time.RFC3339),
+	}, nil
+}
+
+// EraseUser erases all of the data of the user physically.
+// The messages of the user are deleted or attributed to model.ErasedUserID with their payload erased by the erasure of the request.
+func EraseUser(ctx context.Context, req *model.EraseUserRequest) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "EraseUser", "service")
+	defer tracer.Finish(span)
+
+	errRes := req.Validate()
+	if errRes != nil {
+		return errRes
+	}
+
+	user, errRes := confirmUserExist(ctx, req.UserID)
+	if errRes != nil {
+		errRes.Message = "Failed to erase user."
+		return errRes
+	}
+
+	dsp := datastore.Provider(ctx)
+	devices, err := dsp.SelectDevices(datastore.SelectDevicesOptionFilterByUserID(req.UserID))
+	if err != nil {
+		return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	for _, device := range devices {
+		nRes := <-notification.Provider(ctx).DeleteEndpoint(device.NotificationDeviceID)
+		if nRes.Error != nil {
+			return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(nRes.Error))
+		}
+	}
+
+	// The files are deleted one by one with their records, so that the erasure can be retried when it fails halfway
+	assets, err := selectUserAssets(ctx, req.UserID)
+	if err != nil {
+		return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	for _, asset := range assets {
+		err = storage.Provider(ctx).Delete(&storage.AssetInfo{
+			Filename: fmt.Sprintf("%s.%s", asset.AssetID, asset.Extension),
+		})
+		if err != nil {
+			return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(err))
+		}
+
+		err = dsp.DeleteAssets(datastore.DeleteAssetsOptionFilterByAssetIDs([]string{asset.AssetID}))
+		if err != nil {
+			return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(err))
+		}
+	}
+
+	user.DeletedTimestamp = time.Now().Unix()
+	err = dsp.EraseUser(user, datastore.EraseUserOptionDeleteMessages(req.Erasure == model.UserErasureDelete))
+	if err != nil {
+		return model.NewErrorResponse("Failed to erase user.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	writeAuditLog(ctx, model.AuditActionEraseUser, model.AuditTargetTypeUser, req.UserID, nil, map[string]interface{}{"erase": req.Erasure})
+
+	go unsubscribeByUserID(ctx, req.UserID)
+
+	return nil
+}
+
+func selectUserAssets(ctx context.Context, userID string) ([]*model.Asset, error) {
+	assets := make([]*model.Asset, 0)
+	for offset := int32(0); ; offset += exportPageSize {
+		page, err := datastore.Provider(ctx).SelectAssets(exportPageSize, offset, datastore.SelectAssetsOptionFilterByUserID(userID))
+		if err != nil {
+			return nil, err
+		}
+		assets = append(assets, page...)
+		if int32(len(page)) < exportPageSize {
+			break
+		}
+	}
+	return assets, nil
+}
+
 // RetrieveUserRooms retrieves user rooms
 func RetrieveUserRooms(ctx context.Context, req *model.RetrieveUserRoomsRequest) (*model.UserRoomsResponse, *model.ErrorResponse) {
 	span := tracer.StartSpan(ctx, "RetrieveUserRooms", "service")
 	defer tracer.Finish(span)
 
+	errRes := req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	// The previews are in the language of the user, and the user who doesn't exist has no rooms
+	user, err := datastore.Provider(ctx).SelectUser(req.UserID)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve user rooms.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if user == nil {
+		user = &model.User{}
+	}
+
+	opts := []datastore.SelectMiniRoomsOption{
+		datastore.SelectMiniRoomsOptionFilter(req.Filter),
+		datastore.SelectMiniRoomsOptionIncludeArchived(req.IncludeArchived),
+		datastore.SelectMiniRoomsOptionIncludeHidden(req.IncludeHidden),
+	}
+	if req.Favorite != nil {
+		opts = append(opts, datastore.SelectMiniRoomsOptionFilterByFavorite(*req.Favorite))
+	}
+	if req.Muted != nil {
+		opts = append(opts, datastore.SelectMiniRoomsOptionFilterByMuted(*req.Muted))
+	}
+
 	miniRooms, err := datastore.Provider(ctx).SelectMiniRooms(
 		req.Limit,
 		req.Offset,
 		req.UserID,
-		datastore.SelectMiniRoomsOptionWithOrders(req.Orders),
-		datastore.SelectMiniRoomsOptionFilter(req.Filter),
+		append(
+			opts,
+			datastore.SelectMiniRoomsOptionWithOrders(req.Orders),
+			datastore.SelectMiniRoomsOptionWithSort(req.Sort),
+		)...,
 	)
 	if err != nil {
 		return nil, model.NewErrorResponse("Failed to retrieve user rooms.", http.StatusInternalServerError, model.WithError(err))
 	}
 
-	allCount, err := datastore.Provider(ctx).SelectCountMiniRooms(
-		req.UserID,
-		datastore.SelectMiniRoomsOptionFilter(req.Filter),
-	)
+	allCount, err := datastore.Provider(ctx).SelectCountMiniRooms(req.UserID, opts...)
 	if err != nil {
 		return nil, model.NewErrorResponse("Failed to retrieve user rooms.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	// The language of the request is used for the user who doesn't set the language
+	lang := user.Lang
+	if lang == "" {
+		lang, _ = ctx.Value(config.CtxLang).(string)
+	}
+	for _, miniRoom := range miniRooms {
+		miniRoom.Lang = lang
+	}
+
 	res := &model.UserRoomsResponse{}
 	res.Rooms = miniRooms
 	res.AllCount = allCount
+	res.UnreadCount = user.UnreadCount
 	res.Limit = req.Limit
 	res.Offset = req.Offset
 	res.Filter = req.Filter
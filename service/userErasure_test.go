@@ -0,0 +1,208 @@
+package service
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+	scpb "github.com/swagchat/protobuf/protoc-gen-go"
+)
+
+const (
+	TestServiceSetUpUserErasure     = "[service] set up user erasure"
+	TestServiceRetrieveUserData     = "[service] retrieve user data test"
+	TestServiceEraseUser            = "[service] erase user test"
+	TestServiceTearDownUserErasure  = "[service] tear down user erasure"
+	testServiceErasureUserID        = "erasure-service-user-id-0001"
+	testServiceErasureDeleteUserID  = "erasure-service-user-id-0002"
+	testServiceErasureMemberUserID  = "erasure-service-user-id-0003"
+	testServiceErasureRoomID        = "erasure-service-room-id-0001"
+	testServiceErasureMessageID     = "erasure-service-message-id-0001"
+	testServiceErasureDeleteMessage = "erasure-service-message-id-0002"
+	testServiceErasureAssetID       = "erasure-service-asset-id-0001"
+)
+
+func TestUserErasure(t *testing.T) {
+	t.Run(TestServiceSetUpUserErasure, func(t *testing.T) {
+		nowTimestamp := time.Now().Unix()
+
+		for _, userID := range []string{testServiceErasureUserID, testServiceErasureDeleteUserID, testServiceErasureMemberUserID} {
+			newUser := &model.User{}
+			newUser.UserID = userID
+			newUser.MetaData = []byte(`{"key":"value"}`)
+			newUser.CreatedTimestamp = nowTimestamp
+			newUser.ModifiedTimestamp = nowTimestamp
+			err := datastore.Provider(ctx).InsertUser(newUser)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpUserErasure, err.Error())
+			}
+		}
+
+		newRoom := &model.Room{}
+		newRoom.RoomID = testServiceErasureRoomID
+		newRoom.UserID = testServiceErasureUserID
+		newRoom.Type = scpb.RoomType_PublicRoom
+		newRoom.MetaData = []byte(`{"key":"value"}`)
+		newRoom.CreatedTimestamp = nowTimestamp
+		newRoom.ModifiedTimestamp = nowTimestamp
+		err := datastore.Provider(ctx).InsertRoom(newRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpUserErasure, err.Error())
+		}
+
+		for _, userID := range []string{testServiceErasureUserID, testServiceErasureMemberUserID} {
+			newRoomUser := &model.RoomUser{}
+			newRoomUser.RoomID = testServiceErasureRoomID
+			newRoomUser.UserID = userID
+			newRoomUser.Display = true
+			err = datastore.Provider(ctx).InsertRoomUsers([]*model.RoomUser{newRoomUser})
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpUserErasure, err.Error())
+			}
+		}
+
+		// The message of the user erased with its messages is the last one and mentions the member
+		for i, messageID := range []string{testServiceErasureMessageID, testServiceErasureDeleteMessage} {
+			newMessage := &model.Message{}
+			newMessage.MessageID = messageID
+			newMessage.RoomID = testServiceErasureRoomID
+			newMessage.UserID = testServiceErasureUserID
+			newMessage.Type = model.MessageTypeText
+			newMessage.Payload = []byte(`{"text":"hello"}`)
+			if messageID == testServiceErasureDeleteMessage {
+				newMessage.UserID = testServiceErasureDeleteUserID
+				newMessage.Mentions = []string{testServiceErasureMemberUserID}
+			}
+			newMessage.CreatedTimestamp = nowTimestamp + int64(i)
+			newMessage.ModifiedTimestamp = nowTimestamp + int64(i)
+			err = datastore.Provider(ctx).InsertMessage(newMessage)
+			if err != nil {
+				t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpUserErasure, err.Error())
+			}
+		}
+
+		newAsset := &model.Asset{}
+		newAsset.AssetID = testServiceErasureAssetID
+		newAsset.UserID = testServiceErasureUserID
+		newAsset.Extension = "png"
+		newAsset.Mime = "image/png"
+		newAsset.Created = nowTimestamp
+		newAsset.Modified = nowTimestamp
+		err = datastore.Provider(ctx).InsertAsset(newAsset)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceSetUpUserErasure, err.Error())
+		}
+	})
+
+	t.Run(TestServiceRetrieveUserData, func(t *testing.T) {
+		res, errRes := RetrieveUserData(ctx, &model.RetrieveUserDataRequest{UserID: testServiceErasureUserID})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveUserData, errRes.Message)
+		}
+		if res.User == nil || res.User.UserID != testServiceErasureUserID {
+			t.Fatalf("Failed to %s. Expected user to be %s", TestServiceRetrieveUserData, testServiceErasureUserID)
+		}
+		if len(res.RoomUsers) != 1 || len(res.Messages) != 1 || len(res.Assets) != 1 {
+			t.Fatalf("Failed to %s. Expected a room user, a message and an asset, but got %d, %d and %d", TestServiceRetrieveUserData, len(res.RoomUsers), len(res.Messages), len(res.Assets))
+		}
+
+		_, errRes = RetrieveUserData(ctx, &model.RetrieveUserDataRequest{UserID: "not-exist-user"})
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status of not existing user to be %d", TestServiceRetrieveUserData, http.StatusNotFound)
+		}
+	})
+
+	t.Run(TestServiceEraseUser, func(t *testing.T) {
+		errRes := EraseUser(ctx, &model.EraseUserRequest{UserID: testServiceErasureUserID, Erasure: "unknown"})
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status of unknown erasure to be %d", TestServiceEraseUser, http.StatusBadRequest)
+		}
+
+		errRes = EraseUser(ctx, &model.EraseUserRequest{UserID: testServiceErasureUserID, Erasure: model.UserErasureAnonymize})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, errRes.Message)
+		}
+
+		user, err := datastore.Provider(ctx).SelectUser(testServiceErasureUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if user != nil {
+			t.Fatalf("Failed to %s. Expected user to be erased", TestServiceEraseUser)
+		}
+		message, err := datastore.Provider(ctx).SelectMessage(testServiceErasureMessageID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if message == nil || message.UserID != model.ErasedUserID {
+			t.Fatalf("Failed to %s. Expected message to be attributed to %s", TestServiceEraseUser, model.ErasedUserID)
+		}
+		if string(message.Payload) != model.ErasedMessagePayload {
+			t.Fatalf("Failed to %s. Expected payload of message to be erased, but it was %s", TestServiceEraseUser, string(message.Payload))
+		}
+		roomUser, err := datastore.Provider(ctx).SelectRoomUser(testServiceErasureRoomID, testServiceErasureUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if roomUser != nil {
+			t.Fatalf("Failed to %s. Expected room user to be erased", TestServiceEraseUser)
+		}
+		asset, err := datastore.Provider(ctx).SelectAsset(testServiceErasureAssetID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if asset != nil {
+			t.Fatalf("Failed to %s. Expected asset to be erased", TestServiceEraseUser)
+		}
+
+		errRes = EraseUser(ctx, &model.EraseUserRequest{UserID: testServiceErasureDeleteUserID, Erasure: model.UserErasureDelete})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, errRes.Message)
+		}
+		message, err = datastore.Provider(ctx).SelectMessage(testServiceErasureDeleteMessage)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if message != nil {
+			t.Fatalf("Failed to %s. Expected message to be deleted", TestServiceEraseUser)
+		}
+		room, err := datastore.Provider(ctx).SelectRoom(testServiceErasureRoomID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if room.LastMessageID != testServiceErasureMessageID {
+			t.Fatalf("Failed to %s. Expected last message of room to be %s, but it was %s", TestServiceEraseUser, testServiceErasureMessageID, room.LastMessageID)
+		}
+		roomUser, err = datastore.Provider(ctx).SelectRoomUser(testServiceErasureRoomID, testServiceErasureMemberUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if roomUser.UnreadCount != 1 || roomUser.MentionCount != 0 {
+			t.Fatalf("Failed to %s. Expected unread count and mention count of member to be 1 and 0, but they were %d and %d", TestServiceEraseUser, roomUser.UnreadCount, roomUser.MentionCount)
+		}
+		member, err := datastore.Provider(ctx).SelectUser(testServiceErasureMemberUserID)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceEraseUser, err.Error())
+		}
+		if member.UnreadCount != 1 {
+			t.Fatalf("Failed to %s. Expected unread count of member to be 1, but it was %d", TestServiceEraseUser, member.UnreadCount)
+		}
+	})
+
+	t.Run(TestServiceTearDownUserErasure, func(t *testing.T) {
+		err := datastore.Provider(ctx).DeleteMessages(datastore.DeleteMessagesOptionFilterByMessageIDs([]string{testServiceErasureMessageID}))
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownUserErasure, err.Error())
+		}
+
+		deleteRoom := &model.Room{}
+		deleteRoom.RoomID = testServiceErasureRoomID
+		deleteRoom.DeletedTimestamp = 1
+		err = datastore.Provider(ctx).UpdateRoom(deleteRoom)
+		if err != nil {
+			t.Fatalf("Failed to %s. Expected err to be nil, but it was not nil [%s]", TestServiceTearDownUserErasure, err.Error())
+		}
+	})
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/swagchat/chat-api/datastore"
 	"github.com/swagchat/chat-api/model"
+	"github.com/swagchat/chat-api/utils"
 	"github.com/betchi/tracer"
 )
 
@@ -14,7 +15,7 @@ func AddUserRoles(ctx context.Context, req *model.AddUserRolesRequest) *model.Er
 	span := tracer.StartSpan(ctx, "AddUserRoles", "service")
 	defer tracer.Finish(span)
 
-	_, errRes := confirmUserExist(ctx, req.UserID)
+	user, errRes := confirmUserExist(ctx, req.UserID, datastore.SelectUserOptionWithRoles(true))
 	if errRes != nil {
 		errRes.Message = "Failed to create user roles."
 		return errRes
@@ -26,6 +27,20 @@ func AddUserRoles(ctx context.Context, req *model.AddUserRolesRequest) *model.Er
 		return model.NewErrorResponse("Failed to create user roles.", http.StatusInternalServerError, model.WithError(err))
 	}
 
+	beforeRoles := append([]int32{}, user.Roles...)
+	afterRoles := append([]int32{}, user.Roles...)
+	for _, ur := range urs {
+		afterRoles = append(afterRoles, ur.Role)
+	}
+	writeAuditLog(
+		ctx,
+		model.AuditActionAddUserRoles,
+		model.AuditTargetTypeUser,
+		req.UserID,
+		map[string][]int32{"roles": beforeRoles},
+		map[string][]int32{"roles": utils.RemoveDuplicateInt32(afterRoles)},
+	)
+
 	return nil
 }
 
@@ -18,7 +18,9 @@ import (
 	"google.golang.org/grpc/metadata"
 )
 
-func webhookRoom(ctx context.Context, room *model.Room) {
+// webhookRoom calls the room webhooks. eventID is sent as the deduplication ID.
+// Every webhook is called even if some of them fail, and the last error is returned to retry the event.
+func webhookRoom(ctx context.Context, room *model.Room, eventID string) error {
 	span := tracer.StartSpan(ctx, "webhookRoom", "service")
 	defer tracer.Finish(span)
 
@@ -28,17 +30,18 @@ func webhookRoom(ctx context.Context, room *model.Room) {
 	)
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
 
 	if len(webhooks) == 0 {
-		return
+		return nil
 	}
 
 	pbRoom := &scpb.Room{
 		RoomID: room.RoomID,
 	}
 
+	var lastErr error
 	for _, webhook := range webhooks {
 		switch webhook.Protocol {
 		case model.WebhookProtocolHTTP:
@@ -46,22 +49,30 @@ func webhookRoom(ctx context.Context, room *model.Room) {
 			buf := new(bytes.Buffer)
 			json.NewEncoder(buf).Encode(pbRoom)
 
-			resp, err := http.Post(
-				webhook.Endpoint,
-				"application/json",
-				buf,
-			)
+			req, err := http.NewRequest("POST", webhook.Endpoint, buf)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[HTTP][WebhookRoom]Create request failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
+				continue
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(config.HeaderEventID, eventID)
+
+			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookRoom]Post failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			_, err = ioutil.ReadAll(resp.Body)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookRoom]Response body read failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			if resp.StatusCode != http.StatusOK {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookRoom]Status code is not 200. Endpoint=[%s] StatusCode[%d].", webhook.Endpoint, resp.StatusCode))
+				lastErr = fmt.Errorf("http status code[%d]", resp.StatusCode)
 				continue
 			}
 			logger.Info(fmt.Sprintf("[HTTP][WebhookRoom]Finish Webhook. Endpoint=[%s]", webhook.Endpoint))
@@ -70,6 +81,7 @@ func webhookRoom(ctx context.Context, room *model.Room) {
 			conn, err := grpc.Dial(webhook.Endpoint, grpc.WithInsecure())
 			if err != nil {
 				logger.Error(fmt.Sprintf("[GRPC][WebhookRoom] Connect failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			defer conn.Close()
@@ -78,19 +90,27 @@ func webhookRoom(ctx context.Context, room *model.Room) {
 
 			grpcCtx := metadata.NewOutgoingContext(
 				context.Background(),
-				metadata.Pairs(config.HeaderWorkspace, ctx.Value(config.CtxWorkspace).(string)),
+				metadata.Pairs(
+					config.HeaderWorkspace, ctx.Value(config.CtxWorkspace).(string),
+					config.HeaderEventID, eventID,
+				),
 			)
 			_, err = c.RoomCreationEvent(grpcCtx, pbRoom)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[GRPC][WebhookRoom]Response body read failure. GRPC Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			logger.Info(fmt.Sprintf("[GRPC][WebhookRoom]Finish Webhook. Endpoint=[%s]", webhook.Endpoint))
 		}
 	}
+
+	return lastErr
 }
 
-func webhookMessage(ctx context.Context, message *model.Message, user *model.User) {
+// webhookMessage calls the message webhooks which match the roles of the user. eventID is sent as the deduplication ID.
+// Every webhook is called even if some of them fail, and the last error is returned to retry the event.
+func webhookMessage(ctx context.Context, message *model.Message, user *model.User, eventID string) error {
 	span := tracer.StartSpan(ctx, "webhookMessage", "service")
 	defer tracer.Finish(span)
 
@@ -99,7 +119,7 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 	)
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
 
 	webhooks, err := datastore.Provider(ctx).SelectWebhooks(
@@ -108,17 +128,17 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 	)
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
 
 	if len(webhooks) == 0 {
-		return
+		return nil
 	}
 
 	payload, err := message.Payload.MarshalJSON()
 	if err != nil {
 		logger.Error(err.Error())
-		return
+		return err
 	}
 
 	pbMessage := &scpb.Message{
@@ -129,6 +149,7 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 		UserIDs: userIDs,
 	}
 
+	var lastErr error
 	for _, webhook := range webhooks {
 		matchRole := false
 		for _, v := range user.Roles {
@@ -147,22 +168,30 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 			buf := new(bytes.Buffer)
 			json.NewEncoder(buf).Encode(pbMessage)
 
-			resp, err := http.Post(
-				webhook.Endpoint,
-				"application/json",
-				buf,
-			)
+			req, err := http.NewRequest("POST", webhook.Endpoint, buf)
+			if err != nil {
+				logger.Error(fmt.Sprintf("[HTTP][WebhookMessage]Create request failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
+				continue
+			}
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set(config.HeaderEventID, eventID)
+
+			resp, err := http.DefaultClient.Do(req)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookMessage]Post failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			_, err = ioutil.ReadAll(resp.Body)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookMessage]Response body read failure. Endpoint=[%s]. %v.", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			if resp.StatusCode != http.StatusOK {
 				logger.Error(fmt.Sprintf("[HTTP][WebhookMessage]Status code is not 200. Endpoint=[%s] StatusCode[%d]", webhook.Endpoint, resp.StatusCode))
+				lastErr = fmt.Errorf("http status code[%d]", resp.StatusCode)
 				continue
 			}
 			logger.Info(fmt.Sprintf("[HTTP][WebhookMessage]Finish Webhook. Endpoint=[%s]", webhook.Endpoint))
@@ -171,6 +200,7 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 			conn, err := grpc.Dial(webhook.Endpoint, grpc.WithInsecure())
 			if err != nil {
 				logger.Error(fmt.Sprintf("[GRPC][WebhookMessage]Connect failure. Endpoint=[%s]. %v", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			defer conn.Close()
@@ -178,14 +208,20 @@ func webhookMessage(ctx context.Context, message *model.Message, user *model.Use
 			c := scpb.NewWebhookClient(conn)
 			grpcCtx := metadata.NewOutgoingContext(
 				context.Background(),
-				metadata.Pairs(config.HeaderWorkspace, ctx.Value(config.CtxWorkspace).(string)),
+				metadata.Pairs(
+					config.HeaderWorkspace, ctx.Value(config.CtxWorkspace).(string),
+					config.HeaderEventID, eventID,
+				),
 			)
 			_, err = c.MessageSendEvent(grpcCtx, pbMessage)
 			if err != nil {
 				logger.Error(fmt.Sprintf("[GRPC][WebhookMessage] Response body read failure. GRPC Endpoint=[%s]. %v", webhook.Endpoint, err))
+				lastErr = err
 				continue
 			}
 			logger.Info(fmt.Sprintf("[GRPC][WebhookMessage]Finish Webhook. Endpoint=[%s]", webhook.Endpoint))
 		}
 	}
+
+	return lastErr
 }
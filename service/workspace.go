@@ -0,0 +1,314 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/betchi/tracer"
+	logger "github.com/betchi/zapper"
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+// confirmedWorkspaces caches the time until which the registered workspaces are not looked up again
+var confirmedWorkspaces sync.Map
+
+// controlContext returns the context of the database configured by datastore.database.
+// In dynamic mode, it holds the registry of workspaces.
+func controlContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, config.CtxWorkspace, config.Config().Datastore.Database)
+}
+
+func workspaceContext(ctx context.Context, workspace string) context.Context {
+	return context.WithValue(ctx, config.CtxWorkspace, workspace)
+}
+
+func confirmDynamicDatastore() *model.ErrorResponse {
+	if !config.Config().Datastore.Dynamic {
+		return model.NewErrorResponse("Workspaces are available only when datastore.dynamic is enabled.", http.StatusBadRequest)
+	}
+	return nil
+}
+
+// CreateWorkspace creates the database of the workspace, migrates its schema and registers it.
+// It fails if the database already exists not to adopt the database of another application.
+// The workspace is returned with the app client generated for it, which is the only way to get its client id.
+func CreateWorkspace(ctx context.Context, req *model.CreateWorkspaceRequest) (*model.Workspace, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "CreateWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := confirmDynamicDatastore()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if req.Name == config.Config().Datastore.Database {
+		return nil, model.NewErrorResponse("Failed to create workspace. The name is reserved.", http.StatusBadRequest)
+	}
+
+	cctx := controlContext(ctx)
+	workspace, err := datastore.Provider(cctx).SelectWorkspace(req.Name)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if workspace != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace. The workspace already exists.", http.StatusConflict)
+	}
+
+	wctx := workspaceContext(ctx, req.Name)
+	created, err := datastore.CreateDatabase(wctx)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if !created {
+		return nil, model.NewErrorResponse("Failed to create workspace. The database already exists.", http.StatusConflict)
+	}
+
+	err = datastore.Connect(wctx)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	workspace = req.GenerateWorkspace()
+	workspace.AppClient, err = selectFirstAppClient(wctx)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	err = datastore.Provider(cctx).InsertWorkspace(workspace)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to create workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return workspace, nil
+}
+
+// RegisterWorkspace registers the existing database as the workspace and migrates its schema.
+// It's for the databases used as the workspaces before they were registered.
+// The workspace is returned with its first app client, which is generated unless the database has it.
+func RegisterWorkspace(ctx context.Context, req *model.CreateWorkspaceRequest) (*model.Workspace, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RegisterWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := confirmDynamicDatastore()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	errRes = req.Validate()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	if req.Name == config.Config().Datastore.Database {
+		return nil, model.NewErrorResponse("Failed to register workspace. The name is reserved.", http.StatusBadRequest)
+	}
+
+	cctx := controlContext(ctx)
+	workspace, err := datastore.Provider(cctx).SelectWorkspace(req.Name)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to register workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+	if workspace != nil {
+		return nil, model.NewErrorResponse("Failed to register workspace. The workspace already exists.", http.StatusConflict)
+	}
+
+	wctx := workspaceContext(ctx, req.Name)
+	err = datastore.Connect(wctx)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to register workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	workspace = req.GenerateWorkspace()
+	workspace.AppClient, err = selectFirstAppClient(wctx)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to register workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	err = datastore.Provider(cctx).InsertWorkspace(workspace)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to register workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return workspace, nil
+}
+
+// RetrieveWorkspaces retrieves workspaces
+func RetrieveWorkspaces(ctx context.Context, req *model.RetrieveWorkspacesRequest) (*model.WorkspacesResponse, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveWorkspaces", "service")
+	defer tracer.Finish(span)
+
+	errRes := confirmDynamicDatastore()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	cctx := controlContext(ctx)
+	workspaces, err := datastore.Provider(cctx).SelectWorkspaces(req.Limit, req.Offset)
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve workspaces.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	allCount, err := datastore.Provider(cctx).SelectCountWorkspaces()
+	if err != nil {
+		return nil, model.NewErrorResponse("Failed to retrieve workspaces.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	res := &model.WorkspacesResponse{}
+	res.Workspaces = workspaces
+	res.AllCount = allCount
+	res.Limit = req.Limit
+	res.Offset = req.Offset
+
+	return res, nil
+}
+
+// RetrieveWorkspace retrieves workspace
+func RetrieveWorkspace(ctx context.Context, name string) (*model.Workspace, *model.ErrorResponse) {
+	span := tracer.StartSpan(ctx, "RetrieveWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := confirmDynamicDatastore()
+	if errRes != nil {
+		return nil, errRes
+	}
+
+	workspace, errRes := confirmWorkspaceExist(ctx, name)
+	if errRes != nil {
+		errRes.Message = "Failed to retrieve workspace."
+		return nil, errRes
+	}
+
+	return workspace, nil
+}
+
+// DeleteWorkspace unregisters the workspace and drops its database
+func DeleteWorkspace(ctx context.Context, name string) *model.ErrorResponse {
+	span := tracer.StartSpan(ctx, "DeleteWorkspace", "service")
+	defer tracer.Finish(span)
+
+	errRes := confirmDynamicDatastore()
+	if errRes != nil {
+		return errRes
+	}
+
+	_, errRes = confirmWorkspaceExist(ctx, name)
+	if errRes != nil {
+		errRes.Message = "Failed to delete workspace."
+		return errRes
+	}
+
+	// Unregister first not to accept requests to the workspace being dropped
+	err := datastore.Provider(controlContext(ctx)).DeleteWorkspace(name)
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+	confirmedWorkspaces.Delete(name)
+
+	err = datastore.Provider(workspaceContext(ctx, name)).DropDatabase()
+	if err != nil {
+		return model.NewErrorResponse("Failed to delete workspace.", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+// ConfirmWorkspace confirms that the workspace of the request is registered, and connects to its database.
+// It's required only in dynamic mode because the workspace is used as the database name.
+// The registered workspaces are cached for a while not to look up the registry on every request.
+func ConfirmWorkspace(ctx context.Context, name string) *model.ErrorResponse {
+	if !config.Config().Datastore.Dynamic {
+		return nil
+	}
+
+	errRes := model.ValidateWorkspaceName(name)
+	if errRes != nil {
+		return errRes
+	}
+
+	now := time.Now()
+	if expires, ok := confirmedWorkspaces.Load(name); !ok || now.After(expires.(time.Time)) {
+		_, errRes = confirmWorkspaceExist(ctx, name)
+		if errRes != nil {
+			return errRes
+		}
+		confirmedWorkspaces.Store(name, now.Add(config.WorkspaceCacheExpireSecond*time.Second))
+	}
+
+	err := datastore.Connect(workspaceContext(ctx, name))
+	if err != nil {
+		return model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+
+	return nil
+}
+
+// AuthenticateWorkspace confirms the workspace of the request, and the app client of the workspace if the request has one, in dynamic mode.
+// The requests of the users have no client id, and they are authorized by the handlers as the users.
+// The client id is treated as the admin by the handlers, so the client ids of the other workspaces are rejected.
+// The unregistered workspaces are rejected as unauthorized as well as the unknown app clients,
+// so that the callers can't probe which workspaces exist.
+func AuthenticateWorkspace(ctx context.Context, name, clientID string) *model.ErrorResponse {
+	if !config.Config().Datastore.Dynamic {
+		return nil
+	}
+
+	errRes := ConfirmWorkspace(ctx, name)
+	if errRes != nil {
+		if errRes.Status == http.StatusNotFound {
+			return model.NewErrorResponse("Unauthorized.", http.StatusUnauthorized)
+		}
+		return errRes
+	}
+
+	if clientID == "" {
+		return nil
+	}
+
+	appClient, err := datastore.Provider(workspaceContext(ctx, name)).SelectLatestAppClient(
+		datastore.SelectAppClientOptionFilterByClientID(clientID),
+	)
+	if err != nil {
+		return model.NewErrorResponse("", http.StatusInternalServerError, model.WithError(err))
+	}
+	if appClient == nil {
+		return model.NewErrorResponse("Unauthorized.", http.StatusUnauthorized)
+	}
+
+	return nil
+}
+
+// selectFirstAppClient returns the first app client of the workspace in the context, which is seeded when it's migrated
+func selectFirstAppClient(ctx context.Context) (*model.AppClient, error) {
+	return datastore.Provider(ctx).SelectLatestAppClient(
+		datastore.SelectAppClientOptionFilterByName(config.Config().FirstClientID),
+	)
+}
+
+// registeredWorkspaces returns the names of all registered workspaces
+func registeredWorkspaces(ctx context.Context) []string {
+	cctx := controlContext(ctx)
+	limit := int32(100)
+	var names []string
+	for offset := int32(0); ; offset += limit {
+		workspaces, err := datastore.Provider(cctx).SelectWorkspaces(limit, offset)
+		if err != nil {
+			logger.Error(err.Error())
+			return names
+		}
+		for _, workspace := range workspaces {
+			names = append(names, workspace.Name)
+		}
+		if int32(len(workspaces)) < limit {
+			return names
+		}
+	}
+}
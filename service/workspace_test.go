@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/swagchat/chat-api/config"
+	"github.com/swagchat/chat-api/datastore"
+	"github.com/swagchat/chat-api/model"
+)
+
+const (
+	TestServiceCreateWorkspace       = "[service] create workspace test"
+	TestServiceRetrieveWorkspaces    = "[service] retrieve workspaces test"
+	TestServiceRetrieveWorkspace     = "[service] retrieve workspace test"
+	TestServiceConfirmWorkspace      = "[service] confirm workspace test"
+	TestServiceAuthenticateWorkspace = "[service] authenticate workspace test"
+	TestServiceRegisterWorkspace     = "[service] register workspace test"
+	TestServiceDeleteWorkspace       = "[service] delete workspace test"
+	TestServiceWorkspaceNotDynamic   = "[service] workspace not dynamic test"
+)
+
+func TestWorkspace(t *testing.T) {
+	name := "service_workspace"
+	clientID := ""
+
+	t.Run(TestServiceWorkspaceNotDynamic, func(t *testing.T) {
+		_, errRes := CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: name})
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status to be %d", TestServiceWorkspaceNotDynamic, http.StatusBadRequest)
+		}
+	})
+
+	cfg := config.Config()
+	cfg.Datastore.Dynamic = true
+	defer func() {
+		cfg.Datastore.Dynamic = false
+	}()
+
+	t.Run(TestServiceCreateWorkspace, func(t *testing.T) {
+		_, errRes := CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: "../invalid"})
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status of invalid name to be %d", TestServiceCreateWorkspace, http.StatusBadRequest)
+		}
+
+		_, errRes = CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: "mysql"})
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status of reserved name to be %d", TestServiceCreateWorkspace, http.StatusBadRequest)
+		}
+
+		// The database which is not registered as a workspace is not adopted
+		unregistered := "service_unregistered"
+		uctx := context.WithValue(ctx, config.CtxWorkspace, unregistered)
+		datastore.Provider(uctx)
+		_, errRes = CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: unregistered})
+		datastore.Provider(uctx).DropDatabase()
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected status of existing database to be %d", TestServiceCreateWorkspace, http.StatusConflict)
+		}
+
+		workspace, errRes := CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: name})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceCreateWorkspace, errRes.Message)
+		}
+		if workspace.Name != name {
+			t.Fatalf("Failed to %s. Expected name to be %s, but it was %s", TestServiceCreateWorkspace, name, workspace.Name)
+		}
+
+		if workspace.AppClient == nil {
+			t.Fatalf("Failed to %s. Expected the app client of the workspace to be returned", TestServiceCreateWorkspace)
+		}
+		if workspace.AppClient.ClientID == "" || workspace.AppClient.ClientID == cfg.FirstClientID {
+			t.Fatalf("Failed to %s. Expected the client id of the workspace to be generated, but it was \"%s\"", TestServiceCreateWorkspace, workspace.AppClient.ClientID)
+		}
+		clientID = workspace.AppClient.ClientID
+
+		wctx := context.WithValue(ctx, config.CtxWorkspace, name)
+		appClient, err := datastore.Provider(wctx).SelectLatestAppClient(
+			datastore.SelectAppClientOptionFilterByClientID(clientID),
+		)
+		if err != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceCreateWorkspace, err.Error())
+		}
+		if appClient == nil {
+			t.Fatalf("Failed to %s. Expected first app client to be seeded", TestServiceCreateWorkspace)
+		}
+
+		_, errRes = CreateWorkspace(ctx, &model.CreateWorkspaceRequest{Name: name})
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected status of existing workspace to be %d", TestServiceCreateWorkspace, http.StatusConflict)
+		}
+	})
+
+	t.Run(TestServiceRetrieveWorkspaces, func(t *testing.T) {
+		res, errRes := RetrieveWorkspaces(ctx, &model.RetrieveWorkspacesRequest{Limit: 10})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveWorkspaces, errRes.Message)
+		}
+		if res.AllCount != 1 || len(res.Workspaces) != 1 {
+			t.Fatalf("Failed to %s. Expected workspaces count to be 1, but it was %d", TestServiceRetrieveWorkspaces, res.AllCount)
+		}
+	})
+
+	t.Run(TestServiceRetrieveWorkspace, func(t *testing.T) {
+		_, errRes := RetrieveWorkspace(ctx, name)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRetrieveWorkspace, errRes.Message)
+		}
+
+		_, errRes = RetrieveWorkspace(ctx, "not_exist_workspace")
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status to be %d", TestServiceRetrieveWorkspace, http.StatusNotFound)
+		}
+	})
+
+	t.Run(TestServiceConfirmWorkspace, func(t *testing.T) {
+		errRes := ConfirmWorkspace(ctx, name)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceConfirmWorkspace, errRes.Message)
+		}
+
+		errRes = ConfirmWorkspace(ctx, "")
+		if errRes == nil || errRes.Status != http.StatusBadRequest {
+			t.Fatalf("Failed to %s. Expected status of empty workspace to be %d", TestServiceConfirmWorkspace, http.StatusBadRequest)
+		}
+
+		errRes = ConfirmWorkspace(ctx, "not_exist_workspace")
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status of unregistered workspace to be %d", TestServiceConfirmWorkspace, http.StatusNotFound)
+		}
+	})
+
+	t.Run(TestServiceAuthenticateWorkspace, func(t *testing.T) {
+		errRes := AuthenticateWorkspace(ctx, name, clientID)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceAuthenticateWorkspace, errRes.Message)
+		}
+
+		// The requests of the users have no client id
+		errRes = AuthenticateWorkspace(ctx, name, "")
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceAuthenticateWorkspace, errRes.Message)
+		}
+
+		// The client id of the admin is not shared with the workspaces
+		errRes = AuthenticateWorkspace(ctx, name, cfg.FirstClientID)
+		if errRes == nil || errRes.Status != http.StatusUnauthorized {
+			t.Fatalf("Failed to %s. Expected status of the client id of the admin to be %d", TestServiceAuthenticateWorkspace, http.StatusUnauthorized)
+		}
+
+		errRes = AuthenticateWorkspace(ctx, name, "not-exist-client-id")
+		if errRes == nil || errRes.Status != http.StatusUnauthorized {
+			t.Fatalf("Failed to %s. Expected status of unknown client id to be %d", TestServiceAuthenticateWorkspace, http.StatusUnauthorized)
+		}
+
+		// Unregistered workspaces are not distinguished from unknown client ids
+		errRes = AuthenticateWorkspace(ctx, "not_exist_workspace", clientID)
+		if errRes == nil || errRes.Status != http.StatusUnauthorized {
+			t.Fatalf("Failed to %s. Expected status of unregistered workspace to be %d", TestServiceAuthenticateWorkspace, http.StatusUnauthorized)
+		}
+
+		errRes = AuthenticateWorkspace(ctx, "not_exist_workspace", "")
+		if errRes == nil || errRes.Status != http.StatusUnauthorized {
+			t.Fatalf("Failed to %s. Expected status of unregistered workspace without client id to be %d", TestServiceAuthenticateWorkspace, http.StatusUnauthorized)
+		}
+	})
+
+	t.Run(TestServiceRegisterWorkspace, func(t *testing.T) {
+		unregistered := "service_registered"
+		uctx := context.WithValue(ctx, config.CtxWorkspace, unregistered)
+		datastore.Provider(uctx)
+		defer datastore.Provider(uctx).DropDatabase()
+
+		errRes := ConfirmWorkspace(ctx, unregistered)
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status of unregistered database to be %d", TestServiceRegisterWorkspace, http.StatusNotFound)
+		}
+
+		_, errRes = RegisterWorkspace(ctx, &model.CreateWorkspaceRequest{Name: unregistered})
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRegisterWorkspace, errRes.Message)
+		}
+		defer datastore.Provider(controlContext(ctx)).DeleteWorkspace(unregistered)
+
+		errRes = ConfirmWorkspace(ctx, unregistered)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceRegisterWorkspace, errRes.Message)
+		}
+
+		_, errRes = RegisterWorkspace(ctx, &model.CreateWorkspaceRequest{Name: unregistered})
+		if errRes == nil || errRes.Status != http.StatusConflict {
+			t.Fatalf("Failed to %s. Expected status of registered workspace to be %d", TestServiceRegisterWorkspace, http.StatusConflict)
+		}
+	})
+
+	t.Run(TestServiceDeleteWorkspace, func(t *testing.T) {
+		errRes := DeleteWorkspace(ctx, name)
+		if errRes != nil {
+			t.Fatalf("Failed to %s. %s", TestServiceDeleteWorkspace, errRes.Message)
+		}
+
+		errRes = ConfirmWorkspace(ctx, name)
+		if errRes == nil || errRes.Status != http.StatusNotFound {
+			t.Fatalf("Failed to %s. Expected status of deleted workspace to be %d", TestServiceDeleteWorkspace, http.StatusNotFound)
+		}
+	})
+}
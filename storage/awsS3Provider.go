@@ -104,6 +104,31 @@ func (ap *awss3Provider) Get(assetInfo *AssetInfo) ([]byte, error) {
 	return nil, nil
 }
 
+func (ap *awss3Provider) Delete(assetInfo *AssetInfo) error {
+	span := tracer.StartSpan(ap.ctx, "Delete", "storage")
+	defer tracer.Finish(span)
+
+	awsS3Client, err := ap.getSession()
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	filePath := fmt.Sprintf("%s/%s", ap.uploadDirectory, assetInfo.Filename)
+	_, err = awsS3Client.DeleteObject(&s3.DeleteObjectInput{
+		Bucket: aws.String(ap.uploadBucket),
+		Key:    aws.String(filePath),
+	})
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
+
 func (ap *awss3Provider) getSession() (*s3.S3, error) {
 	sess, err := session.NewSession(&aws.Config{
 		Region:      aws.String(ap.region),
@@ -93,3 +93,18 @@ func (gp *gcsProvider) Get(assetInfo *AssetInfo) ([]byte, error) {
 
 	return nil, nil
 }
+
+func (gp *gcsProvider) Delete(assetInfo *AssetInfo) error {
+	span := tracer.StartSpan(gp.ctx, "Delete", "storage")
+	defer tracer.Finish(span)
+
+	filePath := fmt.Sprintf("%s/%s", gp.uploadDirectory, assetInfo.Filename)
+	err := gcsService.Objects.Delete(gp.uploadBucket, filePath).Do()
+	if err != nil {
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
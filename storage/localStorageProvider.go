@@ -74,3 +74,18 @@ func (lp *localStorageProvider) Get(assetInfo *AssetInfo) ([]byte, error) {
 
 	return bytes, nil
 }
+
+func (lp *localStorageProvider) Delete(assetInfo *AssetInfo) error {
+	span := tracer.StartSpan(lp.ctx, "Delete", "storage")
+	defer tracer.Finish(span)
+
+	err := os.Remove(fmt.Sprintf("%s/%s", lp.localPath, assetInfo.Filename))
+	if err != nil && !os.IsNotExist(err) {
+		err = errors.Wrap(err, fmt.Sprintf("Failed to remove file. path=%s/%s", lp.localPath, assetInfo.Filename))
+		logger.Error(err.Error())
+		tracer.SetError(span, err)
+		return err
+	}
+
+	return nil
+}
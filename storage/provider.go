@@ -16,6 +16,7 @@ type provider interface {
 	Init() error
 	Post(*AssetInfo) (string, error)
 	Get(*AssetInfo) ([]byte, error)
+	Delete(*AssetInfo) error
 }
 
 func Provider(ctx context.Context) provider {